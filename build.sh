@@ -38,6 +38,8 @@ fi
 
 GO_VERSION=$(go version | awk '{print $3}')
 echo -e "${GREEN}✓${NC} Go version: ${GO_VERSION}"
+# The SQLite driver needs cgo, which cross builds have no C compiler for
+echo -e "${YELLOW}!${NC} Building without cgo: --storage-backend sqlite is unavailable in these binaries"
 echo ""
 
 # Create build directory
@@ -52,7 +54,7 @@ for PLATFORM in "${PLATFORMS[@]}"; do
     echo -e "${YELLOW}→${NC} Building for ${GOOS}/${GOARCH}..."
 
     # Build with optimizations
-    if GOOS=$GOOS GOARCH=$GOARCH go build \
+    if CGO_ENABLED=0 GOOS=$GOOS GOARCH=$GOARCH go build \
         -ldflags="-s -w" \
         -o "$OUTPUT_PATH" \
         "$CMD_PATH"; then
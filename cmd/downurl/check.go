@@ -0,0 +1,159 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lcalzada-xor/downurl/internal/config"
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/notify"
+	"github.com/lcalzada-xor/downurl/internal/output"
+	"github.com/lcalzada-xor/downurl/internal/parser"
+	"github.com/lcalzada-xor/downurl/internal/scanner"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/internal/ui"
+)
+
+// errCheckFailed is returned by runCheck when it found problems
+var errCheckFailed = errors.New("check failed")
+
+// runCheck implements "downurl check": it validates the .downurlrc, the
+// options and every input URL without downloading anything, listing all
+// problems rather than stopping at the first. stdin is read when no input
+// file is given; nil means nothing was piped in.
+func runCheck(cfg *config.Config, stdin io.Reader, w io.Writer) error {
+	problems := 0
+	section := func(name string, issues []string, okNote string) {
+		problems += len(issues)
+		if len(issues) == 0 {
+			fmt.Fprintf(w, "%s %s: %s\n", ui.Colorize("✓", ui.ColorGreen), name, okNote)
+			return
+		}
+		fmt.Fprintf(w, "%s %s: %d problem(s)\n", ui.Colorize("✗", ui.ColorRed), name, len(issues))
+		for _, issue := range issues {
+			fmt.Fprintf(w, "   %s\n", issue)
+		}
+	}
+
+	// Configuration file
+	if rc := config.FindConfigFile(); rc != "" {
+		issues, err := config.CheckConfigFile(rc)
+		if err != nil {
+			issues = []string{err.Error()}
+		}
+		section(rc, issues, "ok")
+	}
+
+	// Options, including the ones runDownload parses before downloading
+	var issues []string
+	if err := cfg.Validate(); err != nil && err != config.ErrMissingInputFile {
+		issues = append(issues, err.Error())
+	}
+	if _, err := output.ParseFormats(cfg.OutputFormat); err != nil {
+		issues = append(issues, err.Error())
+	}
+	if _, err := scanner.ParseEndpointScope(cfg.EndpointsScope); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --endpoints-scope: %v", err))
+	}
+	if _, err := scanner.ParseConfidence(cfg.SecretsMinConf); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --secrets-min-confidence: %v", err))
+	}
+	if cfg.SecretsRules != "" {
+		if _, err := scanner.LoadSecretRules(cfg.SecretsRules); err != nil {
+			issues = append(issues, fmt.Sprintf("invalid --secrets-rules: %v", err))
+		}
+	}
+	if _, err := storage.ParseCollisionFormat(cfg.CollisionFormat); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --collision-format: %v", err))
+	}
+	if _, err := storage.ParseArchiveFormat(cfg.ArchiveFormat); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --archive-format: %v", err))
+	}
+	if _, err := storage.ParseDedupeMode(cfg.DedupeMode); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --dedupe-mode: %v", err))
+	}
+	if cfg.WebhookURL != "" {
+		if _, err := notify.New(cfg.WebhookURL, cfg.Timeout); err != nil {
+			issues = append(issues, fmt.Sprintf("invalid --webhook-url: %v", err))
+		}
+	}
+	if _, err := storage.ParseHostDirs(cfg.HostOutput); err != nil {
+		issues = append(issues, fmt.Sprintf("invalid --host-output: %v", err))
+	}
+	if _, err := parseTLSOptions(cfg); err != nil {
+		issues = append(issues, err.Error())
+	}
+	if cfg.VerifyHashes != "" {
+		if _, err := downloader.LoadExpectedHashes(cfg.VerifyHashes); err != nil {
+			issues = append(issues, fmt.Sprintf("invalid --verify-hashes: %v", err))
+		}
+	}
+	if err := nucleiTemplate(cfg).Validate(); err != nil {
+		issues = append(issues, err.Error())
+	}
+	section("options", issues, "ok")
+
+	// URLs from the input file or stdin, then from arguments
+	valid := 0
+	var input io.Reader
+	source := cfg.InputFile
+	if source != "" {
+		file, err := os.Open(source)
+		if err != nil {
+			section(source, []string{err.Error()}, "")
+		} else {
+			defer file.Close()
+			input = file
+		}
+	} else if stdin != nil {
+		input, source = stdin, "stdin"
+	}
+	if input != nil {
+		var entries []parser.Entry
+		var invalid parser.ParseErrors
+		var err error
+		// Stdin has no extension, so it is always read as text
+		switch parser.InputFormat(cfg.InputFile) {
+		case parser.FormatJSON:
+			entries, invalid, err = parser.CheckJSON(input, source)
+		case parser.FormatCSV:
+			entries, invalid, err = parser.CheckCSV(input, source, cfg.CSVColumn)
+		default:
+			entries, invalid, err = parser.CheckEntries(input, source)
+		}
+		issues = nil
+		if err != nil {
+			issues = append(issues, err.Error())
+		}
+		for _, le := range invalid {
+			issues = append(issues, le.Error())
+		}
+		valid += len(entries)
+		section(source, issues, fmt.Sprintf("%d valid URL(s)", len(entries)))
+	}
+
+	if len(cfg.URLArgs) > 0 {
+		issues = nil
+		for i, arg := range cfg.URLArgs {
+			if _, err := parser.ParseSingleURL(arg); err != nil {
+				issues = append(issues, fmt.Sprintf("argument %d: %v", i+1, err))
+			} else {
+				valid++
+			}
+		}
+		section("arguments", issues, fmt.Sprintf("%d valid URL(s)", len(cfg.URLArgs)))
+	}
+
+	if valid == 0 && problems == 0 {
+		section("input", []string{"no URLs to download (give --input, URLs or pipe them in)"}, "")
+	}
+
+	if problems > 0 {
+		fmt.Fprintf(w, "\n%s\n", ui.Colorize(fmt.Sprintf("FAIL: %d problem(s)", problems), ui.ColorRed))
+		return fmt.Errorf("%w: %d problem(s)", errCheckFailed, problems)
+	}
+	fmt.Fprintf(w, "\n%s\n", ui.Colorize(fmt.Sprintf("PASS: %d URL(s) ready to download", valid), ui.ColorGreen))
+	return nil
+}
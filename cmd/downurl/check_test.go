@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/internal/config"
+)
+
+func TestRunCheck(t *testing.T) {
+	// Keep a ~/.downurlrc on the test machine out of the check
+	t.Setenv("HOME", t.TempDir())
+
+	input := filepath.Join(t.TempDir(), "urls.txt")
+	content := "https://example.com/a.js\nftp://example.com/b.zip\nhttps://example.com/c.js\nhttp:///no-host\n"
+	if err := os.WriteFile(input, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	cfg := newRunConfig(t.TempDir())
+	cfg.InputFile = input
+	var out bytes.Buffer
+	err := runCheck(cfg, nil, &out)
+	if !errors.Is(err, errCheckFailed) {
+		t.Fatalf("runCheck() error = %v, want errCheckFailed", err)
+	}
+	for _, want := range []string{"line 2: invalid URL scheme", "line 4: invalid URL (missing host)", "FAIL: 2 problem(s)"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("runCheck() output lacks %q:\n%s", want, out.String())
+		}
+	}
+
+	// Valid URLs from stdin pass
+	cfg = newRunConfig(t.TempDir())
+	out.Reset()
+	if err := runCheck(cfg, strings.NewReader("https://example.com/a.js\n"), &out); err != nil {
+		t.Errorf("runCheck() error = %v, want nil\n%s", err, out.String())
+	}
+	if !strings.Contains(out.String(), "PASS: 1 URL(s)") {
+		t.Errorf("runCheck() output = %s, want PASS", out.String())
+	}
+
+	// Option errors are reported alongside
+	cfg = &config.Config{URLArgs: []string{"https://example.com/"}, OutputFormat: "yaml", EndpointsScope: "both"}
+	out.Reset()
+	if err := runCheck(cfg, nil, &out); err == nil || !strings.Contains(out.String(), "yaml") {
+		t.Errorf("runCheck() error = %v, output:\n%s\nwant the unknown format reported", err, out.String())
+	}
+}
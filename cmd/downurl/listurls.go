@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/lcalzada-xor/downurl/internal/config"
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+)
+
+// runListURLs implements --list-urls: it writes the URLs a run would fetch to
+// w, one per line and in download order, without downloading anything.
+// Input file or stdin and arguments are merged as for a download, and URLs
+// that --filter-ext/--exclude-ext would skip before any request are left out.
+// Rules needing a response or DNS (content types, sizes, --scope-cidr) are
+// not applied.
+func runListURLs(cfg *config.Config, w io.Writer) error {
+	jobs, err := readURLs(cfg)
+	if err != nil {
+		return err
+	}
+
+	var urlFilter *filter.ContentFilter
+	if cfg.FilterExt != "" || cfg.ExcludeExt != "" {
+		urlFilter = filter.NewContentFilter(filter.FilterConfig{
+			FilterExt:  cfg.FilterExt,
+			ExcludeExt: cfg.ExcludeExt,
+		})
+	}
+
+	for _, u := range downloader.JobURLs(jobs) {
+		if urlFilter != nil {
+			if ok, reason := urlFilter.ShouldDownloadURL(u); !ok {
+				if !cfg.Quiet {
+					log.Printf("[SKIP] %s: %s", sanitize.Text(u), sanitize.Text(reason))
+				}
+				continue
+			}
+		}
+		if _, err := fmt.Fprintln(w, u); err != nil {
+			return fmt.Errorf("failed to write URL list: %w", err)
+		}
+	}
+	return nil
+}
@@ -2,28 +2,49 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/lcalzada-xor/downurl/internal/benchmark"
+	"github.com/lcalzada-xor/downurl/internal/cache"
 	"github.com/lcalzada-xor/downurl/internal/config"
 	"github.com/lcalzada-xor/downurl/internal/downloader"
 	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/jsanalyzer"
+	"github.com/lcalzada-xor/downurl/internal/logging"
+	"github.com/lcalzada-xor/downurl/internal/notify"
+	"github.com/lcalzada-xor/downurl/internal/output"
 	"github.com/lcalzada-xor/downurl/internal/parser"
 	"github.com/lcalzada-xor/downurl/internal/processor"
 	"github.com/lcalzada-xor/downurl/internal/ratelimit"
 	"github.com/lcalzada-xor/downurl/internal/reporter"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+	"github.com/lcalzada-xor/downurl/internal/scanner"
+	"github.com/lcalzada-xor/downurl/internal/scope"
 	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/internal/timing"
 	"github.com/lcalzada-xor/downurl/internal/ui"
 	"github.com/lcalzada-xor/downurl/internal/watcher"
 	"github.com/lcalzada-xor/downurl/pkg/models"
 )
 
 func main() {
+	// "downurl check [options]" validates input and configuration without downloading
+	check := len(os.Args) > 1 && os.Args[1] == "check"
+	if check {
+		os.Args = append(os.Args[:1], os.Args[2:]...)
+	}
+
 	// Load configuration from flags
 	cfg := config.Load()
 
@@ -33,6 +54,17 @@ func main() {
 		configFile.ApplyToConfig(cfg)
 	}
 
+	if check {
+		var stdin io.Reader
+		if cfg.InputFile == "" && parser.IsStdinAvailable() {
+			stdin = os.Stdin
+		}
+		if err := runCheck(cfg, stdin, os.Stdout); err != nil {
+			os.Exit(1)
+		}
+		return
+	}
+
 	// Save config if requested
 	if cfg.SaveConfig != "" {
 		if err := config.SaveConfigFile(cfg, cfg.SaveConfig); err != nil {
@@ -47,7 +79,7 @@ func main() {
 	if err := cfg.Validate(); err != nil {
 		if err == config.ErrMissingInputFile {
 			// Special handling for missing input file
-			if cfg.SingleURL == "" && !parser.IsStdinAvailable() {
+			if !parser.IsStdinAvailable() {
 				fmt.Fprintln(os.Stderr, ui.WrapNoURLsError())
 				ui.PrintUsageHint()
 				os.Exit(1)
@@ -60,6 +92,31 @@ func main() {
 		}
 	}
 
+	// Route log lines through the leveled logger (validated above)
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Only list what would be fetched: no output directory, no downloads
+	if cfg.ListURLs {
+		if err := runListURLs(cfg, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Give each run its own directory if --output uses {date}/{time}/{runid}
+	cfg.OutputTemplate = cfg.OutputDir
+	cfg.OutputDir = config.ExpandOutputDir(cfg.OutputTemplate, time.Now(), config.NewRunID())
+
+	// Deal with files from earlier runs once, before watch/schedule start repeating
+	if err := prepareOutputDir(cfg, os.Stdin, os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
 	// Run the application
 	if err := run(cfg); err != nil {
 		// Print friendly error
@@ -69,56 +126,90 @@ func main() {
 }
 
 func run(cfg *config.Config) error {
-	return runDownload(cfg, context.Background())
+	if schedulerRunsFirst(cfg) {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		scheduler := newScheduler(cfg, ctx)
+		if cfg.ScheduleState != "" {
+			scheduler.SetStateFile(cfg.ScheduleState)
+		}
+		return scheduler.Start(ctx)
+	}
+	return runDownload(cfg, context.Background(), true)
+}
+
+// schedulerRunsFirst reports whether the scheduler decides when the first
+// download runs, instead of one running right away: with saved state, so a
+// restart does not download again before the interval is over, and with a
+// cron schedule, which only runs at the times it lists
+func schedulerRunsFirst(cfg *config.Config) bool {
+	return cfg.Schedule != "" && (cfg.ScheduleState != "" || watcher.IsCron(cfg.Schedule))
 }
 
-func runDownload(cfg *config.Config, parentCtx context.Context) error {
-	startTime := time.Now()
+// runDownload downloads the URLs of cfg once. The top-level run (topLevel)
+// also handles interrupt signals and goes on to --watch or --schedule; the
+// runs those start pass false.
+func runDownload(cfg *config.Config, parentCtx context.Context, topLevel bool) error {
+	// Measure each pipeline step so the summary can show where time went
+	timer := timing.NewPhaseTimer()
+	timer.Start("parse")
+
+	// Parse requested report formats up front so typos fail before downloading
+	formats, err := output.ParseFormats(cfg.OutputFormat)
+	if err != nil {
+		return err
+	}
+	if cfg.OutputFile == "-" && len(formats) > 1 {
+		return fmt.Errorf("--output-file - writes a single report to stdout, but %d formats were requested", len(formats))
+	}
+	archiveFormat, err := storage.ParseArchiveFormat(cfg.ArchiveFormat)
+	if err != nil {
+		return fmt.Errorf("invalid --archive-format: %w", err)
+	}
+	dedupeMode, err := storage.ParseDedupeMode(cfg.DedupeMode)
+	if err != nil {
+		return fmt.Errorf("invalid --dedupe-mode: %w", err)
+	}
+	var notifier *notify.Notifier
+	if cfg.WebhookURL != "" {
+		if notifier, err = notify.New(cfg.WebhookURL, cfg.Timeout); err != nil {
+			return fmt.Errorf("invalid --webhook-url: %w", err)
+		}
+	}
+	ndjsonPath := ndjsonDestination(cfg, formats)
+	if ndjsonPath == "-" && cfg.PathsOutput == "-" {
+		return fmt.Errorf("--output-format ndjson and --paths-output - cannot both write to stdout")
+	}
+
+	// Keep stdout clean when a report, the path list or NDJSON is written there
+	if cfg.OutputFile == "-" || cfg.PathsOutput == "-" || ndjsonPath == "-" {
+		ui.SetOutput(os.Stderr)
+	} else {
+		ui.SetOutput(os.Stdout)
+	}
 
 	if !cfg.Quiet {
 		ui.Info("Starting downurl...")
 	}
 
-	// Parse URLs based on input mode
-	var urls []string
-	var err error
-
-	if cfg.SingleURL != "" {
-		// Single URL mode
-		if !cfg.Quiet {
-			log.Printf("[1/5] Processing single URL...")
-		}
-		validURL, err := parser.ParseSingleURL(cfg.SingleURL)
-		if err != nil {
-			return ui.WrapInvalidURL(cfg.SingleURL, 1, err)
-		}
-		urls = []string{validURL}
-	} else if cfg.InputFile == "" && parser.IsStdinAvailable() {
-		// Stdin mode
-		if !cfg.Quiet {
-			log.Printf("[1/5] Reading URLs from stdin...")
-		}
-		urls, err = parser.ParseURLsFromStdin()
-		if err != nil {
-			return fmt.Errorf("failed to parse URLs from stdin: %w", err)
-		}
-	} else {
-		// File mode
-		if !cfg.Quiet {
-			log.Printf("[1/5] Parsing URLs from file: %s", cfg.InputFile)
-		}
-		urls, err = parser.ParseURLsFromFile(cfg.InputFile)
-		if err != nil {
-			if os.IsNotExist(err) {
-				return ui.WrapFileNotFound(cfg.InputFile, err)
-			}
-			return fmt.Errorf("failed to parse URLs: %w", err)
+	jobs, err := readURLs(cfg)
+	if err != nil {
+		return err
+	}
+	urls := downloader.JobURLs(jobs)
+	if _, err := scanner.ParseEndpointScope(cfg.EndpointsScope); err != nil {
+		return fmt.Errorf("invalid --endpoints-scope: %w", err)
+	}
+	if _, err := scanner.ParseConfidence(cfg.SecretsMinConf); err != nil {
+		return fmt.Errorf("invalid --secrets-min-confidence: %w", err)
+	}
+	if cfg.SecretsRules != "" {
+		if _, err := scanner.LoadSecretRules(cfg.SecretsRules); err != nil {
+			return fmt.Errorf("invalid --secrets-rules: %w", err)
 		}
 	}
-
-	// Validate we have URLs
-	if len(urls) == 0 {
-		return ui.WrapNoURLsError()
+	if err := nucleiTemplate(cfg).Validate(); err != nil {
+		return err
 	}
 
 	if !cfg.Quiet {
@@ -142,37 +233,212 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 	if !cfg.Quiet && authProvider != nil && authProvider.GetType() != "none" {
 		log.Printf("  Authentication: %s", authProvider.GetType())
 	}
+	hostAuth, err := cfg.BuildHostAuth()
+	if err != nil {
+		return fmt.Errorf("failed to configure authentication: %w", err)
+	}
+	if !cfg.Quiet && hostAuth.Len() > 0 {
+		log.Printf("  Per-host authentication: %d host(s)", hostAuth.Len())
+	}
 
 	// Initialize storage
+	timer.Start("setup")
 	if !cfg.Quiet {
 		log.Printf("\n[2/5] Initializing storage...")
 	}
-	fileStorage := storage.NewFileStorage(cfg.OutputDir, cfg.StorageMode)
-	if err := fileStorage.Init(); err != nil {
-		return ui.WrapPermissionError(cfg.OutputDir, err)
+	hostLayout, err := storage.ParseHostLayout(cfg.HostLayout)
+	if err != nil {
+		return fmt.Errorf("invalid --host-layout: %w", err)
 	}
-	if !cfg.Quiet {
-		ui.Success(fmt.Sprintf("Storage initialized at: %s", cfg.OutputDir))
-		log.Printf("  Storage mode: %s", cfg.StorageMode)
+	strategy := storage.NewStrategyWithHostLayout(cfg.StorageMode, hostLayout)
+	if cfg.FlatHost {
+		if flat, ok := strategy.(*storage.FlatMode); ok {
+			flat.HostPrefix = true
+		} else {
+			log.Printf("[WARN] --flat-host only applies to flat mode")
+		}
+	}
+	collision, err := storage.ParseCollisionFormat(cfg.CollisionFormat)
+	if err != nil {
+		return fmt.Errorf("invalid --collision-format: %w", err)
+	}
+	hostDirs, err := storage.ParseHostDirs(cfg.HostOutput)
+	if err != nil {
+		return fmt.Errorf("invalid --host-output: %w", err)
+	}
+	if cfg.TempDir != "" {
+		if err := storage.ValidateTempDir(cfg.TempDir); err != nil {
+			return ui.WrapTempDirError(cfg.TempDir, err)
+		}
+	}
+	var fileStorage storage.Storage
+	var sink *storage.DiscardStorage
+	var database *storage.SQLiteStorage // Closed before archiving (--storage-backend sqlite)
+	if cfg.Benchmark {
+		// Bodies are read and dropped so only network throughput is measured
+		sink = storage.NewDiscardStorage(cfg.OutputDir)
+		fileStorage = sink
+		if !cfg.Quiet {
+			log.Printf("  Benchmark mode: files are not saved")
+		}
+	} else if cfg.DryRun {
+		// Only used to work out paths: no directory is created
+		fs := storage.NewFileStorageWithStrategy(cfg.OutputDir, strategy)
+		fs.SetHostDirs(hostDirs)
+		fileStorage = fs
+		if !cfg.Quiet {
+			log.Printf("  Dry run: nothing is downloaded or written")
+		}
+	} else if backend, _ := storage.ParseBackend(cfg.StorageBackend); backend == storage.BackendSQLite {
+		db := storage.NewSQLiteStorage(cfg.OutputDir)
+		if err := db.Init(); err != nil {
+			return ui.WrapPermissionError(cfg.OutputDir, err)
+		}
+		defer db.Close()
+		database = db
+		fileStorage = db
+		if !cfg.Quiet {
+			ui.Success(fmt.Sprintf("Storage initialized at: %s", filepath.Join(cfg.OutputDir, storage.SQLiteFile)))
+			log.Printf("  Storage backend: sqlite")
+		}
+	} else {
+		fs := storage.NewFileStorageWithStrategy(cfg.OutputDir, strategy)
+		fs.SetCollisionFormat(collision)
+		fs.SetHostDirs(hostDirs)
+		if err := fs.Init(); err != nil {
+			return ui.WrapPermissionError(cfg.OutputDir, err)
+		}
+		fileStorage = fs
+		if !cfg.Quiet {
+			ui.Success(fmt.Sprintf("Storage initialized at: %s", cfg.OutputDir))
+			log.Printf("  Storage mode: %s", cfg.StorageMode)
+			if len(hostDirs) > 0 {
+				log.Printf("  Per-host output directories: %d", len(hostDirs))
+			}
+		}
 	}
 
 	// Initialize HTTP client with authentication
 	httpClient := downloader.NewHTTPClientWithAuth(cfg.Timeout, cfg.RetryAttempts, authProvider)
+	httpClient.SetRetryInterrupted(cfg.RetryInterrupted)
+	httpClient.SetHostAuth(hostAuth)
+	httpClient.SetMaxDownloadSize(cfg.MaxDownloadSize)
+	httpClient.SetDecompress(!cfg.NoDecompress)
+	acceptByExt, err := downloader.ParseAcceptMap(cfg.AcceptExt)
+	if err != nil {
+		return fmt.Errorf("invalid --accept-ext: %w", err)
+	}
+	httpClient.SetAccept(cfg.Accept, acceptByExt)
+	httpClient.SetUserAgent(downloader.ParseUserAgent(cfg.DefaultUA))
+	if cfg.SignKey != "" {
+		httpClient.SetSigner(downloader.NewHMACSigner(cfg.SignKey, cfg.SignHeader, cfg.SignTemplate))
+	}
+	httpClient.SetPreviewBytes(cfg.PreviewBytes)
+	if cfg.TLSMinVersion != "" || cfg.TLSMaxVersion != "" || cfg.TLSCiphers != "" || cfg.Insecure || cfg.CACert != "" {
+		tlsOpts, err := parseTLSOptions(cfg)
+		if err != nil {
+			return err
+		}
+		if err := httpClient.SetTLSOptions(tlsOpts); err != nil {
+			return fmt.Errorf("invalid TLS settings: %w", err)
+		}
+		if cfg.Insecure {
+			insecureWarning.Do(func() {
+				ui.Warning("TLS certificate verification is disabled (--insecure)")
+			})
+		}
+	}
+	// After TLS options, which replace the transport
+	var dnsCache *downloader.DNSCache
+	if cfg.DNSCacheTTL > 0 || cfg.DNSMaxLookups > 0 {
+		dnsCache = downloader.NewDNSCache(nil, cfg.DNSCacheTTL, cfg.DNSMaxLookups)
+		httpClient.SetDNSCache(dnsCache)
+	}
+	// After TLS options, so HTTP/3 and its fallback share them
+	if err := httpClient.SetHTTP3(cfg.HTTP3); err != nil {
+		return fmt.Errorf("--http3: %w", err)
+	}
+	// After TLS and DNS options, which configure the transport proxies wrap
+	if cfg.ProxiesFile != "" {
+		rotation, err := downloader.ParseProxyRotation(cfg.ProxyRotation)
+		if err != nil {
+			return fmt.Errorf("invalid --proxy-rotation: %w", err)
+		}
+		proxies, err := downloader.LoadProxies(cfg.ProxiesFile)
+		if err != nil {
+			return err
+		}
+		httpClient.SetProxies(downloader.NewProxyPool(proxies, rotation, downloader.DefaultProxyCooldown))
+		if !cfg.Quiet {
+			log.Printf("  Proxies: %d (%s)", len(proxies), rotation)
+		}
+	}
 
 	// Initialize downloader
 	dl := downloader.New(httpClient, fileStorage, cfg.Workers)
+	dl.SetMaxTotalBytes(cfg.MaxTotalBytes)
+	dl.SetNormalizeText(cfg.NormalizeText)
+	dl.SetIndexNames(cfg.IndexNames)
+	dl.SetReportRequestHeaders(cfg.ReportHeaders)
+	hostDelays, err := downloader.ParseHostDelays(cfg.HostDelay)
+	if err != nil {
+		return fmt.Errorf("invalid --host-delay: %w", err)
+	}
+	dl.SetHostDelays(hostDelays)
+	dl.SetPerHostLimit(cfg.PerHostLimit)
+	dl.SetDryRun(cfg.DryRun)
+	if cfg.VerifyHashes != "" {
+		hashes, err := downloader.LoadExpectedHashes(cfg.VerifyHashes)
+		if err != nil {
+			return fmt.Errorf("--verify-hashes: %w", err)
+		}
+		dl.SetExpectedHashes(hashes, cfg.DeleteMismatched)
+		if !cfg.Quiet {
+			log.Printf("  Expected hashes: %d", hashes.Len())
+		}
+	}
+	categoryRules, err := downloader.ParseCategoryRules(cfg.ErrorCategories)
+	if err != nil {
+		return fmt.Errorf("invalid --error-categories: %w", err)
+	}
+	dl.SetCategorizer(downloader.NewCategorizer(categoryRules...))
+	if cfg.CacheDir != "" && cfg.PreviewBytes > 0 {
+		// A preview is not the resource: caching it would serve it as the whole file later
+		log.Printf("[WARN] --cache-dir is ignored with --preview-bytes")
+	} else if cfg.CacheDir != "" && !cfg.DryRun {
+		c, err := cache.Open(cfg.CacheDir, cfg.CacheSize*1024*1024)
+		if err != nil {
+			return fmt.Errorf("--cache-dir: %w", err)
+		}
+		dl.SetCache(c)
+		if !cfg.Quiet {
+			log.Printf("  Cache: %s", cfg.CacheDir)
+		}
+	}
 
 	// Setup content filter if any filters are configured
 	if cfg.FilterType != "" || cfg.ExcludeType != "" || cfg.FilterExt != "" ||
-		cfg.ExcludeExt != "" || cfg.MinSize > 0 || cfg.MaxSize > 0 || cfg.SkipEmpty {
+		cfg.ExcludeExt != "" || cfg.MinSize > 0 || cfg.MaxSize > 0 || cfg.SkipEmpty ||
+		len(cfg.RequireHeader) > 0 || len(cfg.SkipHeader) > 0 {
+		requireHeaders, err := filter.ParseHeaderRules(cfg.RequireHeader)
+		if err != nil {
+			return fmt.Errorf("invalid --require-header: %w", err)
+		}
+		skipHeaders, err := filter.ParseHeaderRules(cfg.SkipHeader)
+		if err != nil {
+			return fmt.Errorf("invalid --skip-header: %w", err)
+		}
+
 		filterCfg := filter.FilterConfig{
-			FilterType:  cfg.FilterType,
-			ExcludeType: cfg.ExcludeType,
-			FilterExt:   cfg.FilterExt,
-			ExcludeExt:  cfg.ExcludeExt,
-			MinSize:     cfg.MinSize,
-			MaxSize:     cfg.MaxSize,
-			SkipEmpty:   cfg.SkipEmpty,
+			FilterType:     cfg.FilterType,
+			ExcludeType:    cfg.ExcludeType,
+			FilterExt:      cfg.FilterExt,
+			ExcludeExt:     cfg.ExcludeExt,
+			MinSize:        cfg.MinSize,
+			MaxSize:        cfg.MaxSize,
+			SkipEmpty:      cfg.SkipEmpty,
+			RequireHeaders: requireHeaders,
+			SkipHeaders:    skipHeaders,
 		}
 		contentFilter := filter.NewContentFilter(filterCfg)
 		dl.SetFilter(contentFilter)
@@ -181,16 +447,39 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 		}
 	}
 
-	// Setup rate limiter if configured
-	var limiter *ratelimit.Limiter
-	if cfg.RateLimit != "" {
-		limiter, err = ratelimit.ParseRateLimit(cfg.RateLimit)
+	// Setup CIDR scope enforcement if configured
+	if cfg.ScopeCIDR != "" {
+		networks, err := scope.ParseCIDRs(cfg.ScopeCIDR)
 		if err != nil {
-			return fmt.Errorf("invalid rate limit: %w", err)
+			return fmt.Errorf("invalid --scope-cidr: %w", err)
 		}
+		// Share the DNS cache so scope checks and connections resolve each host once
+		var resolver scope.Resolver
+		if dnsCache != nil {
+			resolver = dnsCache
+		}
+		cidrScope := scope.NewCIDRScope(networks, resolver)
+		dl.SetScope(cidrScope)
+		// The downloader's check resolves hosts on its own; the client also
+		// vets the address each connection really dials, and redirects
+		httpClient.SetScope(cidrScope)
 		if !cfg.Quiet {
+			log.Printf("  Scope: %s", cfg.ScopeCIDR)
+		}
+	}
+
+	// Setup rate limiter if configured
+	limiter, err := cfg.BuildRateLimiter()
+	if err != nil {
+		return err
+	}
+	if !cfg.Quiet {
+		if cfg.RateLimit != "" {
 			log.Printf("  Rate limiting: %s", cfg.RateLimit)
 		}
+		if limiter.Len() > 0 {
+			log.Printf("  Per-host rate limits: %d host(s)", limiter.Len())
+		}
 	}
 
 	// Setup context with cancellation for graceful shutdown
@@ -199,7 +488,7 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 	defer cancel()
 
 	// Handle interruption signals only if this is the top-level call
-	if parentCtx == context.Background() {
+	if topLevel {
 		sigChan := make(chan os.Signal, 1)
 		signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 		go func() {
@@ -211,67 +500,177 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 		}()
 	}
 
+	if cfg.Benchmark {
+		return runBenchmark(ctx, cfg, dl, sink, jobs, limiter)
+	}
+	if cfg.DryRun {
+		return runDryRun(ctx, cfg, dl, urls, limiter)
+	}
+
+	// Resume an interrupted run: finished URLs keep their recorded results
+	var resumed []*downloader.Result
+	if cfg.Checkpoint != "" {
+		checkpoint, err := downloader.OpenCheckpoint(cfg.Checkpoint)
+		if err != nil {
+			return err
+		}
+		defer checkpoint.Close()
+		resumed = checkpoint.Finished(jobs)
+		remaining := checkpoint.RemainingJobs(jobs)
+		if !cfg.Quiet && len(remaining) < len(jobs) {
+			log.Printf("  Checkpoint: %d of %d URLs already done, resuming with %d", len(jobs)-len(remaining), len(jobs), len(remaining))
+		}
+		jobs = remaining
+		urls = downloader.JobURLs(jobs)
+		dl.SetCheckpoint(checkpoint)
+	}
+
 	// Download all files
+	timer.Start("download")
 	if !cfg.Quiet {
 		log.Printf("\n[3/5] Downloading files with %d workers...", cfg.Workers)
 	}
 
-	// Create progress bar if not disabled
+	// The compact log replaces the progress bar and the per-file log lines
+	// while downloading
 	var pb *ui.ProgressBar
-	if !cfg.Quiet && !cfg.NoProgress {
+	restoreLog := func() {}
+	if cfg.LogMode == "compact" && !cfg.Quiet {
+		dl.SetObserver(ui.NewCompactLog(ui.Output(), urls, ui.IsTerminal(ui.Output())))
+		logOutput := log.Writer()
+		log.SetOutput(io.Discard)
+		restoreLog = func() { log.SetOutput(logOutput) }
+		defer restoreLog()
+	} else if !cfg.Quiet && !cfg.NoProgress {
+		// Create progress bar if not disabled
 		pb = ui.NewProgressBar(len(urls), true)
-		fmt.Print(pb.Render())
+		fmt.Fprint(ui.Output(), pb.Render())
 	}
 
+	// Processing is enabled by scanners, and by structured report formats built from its findings
+	needsProcessor := cfg.ScanSecrets || cfg.ScanEndpoints || cfg.ScanMixedContent || cfg.JSBeautify || cfg.Beautify || cfg.MeasureGzip || needsScanReport(formats)
+	var proc *processor.Processor
+
 	// Download with rate limiting if configured
 	var results []*downloader.Result
-	if limiter != nil {
-		// Download with rate limiting
-		results = dl.DownloadAllWithRateLimit(ctx, urls, limiter, func(completed, total int) {
+	var summary models.RunSummary
+	// From here on downloads are done or underway: failures are recorded
+	// and the remaining steps still run, so completed work is kept
+	var steps stepErrors
+	var secrets *secretsDelta // Set when --secrets-diff compared this run with the previous one
+	var ndjson *output.NDJSONWriter
+	if ndjsonPath != "" {
+		if ndjson, err = output.CreateNDJSON(ndjsonPath); err != nil {
+			return fmt.Errorf("failed to write NDJSON: %w", err)
+		}
+		// Each line goes out as its download finishes, whatever the mode
+		var ndjsonErr error
+		dl.SetResultHandler(func(result *downloader.Result) {
+			if ndjsonErr == nil {
+				if ndjsonErr = ndjson.Add(result); ndjsonErr != nil {
+					steps.fail("NDJSON output", ndjsonErr)
+				}
+			}
+		})
+	}
+	if cfg.StreamResults {
+		// Handle each result as it completes instead of collecting them all
+		if needsProcessor {
+			proc = newProcessor(cfg, fileStorage)
+		}
+		stream, err := openResultStream(cfg, formats)
+		if err != nil {
+			return err
+		}
+		handle := func(result *downloader.Result) {
+			summary.Add(result)
+			if proc != nil {
+				if err := proc.ProcessResult(*result, cfg.OutputDir); err != nil && !cfg.Quiet {
+					log.Printf("[WARN] Failed to process result for %s: %s", sanitize.Text(result.URL), sanitize.Text(err.Error()))
+				}
+			}
+			stream.add(*result)
+		}
+		for _, result := range resumed {
+			handle(result)
+		}
+		dl.DownloadJobsStream(ctx, jobs, limiter, func(completed, total int) {
 			if pb != nil {
 				pb.Update(completed)
-				fmt.Print(pb.Render())
+				fmt.Fprint(ui.Output(), pb.Render())
 			}
-		})
+		}, handle)
+		if err := stream.close(); err != nil {
+			steps.fail("streamed report", err)
+		}
 	} else {
-		// Standard download with progress callback
-		results = dl.DownloadAllWithProgress(ctx, urls, func(completed, total int) {
+		// Rate limited if a limiter is configured
+		results = dl.DownloadJobs(ctx, jobs, limiter, func(completed, total int) {
 			if pb != nil {
 				pb.Update(completed)
-				fmt.Print(pb.Render())
+				fmt.Fprint(ui.Output(), pb.Render())
 			}
 		})
 	}
+	if !cfg.StreamResults {
+		results = append(resumed, results...)
+	}
+	// Follow chunks lazily loaded by the JavaScript just downloaded
+	if cfg.CrawlDepth > 0 {
+		if cfg.StreamResults {
+			log.Printf("[WARN] --crawl-depth is ignored with --stream-results")
+		} else {
+			results = crawlChunks(ctx, cfg, dl, limiter, urls, results)
+		}
+	}
+	// Fetch the source maps of the JavaScript downloaded so far, chunks included
+	if cfg.FetchSourceMaps {
+		if cfg.StreamResults {
+			log.Printf("[WARN] --fetch-sourcemaps is ignored with --stream-results")
+		} else {
+			results = fetchSourceMaps(ctx, cfg, dl, limiter, results)
+		}
+	}
+	for _, r := range results {
+		summary.Add(r)
+	}
+	if ndjson != nil {
+		if err := ndjson.Close(); err != nil {
+			steps.fail("NDJSON output", err)
+		}
+	}
 
 	// Finish progress bar
 	if pb != nil {
 		pb.Finish()
 	}
+	restoreLog()
 
 	// Check if context was cancelled
 	if ctx.Err() != nil && !cfg.Quiet {
 		ui.Warning("Download process was interrupted")
 	}
+	// The rest of the run goes on with the files saved within the budget
+	if dl.BudgetReached() && !cfg.Quiet {
+		ui.Warning(fmt.Sprintf("Total byte budget reached (%d of %d bytes): remaining downloads were not started", dl.TotalBytes(), cfg.MaxTotalBytes))
+	}
 
 	// Process downloaded files if any processing is enabled
-	var proc *processor.Processor
-	if cfg.ScanSecrets || cfg.ScanEndpoints || cfg.JSBeautify {
+	// (streamed results were already processed as they arrived)
+	if needsProcessor {
+		timer.Start("process")
 		if !cfg.Quiet {
 			log.Printf("\n[4/7] Processing downloaded files...")
 		}
-		processorCfg := processor.Config{
-			ScanSecrets:    cfg.ScanSecrets,
-			ScanEndpoints:  cfg.ScanEndpoints,
-			JSBeautify:     cfg.JSBeautify,
-			SecretsEntropy: cfg.SecretsEntropy,
+		if proc == nil {
+			proc = newProcessor(cfg, fileStorage)
 		}
-		proc = processor.NewProcessor(processorCfg)
 
 		// Process each result
 		for _, result := range results {
 			if err := proc.ProcessResult(*result, cfg.OutputDir); err != nil {
 				if !cfg.Quiet {
-					log.Printf("[WARN] Failed to process result for %s: %v", result.URL, err)
+					log.Printf("[WARN] Failed to process result for %s: %s", sanitize.Text(result.URL), sanitize.Text(err.Error()))
 				}
 			}
 		}
@@ -279,6 +678,21 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 			ui.Success("Processing complete")
 		}
 
+		// High-entropy strings go to their own file, ahead of the secrets diff
+		if cfg.ScanSecrets && cfg.EntropyOutput != "" {
+			proc.SeparateEntropy()
+		}
+
+		// Keep only the secrets found since the previous run
+		if cfg.SecretsDiff != "" {
+			delta, err := diffSecrets(cfg.SecretsDiff, proc)
+			if err != nil {
+				steps.fail("secrets diff", err)
+			} else {
+				secrets = &delta
+			}
+		}
+
 		// Save secrets if requested
 		if cfg.ScanSecrets && cfg.SecretsOutput != "" {
 			if !cfg.Quiet {
@@ -296,13 +710,36 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 			}
 		}
 
+		if cfg.ScanSecrets && cfg.EntropyOutput != "" {
+			entropyPath := filepath.Join(cfg.OutputDir, cfg.EntropyOutput)
+			if err := proc.SaveEntropy(entropyPath); err != nil {
+				if !cfg.Quiet {
+					log.Printf("[WARN] Failed to save high-entropy strings: %v", err)
+				}
+			} else if !cfg.Quiet {
+				ui.Success(fmt.Sprintf("High-entropy strings saved to: %s", entropyPath))
+			}
+		}
+
+		// Save entropy candidates for threshold tuning
+		if cfg.ScanSecrets && cfg.EntropyDebug {
+			debugPath := filepath.Join(cfg.OutputDir, "entropy-debug.json")
+			if err := proc.SaveEntropyCandidates(debugPath); err != nil {
+				if !cfg.Quiet {
+					log.Printf("[WARN] Failed to save entropy candidates: %v", err)
+				}
+			} else if !cfg.Quiet {
+				ui.Success(fmt.Sprintf("Entropy candidates saved to: %s", debugPath))
+			}
+		}
+
 		// Save endpoints if requested
 		if cfg.ScanEndpoints && cfg.EndpointsOutput != "" {
 			if !cfg.Quiet {
 				log.Printf("\n[6/7] Saving endpoints...")
 			}
 			endpointsPath := filepath.Join(cfg.OutputDir, cfg.EndpointsOutput)
-			if err := proc.SaveEndpoints(endpointsPath); err != nil {
+			if err := proc.SaveEndpoints(endpointsPath, cfg.EndpointsGrouped); err != nil {
 				if !cfg.Quiet {
 					log.Printf("[WARN] Failed to save endpoints: %v", err)
 				}
@@ -312,9 +749,37 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 				}
 			}
 		}
+		if cfg.ScanEndpoints && cfg.NucleiOutput != "" {
+			nucleiPath := filepath.Join(cfg.OutputDir, cfg.NucleiOutput)
+			if err := proc.SaveNucleiTemplate(nucleiPath, nucleiTemplate(cfg)); err != nil {
+				if !cfg.Quiet {
+					log.Printf("[WARN] Failed to save nuclei template: %v", err)
+				}
+			} else if !cfg.Quiet {
+				ui.Success(fmt.Sprintf("Nuclei template saved to: %s", nucleiPath))
+			}
+		}
 	}
 
-	// Generate output in requested format
+	// Collapse identical files before the reports and archive are written
+	var dedupe *storage.DedupeResult
+	if cfg.Dedupe {
+		timer.Start("dedupe")
+		res, err := storage.DeduplicateByHash(cfg.OutputDir, dedupeMode)
+		if err != nil {
+			steps.fail("deduplication", err)
+		} else {
+			dedupe = &res
+		}
+		if !cfg.Quiet && dedupeMode == storage.DedupeReport {
+			for _, g := range res.Groups {
+				log.Printf("[DUPLICATE] %s has %d identical copies: %s", sanitize.Text(g.Kept), len(g.Duplicates), sanitize.Text(strings.Join(g.Duplicates, ", ")))
+			}
+		}
+	}
+
+	// Generate output in requested formats
+	timer.Start("report")
 	stepNum := 4
 	if proc != nil {
 		stepNum = 7
@@ -323,100 +788,196 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 		log.Printf("\n[%d/%d] Generating report...", stepNum, stepNum)
 	}
 
-	// Generate output report
-	var reportPath string
-	if proc != nil && (cfg.OutputFormat == "json" || cfg.OutputFormat == "csv" || cfg.OutputFormat == "markdown") {
-		// Use processor reporter for advanced formats
-		outputPath := cfg.OutputFile
-		if outputPath == "" {
-			ext := ".txt"
-			switch cfg.OutputFormat {
-			case "json":
-				ext = ".json"
-			case "csv":
-				ext = ".csv"
-			case "markdown":
-				ext = ".md"
+	// Reports are written before archiving with the timings measured so far,
+	// and rewritten with the complete ones once the archive is done
+	if proc != nil {
+		proc.GetReporter().SetPhaseTimings(timer.Seconds())
+		proc.GetReporter().GroupDuplicates()
+	}
+
+	// Generate output reports, one per requested format
+	var reportPaths []string
+	timedReports := make(map[output.Format]string) // Reports carrying phase_timings, by format
+	for _, format := range formats {
+		reportPath := output.ReportPath(format, cfg.OutputDir, cfg.OutputFile, len(formats) > 1)
+
+		if format == output.FormatNDJSON {
+			// Already written as results arrived
+			reportPath = ndjsonPath
+		} else if format == output.FormatText && cfg.StreamResults {
+			// Already written as results arrived
+		} else if format == output.FormatText {
+			// Basic text report
+			rep := reporter.New()
+			// Convert []*Result to []Result
+			plainResults := make([]models.DownloadResult, len(results))
+			for i, r := range results {
+				plainResults[i] = *r
 			}
-			outputPath = filepath.Join(cfg.OutputDir, "report"+ext)
-		}
+			rep.AddBatch(plainResults)
 
-		// Generate based on format
-		var err error
-		switch cfg.OutputFormat {
-		case "json":
-			err = proc.GetReporter().GenerateJSON(outputPath, cfg.PrettyJSON)
-		case "csv":
-			err = proc.GetReporter().GenerateCSV(outputPath)
-		case "markdown":
-			err = proc.GetReporter().GenerateMarkdown(outputPath)
+			if err := rep.Generate(reportPath); err != nil {
+				steps.fail("text report", err)
+				continue
+			}
+		} else if err := proc.GetReporter().Generate(format, reportPath, cfg.PrettyJSON); err != nil {
+			steps.fail(fmt.Sprintf("%s report", format), err)
+			continue
+		} else if reportPath != "-" {
+			timedReports[format] = reportPath
 		}
 
-		if err != nil {
-			return fmt.Errorf("failed to generate %s output: %w", cfg.OutputFormat, err)
-		}
-		if !cfg.Quiet {
-			ui.Success(fmt.Sprintf("Report saved to: %s", outputPath))
+		if reportPath == "-" {
+			reportPath = "stdout"
+		} else if !cfg.Quiet {
+			ui.Success(fmt.Sprintf("Report saved to: %s", reportPath))
 		}
-		reportPath = outputPath
-	} else {
-		// Basic text report
-		rep := reporter.New()
-		// Convert []*Result to []Result
+		reportPaths = append(reportPaths, reportPath)
+	}
+
+	// Write the plain path list for scripting (streamed runs wrote it during download)
+	if cfg.PathsOutput != "" && !cfg.StreamResults {
 		plainResults := make([]models.DownloadResult, len(results))
 		for i, r := range results {
 			plainResults[i] = *r
 		}
-		rep.AddBatch(plainResults)
-
-		reportPath = filepath.Join(cfg.OutputDir, "report.txt")
-		if err := rep.Generate(reportPath); err != nil {
-			return fmt.Errorf("failed to generate report: %w", err)
+		if err := output.SavePaths(cfg.PathsOutput, plainResults); err != nil {
+			steps.fail("path list", err)
+		} else if !cfg.Quiet && cfg.PathsOutput != "-" {
+			ui.Success(fmt.Sprintf("Paths saved to: %s", cfg.PathsOutput))
 		}
-		if !cfg.Quiet {
-			ui.Success(fmt.Sprintf("Report saved to: %s", reportPath))
+	}
+
+	// Prune old dated runs only after a run that went through: not interrupted,
+	// no failed step and at least one download saved
+	if cfg.Retain > 0 && (ctx.Err() != nil || steps.err() != nil || summary.Successful == 0) {
+		log.Printf("[SKIP] Retention pruning skipped: the run was interrupted, had failed steps or saved nothing")
+	} else if cfg.Retain > 0 {
+		if cfg.StorageMode != "dated" {
+			log.Printf("[WARN] --retain only applies to --mode dated, ignoring")
+		} else {
+			removed, err := storage.PruneDated(cfg.OutputDir, cfg.Retain)
+			for _, dir := range removed {
+				log.Printf("[PRUNE] Removed old dated directory %s", dir)
+			}
+			if err != nil {
+				log.Printf("[WARN] Retention pruning failed: %v", err)
+			}
 		}
 	}
 
-	// Create tar.gz archive
-	finalStep := stepNum + 1
-	if !cfg.Quiet {
-		log.Printf("\n[%d/%d] Creating tar.gz archive...", finalStep, finalStep)
+	// Close the database first, so its write-ahead log is folded into
+	// downurl.db rather than archived half-written beside it
+	if database != nil {
+		if err := database.Close(); err != nil {
+			steps.fail("database", err)
+		}
 	}
-	archiver := storage.NewArchiver()
-	tarPath := filepath.Join(cfg.OutputDir, "output.tar.gz")
-	if err := archiver.CreateTarGz(cfg.OutputDir, tarPath); err != nil {
-		return fmt.Errorf("failed to create archive: %w", err)
+
+	// Create the archive, unless disabled or too many downloads failed to replace the last good one
+	archiveNote := "disabled (--no-archive)"
+	if !cfg.NoArchive {
+		timer.Start("archive")
+		finalStep := stepNum + 1
+		if !cfg.Quiet {
+			log.Printf("\n[%d/%d] Creating %s archive...", finalStep, finalStep, archiveFormat)
+		}
+		archivePath := filepath.Join(cfg.OutputDir, "output."+archiveFormat)
+		archiveNote = archivePath
+		if rate := summary.SuccessRate(); cfg.ArchiveMinRate > 0 && rate < cfg.ArchiveMinRate {
+			log.Printf("[SKIP] Archive not created: %.1f%% of downloads succeeded, --archive-on-success-only needs %g%%; the previous archive is kept", rate, cfg.ArchiveMinRate)
+			archiveNote = "skipped (too many failed downloads)"
+		} else {
+			archiver := storage.NewArchiver()
+			archiver.SetTempDir(cfg.TempDir)
+			if cfg.ArchiveNoReports {
+				archiver.SetExclude(reportFiles(cfg, reportPaths)...)
+			}
+			if err := archiver.Create(archiveFormat, cfg.OutputDir, archivePath); err != nil {
+				steps.fail("archive", err)
+				archiveNote = "not created"
+			} else if !cfg.Quiet {
+				ui.Success(fmt.Sprintf("Archive created: %s", archivePath))
+			}
+		}
 	}
-	if !cfg.Quiet {
-		ui.Success(fmt.Sprintf("Archive created: %s", tarPath))
+
+	timer.Stop()
+	elapsed := timer.Total()
+
+	// Rewrite the reports so phase_timings covers the report and archive
+	// phases too; the copies in the archive keep the earlier timings
+	if len(timedReports) > 0 {
+		proc.GetReporter().SetPhaseTimings(timer.Seconds())
+		for format, reportPath := range timedReports {
+			if err := proc.GetReporter().Generate(format, reportPath, cfg.PrettyJSON); err != nil {
+				steps.fail(fmt.Sprintf("%s report", format), err)
+			}
+		}
 	}
 
 	// Print enhanced summary
-	elapsed := time.Since(startTime)
 	if !cfg.Quiet {
-		fmt.Println()
-		// Convert []*Result to []Result for UI
-		plainResults := make([]models.DownloadResult, len(results))
-		for i, r := range results {
-			plainResults[i] = *r
-		}
+		fmt.Fprintln(ui.Output())
+		// Streamed runs keep no results to tabulate
+		if !cfg.StreamResults {
+			// Convert []*Result to []Result for UI
+			plainResults := make([]models.DownloadResult, len(results))
+			for i, r := range results {
+				plainResults[i] = *r
+			}
 
-		// Show results table
-		table := ui.NewResultsTable(plainResults)
-		fmt.Println(table.Render())
+			// Show results table
+			table := ui.NewResultsTable(plainResults)
+			fmt.Fprintln(ui.Output(), table.Render())
+		}
 
 		// Show detailed summary
-		summary := ui.RenderSummary(plainResults, elapsed, cfg.OutputDir)
-		fmt.Print(summary)
+		fmt.Fprint(ui.Output(), ui.RenderRunSummary(summary, elapsed, cfg.OutputDir))
+		if secrets != nil {
+			fmt.Fprint(ui.Output(), ui.RenderSecretsDiff(secrets.added, secrets.resolved))
+		}
+		if limiter != nil {
+			fmt.Fprint(ui.Output(), ui.RenderRateLimit(limiter.Metrics()))
+		}
+		if dedupe != nil {
+			fmt.Fprint(ui.Output(), ui.RenderDedupe(dedupe.Files, dedupe.Linked, dedupe.SavedBytes))
+		}
+		if proc != nil {
+			stats := proc.GetReporter().GetReport().Statistics
+			fmt.Fprint(ui.Output(), ui.RenderDuplicates(stats.DuplicateClusters, stats.DuplicateFiles, stats.DuplicateBytes))
+		}
+		fmt.Fprint(ui.Output(), ui.RenderPhaseTimings(timer.Phases(), elapsed))
 
-		fmt.Printf("\nReport: %s\n", reportPath)
-		fmt.Printf("Archive: %s\n", tarPath)
+		fmt.Fprintf(ui.Output(), "\nReport: %s\n", strings.Join(reportPaths, ", "))
+		fmt.Fprintf(ui.Output(), "Archive: %s\n", archiveNote)
+	}
+
+	// Tell the webhook how the run went; a notification that fails is no reason to fail the run
+	if notifier != nil {
+		notifyRun(cfg, notifier, summary, proc, steps, elapsed)
+	}
+
+	// Only now, with everything that could be saved saved, report what failed.
+	// A watch/schedule loop keeps going; the next run may succeed.
+	loops := (cfg.Watch || cfg.Schedule != "") && topLevel
+	if err := steps.err(); err != nil {
+		if !loops {
+			return err
+		}
+		log.Printf("[WARN] %v", err)
+	}
+	if cfg.FailOnNewSecrets && secrets != nil && secrets.added > 0 {
+		err := fmt.Errorf("%w: %d not in %s", errNewSecrets, secrets.added, cfg.SecretsDiff)
+		if !loops {
+			return err
+		}
+		log.Printf("[WARN] %v", err)
 	}
 
 	// Watch mode - keep running and watch for file changes
 	// Only start watch/schedule on top-level run (not in recursive calls)
-	if cfg.Watch && parentCtx == context.Background() {
+	if cfg.Watch && topLevel {
 		if cfg.InputFile == "" {
 			return fmt.Errorf("--watch requires an input file (--input)")
 		}
@@ -425,7 +986,7 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 			log.Println("File changed, re-running download...")
 			log.Println(separator(60))
 			// Re-run with same context to avoid goroutine leak
-			if err := runDownload(cfg, ctx); err != nil {
+			if err := runDownload(nextRun(cfg), ctx, false); err != nil {
 				log.Printf("Error during re-run: %v", err)
 			}
 		})
@@ -434,20 +995,528 @@ func runDownload(cfg *config.Config, parentCtx context.Context) error {
 
 	// Schedule mode - run periodically
 	// Only start watch/schedule on top-level run (not in recursive calls)
-	if cfg.Schedule != "" && parentCtx == context.Background() {
-		scheduler := watcher.NewScheduler(cfg.Schedule, func() error {
-			log.Println("\n" + separator(60))
-			log.Println("Running scheduled download...")
-			log.Println(separator(60))
-			// Use parent context to avoid creating nested contexts
-			return runDownload(cfg, ctx)
-		})
-		return scheduler.Start(ctx)
+	if cfg.Schedule != "" && topLevel {
+		return newScheduler(cfg, ctx).Start(ctx)
 	}
 
 	return nil
 }
 
+// newScheduler creates the scheduler for --schedule, running each download under ctx
+func newScheduler(cfg *config.Config, ctx context.Context) *watcher.Scheduler {
+	return watcher.NewScheduler(cfg.Schedule, func() error {
+		log.Println("\n" + separator(60))
+		log.Println("Running scheduled download...")
+		log.Println(separator(60))
+		// Use parent context to avoid creating nested contexts
+		return runDownload(nextRun(cfg), ctx, false)
+	})
+}
+
+// nextRun returns the config for a repeated (scheduled or watched) run: an
+// --output with {date}, {time} or {runid} is expanded again, so each run
+// lands in its own directory instead of the first run's
+func nextRun(cfg *config.Config) *config.Config {
+	next := *cfg
+	next.OutputDir = config.ExpandOutputDir(cfg.OutputTemplate, time.Now(), config.NewRunID())
+	if next.OutputDir == "" || next.OutputDir == cfg.OutputDir {
+		return cfg
+	}
+	return &next
+}
+
+// crawlChunks downloads the chunks that downloaded JavaScript lazily loads
+// (see jsanalyzer.ExtractChunkURLs), up to cfg.CrawlDepth levels deep, and
+// returns results with every round's results appended
+func crawlChunks(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, limiter *ratelimit.HostLimiter, urls []string, results []*downloader.Result) []*downloader.Result {
+	seen := make(map[string]bool, len(urls))
+	for _, u := range urls {
+		seen[u] = true
+	}
+	// Chunks fetched before a --checkpoint resume are among the results
+	for _, r := range results {
+		seen[r.URL] = true
+	}
+
+	round := results
+	for depth := 1; depth <= cfg.CrawlDepth && ctx.Err() == nil; depth++ {
+		var chunks []string
+		for _, r := range round {
+			for _, path := range r.Downloaded {
+				if !isJavaScript(path) {
+					continue
+				}
+				data, err := os.ReadFile(path)
+				if err != nil {
+					continue
+				}
+				for _, chunk := range jsanalyzer.ExtractChunkURLs(r.URL, data) {
+					if !seen[chunk] {
+						seen[chunk] = true
+						chunks = append(chunks, chunk)
+					}
+				}
+			}
+		}
+		if len(chunks) == 0 {
+			break
+		}
+
+		if !cfg.Quiet {
+			log.Printf("  Crawl depth %d: downloading %d chunk(s)", depth, len(chunks))
+		}
+		round = dl.DownloadAllWithRateLimit(ctx, chunks, limiter, nil)
+		results = append(results, round...)
+	}
+	return results
+}
+
+// isJavaScript reports whether a saved file is JavaScript, judging by its extension
+func isJavaScript(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".js", ".mjs", ".cjs":
+		return true
+	}
+	return false
+}
+
+// readURLs reads the URLs to download from the input file or stdin, then from
+// the arguments, as jobs carrying the headers and request of each input entry
+func readURLs(cfg *config.Config) (jobs []downloader.Job, err error) {
+	// Parse URLs based on input mode
+	if cfg.InputFile == "" && len(cfg.URLArgs) > 0 {
+		// URL argument mode
+		if !cfg.Quiet {
+			log.Printf("[1/5] Processing %d URL(s) from arguments...", len(cfg.URLArgs))
+		}
+	} else if cfg.InputFile == "" && parser.IsStdinAvailable() {
+		// Stdin mode
+		if !cfg.Quiet {
+			log.Printf("[1/5] Reading URLs from stdin...")
+		}
+		entries, err := parser.ParseEntriesFromStdin()
+		if err != nil && !skipInvalid(cfg, err) {
+			return nil, fmt.Errorf("failed to parse URLs from stdin: %w", err)
+		}
+		jobs = entryJobs(entries)
+	} else {
+		// File mode; .json and .csv files are read as such
+		if !cfg.Quiet {
+			log.Printf("[1/5] Parsing URLs from file: %s", cfg.InputFile)
+		}
+		entries, err := parser.ParseEntriesFromInput(cfg.InputFile, cfg.CSVColumn)
+		if err != nil && !skipInvalid(cfg, err) {
+			if os.IsNotExist(err) {
+				return nil, ui.WrapFileNotFound(cfg.InputFile, err)
+			}
+			return nil, fmt.Errorf("failed to parse URLs: %w", err)
+		}
+		jobs = entryJobs(entries)
+	}
+
+	// URLs given as arguments are downloaded alongside any input file
+	for i, arg := range cfg.URLArgs {
+		validURL, err := parser.ParseSingleURL(arg)
+		if err != nil {
+			if cfg.SkipInvalid {
+				log.Printf("[SKIP] Invalid URL argument %d: %s", i+1, sanitize.Text(err.Error()))
+				continue
+			}
+			return nil, ui.WrapInvalidURL(arg, i+1, err)
+		}
+		jobs = append(jobs, downloader.Job{URL: validURL})
+	}
+
+	// Validate we have URLs
+	if len(jobs) == 0 {
+		return nil, ui.WrapNoURLsError()
+	}
+	return jobs, nil
+}
+
+// skipInvalid logs the invalid input lines listed by err and reports whether
+// the run goes on without them, as it does with --skip-invalid
+func skipInvalid(cfg *config.Config, err error) bool {
+	var invalid parser.ParseErrors
+	if !cfg.SkipInvalid || !errors.As(err, &invalid) {
+		return false
+	}
+	for _, le := range invalid {
+		log.Printf("[SKIP] Invalid URL at %s", sanitize.Text(le.Error()))
+	}
+	return true
+}
+
+// errPartialRun marks runs whose downloads completed but some later step failed
+var errPartialRun = errors.New("downloads completed, but some steps failed")
+
+// errNewSecrets marks runs where --fail-on-new-secrets found secrets that
+// the previous run's --secrets-diff file didn't have
+var errNewSecrets = errors.New("new secrets found")
+
+// secretsDelta counts how a run's secrets changed since the previous run
+type secretsDelta struct {
+	added    int
+	resolved int
+}
+
+// diffSecrets narrows proc's report to the secrets missing from the file at
+// path, then saves this run's full set there for the next run to diff
+// against. The previous secrets of URLs that failed to download are kept.
+func diffSecrets(path string, proc *processor.Processor) (secretsDelta, error) {
+	previous, err := scanner.LoadSecrets(path)
+	if err != nil {
+		return secretsDelta{}, err
+	}
+
+	current, resolved := proc.DiffSecrets(previous)
+	delta := secretsDelta{
+		added:    len(proc.ActionableSecrets()), // Low-confidence noise does not count as new
+		resolved: len(resolved),
+	}
+	if err := scanner.SaveSecrets(path, current); err != nil {
+		return delta, err
+	}
+	return delta, nil
+}
+
+// notifyRun posts the summary of a completed run to --webhook-url, only
+// warning if that fails
+func notifyRun(cfg *config.Config, notifier *notify.Notifier, summary models.RunSummary, proc *processor.Processor, steps stepErrors, elapsed time.Duration) {
+	s := notify.Summary{
+		TotalURLs:  summary.Total,
+		Successful: summary.Successful,
+		Failed:     summary.Failed,
+		Skipped:    summary.Skipped,
+		DurationMs: elapsed.Milliseconds(),
+		OutputDir:  cfg.OutputDir,
+	}
+	if proc != nil {
+		s.Secrets = proc.GetReporter().GetReport().Statistics.SecretsCount
+	}
+	for _, err := range steps {
+		s.FailedSteps = append(s.FailedSteps, err.Error())
+	}
+
+	// The run's context may be cancelled already (Ctrl-C); still say how far it got
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Timeout)
+	defer cancel()
+	if err := notifier.Notify(ctx, s); err != nil {
+		log.Printf("[WARN] Webhook notification failed: %s", sanitize.Text(err.Error()))
+	} else if !cfg.Quiet {
+		ui.Success("Webhook notified")
+	}
+}
+
+// stepErrors collects failures of post-download steps (reports, archive),
+// which are recoverable: the run carries on and reports them at the end
+type stepErrors []error
+
+// fail records a failed step and warns about it right away
+func (s *stepErrors) fail(step string, err error) {
+	*s = append(*s, fmt.Errorf("%s: %w", step, err))
+	log.Printf("[ERROR] %s failed: %v", step, err)
+}
+
+// err returns nil if every step succeeded, otherwise an error wrapping
+// errPartialRun and each failure
+func (s stepErrors) err() error {
+	if len(s) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w:\n%w", errPartialRun, errors.Join(s...))
+}
+
+// runBenchmark downloads every URL into a discarding sink and prints throughput figures
+func runBenchmark(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, sink *storage.DiscardStorage, jobs []downloader.Job, limiter *ratelimit.HostLimiter) error {
+	if !cfg.Quiet {
+		log.Printf("\n[3/5] Benchmarking %d URLs with %d workers...", len(jobs), cfg.Workers)
+	}
+
+	var pb *ui.ProgressBar
+	if !cfg.Quiet && !cfg.NoProgress {
+		pb = ui.NewProgressBar(len(jobs), true)
+		fmt.Print(pb.Render())
+	}
+
+	report := benchmark.Run(ctx, dl, sink, jobs, limiter, func(completed, total int) {
+		if pb != nil {
+			pb.Update(completed)
+			fmt.Print(pb.Render())
+		}
+	})
+
+	if pb != nil {
+		pb.Finish()
+	}
+	if ctx.Err() != nil && !cfg.Quiet {
+		ui.Warning("Benchmark was interrupted; figures cover completed requests only")
+	}
+
+	// The figures are the point of the run, so print them even in quiet mode
+	fmt.Print("\n" + ui.RenderBenchmark(report))
+	return nil
+}
+
+// runDryRun plans every URL with a HEAD request and prints what would be downloaded where
+func runDryRun(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, urls []string, limiter *ratelimit.HostLimiter) error {
+	if !cfg.Quiet {
+		log.Printf("\n[3/5] Planning %d URLs with %d workers...", len(urls), cfg.Workers)
+	}
+
+	var pb *ui.ProgressBar
+	if !cfg.Quiet && !cfg.NoProgress {
+		pb = ui.NewProgressBar(len(urls), true)
+		fmt.Fprint(ui.Output(), pb.Render())
+	}
+
+	results := dl.DownloadAllWithRateLimit(ctx, urls, limiter, func(completed, total int) {
+		if pb != nil {
+			pb.Update(completed)
+			fmt.Fprint(ui.Output(), pb.Render())
+		}
+	})
+
+	if pb != nil {
+		pb.Finish()
+	}
+	if ctx.Err() != nil && !cfg.Quiet {
+		ui.Warning("Dry run was interrupted; the plan covers completed requests only")
+	}
+
+	// Results arrive as requests finish; list them in input order
+	order := make(map[string]int, len(urls))
+	for i, url := range urls {
+		order[url] = i
+	}
+	plainResults := make([]models.DownloadResult, len(results))
+	for i, r := range results {
+		plainResults[i] = *r
+	}
+	sort.SliceStable(plainResults, func(i, j int) bool {
+		return order[plainResults[i].URL] < order[plainResults[j].URL]
+	})
+
+	// The plan is the point of the run, so print it even in quiet mode
+	fmt.Print("\n" + ui.RenderPlan(plainResults))
+	return nil
+}
+
+// prepareOutputDir applies --require-empty and --clean to the output directory
+func prepareOutputDir(cfg *config.Config, in io.Reader, out io.Writer) error {
+	if cfg.RequireEmpty {
+		if err := storage.RequireEmpty(cfg.OutputDir); err != nil {
+			if errors.Is(err, storage.ErrOutputNotEmpty) {
+				return ui.WrapOutputNotEmpty(cfg.OutputDir, err)
+			}
+			return err
+		}
+	}
+	if !cfg.Clean {
+		return nil
+	}
+
+	if !cfg.Yes {
+		// The prompt would swallow URLs piped on stdin
+		if cfg.InputFile == "" && len(cfg.URLArgs) == 0 {
+			return fmt.Errorf("--clean needs --yes when URLs are read from stdin")
+		}
+		if !ui.Confirm(in, out, fmt.Sprintf("Remove everything in %s?", cfg.OutputDir)) {
+			return fmt.Errorf("aborted: output directory not cleaned")
+		}
+	}
+	if err := storage.CleanDir(cfg.OutputDir); err != nil {
+		return fmt.Errorf("--clean: %w", err)
+	}
+	if !cfg.Quiet {
+		ui.Success(fmt.Sprintf("Cleaned output directory: %s", cfg.OutputDir))
+	}
+	return nil
+}
+
+// insecureWarning makes --insecure warn once, not on every watch or schedule run
+var insecureWarning sync.Once
+
+// parseTLSOptions builds the client TLS settings from the --tls-*, --insecure and --ca-cert flags
+func parseTLSOptions(cfg *config.Config) (downloader.TLSOptions, error) {
+	var opts downloader.TLSOptions
+	var err error
+	if opts.MinVersion, err = downloader.ParseTLSVersion(cfg.TLSMinVersion); err != nil {
+		return opts, fmt.Errorf("invalid --tls-min-version: %w", err)
+	}
+	if opts.MaxVersion, err = downloader.ParseTLSVersion(cfg.TLSMaxVersion); err != nil {
+		return opts, fmt.Errorf("invalid --tls-max-version: %w", err)
+	}
+	if opts.CipherSuites, err = downloader.ParseCipherSuites(cfg.TLSCiphers); err != nil {
+		return opts, fmt.Errorf("invalid --tls-ciphers: %w", err)
+	}
+	if cfg.CACert != "" {
+		if opts.RootCAs, err = downloader.LoadCACerts(cfg.CACert); err != nil {
+			return opts, fmt.Errorf("invalid --ca-cert: %w", err)
+		}
+	}
+	opts.InsecureSkipVerify = cfg.Insecure
+	return opts, nil
+}
+
+// entryJobs turns input entries into jobs, each with its own headers and,
+// for JSON-lines records, method and body
+func entryJobs(entries []parser.Entry) []downloader.Job {
+	jobs := make([]downloader.Job, len(entries))
+	for i, e := range entries {
+		jobs[i] = downloader.Job{URL: e.URL, Headers: e.Headers, Request: downloader.RequestSpec{Method: e.Method}}
+		if e.HasBody {
+			jobs[i].Request.Body = []byte(e.Body)
+		}
+	}
+	return jobs
+}
+
+// newProcessor creates the post-download processor from the run configuration
+func newProcessor(cfg *config.Config, store storage.Storage) *processor.Processor {
+	// Already checked by runDownload
+	endpointScope, _ := scanner.ParseEndpointScope(cfg.EndpointsScope)
+	minConfidence, _ := scanner.ParseConfidence(cfg.SecretsMinConf)
+	var secretRules []scanner.SecretPattern
+	if cfg.SecretsRules != "" {
+		secretRules, _ = scanner.LoadSecretRules(cfg.SecretsRules)
+	}
+
+	proc := processor.NewProcessor(processor.Config{
+		ScanSecrets:          cfg.ScanSecrets,
+		ScanEndpoints:        cfg.ScanEndpoints,
+		JSBeautify:           cfg.JSBeautify || cfg.Beautify,
+		BeautifyCSS:          cfg.Beautify,
+		BeautifyJSON:         cfg.Beautify,
+		SecretsEntropy:       cfg.SecretsEntropy,
+		SecretsAssigned:      cfg.SecretsAssigned,
+		EntropyDebug:         cfg.EntropyDebug,
+		ScanMixedContent:     cfg.ScanMixedContent,
+		FileTimeout:          cfg.ProcessTimeout,
+		MeasureGzip:          cfg.MeasureGzip,
+		ScanBinary:           cfg.ScanBinary,
+		EndpointScope:        endpointScope,
+		MaxLineLength:        cfg.MaxLineLength,
+		SecretsMinConfidence: minConfidence,
+		SecretRules:          secretRules,
+		SummaryOnly:          cfg.StreamResults,
+	})
+
+	// Rows of a SQLite database are not files on disk
+	if db, ok := store.(*storage.SQLiteStorage); ok {
+		proc.SetFileReader(db.ReadFile)
+	}
+	return proc
+}
+
+// nucleiTemplate returns the Nuclei template metadata set by the --nuclei-* flags
+func nucleiTemplate(cfg *config.Config) scanner.NucleiTemplate {
+	return scanner.NucleiTemplate{
+		ID:       cfg.NucleiID,
+		Author:   cfg.NucleiAuthor,
+		Severity: cfg.NucleiSeverity,
+	}
+}
+
+// resultStream writes the text report and path list as results arrive
+type resultStream struct {
+	text  []*reporter.StreamWriter
+	paths io.WriteCloser
+}
+
+// openResultStream opens the incremental outputs of a streamed run
+func openResultStream(cfg *config.Config, formats []output.Format) (*resultStream, error) {
+	s := &resultStream{}
+	for _, format := range formats {
+		if format != output.FormatText {
+			continue
+		}
+		w, err := reporter.NewStreamWriter(output.ReportPath(format, cfg.OutputDir, cfg.OutputFile, len(formats) > 1))
+		if err != nil {
+			s.close()
+			return nil, fmt.Errorf("failed to generate report: %w", err)
+		}
+		s.text = append(s.text, w)
+	}
+
+	if cfg.PathsOutput != "" {
+		w, err := output.CreatePaths(cfg.PathsOutput)
+		if err != nil {
+			s.close()
+			return nil, fmt.Errorf("failed to write paths: %w", err)
+		}
+		s.paths = w
+	}
+
+	return s, nil
+}
+
+// add writes one result to every open output
+func (s *resultStream) add(result models.DownloadResult) {
+	for _, w := range s.text {
+		w.Add(result)
+	}
+	if s.paths != nil {
+		output.WritePaths(s.paths, []models.DownloadResult{result})
+	}
+}
+
+// close finishes every open output
+func (s *resultStream) close() error {
+	var errs []error
+	for _, w := range s.text {
+		if err := w.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to generate report: %w", err))
+		}
+	}
+	if s.paths != nil {
+		if err := s.paths.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to write paths: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// reportFiles returns the files written for reporting rather than downloaded:
+// the reports and the path list, stdout left out
+func reportFiles(cfg *config.Config, reportPaths []string) []string {
+	var files []string
+	for _, path := range reportPaths {
+		if path != "stdout" {
+			files = append(files, path)
+		}
+	}
+	if cfg.PathsOutput != "" && cfg.PathsOutput != "-" {
+		files = append(files, cfg.PathsOutput)
+	}
+	return files
+}
+
+// ndjsonDestination returns where NDJSON results are written: stdout, unless
+// --output-file names a file. It is "" if the format was not requested.
+func ndjsonDestination(cfg *config.Config, formats []output.Format) string {
+	for _, format := range formats {
+		if format != output.FormatNDJSON {
+			continue
+		}
+		if cfg.OutputFile == "" {
+			return "-"
+		}
+		return output.ReportPath(format, cfg.OutputDir, cfg.OutputFile, len(formats) > 1)
+	}
+	return ""
+}
+
+// needsScanReport reports whether any format is generated from the processor's scan report
+func needsScanReport(formats []output.Format) bool {
+	for _, format := range formats {
+		if format != output.FormatText && format != output.FormatNDJSON {
+			return true
+		}
+	}
+	return false
+}
+
 func separator(length int) string {
 	result := ""
 	for i := 0; i < length; i++ {
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lcalzada-xor/downurl/internal/config"
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/jsanalyzer"
+	"github.com/lcalzada-xor/downurl/internal/parser"
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+)
+
+// fetchSourceMaps downloads the source maps named by the sourceMappingURL
+// comments of the JavaScript in results (see jsanalyzer.SourceMappingURL)
+// and writes the original sources they embed under <output>/sources/<host>.
+// Inline data: maps are extracted without a download. The map downloads
+// are returned along with results, so they are reported and scanned too.
+func fetchSourceMaps(ctx context.Context, cfg *config.Config, dl *downloader.Downloader, limiter *ratelimit.HostLimiter, results []*downloader.Result) []*downloader.Result {
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		seen[r.URL] = true
+	}
+
+	var mapURLs []string
+	for _, r := range results {
+		for _, path := range r.Downloaded {
+			if !isJavaScript(path) {
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			ref := jsanalyzer.SourceMappingURL(r.URL, data)
+			switch {
+			case ref == "":
+			case strings.HasPrefix(ref, "data:"):
+				mapData, err := jsanalyzer.DecodeInlineSourceMap(ref)
+				if err != nil {
+					if !cfg.Quiet {
+						log.Printf("[WARN] Skipping inline source map of %s: %s", sanitize.Text(r.URL), sanitize.Text(err.Error()))
+					}
+					continue
+				}
+				extractSourceMap(cfg, r.URL, mapData)
+			case !seen[ref]:
+				seen[ref] = true
+				mapURLs = append(mapURLs, ref)
+			}
+		}
+	}
+	if len(mapURLs) == 0 || ctx.Err() != nil {
+		return results
+	}
+
+	if !cfg.Quiet {
+		log.Printf("  Source maps: downloading %d map(s)", len(mapURLs))
+	}
+	maps := dl.DownloadAllWithRateLimit(ctx, mapURLs, limiter, nil)
+	for _, r := range maps {
+		for _, path := range r.Downloaded {
+			if data, err := os.ReadFile(path); err == nil {
+				extractSourceMap(cfg, r.URL, data)
+			}
+		}
+	}
+	return append(results, maps...)
+}
+
+// extractSourceMap writes the sources embedded in the source map of
+// mapURL's host under <output>/sources/<host>
+func extractSourceMap(cfg *config.Config, mapURL string, data []byte) {
+	m, err := jsanalyzer.ParseSourceMap(data)
+	if err != nil {
+		if !cfg.Quiet {
+			log.Printf("[WARN] Skipping source map %s: %s", sanitize.Text(mapURL), sanitize.Text(err.Error()))
+		}
+		return
+	}
+
+	// Host names may carry a port; ".." would step out of sources/
+	host := strings.NewReplacer(":", "_", "..", "_").Replace(parser.HostnameFromURL(mapURL))
+	dir := filepath.Join(cfg.OutputDir, "sources", host)
+	written, err := jsanalyzer.ExtractSources(m, dir)
+	if err != nil && !cfg.Quiet {
+		log.Printf("[WARN] Source map %s: %s", sanitize.Text(mapURL), sanitize.Text(err.Error()))
+	}
+	if len(written) > 0 && !cfg.Quiet {
+		log.Printf("  Source map %s: %d original source(s) written to %s", sanitize.Text(mapURL), len(written), sanitize.Text(dir))
+	}
+}
@@ -1,3 +1,18 @@
 module github.com/lcalzada-xor/downurl
 
 go 1.24.9
+
+require (
+	github.com/andybalholm/brotli v1.2.0
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/quic-go/quic-go v0.59.1
+	golang.org/x/term v0.34.0
+)
+
+require (
+	github.com/quic-go/qpack v0.6.0 // indirect
+	golang.org/x/crypto v0.41.0 // indirect
+	golang.org/x/net v0.43.0 // indirect
+	golang.org/x/sys v0.35.0 // indirect
+	golang.org/x/text v0.28.0 // indirect
+)
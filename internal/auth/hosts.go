@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"sort"
+	"strings"
+)
+
+// HostProviders picks the provider for a request by its host. Keys are
+// exact host names ("api.github.com") or wildcards ("*.corp", matching any
+// subdomain of corp); an exact name wins over wildcards, and a longer
+// wildcard over a shorter one.
+type HostProviders struct {
+	exact     map[string]*Provider
+	wildcards []string // Suffixes such as ".corp", longest first
+	bySuffix  map[string]*Provider
+}
+
+// NewHostProviders indexes providers by host key
+func NewHostProviders(byHost map[string]*Provider) *HostProviders {
+	h := &HostProviders{
+		exact:    make(map[string]*Provider),
+		bySuffix: make(map[string]*Provider),
+	}
+	for key, p := range byHost {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if strings.HasPrefix(key, "*.") {
+			suffix := key[1:]
+			h.wildcards = append(h.wildcards, suffix)
+			h.bySuffix[suffix] = p
+			continue
+		}
+		h.exact[key] = p
+	}
+	sort.Slice(h.wildcards, func(i, j int) bool {
+		return len(h.wildcards[i]) > len(h.wildcards[j])
+	})
+	return h
+}
+
+// For returns the provider for host (a host name, without port), or nil if
+// no key matches
+func (h *HostProviders) For(host string) *Provider {
+	if h == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+	if p, ok := h.exact[host]; ok {
+		return p
+	}
+	for _, suffix := range h.wildcards {
+		if strings.HasSuffix(host, suffix) {
+			return h.bySuffix[suffix]
+		}
+	}
+	return nil
+}
+
+// Len returns the number of host keys
+func (h *HostProviders) Len() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.exact) + len(h.wildcards)
+}
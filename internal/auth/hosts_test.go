@@ -0,0 +1,42 @@
+package auth
+
+import "testing"
+
+func TestHostProviders_For(t *testing.T) {
+	github := &Provider{authType: AuthTypeBearer}
+	corp := &Provider{authType: AuthTypeBasic}
+	eu := &Provider{authType: AuthTypeCustom}
+
+	hosts := NewHostProviders(map[string]*Provider{
+		"api.github.com": github,
+		"*.corp":         corp,
+		"*.EU.corp":      eu,
+	})
+	if hosts.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", hosts.Len())
+	}
+
+	tests := []struct {
+		host string
+		want *Provider
+	}{
+		{"api.github.com", github},
+		{"API.GitHub.com", github},
+		{"github.com", nil},
+		{"internal.corp", corp},
+		{"a.b.corp", corp},
+		{"corp", nil},
+		{"wiki.eu.corp", eu},
+		{"example.com", nil},
+	}
+	for _, tt := range tests {
+		if got := hosts.For(tt.host); got != tt.want {
+			t.Errorf("For(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+	}
+
+	var none *HostProviders
+	if none.For("api.github.com") != nil || none.Len() != 0 {
+		t.Error("nil HostProviders should match nothing")
+	}
+}
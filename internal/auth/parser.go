@@ -7,8 +7,17 @@ import (
 	"strings"
 )
 
+// expandEnv expands ${VAR} references from the environment, like config files do.
+// Values without "${" are returned untouched, so a literal "$" stays as-is.
+func expandEnv(value string) string {
+	if strings.Contains(value, "${") {
+		return os.ExpandEnv(value)
+	}
+	return value
+}
+
 // ParseHeadersFile parses a headers file and returns a map of headers
-// Format: "Header-Name: value"
+// Format: "Header-Name: value" (values may reference ${VAR} environment variables)
 func ParseHeadersFile(filepath string) (map[string]string, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -36,7 +45,7 @@ func ParseHeadersFile(filepath string) (map[string]string, error) {
 		}
 
 		headerName := strings.TrimSpace(parts[0])
-		headerValue := strings.TrimSpace(parts[1])
+		headerValue := expandEnv(strings.TrimSpace(parts[1]))
 
 		if headerName == "" {
 			return nil, fmt.Errorf("empty header name at line %d", lineNum)
@@ -57,7 +66,7 @@ func ParseHeadersFile(filepath string) (map[string]string, error) {
 }
 
 // ParseCookiesFile parses a cookies file and returns a map of cookies
-// Format: "name=value"
+// Format: "name=value" (values may reference ${VAR} environment variables)
 func ParseCookiesFile(filepath string) (map[string]string, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -85,7 +94,7 @@ func ParseCookiesFile(filepath string) (map[string]string, error) {
 		}
 
 		cookieName := strings.TrimSpace(parts[0])
-		cookieValue := strings.TrimSpace(parts[1])
+		cookieValue := expandEnv(strings.TrimSpace(parts[1]))
 
 		if cookieName == "" {
 			return nil, fmt.Errorf("empty cookie name at line %d", lineNum)
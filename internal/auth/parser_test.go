@@ -129,6 +129,52 @@ another=valid
 	}
 }
 
+func TestParseHeadersFile_EnvExpansion(t *testing.T) {
+	t.Setenv("DOWNURL_TEST_TOKEN", "s3cr3t")
+
+	headersFile := filepath.Join(t.TempDir(), "headers.txt")
+	content := `Authorization: Bearer ${DOWNURL_TEST_TOKEN}
+X-Price: $5
+X-Missing: ${DOWNURL_TEST_UNSET}
+`
+	if err := os.WriteFile(headersFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	headers, err := ParseHeadersFile(headersFile)
+	if err != nil {
+		t.Fatalf("ParseHeadersFile() error = %v", err)
+	}
+
+	if headers["Authorization"] != "Bearer s3cr3t" {
+		t.Errorf("Authorization header = %v, want 'Bearer s3cr3t'", headers["Authorization"])
+	}
+	if headers["X-Price"] != "$5" {
+		t.Errorf("X-Price header = %v, want '$5' left as-is", headers["X-Price"])
+	}
+	if headers["X-Missing"] != "" {
+		t.Errorf("X-Missing header = %v, want empty for an unset variable", headers["X-Missing"])
+	}
+}
+
+func TestParseCookiesFile_EnvExpansion(t *testing.T) {
+	t.Setenv("DOWNURL_TEST_SESSION", "abc123")
+
+	cookiesFile := filepath.Join(t.TempDir(), "cookies.txt")
+	if err := os.WriteFile(cookiesFile, []byte("session=${DOWNURL_TEST_SESSION}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	cookies, err := ParseCookiesFile(cookiesFile)
+	if err != nil {
+		t.Fatalf("ParseCookiesFile() error = %v", err)
+	}
+
+	if cookies["session"] != "abc123" {
+		t.Errorf("session cookie = %v, want 'abc123'", cookies["session"])
+	}
+}
+
 func TestParseCookieString(t *testing.T) {
 	tests := []struct {
 		name     string
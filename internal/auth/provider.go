@@ -164,6 +164,19 @@ func validateConfig(cfg Config) error {
 	return nil
 }
 
+// HeaderNames returns the canonical names of the headers the provider sets
+// besides Authorization and Cookie; they carry credentials
+func (p *Provider) HeaderNames() []string {
+	if p == nil {
+		return nil
+	}
+	names := make([]string, 0, len(p.headers))
+	for name := range p.headers {
+		names = append(names, http.CanonicalHeaderKey(name))
+	}
+	return names
+}
+
 // GetType returns the authentication type
 func (p *Provider) GetType() AuthType {
 	if p == nil {
@@ -0,0 +1,82 @@
+package benchmark
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// Report summarizes the throughput of a benchmark run
+type Report struct {
+	Requests       int           // URLs attempted
+	Succeeded      int           // URLs downloaded completely
+	Failed         int           // URLs that failed or were skipped
+	Bytes          int64         // Body bytes received
+	Elapsed        time.Duration // Wall-clock time of the run
+	RequestsPerSec float64       // Completed requests per second
+	MBPerSec       float64       // Body bytes per second, in MiB
+	P50            time.Duration // Median per-URL latency
+	P95            time.Duration // 95th percentile per-URL latency
+	Max            time.Duration // Slowest URL
+}
+
+// Run downloads jobs with dl, which must have been created with sink as its
+// storage, and measures throughput. The downloader's worker count and the
+// limiter (nil for none) apply exactly as in a normal run.
+func Run(ctx context.Context, dl *downloader.Downloader, sink *storage.DiscardStorage, jobs []downloader.Job, limiter *ratelimit.HostLimiter, callback downloader.ProgressCallback) Report {
+	start := time.Now()
+	results := dl.DownloadJobs(ctx, jobs, limiter, callback)
+	elapsed := time.Since(start)
+
+	return Summarize(results, sink.Bytes(), elapsed)
+}
+
+// Summarize computes throughput figures from finished results
+func Summarize(results []*models.DownloadResult, bytes int64, elapsed time.Duration) Report {
+	r := Report{
+		Requests: len(results),
+		Bytes:    bytes,
+		Elapsed:  elapsed,
+	}
+
+	latencies := make([]time.Duration, 0, len(results))
+	for _, res := range results {
+		if res.IsSuccess() {
+			r.Succeeded++
+		} else {
+			r.Failed++
+		}
+		latencies = append(latencies, res.Duration)
+	}
+
+	if secs := elapsed.Seconds(); secs > 0 {
+		r.RequestsPerSec = float64(r.Requests) / secs
+		r.MBPerSec = float64(bytes) / secs / 1024 / 1024
+	}
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	r.P50 = percentile(latencies, 50)
+	r.P95 = percentile(latencies, 95)
+	if len(latencies) > 0 {
+		r.Max = latencies[len(latencies)-1]
+	}
+
+	return r
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted durations
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
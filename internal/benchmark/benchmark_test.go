@@ -0,0 +1,89 @@
+package benchmark
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestRun(t *testing.T) {
+	body := strings.Repeat("x", 4096)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.js" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	urls := []string{server.URL + "/missing.js"}
+	for i := 0; i < 10; i++ {
+		urls = append(urls, fmt.Sprintf("%s/file%d.js", server.URL, i))
+	}
+
+	sink := storage.NewDiscardStorage(t.TempDir())
+	client := downloader.NewHTTPClient(5*time.Second, 0)
+	dl := downloader.New(client, sink, 4)
+
+	report := Run(context.Background(), dl, sink, downloader.NewJobs(urls), nil, nil)
+
+	if report.Requests != 11 {
+		t.Errorf("Requests = %d, want 11", report.Requests)
+	}
+	if report.Succeeded != 10 {
+		t.Errorf("Succeeded = %d, want 10", report.Succeeded)
+	}
+	if report.Failed != 1 {
+		t.Errorf("Failed = %d, want 1", report.Failed)
+	}
+	if want := int64(10 * len(body)); report.Bytes != want {
+		t.Errorf("Bytes = %d, want %d", report.Bytes, want)
+	}
+	if report.RequestsPerSec <= 0 {
+		t.Errorf("RequestsPerSec = %v, want > 0", report.RequestsPerSec)
+	}
+	if report.MBPerSec <= 0 {
+		t.Errorf("MBPerSec = %v, want > 0", report.MBPerSec)
+	}
+	if report.P50 > report.P95 || report.P95 > report.Max {
+		t.Errorf("latencies out of order: p50=%v p95=%v max=%v", report.P50, report.P95, report.Max)
+	}
+}
+
+func TestSummarizePercentiles(t *testing.T) {
+	var results []*models.DownloadResult
+	for i := 1; i <= 100; i++ {
+		results = append(results, &models.DownloadResult{
+			URL:        fmt.Sprintf("https://example.com/%d", i),
+			Downloaded: []string{"f"},
+			Duration:   time.Duration(i) * time.Millisecond,
+		})
+	}
+
+	report := Summarize(results, 1024*1024, 2*time.Second)
+
+	if report.P50 != 50*time.Millisecond {
+		t.Errorf("P50 = %v, want 50ms", report.P50)
+	}
+	if report.P95 != 95*time.Millisecond {
+		t.Errorf("P95 = %v, want 95ms", report.P95)
+	}
+	if report.Max != 100*time.Millisecond {
+		t.Errorf("Max = %v, want 100ms", report.Max)
+	}
+	if report.RequestsPerSec != 50 {
+		t.Errorf("RequestsPerSec = %v, want 50", report.RequestsPerSec)
+	}
+	if report.MBPerSec != 0.5 {
+		t.Errorf("MBPerSec = %v, want 0.5", report.MBPerSec)
+	}
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entry describes a cached response
+type Entry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Size         int64     `json:"size"`
+	LastUsed     time.Time `json:"last_used"`
+}
+
+// Cache stores response bodies with their validators (ETag, Last-Modified)
+// in a directory, one body file and one JSON metadata file per URL. When the
+// bodies grow beyond the size limit, the least recently used are evicted.
+type Cache struct {
+	dir      string
+	maxBytes int64 // 0 = unlimited
+	mu       sync.Mutex
+	now      func() time.Time
+}
+
+// Open creates the cache directory if needed and returns a cache using it
+func Open(dir string, maxBytes int64) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	return &Cache{dir: dir, maxBytes: maxBytes, now: time.Now}, nil
+}
+
+// key returns the file name stem for a URL
+func key(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *Cache) bodyPath(url string) string { return filepath.Join(c.dir, key(url)+".body") }
+func (c *Cache) metaPath(url string) string { return filepath.Join(c.dir, key(url)+".json") }
+
+// Lookup returns the entry for url if its body is present and complete
+func (c *Cache) Lookup(url string) (*Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, err := readEntry(c.metaPath(url))
+	if err != nil || entry.URL != url {
+		return nil, false
+	}
+	info, err := os.Stat(c.bodyPath(url))
+	if err != nil || info.Size() != entry.Size {
+		return nil, false
+	}
+	return entry, true
+}
+
+// Open returns the cached body for url and marks it recently used
+func (c *Cache) Open(url string) (*os.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, err := os.Open(c.bodyPath(url))
+	if err != nil {
+		return nil, err
+	}
+	if entry, err := readEntry(c.metaPath(url)); err == nil {
+		entry.LastUsed = c.now()
+		writeEntry(c.metaPath(url), entry)
+	}
+	return f, nil
+}
+
+// Writer collects a body for the cache. Nothing is visible to Lookup until
+// Commit; Abort discards the body.
+type Writer struct {
+	cache *Cache
+	url   string
+	file  *os.File
+	size  int64
+}
+
+// NewWriter starts caching a body for url
+func (c *Cache) NewWriter(url string) (*Writer, error) {
+	f, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cache file: %w", err)
+	}
+	return &Writer{cache: c, url: url, file: f}, nil
+}
+
+// Write appends to the body
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Reset discards everything written so far, for a download starting over
+func (w *Writer) Reset() error {
+	if err := w.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := w.file.Seek(0, io.SeekStart)
+	w.size = 0
+	return err
+}
+
+// Commit stores the body under its URL with the response's validators and
+// evicts old entries if the cache is over its size limit
+func (w *Writer) Commit(etag, lastModified string) error {
+	if err := w.file.Close(); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+
+	c := w.cache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(w.file.Name(), c.bodyPath(w.url)); err != nil {
+		os.Remove(w.file.Name())
+		return fmt.Errorf("failed to store cache file: %w", err)
+	}
+	entry := &Entry{URL: w.url, ETag: etag, LastModified: lastModified, Size: w.size, LastUsed: c.now()}
+	if err := writeEntry(c.metaPath(w.url), entry); err != nil {
+		os.Remove(c.bodyPath(w.url))
+		return err
+	}
+	return c.evict()
+}
+
+// Abort discards the body
+func (w *Writer) Abort() {
+	w.file.Close()
+	os.Remove(w.file.Name())
+}
+
+// evict removes least recently used entries until the bodies fit in maxBytes.
+// The caller holds c.mu.
+func (c *Cache) evict() error {
+	if c.maxBytes <= 0 {
+		return nil
+	}
+
+	metas, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+
+	type stored struct {
+		stem  string
+		entry *Entry
+	}
+	var entries []stored
+	var total int64
+	for _, meta := range metas {
+		entry, err := readEntry(meta)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, stored{strings.TrimSuffix(meta, ".json"), entry})
+		total += entry.Size
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].entry.LastUsed.Before(entries[j].entry.LastUsed)
+	})
+	for _, e := range entries {
+		if total <= c.maxBytes {
+			break
+		}
+		os.Remove(e.stem + ".body")
+		os.Remove(e.stem + ".json")
+		total -= e.entry.Size
+	}
+	return nil
+}
+
+// readEntry loads entry metadata from path
+func readEntry(path string) (*Entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// writeEntry saves entry metadata to path, replacing it atomically
+func writeEntry(path string, entry *Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache entry: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
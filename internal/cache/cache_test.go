@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+// store caches body for url through a Writer
+func store(t *testing.T, c *Cache, url, body, etag string) {
+	t.Helper()
+	w, err := c.NewWriter(url)
+	if err != nil {
+		t.Fatalf("NewWriter() error = %v", err)
+	}
+	if _, err := w.Write([]byte(body)); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if err := w.Commit(etag, ""); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+}
+
+func TestCache_StoreAndLookup(t *testing.T) {
+	c, err := Open(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+
+	if _, ok := c.Lookup("https://example.com/app.js"); ok {
+		t.Error("Lookup() found an entry in an empty cache")
+	}
+
+	// A reset discards the partial body of a failed attempt
+	w, _ := c.NewWriter("https://example.com/app.js")
+	w.Write([]byte("partial"))
+	if err := w.Reset(); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	w.Write([]byte("console.log(1)"))
+	if err := w.Commit(`"v1"`, "Mon, 02 Jan 2006 15:04:05 GMT"); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	entry, ok := c.Lookup("https://example.com/app.js")
+	if !ok {
+		t.Fatal("Lookup() found no entry after Commit()")
+	}
+	if entry.ETag != `"v1"` || entry.LastModified != "Mon, 02 Jan 2006 15:04:05 GMT" || entry.Size != 14 {
+		t.Errorf("Lookup() = %+v", entry)
+	}
+
+	f, err := c.Open("https://example.com/app.js")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer f.Close()
+	data, _ := io.ReadAll(f)
+	if string(data) != "console.log(1)" {
+		t.Errorf("cached body = %q, want %q", data, "console.log(1)")
+	}
+
+	// An aborted writer leaves the stored entry alone
+	w, _ = c.NewWriter("https://example.com/app.js")
+	w.Write([]byte("other"))
+	w.Abort()
+	if entry, ok := c.Lookup("https://example.com/app.js"); !ok || entry.ETag != `"v1"` {
+		t.Errorf("Lookup() after Abort() = %+v, %v", entry, ok)
+	}
+}
+
+func TestCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c, err := Open(t.TempDir(), 25)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+
+	body := strings.Repeat("x", 10)
+	store(t, c, "https://example.com/a.js", body, `"a"`)
+	store(t, c, "https://example.com/b.js", body, `"b"`)
+
+	// Using a makes b the least recently used
+	f, err := c.Open("https://example.com/a.js")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	f.Close()
+
+	store(t, c, "https://example.com/c.js", body, `"c"`)
+
+	for url, want := range map[string]bool{
+		"https://example.com/a.js": true,
+		"https://example.com/b.js": false,
+		"https://example.com/c.js": true,
+	} {
+		if _, ok := c.Lookup(url); ok != want {
+			t.Errorf("Lookup(%s) = %v, want %v", url, ok, want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package config
+
+import "strings"
+
+// applyArgs sorts positional arguments into URLs and the input file.
+// Arguments with an http:// or https:// scheme are URLs to download; the
+// first other argument is the input file unless --input was given. Anything
+// left over is reported by Validate.
+func applyArgs(cfg *Config, args []string) {
+	for _, arg := range args {
+		switch {
+		case isURLArg(arg):
+			cfg.URLArgs = append(cfg.URLArgs, arg)
+		case cfg.InputFile == "":
+			cfg.InputFile = arg
+		default:
+			cfg.strayArgs = append(cfg.strayArgs, arg)
+		}
+	}
+}
+
+// isURLArg reports whether a positional argument is a URL rather than a file path
+func isURLArg(arg string) bool {
+	lower := strings.ToLower(arg)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
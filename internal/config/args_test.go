@@ -0,0 +1,59 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyArgs(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		args      []string
+		wantInput string
+		wantURLs  []string
+		wantStray []string
+	}{
+		{"input file", "", []string{"urls.txt"}, "urls.txt", nil, nil},
+		{"single URL", "", []string{"https://a.com/x.js"}, "", []string{"https://a.com/x.js"}, nil},
+		{
+			"several URLs", "",
+			[]string{"https://a.com/x.js", "http://b.com/y.js", "HTTPS://c.com/z.js"},
+			"", []string{"https://a.com/x.js", "http://b.com/y.js", "HTTPS://c.com/z.js"}, nil,
+		},
+		{"file and URLs", "", []string{"urls.txt", "https://a.com/x.js"}, "urls.txt", []string{"https://a.com/x.js"}, nil},
+		{"--input already set", "list.txt", []string{"urls.txt", "https://a.com/x.js"}, "list.txt", []string{"https://a.com/x.js"}, []string{"urls.txt"}},
+		{"ftp is not a URL argument", "", []string{"urls.txt", "ftp://a.com/x"}, "urls.txt", nil, []string{"ftp://a.com/x"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{InputFile: tt.input}
+			applyArgs(cfg, tt.args)
+
+			if cfg.InputFile != tt.wantInput {
+				t.Errorf("InputFile = %q, want %q", cfg.InputFile, tt.wantInput)
+			}
+			if !reflect.DeepEqual(cfg.URLArgs, tt.wantURLs) {
+				t.Errorf("URLArgs = %v, want %v", cfg.URLArgs, tt.wantURLs)
+			}
+			if !reflect.DeepEqual(cfg.strayArgs, tt.wantStray) {
+				t.Errorf("strayArgs = %v, want %v", cfg.strayArgs, tt.wantStray)
+			}
+		})
+	}
+}
+
+func TestValidate_URLArgs(t *testing.T) {
+	cfg := &Config{}
+	applyArgs(cfg, []string{"https://a.com/x.js", "https://b.com/y.js"})
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() error = %v, want nil for URL arguments without an input file", err)
+	}
+
+	cfg = &Config{}
+	applyArgs(cfg, []string{"urls.txt", "more.txt"})
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() expected error for a second non-URL argument")
+	}
+}
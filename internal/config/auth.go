@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/lcalzada-xor/downurl/internal/auth"
 )
@@ -107,3 +108,72 @@ func (c *Config) BuildAuthProvider() (*auth.Provider, error) {
 	// Create and return provider
 	return auth.NewProvider(authCfg)
 }
+
+// BuildHostAuth creates the per-host providers from the [auth.<host>]
+// sections of .downurlrc (see ConfigFile.ApplyToConfig). A section takes
+// one of bearer, basic (user:pass) or header (an Authorization value), and
+// optionally cookie ("name=value; name2=value2"). It returns nil if there
+// are no sections.
+func (c *Config) BuildHostAuth() (*auth.HostProviders, error) {
+	if len(c.HostAuth) == 0 {
+		return nil, nil
+	}
+
+	// Sorted, so the first bad section reported is always the same one
+	hosts := make([]string, 0, len(c.HostAuth))
+	for host := range c.HostAuth {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+
+	byHost := make(map[string]*auth.Provider, len(hosts))
+	for _, host := range hosts {
+		provider, err := hostProvider(c.HostAuth[host])
+		if err != nil {
+			return nil, fmt.Errorf("[auth.%s]: %w", host, err)
+		}
+		byHost[host] = provider
+	}
+	return auth.NewHostProviders(byHost), nil
+}
+
+// hostProvider builds the provider for one [auth.<host>] section
+func hostProvider(section map[string]string) (*auth.Provider, error) {
+	authCfg := auth.Config{
+		Type:    auth.AuthTypeCustom,
+		Headers: make(map[string]string),
+		Cookies: make(map[string]string),
+	}
+
+	methods := 0
+	if token := section["bearer"]; token != "" {
+		methods++
+		authCfg.Type = auth.AuthTypeBearer
+		authCfg.Token = token
+	}
+	if basic := section["basic"]; basic != "" {
+		methods++
+		username, password, err := auth.ParseBasicAuth(basic)
+		if err != nil {
+			return nil, fmt.Errorf("invalid basic auth format: %w", err)
+		}
+		authCfg.Type = auth.AuthTypeBasic
+		authCfg.Username = username
+		authCfg.Password = password
+	}
+	if header := section["header"]; header != "" {
+		methods++
+		authCfg.Headers["Authorization"] = header
+	}
+	if methods > 1 {
+		return nil, fmt.Errorf("multiple authentication methods specified (use only one of: bearer, basic, header)")
+	}
+
+	if cookie := section["cookie"]; cookie != "" {
+		for k, v := range auth.ParseCookieString(cookie) {
+			authCfg.Cookies[k] = v
+		}
+	}
+
+	return auth.NewProvider(authCfg)
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+)
+
+func TestBuildHostAuth(t *testing.T) {
+	cf := &ConfigFile{Auth: map[string]map[string]string{
+		"api.github.com": {"bearer": "gh-token"},
+		"*.corp":         {"basic": "alice:secret", "cookie": "sso=1"},
+		"legacy.example": {"header": "Token abc"},
+	}}
+	c := &Config{}
+	cf.ApplyToConfig(c)
+
+	hosts, err := c.BuildHostAuth()
+	if err != nil {
+		t.Fatalf("BuildHostAuth() error = %v", err)
+	}
+	want := map[string]auth.AuthType{
+		"api.github.com": auth.AuthTypeBearer,
+		"internal.corp":  auth.AuthTypeBasic,
+		"legacy.example": auth.AuthTypeCustom,
+	}
+	for host, typ := range want {
+		if got := hosts.For(host).GetType(); got != typ {
+			t.Errorf("For(%q) type = %s, want %s", host, got, typ)
+		}
+	}
+	if hosts.For("example.com") != nil {
+		t.Error("For(example.com) should not match any section")
+	}
+
+	// No sections, no providers
+	if hosts, err := (&Config{}).BuildHostAuth(); hosts != nil || err != nil {
+		t.Errorf("BuildHostAuth() without sections = %v, %v", hosts, err)
+	}
+}
+
+func TestBuildHostAuth_Invalid(t *testing.T) {
+	for _, section := range []map[string]string{
+		{"bearer": "t", "basic": "u:p"},
+		{"basic": ":nouser"},
+		{"note": "nothing usable"},
+	} {
+		c := &Config{HostAuth: map[string]map[string]string{"example.com": section}}
+		if _, err := c.BuildHostAuth(); err == nil {
+			t.Errorf("BuildHostAuth(%v) expected error", section)
+		}
+	}
+}
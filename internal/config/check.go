@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+)
+
+// rcKeys lists the keys read from each .downurlrc section, with a check of
+// their value (nil accepts any value)
+var rcKeys = map[string]map[string]func(string) error{
+	"defaults": {
+		"output":      nil,
+		"mode":        nil,
+		"host_layout": nil,
+		"flat_host":   checkBool,
+		"retain":      checkInt,
+		"workers":     checkInt,
+		"timeout":     checkDuration,
+	},
+	"filters": {
+		"extensions":         nil,
+		"exclude_extensions": nil,
+		"max_size":           checkSize,
+	},
+}
+
+// rcAuthKeys lists the keys read from [auth.<host>] sections
+var rcAuthKeys = map[string]func(string) error{
+	"bearer": nil,
+	"basic":  checkBasic,
+	"header": nil,
+	"cookie": nil,
+}
+
+// CheckConfigFile reports what LoadConfigFile silently ignores in a
+// .downurlrc: malformed lines, unknown sections and keys, and values that
+// don't parse. Each problem names its line.
+func CheckConfigFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []string
+	section := ""
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lineNum := i + 1
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.Trim(line, "[]")
+			if _, ok := rcKeys[section]; !ok && section != "ratelimit" && !strings.HasPrefix(section, "auth.") {
+				problems = append(problems, fmt.Sprintf("line %d: unknown section [%s]", lineNum, section))
+			}
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: expected 'key = value', got %q", lineNum, line))
+			continue
+		}
+		key = strings.TrimSpace(key)
+		value = strings.Trim(strings.TrimSpace(value), "\"'")
+
+		keys, known := rcKeys[section]
+		if strings.HasPrefix(section, "auth.") {
+			keys, known = rcAuthKeys, true
+		}
+		// [ratelimit] keys are hosts (or "default"), each with a limit
+		if section == "ratelimit" {
+			if err := checkRateLimitHost(key); err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: invalid [ratelimit] host %q: %v", lineNum, key, err))
+				continue
+			}
+			keys, known = map[string]func(string) error{key: checkRateLimit}, true
+		}
+		if !known {
+			if section == "" {
+				problems = append(problems, fmt.Sprintf("line %d: %q is outside any section", lineNum, key))
+			}
+			continue
+		}
+		check, ok := keys[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d: unknown key %q in [%s]", lineNum, key, section))
+			continue
+		}
+		if check != nil && !strings.Contains(value, "${") {
+			if err := check(value); err != nil {
+				problems = append(problems, fmt.Sprintf("line %d: invalid %s %q: %v", lineNum, key, value, err))
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+func checkBool(s string) error {
+	_, err := strconv.ParseBool(s)
+	return err
+}
+
+func checkInt(s string) error {
+	_, err := strconv.Atoi(s)
+	return err
+}
+
+func checkDuration(s string) error {
+	_, err := time.ParseDuration(s)
+	return err
+}
+
+func checkBasic(s string) error {
+	_, _, err := auth.ParseBasicAuth(s)
+	return err
+}
+
+func checkRateLimit(s string) error {
+	_, err := ratelimit.ParseRateLimit(s)
+	return err
+}
+
+func checkSize(s string) error {
+	_, err := parseSize(s)
+	return err
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCheckConfigFile(t *testing.T) {
+	rc := `# comment
+[defaults]
+workers = 20
+wokers = 5
+timeout = soon
+output = ${HOME}/out
+
+[filters]
+max_size = 50MB
+exclude_extensions = map
+
+[auth.example.com]
+token = abc
+basic = :secret
+
+[ratelimit]
+api.example.com = 2/second
+cdn.example.com = fast
+api.example.com:8443 = 1/second
+
+[proxy]
+not a key value line
+`
+	path := filepath.Join(t.TempDir(), ".downurlrc")
+	if err := os.WriteFile(path, []byte(rc), 0644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	got, err := CheckConfigFile(path)
+	if err != nil {
+		t.Fatalf("CheckConfigFile() error = %v", err)
+	}
+	want := []string{
+		`line 4: unknown key "wokers" in [defaults]`,
+		`line 5: invalid timeout "soon": time: invalid duration "soon"`,
+		`line 13: unknown key "token" in [auth.example.com]`,
+		`line 14: invalid basic ":secret": invalid basic auth format (expected 'username:password')`,
+		`line 18: invalid cdn.example.com "fast": invalid rate limit format: fast (expected number/period)`,
+		`line 19: invalid [ratelimit] host "api.example.com:8443": limits apply to every port of a host; remove :8443`,
+		`line 21: unknown section [proxy]`,
+		`line 22: expected 'key = value', got "not a key value line"`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckConfigFile() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -6,66 +6,166 @@ import (
 	"os"
 	"strconv"
 	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/logging"
+	"github.com/lcalzada-xor/downurl/internal/output"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/internal/watcher"
 )
 
 // Config holds all configuration for the downloader
 type Config struct {
-	InputFile     string        // Path to file containing URLs
-	OutputDir     string        // Directory to save downloaded files
-	Workers       int           // Number of concurrent workers
-	Timeout       time.Duration // HTTP request timeout
-	RetryAttempts int           // Number of retry attempts per download
+	InputFile        string        // Path to file containing URLs
+	SkipInvalid      bool          // Log and skip malformed URL lines instead of aborting the run
+	CSVColumn        string        // URL column of a .csv input: header name or 1-based number ("" = "url" header, else first)
+	ListURLs         bool          // Print the URLs a run would fetch, one per line, and exit
+	Checkpoint       string        // File recording finished URLs, so an interrupted run can be resumed
+	OutputDir        string        // Directory to save downloaded files
+	OutputTemplate   string        // OutputDir as given, before ExpandOutputDir (expanded again for each scheduled or watched run)
+	Workers          int           // Number of concurrent workers
+	PerHostLimit     int           // Most concurrent requests to a single host (0 = no limit)
+	Timeout          time.Duration // HTTP request timeout
+	RetryAttempts    int           // Number of retry attempts per download
+	RetryInterrupted bool          // Retry downloads cut off mid-body (connection reset, unexpected EOF)
+	NoDecompress     bool          // Save gzip/deflate bodies as served instead of decoding them
+	PreviewBytes     int64         // Download only the first N bytes of each file via a Range request (0 = whole files)
+	MaxTotalBytes    int64         // Stop starting downloads once this many bytes are saved (0 = no limit)
+	MaxDownloadSize  int64         // Largest single download; bigger bodies fail (0 = no limit)
+	VerifyHashes     string        // File of expected SHA-256/MD5 digests by URL or filename; mismatches fail
+	DeleteMismatched bool          // Delete files that fail --verify-hashes instead of keeping them
+	NormalizeText    bool          // Save text files as UTF-8 without a BOM, re-encoding from the declared charset
+	ErrorCategories  string        // Failure categories for HTTP statuses, e.g. "401=auth,403=auth,5xx=upstream"
+	TLSMinVersion    string        // Lowest TLS version to negotiate: 1.0, 1.1, 1.2, 1.3
+	TLSMaxVersion    string        // Highest TLS version to negotiate
+	TLSCiphers       string        // Comma-separated TLS 1.0-1.2 cipher suite names
+	Insecure         bool          // Skip TLS certificate verification
+	CACert           string        // PEM bundle of extra certificate authorities to trust
+	TempDir          string        // Directory for temporary files such as in-progress archives ("" = system temp)
+	ArchiveMinRate   float64       // Skip the archive when fewer than this percent of downloads succeeded (0 = always archive)
+	NoArchive        bool          // Do not create an archive of the output directory
+	ArchiveFormat    string        // Archive format: tar.gz or zip
+	ArchiveNoReports bool          // Leave the reports and path list out of the archive
+	Dedupe           bool          // Find files with identical content once downloads complete
+	DedupeMode       string        // What --dedupe does with them: report, hardlink or symlink
+	HTTP3            bool          // Try HTTP/3 (QUIC) first, falling back to HTTP/2 and HTTP/1.1
+	DNSCacheTTL      time.Duration // How long resolved hosts are remembered (0 = no caching)
+	DNSMaxLookups    int           // Maximum concurrent DNS lookups (0 = unlimited)
+	ProxiesFile      string        // File with one proxy URL per line; requests are spread over them
+	ProxyRotation    string        // How proxies are picked: round-robin or per-host
+	HostDelay        string        // Minimum delay between requests to specific hosts, e.g. "fragile.com:2s,other.com:500ms"
 
 	// Authentication options
-	AuthBearer    string // Bearer token for authentication
-	AuthBasic     string // Basic auth in format "username:password"
-	AuthHeader    string // Custom Authorization header value
-	HeadersFile   string // Path to file containing custom headers
-	CookiesFile   string // Path to file containing cookies
-	CookieString  string // Cookie string in format "name1=value1; name2=value2"
-	UserAgent     string // Custom User-Agent header
+	AuthBearer   string // Bearer token for authentication
+	AuthBasic    string // Basic auth in format "username:password"
+	AuthHeader   string // Custom Authorization header value
+	HeadersFile  string // Path to file containing custom headers
+	CookiesFile  string // Path to file containing cookies
+	CookieString string // Cookie string in format "name1=value1; name2=value2"
+	UserAgent    string // Custom User-Agent header
+	DefaultUA    string // User-Agent sent when no header sets one: downurl, browser, or a literal value
+	Accept       string // Accept header for every request (default: */*)
+	AcceptExt    string // Per-extension Accept headers, e.g. "json=application/json,js=application/javascript"
+	SignKey      string // HMAC-SHA256 key signing every request ("" = no signing)
+	SignHeader   string // Header carrying the signature (default: X-Signature)
+	SignTemplate string // Message signed, e.g. "{method}\n{path}\n{timestamp}"
+
+	// Per-host credentials from the [auth.<host>] sections of .downurlrc, keyed by host
+	HostAuth map[string]map[string]string
 
 	// Scanner options
-	ScanSecrets     bool    // Enable secret scanning
-	ScanEndpoints   bool    // Enable endpoint discovery
-	SecretsEntropy  float64 // Minimum entropy for secret detection
-	SecretsOutput   string  // Output file for secrets
-	EndpointsOutput string  // Output file for endpoints
+	ScanSecrets      bool          // Enable secret scanning
+	ScanEndpoints    bool          // Enable endpoint discovery
+	SecretsEntropy   float64       // Minimum entropy for secret detection
+	SecretsAssigned  bool          // Report high-entropy strings only when assigned (x = '...', key: '...')
+	EntropyDebug     bool          // Write entropy-debug.json with near-threshold candidates and their scores
+	SecretsOutput    string        // Output file for secrets
+	EntropyOutput    string        // Output file for high-entropy strings, which are then left out of the secrets
+	SecretsDiff      string        // Secrets JSON from the previous run; only findings not in it are reported, then it is updated
+	FailOnNewSecrets bool          // Exit non-zero when --secrets-diff finds new secrets
+	SecretsMinConf   string        // Lowest confidence written to --secrets-output and counted as new: low, medium, high
+	SecretsRules     string        // JSON or YAML file of custom {name, regex, confidence} secret patterns
+	EndpointsOutput  string        // Output file for endpoints
+	EndpointsScope   string        // Endpoints to keep: relative, absolute, both
+	EndpointsGrouped bool          // Write each unique endpoint once, with its occurrence count, sources and methods
+	NucleiOutput     string        // Output file for a Nuclei template built from relative endpoints
+	NucleiID         string        // Nuclei template id
+	NucleiSeverity   string        // Nuclei template severity
+	NucleiAuthor     string        // Nuclei template author
+	ScanMixedContent bool          // Report http:// subresources on https:// HTML pages
+	ProcessTimeout   time.Duration // Maximum time spent scanning a single file (0 = no limit)
+	MaxLineLength    int           // Scan lines longer than this many bytes in overlapping chunks (0 = never)
+	MeasureGzip      bool          // Record each text file's gzip-compressed size in reports
+	ScanBinary       bool          // Scan files that look binary (NUL bytes, invalid UTF-8) instead of skipping them
 
 	// Filter options
-	FilterType   string // Filter by content type (comma-separated)
-	ExcludeType  string // Exclude content types (comma-separated)
-	FilterExt    string // Filter by extension (comma-separated)
-	ExcludeExt   string // Exclude extensions (comma-separated)
-	MinSize      int64  // Minimum file size in bytes
-	MaxSize      int64  // Maximum file size in bytes (0 = use default)
-	SkipEmpty    bool   // Skip empty files
+	FilterType    string   // Filter by content type (comma-separated)
+	ExcludeType   string   // Exclude content types (comma-separated)
+	FilterExt     string   // Filter by extension (comma-separated)
+	ExcludeExt    string   // Exclude extensions (comma-separated)
+	MinSize       int64    // Minimum file size in bytes
+	MaxSize       int64    // Maximum file size in bytes (0 = use default)
+	SkipEmpty     bool     // Skip empty files
+	RequireHeader []string // Response headers that must match ("Name" or "Name: value", one per flag)
+	SkipHeader    []string // Response headers that cause a skip ("Name" or "Name: value", one per flag)
 
 	// JS Analysis options
 	JSBeautify       bool   // Beautify minified JavaScript
+	Beautify         bool   // Beautify minified JavaScript and CSS, and indent compact JSON
 	ExtractStrings   bool   // Extract strings from JS files
 	StringsMinLength int    // Minimum string length
 	StringsPattern   string // Pattern to match in strings
+	FetchSourceMaps  bool   // Download the source maps named by fetched JS and write their original sources
 
 	// Output options
-	OutputFormat string // Output format: text, json, csv, markdown
-	OutputFile   string // Output file path (for JSON/CSV/Markdown)
-	PrettyJSON   bool   // Pretty print JSON
+	OutputFormat  string // Output formats (comma-separated): text, json, csv, markdown, html, ndjson
+	OutputFile    string // Report file path (base name when several formats are requested)
+	PrettyJSON    bool   // Pretty print JSON
+	ReportHeaders bool   // Record each request's headers (credentials redacted) and auth type in reports
+	PathsOutput   string // File for "url<TAB>path" lines of successful downloads ("-" for stdout)
+	StreamResults bool   // Handle results as they complete instead of keeping them all (huge URL lists)
+	WebhookURL    string // Webhook POSTed a summary of each completed run (Slack or generic JSON)
 
 	// Storage mode
-	StorageMode string // Storage organization mode: flat, path, host, type, dated
+	StorageBackend  string // Where downloads are written: fs (files) or sqlite (rows of downurl.db)
+	StorageMode     string // Storage organization mode: flat, path, host, type, dated
+	HostLayout      string // How type/dated modes separate hosts: prefix, dir
+	IndexNames      bool   // Save URLs ending in "/" (and query-only URLs) as index.<ext> in their directory
+	CollisionFormat string // Name for files whose name is taken, e.g. {name}-{n}{ext} ("" = {name}_{n}{ext})
+	FlatHost        bool   // Flat mode: prefix filenames with their host
+	HostOutput      string // Per-host base directories, e.g. "client-a.com:/data/a,client-b.com:/data/b"
+	Retain          int    // Dated mode: keep only the N most recent date directories (0 = keep all)
+
+	// Output directory handling
+	Clean        bool // Remove everything in the output directory before downloading
+	Yes          bool // Skip the --clean confirmation prompt
+	RequireEmpty bool // Fail if the output directory already has entries
 
 	// UI/UX options
 	Quiet      bool   // Suppress progress output
 	NoProgress bool   // Disable progress bar
+	LogMode    string // Live log style: default (a line per event) or compact (one line per URL)
+	LogLevel   string // Lowest level logged: debug, info, warn, error
+	LogFormat  string // Log line format: text or json
 	SaveConfig string // Save current config to file
 
 	// Advanced options
-	RateLimit string        // Rate limit (e.g., "10/minute")
-	Watch     bool          // Watch input file for changes
-	Schedule  string        // Schedule downloads (e.g., "5m", "1h")
-	UseStdin  bool          // Read URLs from stdin
-	SingleURL string        // Single URL to download (quick mode)
+	RateLimit     string   // Rate limit (e.g., "10/minute")
+	Watch         bool     // Watch input file for changes
+	Schedule      string   // Schedule downloads: an interval ("5m", "1h") or a cron expression ("0 */6 * * *")
+	ScheduleState string   // File keeping the last successful scheduled run, so a restart waits out the interval
+	UseStdin      bool     // Read URLs from stdin
+	URLArgs       []string // URLs given as arguments (quick mode, no input file needed)
+	ScopeCIDR     string   // Allowed CIDR ranges for resolved hosts (comma-separated)
+	CrawlDepth    int      // Download chunks lazily loaded by fetched JavaScript, this many levels deep (0 = off)
+	Benchmark     bool     // Measure throughput only: discard bodies, skip reports and archiving
+	DryRun        bool     // HEAD each URL and print where files would be saved, without downloading or writing
+	CacheDir      string   // Cache directory shared across runs ("" = no cache)
+	CacheSize     int64    // Cache size limit in MB (0 = unlimited)
+
+	// Per-host rate limits from the [ratelimit] section of .downurlrc, keyed by host
+	HostRateLimits map[string]string
+
+	strayArgs []string // Positional arguments that are neither URLs nor the input file
 }
 
 // Load parses command line flags and environment variables to create a Config
@@ -75,12 +175,46 @@ func Load() *Config {
 	// Custom usage function
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: downurl --input <urls.txt> [options]\n")
+		fmt.Fprintf(os.Stderr, "       downurl [options] <url> [url...]\n")
+		fmt.Fprintf(os.Stderr, "       downurl check [options]  Validate the input, options and .downurlrc without downloading\n")
 		fmt.Fprintf(os.Stderr, "\nBasic Options:\n")
 		fmt.Fprintf(os.Stderr, "  --input, -i string      Input file containing URLs (required)\n")
-		fmt.Fprintf(os.Stderr, "  --output, -o string     Output directory (default: output)\n")
+		fmt.Fprintf(os.Stderr, "  --skip-invalid          Warn about and skip malformed URL lines instead of aborting\n")
+		fmt.Fprintf(os.Stderr, "  --csv-column string     URL column of a .csv input, by header name or number (default: \"url\" column, else the first)\n")
+		fmt.Fprintf(os.Stderr, "  --list-urls             Print the URLs that would be fetched, one per line, and exit\n")
+		fmt.Fprintf(os.Stderr, "  --checkpoint string     Record finished URLs in this file; rerun with it to resume an interrupted run\n")
+		fmt.Fprintf(os.Stderr, "  --output, -o string     Output directory (default: output; supports {date}, {time}, {runid})\n")
 		fmt.Fprintf(os.Stderr, "  --workers, -w int       Number of concurrent workers (default: 10)\n")
+		fmt.Fprintf(os.Stderr, "  --per-host-limit int    Most concurrent requests to a single host (default: no limit)\n")
 		fmt.Fprintf(os.Stderr, "  --timeout, -t duration  HTTP request timeout (default: 15s)\n")
 		fmt.Fprintf(os.Stderr, "  --retry, -r int         Number of retry attempts (default: 3)\n")
+		fmt.Fprintf(os.Stderr, "  --retry-interrupted     Retry downloads cut off mid-body from scratch (default: true)\n")
+		fmt.Fprintf(os.Stderr, "  --no-decompress         Save gzip/deflate response bodies as served, without decoding\n")
+		fmt.Fprintf(os.Stderr, "  --preview-bytes int     Download only the first N bytes of each file (Range request, partial in reports)\n")
+		fmt.Fprintf(os.Stderr, "  --max-total-bytes size  Stop starting downloads once the run has saved this much, e.g. 1GB (default: no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --max-download-size size  Fail any single download bigger than this, e.g. 500MB; 0 = no limit (default: 100MB)\n")
+		fmt.Fprintf(os.Stderr, "  --verify-hashes file    Check downloads against SHA-256/MD5 digests listed by URL or filename (sha256sum format works)\n")
+		fmt.Fprintf(os.Stderr, "  --delete-mismatched     Delete files that fail --verify-hashes (default: keep them)\n")
+		fmt.Fprintf(os.Stderr, "  --strip-bom-and-reencode Save text files as UTF-8 without a BOM (UTF-16, ISO-8859-1, windows-1252 are converted)\n")
+		fmt.Fprintf(os.Stderr, "  --error-categories string  Failure categories for HTTP statuses (format: '401=auth,429=rate-limited,5xx=upstream')\n")
+		fmt.Fprintf(os.Stderr, "  --tls-min-version string  Lowest TLS version: 1.0, 1.1, 1.2, 1.3 (default: 1.2)\n")
+		fmt.Fprintf(os.Stderr, "  --tls-max-version string  Highest TLS version (default: 1.3)\n")
+		fmt.Fprintf(os.Stderr, "  --tls-ciphers string      Comma-separated cipher suites for TLS 1.0-1.2\n")
+		fmt.Fprintf(os.Stderr, "  --insecure                Skip TLS certificate verification (self-signed servers)\n")
+		fmt.Fprintf(os.Stderr, "  --ca-cert file            Also trust the certificate authorities in this PEM bundle\n")
+		fmt.Fprintf(os.Stderr, "  --http3                   Try HTTP/3 (QUIC) first, falling back to HTTP/2 and 1.1 (needs -tags http3 build)\n")
+		fmt.Fprintf(os.Stderr, "  --dns-cache-ttl duration  Resolve each host once per TTL (default: 0 = no caching)\n")
+		fmt.Fprintf(os.Stderr, "  --dns-max-lookups int     Maximum concurrent DNS lookups (default: 0 = unlimited)\n")
+		fmt.Fprintf(os.Stderr, "  --temp-dir string         Directory for temporary files (default: system temp)\n")
+		fmt.Fprintf(os.Stderr, "  --archive-on-success-only[=PCT]  Keep the previous archive unless PCT%% of downloads succeeded (default PCT: 100)\n")
+		fmt.Fprintf(os.Stderr, "  --no-archive              Do not archive the output directory at the end of the run\n")
+		fmt.Fprintf(os.Stderr, "  --archive-format string   Archive format: tar.gz, zip (default: tar.gz)\n")
+		fmt.Fprintf(os.Stderr, "  --archive-exclude-reports Leave the reports and --paths-output list out of the archive\n")
+		fmt.Fprintf(os.Stderr, "  --dedupe                  Find downloaded files with identical content and report the space they waste\n")
+		fmt.Fprintf(os.Stderr, "  --dedupe-mode string      What --dedupe does: report, hardlink, symlink (default: report)\n")
+		fmt.Fprintf(os.Stderr, "  --proxies-file string     Spread requests over the proxies in this file (one http/https/socks5 URL per line)\n")
+		fmt.Fprintf(os.Stderr, "  --proxy-rotation string   How proxies are picked: round-robin, per-host (default: round-robin)\n")
+		fmt.Fprintf(os.Stderr, "  --host-delay string       Minimum delay between requests to given hosts (format: 'host:2s,other.com:500ms')\n")
 		fmt.Fprintf(os.Stderr, "\nAuthentication Options:\n")
 		fmt.Fprintf(os.Stderr, "  --auth-bearer, -b string    Bearer token authentication\n")
 		fmt.Fprintf(os.Stderr, "  --auth-basic, -B string     Basic auth (format: username:password)\n")
@@ -89,12 +223,37 @@ func Load() *Config {
 		fmt.Fprintf(os.Stderr, "  --cookies-file, -C string   File with cookies (format: 'name=value')\n")
 		fmt.Fprintf(os.Stderr, "  --cookie, -c string         Cookie string (format: 'name1=value1; name2=value2')\n")
 		fmt.Fprintf(os.Stderr, "  --user-agent, -u string     Custom User-Agent header\n")
+		fmt.Fprintf(os.Stderr, "  --default-user-agent string Default User-Agent: downurl, browser, or any string (default: downurl)\n")
+		fmt.Fprintf(os.Stderr, "  --accept string             Accept header for GET/HEAD requests (default: */*)\n")
+		fmt.Fprintf(os.Stderr, "  --accept-ext string         Accept header by extension (format: 'json=application/json,js=...')\n")
+		fmt.Fprintf(os.Stderr, "  --sign-key string           Sign each request with an HMAC-SHA256 under this key\n")
+		fmt.Fprintf(os.Stderr, "  --sign-header string        Header for the signature (default: X-Signature)\n")
+		fmt.Fprintf(os.Stderr, "  --sign-template string      Message signed (default: '{method}\\n{path}\\n{timestamp}')\n")
+		fmt.Fprintf(os.Stderr, "                              Placeholders: {method} {host} {path} {query} {timestamp}\n")
 		fmt.Fprintf(os.Stderr, "\nScanner Options:\n")
 		fmt.Fprintf(os.Stderr, "  --scan-secrets, -s          Enable secret scanning\n")
 		fmt.Fprintf(os.Stderr, "  --scan-endpoints, -e        Enable endpoint discovery\n")
 		fmt.Fprintf(os.Stderr, "  --secrets-entropy, -E float Minimum entropy for secret detection (default: 4.5)\n")
+		fmt.Fprintf(os.Stderr, "  --secrets-assigned          Report high-entropy strings only in assignments (x = '...', key: '...')\n")
+		fmt.Fprintf(os.Stderr, "  --entropy-debug             Write entropy-debug.json listing candidates near the threshold with their entropy\n")
 		fmt.Fprintf(os.Stderr, "  --secrets-output, -S string Output file for secrets (JSON)\n")
+		fmt.Fprintf(os.Stderr, "  --entropy-output string     Write high-entropy strings here instead of with the secrets (JSON)\n")
+		fmt.Fprintf(os.Stderr, "  --secrets-diff file         Report only secrets not in this file from the previous run, then update it\n")
+		fmt.Fprintf(os.Stderr, "  --fail-on-new-secrets       Exit non-zero if --secrets-diff finds new secrets\n")
+		fmt.Fprintf(os.Stderr, "  --secrets-min-confidence string Lowest confidence saved to --secrets-output and counted as new: low, medium, high (default: low)\n")
+		fmt.Fprintf(os.Stderr, "  --secrets-rules file        Also look for the custom patterns in this JSON/YAML file ({name, regex, confidence} entries)\n")
 		fmt.Fprintf(os.Stderr, "  --endpoints-output, -O string Output file for endpoints (JSON)\n")
+		fmt.Fprintf(os.Stderr, "  --endpoints-scope string    Endpoints to keep: relative, absolute, both (default: both)\n")
+		fmt.Fprintf(os.Stderr, "  --endpoints-grouped         List each endpoint once in --endpoints-output, with counts, files, URLs and methods\n")
+		fmt.Fprintf(os.Stderr, "  --nuclei-output string      Output file for a Nuclei template of the relative endpoints (YAML)\n")
+		fmt.Fprintf(os.Stderr, "  --nuclei-id string          Nuclei template id (default: discovered-endpoints)\n")
+		fmt.Fprintf(os.Stderr, "  --nuclei-severity string    Nuclei template severity: info, low, medium, high, critical (default: info)\n")
+		fmt.Fprintf(os.Stderr, "  --nuclei-author string      Nuclei template author (default: downurl)\n")
+		fmt.Fprintf(os.Stderr, "  --scan-mixed-content        Report http:// subresources on https:// HTML pages\n")
+		fmt.Fprintf(os.Stderr, "  --process-timeout duration  Abandon scanning a file after this long (default: no limit)\n")
+		fmt.Fprintf(os.Stderr, "  --max-line-length int       Scan longer lines (minified bundles) in overlapping chunks, 0 = never (default: 65536)\n")
+		fmt.Fprintf(os.Stderr, "  --measure-gzip              Record each text file's gzipped size in structured reports\n")
+		fmt.Fprintf(os.Stderr, "  --scan-binary               Scan files that look binary instead of skipping them\n")
 		fmt.Fprintf(os.Stderr, "\nFilter Options:\n")
 		fmt.Fprintf(os.Stderr, "  --filter-type, -T string    Filter by content type (comma-separated)\n")
 		fmt.Fprintf(os.Stderr, "  --exclude-type, -X string   Exclude content types (comma-separated)\n")
@@ -103,36 +262,101 @@ func Load() *Config {
 		fmt.Fprintf(os.Stderr, "  --min-size, -m int          Minimum file size in bytes\n")
 		fmt.Fprintf(os.Stderr, "  --max-size, -M int          Maximum file size in bytes (0 = default 100MB)\n")
 		fmt.Fprintf(os.Stderr, "  --skip-empty, -k            Skip empty files\n")
+		fmt.Fprintf(os.Stderr, "  --require-header string     Keep only responses with header (format: 'Name' or 'Name: value', repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --skip-header string        Skip responses with header (format: 'Name' or 'Name: value', repeatable)\n")
+		fmt.Fprintf(os.Stderr, "  --scope-cidr string         Only download from hosts resolving inside these CIDRs\n")
 		fmt.Fprintf(os.Stderr, "\nJS Analysis Options:\n")
 		fmt.Fprintf(os.Stderr, "  --js-beautify, -j           Beautify minified JavaScript\n")
+		fmt.Fprintf(os.Stderr, "  --beautify                  Beautify minified JavaScript and CSS and indent compact JSON (.beautified.* copies)\n")
 		fmt.Fprintf(os.Stderr, "  --extract-strings, -a       Extract strings from JS files\n")
 		fmt.Fprintf(os.Stderr, "  --strings-min-length, -l int Minimum string length (default: 10)\n")
 		fmt.Fprintf(os.Stderr, "  --strings-pattern, -p string Pattern to match in strings (regex)\n")
+		fmt.Fprintf(os.Stderr, "  --fetch-sourcemaps          Download the source maps named by fetched JS (sourceMappingURL) and write their sources to sources/<host>\n")
 		fmt.Fprintf(os.Stderr, "\nOutput Options:\n")
-		fmt.Fprintf(os.Stderr, "  --output-format, -f string  Output format: text, json, csv, markdown (default: text)\n")
-		fmt.Fprintf(os.Stderr, "  --output-file, -P string    Output file path (for JSON/CSV/Markdown)\n")
+		fmt.Fprintf(os.Stderr, "  --output-format, -f string  Output formats, comma-separated: text, json, csv, markdown, html, ndjson (default: text)\n")
+		fmt.Fprintf(os.Stderr, "  --output-file, -P string    Report file path (base name when several formats are requested; '-' for stdout)\n")
 		fmt.Fprintf(os.Stderr, "  --pretty-json, -J           Pretty print JSON output (default: true)\n")
+		fmt.Fprintf(os.Stderr, "  --report-request-headers    Record the headers sent (credentials redacted) and auth type per URL in JSON reports\n")
+		fmt.Fprintf(os.Stderr, "  --paths-output string       Write 'url<TAB>path' per downloaded file ('-' for stdout)\n")
+		fmt.Fprintf(os.Stderr, "  --stream-results            Process and report each result as it completes (bounded memory)\n")
+		fmt.Fprintf(os.Stderr, "  --webhook-url string        POST a summary of each completed run (Slack incoming webhooks get a text message)\n")
+		fmt.Fprintf(os.Stderr, "  --crawl-depth int           Download same-host chunks loaded by fetched JS (import(), webpack), N levels deep\n")
+		fmt.Fprintf(os.Stderr, "  --benchmark                 Measure throughput (req/s, MB/s, latency) without saving anything\n")
+		fmt.Fprintf(os.Stderr, "  --dry-run                   HEAD each URL and show what would be saved where, and the total size; writes nothing\n")
+		fmt.Fprintf(os.Stderr, "  --cache-dir string          Reuse unchanged files across runs via conditional GET (ETag/Last-Modified)\n")
+		fmt.Fprintf(os.Stderr, "  --cache-size int            Cache size limit in MB, least recently used evicted first (default: 1024, 0 = unlimited)\n")
 		fmt.Fprintf(os.Stderr, "\nStorage Mode Options:\n")
+		fmt.Fprintf(os.Stderr, "  --storage-backend string    Where downloads are written (default: fs)\n")
+		fmt.Fprintf(os.Stderr, "                              - fs: one file per download, laid out by --mode\n")
+		fmt.Fprintf(os.Stderr, "                              - sqlite: one row per download in <output>/downurl.db\n")
 		fmt.Fprintf(os.Stderr, "  --mode string               Storage organization mode (default: flat)\n")
 		fmt.Fprintf(os.Stderr, "                              - flat: All files in single directory\n")
 		fmt.Fprintf(os.Stderr, "                              - path: Replicate URL directory structure\n")
 		fmt.Fprintf(os.Stderr, "                              - host: Group files by hostname\n")
 		fmt.Fprintf(os.Stderr, "                              - type: Organize by file extension\n")
 		fmt.Fprintf(os.Stderr, "                              - dated: Organize by download date\n")
+		fmt.Fprintf(os.Stderr, "  --host-layout string        How type/dated modes separate hosts (default: prefix)\n")
+		fmt.Fprintf(os.Stderr, "                              - prefix: js/cdn.example.com_app.js\n")
+		fmt.Fprintf(os.Stderr, "                              - dir: js/cdn.example.com/app.js\n")
+		fmt.Fprintf(os.Stderr, "  --index-names               Save URLs ending in / (e.g. /docs/, /?q=1) as index.<ext> in their directory\n")
+		fmt.Fprintf(os.Stderr, "  --collision-format string   Name for files whose name is taken (default: {name}_{n}{ext})\n")
+		fmt.Fprintf(os.Stderr, "                              Placeholders: {name} {ext} {n} {hash8} {hash}\n")
+		fmt.Fprintf(os.Stderr, "  --flat-host                 Flat mode: prefix filenames with their host (cdn.example.com_app.js)\n")
+		fmt.Fprintf(os.Stderr, "  --host-output string        Save given hosts under their own base dir (format: 'host:dir,...')\n")
+		fmt.Fprintf(os.Stderr, "  --retain int                Dated mode: keep only the N most recent date dirs (default: 0 = all)\n")
+		fmt.Fprintf(os.Stderr, "  --clean                     Remove everything in the output directory first (asks unless --yes)\n")
+		fmt.Fprintf(os.Stderr, "  --yes                       Don't ask for confirmation before --clean\n")
+		fmt.Fprintf(os.Stderr, "  --require-empty             Fail if the output directory is not empty\n")
+		fmt.Fprintf(os.Stderr, "\nLogging Options:\n")
+		fmt.Fprintf(os.Stderr, "  --log-level string          Lowest level logged: debug, info, warn, error (default: info; warn hides per-file lines)\n")
+		fmt.Fprintf(os.Stderr, "  --log-format string         Log format: text, json (one object per line, with url, path, bytes, duration_ms)\n")
 	}
 
 	// Define flags with long and short versions
 	// Basic flags
 	flag.StringVar(&cfg.InputFile, "i", "", "Input file containing URLs (required) [shorthand]")
 	flag.StringVar(&cfg.InputFile, "input", "", "Input file containing URLs (required)")
+	flag.BoolVar(&cfg.SkipInvalid, "skip-invalid", false, "Warn about and skip malformed URL lines instead of aborting")
+	flag.StringVar(&cfg.CSVColumn, "csv-column", "", "URL column of a .csv input, by header name or number (default: \"url\" column, else the first)")
+	flag.BoolVar(&cfg.ListURLs, "list-urls", false, "Print the URLs that would be fetched, one per line, and exit without downloading")
+	flag.StringVar(&cfg.Checkpoint, "checkpoint", "", "File recording finished URLs; rerunning with it skips them and resumes the rest")
 	flag.StringVar(&cfg.OutputDir, "o", getEnvOrDefault("OUTPUT_DIR", "output"), "Output directory [shorthand]")
 	flag.StringVar(&cfg.OutputDir, "output", getEnvOrDefault("OUTPUT_DIR", "output"), "Output directory")
 	flag.IntVar(&cfg.Workers, "w", getEnvIntOrDefault("WORKERS", 10), "Number of concurrent workers [shorthand]")
 	flag.IntVar(&cfg.Workers, "workers", getEnvIntOrDefault("WORKERS", 10), "Number of concurrent workers")
+	flag.IntVar(&cfg.PerHostLimit, "per-host-limit", 0, "Most concurrent requests to a single host (0 = no limit)")
 	flag.DurationVar(&cfg.Timeout, "t", getEnvDurationOrDefault("TIMEOUT", 15*time.Second), "HTTP request timeout [shorthand]")
 	flag.DurationVar(&cfg.Timeout, "timeout", getEnvDurationOrDefault("TIMEOUT", 15*time.Second), "HTTP request timeout")
 	flag.IntVar(&cfg.RetryAttempts, "r", getEnvIntOrDefault("RETRY_ATTEMPTS", 3), "Number of retry attempts [shorthand]")
 	flag.IntVar(&cfg.RetryAttempts, "retry", getEnvIntOrDefault("RETRY_ATTEMPTS", 3), "Number of retry attempts")
+	flag.BoolVar(&cfg.RetryInterrupted, "retry-interrupted", true, "Retry downloads cut off mid-body from scratch")
+	flag.BoolVar(&cfg.NoDecompress, "no-decompress", false, "Save gzip/deflate response bodies as served, without decoding")
+	flag.Int64Var(&cfg.PreviewBytes, "preview-bytes", 0, "Download only the first N bytes of each file (0 = whole files)")
+	flag.BoolVar(&cfg.NormalizeText, "strip-bom-and-reencode", false, "Save text files as UTF-8 without a BOM, re-encoding from the declared charset")
+	cfg.MaxDownloadSize = 100 * 1024 * 1024
+	flag.Var((*sizeValue)(&cfg.MaxDownloadSize), "max-download-size", "Fail any single download bigger than this, e.g. 500MB (0 = no limit)")
+	flag.StringVar(&cfg.VerifyHashes, "verify-hashes", "", "Check downloads against SHA-256/MD5 digests listed by URL or filename")
+	flag.BoolVar(&cfg.DeleteMismatched, "delete-mismatched", false, "Delete files that fail --verify-hashes instead of keeping them")
+	flag.Var((*sizeValue)(&cfg.MaxTotalBytes), "max-total-bytes", "Stop starting downloads once the run has saved this much, e.g. 1GB (0 = no limit)")
+	flag.StringVar(&cfg.ErrorCategories, "error-categories", "", "Failure categories for HTTP statuses (format: 'status=category,...')")
+	flag.StringVar(&cfg.TLSMinVersion, "tls-min-version", getEnvOrDefault("TLS_MIN_VERSION", ""), "Lowest TLS version: 1.0, 1.1, 1.2, 1.3")
+	flag.StringVar(&cfg.TLSMaxVersion, "tls-max-version", getEnvOrDefault("TLS_MAX_VERSION", ""), "Highest TLS version: 1.0, 1.1, 1.2, 1.3")
+	flag.StringVar(&cfg.TLSCiphers, "tls-ciphers", "", "Comma-separated cipher suites for TLS 1.0-1.2")
+	flag.BoolVar(&cfg.Insecure, "insecure", false, "Skip TLS certificate verification")
+	flag.StringVar(&cfg.CACert, "ca-cert", "", "PEM bundle of extra certificate authorities to trust")
+	flag.BoolVar(&cfg.HTTP3, "http3", false, "Try HTTP/3 (QUIC) first, falling back to HTTP/2 and HTTP/1.1")
+	flag.DurationVar(&cfg.DNSCacheTTL, "dns-cache-ttl", getEnvDurationOrDefault("DNS_CACHE_TTL", 0), "Resolve each host once per TTL (0 = no caching)")
+	flag.IntVar(&cfg.DNSMaxLookups, "dns-max-lookups", 0, "Maximum concurrent DNS lookups (0 = unlimited)")
+	flag.StringVar(&cfg.TempDir, "temp-dir", getEnvOrDefault("TEMP_DIR", ""), "Directory for temporary files (default: system temp)")
+	flag.Var((*thresholdValue)(&cfg.ArchiveMinRate), "archive-on-success-only", "Keep the previous archive unless this percent of downloads succeeded (no value = 100)")
+	flag.BoolVar(&cfg.NoArchive, "no-archive", false, "Do not archive the output directory at the end of the run")
+	flag.StringVar(&cfg.ArchiveFormat, "archive-format", "tar.gz", "Archive format: tar.gz, zip")
+	flag.BoolVar(&cfg.ArchiveNoReports, "archive-exclude-reports", false, "Leave the reports and --paths-output list out of the archive")
+	flag.BoolVar(&cfg.Dedupe, "dedupe", false, "Find downloaded files with identical content and report the space they waste")
+	flag.StringVar(&cfg.DedupeMode, "dedupe-mode", "report", "What --dedupe does with duplicates: report, hardlink, symlink")
+	flag.StringVar(&cfg.ProxiesFile, "proxies-file", "", "Spread requests over the proxies in this file (one URL per line)")
+	flag.StringVar(&cfg.ProxyRotation, "proxy-rotation", "round-robin", "How proxies are picked: round-robin, per-host")
+	flag.StringVar(&cfg.HostDelay, "host-delay", "", "Minimum delay between requests to given hosts (format: 'host:duration,...')")
 
 	// Authentication flags
 	flag.StringVar(&cfg.AuthBearer, "b", getEnvOrDefault("AUTH_BEARER", ""), "Bearer token for authentication [shorthand]")
@@ -149,6 +373,12 @@ func Load() *Config {
 	flag.StringVar(&cfg.CookieString, "cookie", getEnvOrDefault("COOKIE", ""), "Cookie string (format: 'name1=value1; name2=value2')")
 	flag.StringVar(&cfg.UserAgent, "u", getEnvOrDefault("USER_AGENT", ""), "Custom User-Agent header [shorthand]")
 	flag.StringVar(&cfg.UserAgent, "user-agent", getEnvOrDefault("USER_AGENT", ""), "Custom User-Agent header")
+	flag.StringVar(&cfg.DefaultUA, "default-user-agent", getEnvOrDefault("DEFAULT_USER_AGENT", "downurl"), "Default User-Agent: downurl, browser, or any string")
+	flag.StringVar(&cfg.Accept, "accept", getEnvOrDefault("ACCEPT", ""), "Accept header for GET/HEAD requests")
+	flag.StringVar(&cfg.AcceptExt, "accept-ext", "", "Accept header by extension (format: 'ext=media/type,...')")
+	flag.StringVar(&cfg.SignKey, "sign-key", getEnvOrDefault("SIGN_KEY", ""), "Sign each request with an HMAC-SHA256 under this key")
+	flag.StringVar(&cfg.SignHeader, "sign-header", "", "Header for the request signature (default: X-Signature)")
+	flag.StringVar(&cfg.SignTemplate, "sign-template", "", "Message signed, with {method} {host} {path} {query} {timestamp}")
 
 	// Scanner flags
 	flag.BoolVar(&cfg.ScanSecrets, "s", false, "Enable secret scanning [shorthand]")
@@ -157,10 +387,28 @@ func Load() *Config {
 	flag.BoolVar(&cfg.ScanEndpoints, "scan-endpoints", false, "Enable endpoint discovery")
 	flag.Float64Var(&cfg.SecretsEntropy, "E", 4.5, "Minimum entropy for secret detection [shorthand]")
 	flag.Float64Var(&cfg.SecretsEntropy, "secrets-entropy", 4.5, "Minimum entropy for secret detection")
+	flag.BoolVar(&cfg.SecretsAssigned, "secrets-assigned", false, "Report high-entropy strings only in assignments (x = '...', key: '...')")
+	flag.BoolVar(&cfg.EntropyDebug, "entropy-debug", false, "Write entropy-debug.json listing candidates near the threshold with their entropy")
 	flag.StringVar(&cfg.SecretsOutput, "S", "", "Output file for secrets (JSON) [shorthand]")
 	flag.StringVar(&cfg.SecretsOutput, "secrets-output", "", "Output file for secrets (JSON)")
+	flag.StringVar(&cfg.EntropyOutput, "entropy-output", "", "Write high-entropy strings here instead of with the secrets (JSON)")
+	flag.StringVar(&cfg.SecretsDiff, "secrets-diff", "", "Report only secrets not in this file from the previous run, then update it")
+	flag.BoolVar(&cfg.FailOnNewSecrets, "fail-on-new-secrets", false, "Exit non-zero if --secrets-diff finds new secrets")
+	flag.StringVar(&cfg.SecretsMinConf, "secrets-min-confidence", "low", "Lowest confidence saved to --secrets-output and counted as new: low, medium, high")
+	flag.StringVar(&cfg.SecretsRules, "secrets-rules", "", "JSON/YAML file of custom secret patterns ({name, regex, confidence} entries)")
 	flag.StringVar(&cfg.EndpointsOutput, "O", "", "Output file for endpoints (JSON) [shorthand]")
 	flag.StringVar(&cfg.EndpointsOutput, "endpoints-output", "", "Output file for endpoints (JSON)")
+	flag.StringVar(&cfg.EndpointsScope, "endpoints-scope", "both", "Endpoints to keep: relative, absolute, both")
+	flag.BoolVar(&cfg.EndpointsGrouped, "endpoints-grouped", false, "List each endpoint once in --endpoints-output, with counts, files, URLs and methods")
+	flag.StringVar(&cfg.NucleiOutput, "nuclei-output", "", "Output file for a Nuclei template of the relative endpoints (YAML)")
+	flag.StringVar(&cfg.NucleiID, "nuclei-id", "", "Nuclei template id")
+	flag.StringVar(&cfg.NucleiSeverity, "nuclei-severity", "", "Nuclei template severity")
+	flag.StringVar(&cfg.NucleiAuthor, "nuclei-author", "", "Nuclei template author")
+	flag.BoolVar(&cfg.ScanMixedContent, "scan-mixed-content", false, "Report http:// subresources on https:// HTML pages")
+	flag.DurationVar(&cfg.ProcessTimeout, "process-timeout", 0, "Abandon scanning a file after this long (0 = no limit)")
+	flag.IntVar(&cfg.MaxLineLength, "max-line-length", 64*1024, "Scan lines longer than this many bytes in overlapping chunks (0 = never)")
+	flag.BoolVar(&cfg.MeasureGzip, "measure-gzip", false, "Record each text file's gzipped size in structured reports")
+	flag.BoolVar(&cfg.ScanBinary, "scan-binary", false, "Scan files that look binary instead of skipping them")
 
 	// Filter flags
 	flag.StringVar(&cfg.FilterType, "T", "", "Filter by content type (comma-separated) [shorthand]")
@@ -177,68 +425,200 @@ func Load() *Config {
 	flag.Int64Var(&cfg.MaxSize, "max-size", 0, "Maximum file size in bytes (0 = default 100MB)")
 	flag.BoolVar(&cfg.SkipEmpty, "k", false, "Skip empty files [shorthand]")
 	flag.BoolVar(&cfg.SkipEmpty, "skip-empty", false, "Skip empty files")
+	flag.Var((*listValue)(&cfg.RequireHeader), "require-header", "Keep only responses with header ('Name' or 'Name: value'; repeat for more rules)")
+	flag.Var((*listValue)(&cfg.SkipHeader), "skip-header", "Skip responses with header ('Name' or 'Name: value'; repeat for more rules)")
 
 	// JS Analysis flags
 	flag.BoolVar(&cfg.JSBeautify, "j", false, "Beautify minified JavaScript [shorthand]")
 	flag.BoolVar(&cfg.JSBeautify, "js-beautify", false, "Beautify minified JavaScript")
+	flag.BoolVar(&cfg.Beautify, "beautify", false, "Beautify minified JavaScript and CSS and indent compact JSON")
 	flag.BoolVar(&cfg.ExtractStrings, "a", false, "Extract strings from JS files [shorthand]")
 	flag.BoolVar(&cfg.ExtractStrings, "extract-strings", false, "Extract strings from JS files")
 	flag.IntVar(&cfg.StringsMinLength, "l", 10, "Minimum string length [shorthand]")
 	flag.IntVar(&cfg.StringsMinLength, "strings-min-length", 10, "Minimum string length")
 	flag.StringVar(&cfg.StringsPattern, "p", "", "Pattern to match in strings (regex) [shorthand]")
 	flag.StringVar(&cfg.StringsPattern, "strings-pattern", "", "Pattern to match in strings (regex)")
+	flag.BoolVar(&cfg.FetchSourceMaps, "fetch-sourcemaps", false, "Download the source maps named by fetched JS and write their sources to sources/<host>")
 
 	// Output flags
-	flag.StringVar(&cfg.OutputFormat, "f", "text", "Output format: text, json, csv, markdown [shorthand]")
-	flag.StringVar(&cfg.OutputFormat, "output-format", "text", "Output format: text, json, csv, markdown")
-	flag.StringVar(&cfg.OutputFile, "P", "", "Output file path (for JSON/CSV/Markdown) [shorthand]")
-	flag.StringVar(&cfg.OutputFile, "output-file", "", "Output file path (for JSON/CSV/Markdown)")
+	flag.StringVar(&cfg.OutputFormat, "f", "text", "Output formats (comma-separated): text, json, csv, markdown, html, ndjson [shorthand]")
+	flag.StringVar(&cfg.OutputFormat, "output-format", "text", "Output formats (comma-separated): text, json, csv, markdown, html, ndjson")
+	flag.StringVar(&cfg.OutputFile, "P", "", "Report file path (base name when several formats are requested) [shorthand]")
+	flag.StringVar(&cfg.OutputFile, "output-file", "", "Report file path (base name when several formats are requested)")
 	flag.BoolVar(&cfg.PrettyJSON, "J", true, "Pretty print JSON output [shorthand]")
 	flag.BoolVar(&cfg.PrettyJSON, "pretty-json", true, "Pretty print JSON output")
+	flag.BoolVar(&cfg.ReportHeaders, "report-request-headers", false, "Record the headers sent (credentials redacted) and auth type per URL in JSON reports")
+	flag.StringVar(&cfg.PathsOutput, "paths-output", "", "Write 'url<TAB>path' per downloaded file ('-' for stdout)")
+	flag.BoolVar(&cfg.StreamResults, "stream-results", false, "Process and report each result as it completes (bounded memory)")
+	flag.StringVar(&cfg.WebhookURL, "webhook-url", getEnvOrDefault("WEBHOOK_URL", ""), "POST a summary of each completed run (Slack incoming webhooks get a text message)")
 
 	// Storage mode flags
+	flag.StringVar(&cfg.StorageBackend, "storage-backend", getEnvOrDefault("STORAGE_BACKEND", "fs"), "Where downloads are written: fs, sqlite")
 	flag.StringVar(&cfg.StorageMode, "mode", getEnvOrDefault("STORAGE_MODE", "flat"), "Storage organization mode")
+	flag.StringVar(&cfg.HostLayout, "host-layout", getEnvOrDefault("HOST_LAYOUT", "prefix"), "How type/dated modes separate hosts: prefix, dir")
+	flag.BoolVar(&cfg.IndexNames, "index-names", false, "Save URLs ending in / (and query-only URLs) as index.<ext> in their directory")
+	flag.StringVar(&cfg.CollisionFormat, "collision-format", "", "Name for files whose name is taken, e.g. {name}-{n}{ext} or {name}.{hash8}{ext}")
+	flag.BoolVar(&cfg.FlatHost, "flat-host", false, "Flat mode: prefix filenames with their host to avoid collisions across hosts")
+	flag.StringVar(&cfg.HostOutput, "host-output", "", "Base directory for files from given hosts (format: 'host:dir,...')")
+	flag.IntVar(&cfg.Retain, "retain", getEnvIntOrDefault("RETAIN", 0), "Dated mode: keep only the N most recent date directories")
+	flag.BoolVar(&cfg.Clean, "clean", false, "Remove everything in the output directory before downloading")
+	flag.BoolVar(&cfg.Yes, "yes", false, "Don't ask for confirmation before --clean")
+	flag.BoolVar(&cfg.RequireEmpty, "require-empty", false, "Fail if the output directory is not empty")
 
 	// UI/UX flags
 	flag.BoolVar(&cfg.Quiet, "quiet", false, "Suppress progress output")
 	flag.BoolVar(&cfg.NoProgress, "no-progress", false, "Disable progress bar")
+	flag.StringVar(&cfg.LogMode, "log", "default", "Live log style: default, compact (one updating line per URL)")
+	flag.StringVar(&cfg.LogLevel, "log-level", getEnvOrDefault("LOG_LEVEL", "info"), "Lowest log level shown: debug, info, warn, error")
+	flag.StringVar(&cfg.LogFormat, "log-format", getEnvOrDefault("LOG_FORMAT", "text"), "Log line format: text, json (one object per line, with url, path, bytes and duration_ms fields)")
 	flag.StringVar(&cfg.SaveConfig, "save-config", "", "Save current config to file (e.g., .downurlrc)")
 
 	// Advanced flags
 	flag.StringVar(&cfg.RateLimit, "rate-limit", "", "Rate limit requests (e.g., '10/minute', '100/hour')")
 	flag.BoolVar(&cfg.Watch, "watch", false, "Watch input file for changes and auto-download")
-	flag.StringVar(&cfg.Schedule, "schedule", "", "Schedule periodic downloads (e.g., '5m', '1h')")
+	flag.StringVar(&cfg.Schedule, "schedule", "", "Schedule periodic downloads: an interval ('5m', '1h') or a cron expression ('0 */6 * * *')")
+	flag.StringVar(&cfg.ScheduleState, "schedule-state", "", "File keeping the last successful scheduled run, so a restart waits out the interval")
+	flag.StringVar(&cfg.ScopeCIDR, "scope-cidr", "", "Only download from hosts resolving inside these CIDRs (e.g., '10.0.0.0/8,192.168.0.0/16')")
+	flag.IntVar(&cfg.CrawlDepth, "crawl-depth", 0, "Download same-host chunks loaded by fetched JS (import(), webpack) up to N levels deep")
+	flag.BoolVar(&cfg.Benchmark, "benchmark", false, "Measure download throughput without saving files or writing reports")
+	flag.BoolVar(&cfg.DryRun, "dry-run", false, "HEAD each URL and show what would be saved where, without downloading or writing anything")
+	flag.StringVar(&cfg.CacheDir, "cache-dir", "", "Cache directory shared across runs; unchanged files are revalidated and copied from it")
+	flag.Int64Var(&cfg.CacheSize, "cache-size", 1024, "Cache size limit in MB (0 = unlimited)")
 
 	flag.Parse()
 
-	// Check for stdin or single URL argument
-	if flag.NArg() > 0 {
-		arg := flag.Arg(0)
-		// If it looks like a URL, treat it as single URL mode
-		if len(arg) > 7 && (arg[:7] == "http://" || arg[:8] == "https://") {
-			cfg.SingleURL = arg
-		}
-	}
-
-	// Validate required fields
-	if cfg.InputFile == "" && flag.NArg() > 0 {
-		cfg.InputFile = flag.Arg(0)
-	}
+	// Positional arguments are URLs or the input file
+	applyArgs(cfg, flag.Args())
 
 	return cfg
 }
 
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
-	if c.InputFile == "" {
+	if len(c.strayArgs) > 0 {
+		return fmt.Errorf("unexpected argument %q (URLs must start with http:// or https://)", c.strayArgs[0])
+	}
+	if c.OutputFile == "-" && c.PathsOutput == "-" {
+		return fmt.Errorf("--output-file and --paths-output cannot both write to stdout")
+	}
+	if c.DryRun && (c.Clean || c.Benchmark || c.Watch || c.Schedule != "") {
+		return fmt.Errorf("--dry-run cannot be used with --clean, --benchmark, --watch or --schedule")
+	}
+	if c.DedupeMode != "" && c.DedupeMode != "report" && !c.Dedupe {
+		return fmt.Errorf("--dedupe-mode %s requires --dedupe", c.DedupeMode)
+	}
+	if c.Clean && c.RequireEmpty {
+		return fmt.Errorf("--clean and --require-empty cannot be used together")
+	}
+	if c.ProxiesFile != "" && c.HTTP3 {
+		return fmt.Errorf("--proxies-file cannot be used with --http3 (QUIC does not go through HTTP proxies)")
+	}
+	if c.ScopeCIDR != "" && (c.ProxiesFile != "" || c.HTTP3) {
+		return fmt.Errorf("--scope-cidr cannot be used with --proxies-file or --http3 (the address each connection reaches cannot be checked)")
+	}
+	if c.EntropyOutput != "" && !c.ScanSecrets {
+		return fmt.Errorf("--entropy-output requires --scan-secrets")
+	}
+	if c.SecretsDiff != "" && !c.ScanSecrets {
+		return fmt.Errorf("--secrets-diff requires --scan-secrets")
+	}
+	if c.StreamResults {
+		formats, _ := output.ParseFormats(c.OutputFormat)
+		for _, f := range formats {
+			if f == output.FormatCSV {
+				return fmt.Errorf("--stream-results cannot write a csv report (it lists every download); use ndjson")
+			}
+		}
+	}
+	if c.SecretsRules != "" && !c.ScanSecrets {
+		return fmt.Errorf("--secrets-rules requires --scan-secrets")
+	}
+	switch c.LogMode {
+	case "", "default", "compact":
+	default:
+		return fmt.Errorf("unknown --log mode: %s (valid: default, compact)", c.LogMode)
+	}
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		return fmt.Errorf("invalid --log-level: %w", err)
+	}
+	if _, err := logging.ParseFormat(c.LogFormat); err != nil {
+		return fmt.Errorf("invalid --log-format: %w", err)
+	}
+	if c.ScheduleState != "" && c.Schedule == "" {
+		return fmt.Errorf("--schedule-state requires --schedule")
+	}
+	if c.Schedule != "" {
+		if err := watcher.ValidateSchedule(c.Schedule); err != nil {
+			return fmt.Errorf("invalid --schedule: %w", err)
+		}
+	}
+	if c.FailOnNewSecrets && c.SecretsDiff == "" {
+		return fmt.Errorf("--fail-on-new-secrets requires --secrets-diff")
+	}
+	if c.EndpointsGrouped && c.EndpointsOutput == "" {
+		return fmt.Errorf("--endpoints-grouped requires --endpoints-output")
+	}
+	if c.NucleiOutput != "" && !c.ScanEndpoints {
+		return fmt.Errorf("--nuclei-output requires --scan-endpoints")
+	}
+	if c.Checkpoint != "" && (c.Watch || c.Schedule != "") {
+		return fmt.Errorf("--checkpoint cannot be used with --watch or --schedule (later runs would skip every URL)")
+	}
+	if c.VerifyHashes != "" && c.PreviewBytes > 0 {
+		return fmt.Errorf("--verify-hashes cannot be used with --preview-bytes (a preview never matches the file's digest)")
+	}
+	if c.DeleteMismatched && c.VerifyHashes == "" {
+		return fmt.Errorf("--delete-mismatched requires --verify-hashes")
+	}
+	if backend, err := storage.ParseBackend(c.StorageBackend); err != nil {
+		return fmt.Errorf("invalid --storage-backend: %w", err)
+	} else if backend == storage.BackendSQLite && !storage.SQLiteAvailable {
+		return fmt.Errorf("invalid --storage-backend: %w", storage.ErrSQLiteUnavailable)
+	} else if backend == storage.BackendSQLite && (c.Dedupe || c.Beautify || c.JSBeautify || c.CrawlDepth > 0 || c.FetchSourceMaps || c.DeleteMismatched || c.Retain > 0 || c.HostOutput != "") {
+		return fmt.Errorf("--storage-backend sqlite cannot be used with --dedupe, --beautify, --js-beautify, --crawl-depth, --fetch-sourcemaps, --delete-mismatched, --retain or --host-output (they work on files)")
+	}
+	if (c.SignHeader != "" || c.SignTemplate != "") && c.SignKey == "" {
+		return fmt.Errorf("--sign-header and --sign-template require --sign-key")
+	}
+	if c.InputFile == "" && len(c.URLArgs) == 0 {
 		return ErrMissingInputFile
 	}
 	if c.Workers < 1 {
 		c.Workers = 1
 	}
+	if c.PerHostLimit < 0 {
+		c.PerHostLimit = 0
+	}
+	if c.MaxLineLength < 0 {
+		c.MaxLineLength = 0
+	}
+	if c.MaxTotalBytes < 0 {
+		c.MaxTotalBytes = 0
+	}
+	if c.MaxDownloadSize < 0 {
+		c.MaxDownloadSize = 0
+	}
+	if c.PreviewBytes < 0 {
+		c.PreviewBytes = 0
+	}
 	if c.Timeout < time.Second {
 		c.Timeout = time.Second
 	}
+	if c.Retain < 0 {
+		c.Retain = 0
+	}
+	if c.CrawlDepth < 0 {
+		c.CrawlDepth = 0
+	}
+	if c.DNSCacheTTL < 0 {
+		c.DNSCacheTTL = 0
+	}
+	if c.DNSMaxLookups < 0 {
+		c.DNSMaxLookups = 0
+	}
+	if c.CacheSize < 0 {
+		c.CacheSize = 0
+	}
 	return nil
 }
 
@@ -19,6 +19,22 @@ type ConfigFile struct {
 
 // LoadConfigFile loads configuration from .downurlrc
 func LoadConfigFile() (*ConfigFile, error) {
+	if path := FindConfigFile(); path != "" {
+		return parseConfigFile(path)
+	}
+
+	// No config file found, return empty config
+	return &ConfigFile{
+		Defaults:  make(map[string]string),
+		Auth:      make(map[string]map[string]string),
+		Filters:   make(map[string]string),
+		RateLimit: make(map[string]string),
+	}, nil
+}
+
+// FindConfigFile returns the .downurlrc that LoadConfigFile reads, or ""
+// if there is none
+func FindConfigFile() string {
 	// Try in order: ./.downurlrc, ~/.downurlrc
 	paths := []string{
 		".downurlrc",
@@ -27,17 +43,10 @@ func LoadConfigFile() (*ConfigFile, error) {
 
 	for _, path := range paths {
 		if _, err := os.Stat(path); err == nil {
-			return parseConfigFile(path)
+			return path
 		}
 	}
-
-	// No config file found, return empty config
-	return &ConfigFile{
-		Defaults:  make(map[string]string),
-		Auth:      make(map[string]map[string]string),
-		Filters:   make(map[string]string),
-		RateLimit: make(map[string]string),
-	}, nil
+	return ""
 }
 
 // parseConfigFile parses a simple INI-style config file
@@ -121,6 +130,20 @@ func (cf *ConfigFile) ApplyToConfig(c *Config) {
 		c.StorageMode = cf.Defaults["mode"]
 	}
 
+	if c.HostLayout == "prefix" && cf.Defaults["host_layout"] != "" {
+		c.HostLayout = cf.Defaults["host_layout"]
+	}
+
+	if !c.FlatHost && cf.Defaults["flat_host"] == "true" {
+		c.FlatHost = true
+	}
+
+	if c.Retain == 0 && cf.Defaults["retain"] != "" {
+		if retain, err := strconv.Atoi(cf.Defaults["retain"]); err == nil {
+			c.Retain = retain
+		}
+	}
+
 	if c.Workers == 10 && cf.Defaults["workers"] != "" {
 		if workers, err := strconv.Atoi(cf.Defaults["workers"]); err == nil {
 			c.Workers = workers
@@ -147,33 +170,103 @@ func (cf *ConfigFile) ApplyToConfig(c *Config) {
 			c.MaxSize = size
 		}
 	}
+
+	// Per-host credentials, used by BuildHostAuth
+	if len(cf.Auth) > 0 {
+		c.HostAuth = cf.Auth
+	}
+
+	// Per-host rate limits, used by BuildRateLimiter
+	if len(cf.RateLimit) > 0 {
+		c.HostRateLimits = cf.RateLimit
+	}
 }
 
 // parseSize parses size strings like "50MB", "1GB"
 func parseSize(s string) (int64, error) {
 	s = strings.ToUpper(strings.TrimSpace(s))
 
-	multipliers := map[string]int64{
-		"B":  1,
-		"KB": 1024,
-		"MB": 1024 * 1024,
-		"GB": 1024 * 1024 * 1024,
+	// Longest suffix first, so "50MB" isn't read as "50M" bytes
+	multipliers := []struct {
+		suffix     string
+		multiplier int64
+	}{
+		{"KB", 1024},
+		{"MB", 1024 * 1024},
+		{"GB", 1024 * 1024 * 1024},
+		{"B", 1},
 	}
 
-	for suffix, multiplier := range multipliers {
-		if strings.HasSuffix(s, suffix) {
-			numStr := strings.TrimSuffix(s, suffix)
+	for _, m := range multipliers {
+		if strings.HasSuffix(s, m.suffix) {
+			numStr := strings.TrimSuffix(s, m.suffix)
 			num, err := strconv.ParseFloat(numStr, 64)
 			if err != nil {
 				return 0, err
 			}
-			return int64(num * float64(multiplier)), nil
+			return int64(num * float64(m.multiplier)), nil
 		}
 	}
 
 	return strconv.ParseInt(s, 10, 64)
 }
 
+// sizeValue is a flag.Value for byte sizes given as 1048576, 512KB or 1GB
+type sizeValue int64
+
+func (s *sizeValue) String() string {
+	return strconv.FormatInt(int64(*s), 10)
+}
+
+func (s *sizeValue) Set(v string) error {
+	n, err := parseSize(v)
+	if err != nil {
+		return fmt.Errorf("invalid size %q (expected bytes or a KB, MB, GB suffix)", v)
+	}
+	*s = sizeValue(n)
+	return nil
+}
+
+// listValue is a flag.Value that collects every occurrence of a repeated
+// flag, so values may contain commas
+type listValue []string
+
+func (l *listValue) String() string {
+	return strings.Join(*l, ", ")
+}
+
+func (l *listValue) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// thresholdValue is a flag.Value for a percentage that may be given without
+// a value: --flag means 100, --flag=90 means 90 and --flag=false turns it off
+type thresholdValue float64
+
+func (t *thresholdValue) String() string {
+	return strconv.FormatFloat(float64(*t), 'f', -1, 64)
+}
+
+func (t *thresholdValue) Set(v string) error {
+	if b, err := strconv.ParseBool(v); err == nil {
+		*t = 0
+		if b {
+			*t = 100
+		}
+		return nil
+	}
+	n, err := strconv.ParseFloat(strings.TrimSuffix(v, "%"), 64)
+	if err != nil || n < 0 || n > 100 {
+		return fmt.Errorf("invalid percentage %q (expected 0-100)", v)
+	}
+	*t = thresholdValue(n)
+	return nil
+}
+
+// IsBoolFlag lets the flag be given without a value
+func (t *thresholdValue) IsBoolFlag() bool { return true }
+
 // SaveConfigFile saves current config to .downurlrc
 func SaveConfigFile(c *Config, path string) error {
 	var sb strings.Builder
@@ -183,6 +276,15 @@ func SaveConfigFile(c *Config, path string) error {
 
 	sb.WriteString("[defaults]\n")
 	sb.WriteString(fmt.Sprintf("mode = %s\n", c.StorageMode))
+	if c.HostLayout != "" && c.HostLayout != "prefix" {
+		sb.WriteString(fmt.Sprintf("host_layout = %s\n", c.HostLayout))
+	}
+	if c.FlatHost {
+		sb.WriteString("flat_host = true\n")
+	}
+	if c.Retain > 0 {
+		sb.WriteString(fmt.Sprintf("retain = %d\n", c.Retain))
+	}
 	sb.WriteString(fmt.Sprintf("workers = %d\n", c.Workers))
 	sb.WriteString(fmt.Sprintf("timeout = %s\n", c.Timeout.String()))
 	sb.WriteString(fmt.Sprintf("output = %s\n", c.OutputDir))
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// ExpandOutputDir replaces run placeholders in an output directory:
+//   - {date}:  run date (2006-01-02)
+//   - {time}:  run time (150405)
+//   - {runid}: random 8-character hex id
+func ExpandOutputDir(dir string, now time.Time, runID string) string {
+	if !strings.Contains(dir, "{") {
+		return dir
+	}
+
+	return strings.NewReplacer(
+		"{date}", now.Format("2006-01-02"),
+		"{time}", now.Format("150405"),
+		"{runid}", runID,
+	).Replace(dir)
+}
+
+// NewRunID returns a random identifier for a run
+func NewRunID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
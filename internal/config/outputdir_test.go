@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestExpandOutputDir(t *testing.T) {
+	now := time.Date(2025, 11, 17, 9, 5, 3, 0, time.UTC)
+
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"output", "output"},
+		{"scans/{date}_{time}", "scans/2025-11-17_090503"},
+		{"scans/{runid}", "scans/abcd1234"},
+		{"{date}/{date}", "2025-11-17/2025-11-17"},
+		{"scans/{unknown}", "scans/{unknown}"},
+	}
+
+	for _, tt := range tests {
+		if got := ExpandOutputDir(tt.dir, now, "abcd1234"); got != tt.want {
+			t.Errorf("ExpandOutputDir(%q) = %q, want %q", tt.dir, got, tt.want)
+		}
+	}
+}
+
+func TestExpandOutputDir_CreatedAndUsed(t *testing.T) {
+	base := t.TempDir()
+	now := time.Date(2025, 11, 17, 9, 5, 3, 0, time.UTC)
+
+	dir := ExpandOutputDir(filepath.Join(base, "scans", "{date}_{runid}"), now, NewRunID())
+	fs := storage.NewFileStorage(dir, "flat")
+	if err := fs.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		t.Fatalf("expanded directory %s was not created: %v", dir, err)
+	}
+	if filepath.Dir(dir) != filepath.Join(base, "scans") {
+		t.Errorf("expanded directory %s is not under scans/", dir)
+	}
+	if name := filepath.Base(dir); len(name) != len("2025-11-17_")+8 || name[:11] != "2025-11-17_" {
+		t.Errorf("expanded directory name = %s, want 2025-11-17_<runid>", name)
+	}
+
+	path, _, err := fs.SaveFileFromReader("example.com", "/app.js", "app.js", strings.NewReader("x"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+	if filepath.Dir(path) != dir {
+		t.Errorf("file saved to %s, want it inside %s", path, dir)
+	}
+}
+
+func TestNewRunID_Unique(t *testing.T) {
+	if a, b := NewRunID(), NewRunID(); a == b {
+		t.Errorf("NewRunID() returned %q twice", a)
+	}
+}
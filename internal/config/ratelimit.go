@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+)
+
+// BuildRateLimiter creates the rate limiter from --rate-limit and the
+// [ratelimit] section of .downurlrc, which maps hosts to limits such as
+// "api.example.com = 2/second". A listed host (subdomains included) gets its
+// own limit instead of --rate-limit, which applies to every other host; a
+// "default" key stands in for --rate-limit when it is not given. It returns
+// nil if no limit is set.
+func (c *Config) BuildRateLimiter() (*ratelimit.HostLimiter, error) {
+	defaultLimit := c.RateLimit
+	if defaultLimit == "" {
+		defaultLimit = c.HostRateLimits["default"]
+	}
+	if defaultLimit == "" && len(c.HostRateLimits) == 0 {
+		return nil, nil
+	}
+
+	var def *ratelimit.Limiter
+	if defaultLimit != "" {
+		var err error
+		if def, err = ratelimit.ParseRateLimit(defaultLimit); err != nil {
+			return nil, fmt.Errorf("invalid rate limit: %w", err)
+		}
+	}
+
+	// Sorted, so the first bad host reported is always the same one
+	hosts := make([]string, 0, len(c.HostRateLimits))
+	for host := range c.HostRateLimits {
+		if host != "default" {
+			hosts = append(hosts, host)
+		}
+	}
+	sort.Strings(hosts)
+
+	byHost := make(map[string]*ratelimit.Limiter, len(hosts))
+	for _, host := range hosts {
+		if err := checkRateLimitHost(host); err != nil {
+			return nil, fmt.Errorf("[ratelimit] %s: %w", host, err)
+		}
+		l, err := ratelimit.ParseRateLimit(c.HostRateLimits[host])
+		if err != nil {
+			return nil, fmt.Errorf("[ratelimit] %s: %w", host, err)
+		}
+		byHost[host] = l
+	}
+	if def == nil && len(byHost) == 0 {
+		return nil, nil
+	}
+	return ratelimit.NewHostLimiter(def, byHost), nil
+}
+
+// checkRateLimitHost rejects [ratelimit] keys with a port: requests are
+// matched by host name alone, so such a key would never apply
+func checkRateLimitHost(host string) error {
+	if _, port, err := net.SplitHostPort(host); err == nil {
+		return fmt.Errorf("limits apply to every port of a host; remove :%s", port)
+	}
+	return nil
+}
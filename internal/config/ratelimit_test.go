@@ -0,0 +1,69 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestBuildRateLimiter(t *testing.T) {
+	cf := &ConfigFile{RateLimit: map[string]string{
+		"api.example.com": "2/second",
+		"cdn.example.com": "100/second",
+	}}
+	c := &Config{RateLimit: "10/minute"}
+	cf.ApplyToConfig(c)
+
+	limiter, err := c.BuildRateLimiter()
+	if err != nil {
+		t.Fatalf("BuildRateLimiter() error = %v", err)
+	}
+	if limiter.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", limiter.Len())
+	}
+	api, cdn, other := limiter.For("v2.api.example.com"), limiter.For("cdn.example.com"), limiter.For("example.com")
+	if api == nil || cdn == nil || other == nil || api == cdn || api == other || cdn == other {
+		t.Errorf("For() = %p, %p, %p, want three distinct limiters", api, cdn, other)
+	}
+
+	// No limits, no limiter
+	if limiter, err := (&Config{}).BuildRateLimiter(); limiter != nil || err != nil {
+		t.Errorf("BuildRateLimiter() without limits = %v, %v", limiter, err)
+	}
+}
+
+func TestBuildRateLimiter_Default(t *testing.T) {
+	c := &Config{HostRateLimits: map[string]string{
+		"default":         "5/second",
+		"api.example.com": "1/second",
+	}}
+	limiter, err := c.BuildRateLimiter()
+	if err != nil {
+		t.Fatalf("BuildRateLimiter() error = %v", err)
+	}
+	if limiter.Len() != 1 {
+		t.Errorf("Len() = %d, want 1 (default is not a host)", limiter.Len())
+	}
+	if limiter.For("example.com") == nil {
+		t.Error("For(example.com) = nil, want the [ratelimit] default")
+	}
+
+	// Without a default, unlisted hosts are not limited
+	c = &Config{HostRateLimits: map[string]string{"api.example.com": "1/second"}}
+	if limiter, err = c.BuildRateLimiter(); err != nil {
+		t.Fatalf("BuildRateLimiter() error = %v", err)
+	}
+	if limiter.For("example.com") != nil {
+		t.Error("For(example.com) should not be limited")
+	}
+}
+
+func TestBuildRateLimiter_Invalid(t *testing.T) {
+	for _, c := range []*Config{
+		{RateLimit: "fast"},
+		{HostRateLimits: map[string]string{"api.example.com": "2/fortnight"}},
+		{HostRateLimits: map[string]string{"api.example.com:8443": "2/second"}},
+	} {
+		if _, err := c.BuildRateLimiter(); err == nil {
+			t.Errorf("BuildRateLimiter(%q, %v) expected error", c.RateLimit, c.HostRateLimits)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// DefaultAccept is sent when no Accept header is configured
+const DefaultAccept = "*/*"
+
+// ParseAcceptMap parses a comma-separated list of "ext=media/type" pairs
+// into a per-extension Accept mapping (e.g. "json=application/json")
+func ParseAcceptMap(s string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid accept mapping: %q (expected 'ext=media/type')", item)
+		}
+		ext := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(parts[0]), "."))
+		value := strings.TrimSpace(parts[1])
+		if ext == "" || value == "" {
+			return nil, fmt.Errorf("invalid accept mapping: %q (expected 'ext=media/type')", item)
+		}
+		mapping[ext] = value
+	}
+	return mapping, nil
+}
+
+// SetAccept configures the Accept header. accept applies to every request;
+// byExt overrides it for URLs whose path has a matching extension.
+// Both take precedence over an Accept header from custom headers.
+func (c *HTTPClient) SetAccept(accept string, byExt map[string]string) {
+	c.accept = accept
+	c.acceptByExt = byExt
+}
+
+// applyAccept sets the Accept header for a request. It runs after
+// authentication so configured values aren't overridden by custom headers.
+func (c *HTTPClient) applyAccept(req *http.Request) {
+	if value, ok := c.acceptByExt[urlExtension(req.URL)]; ok {
+		req.Header.Set("Accept", value)
+		return
+	}
+	if c.accept != "" {
+		req.Header.Set("Accept", c.accept)
+		return
+	}
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", DefaultAccept)
+	}
+}
+
+// urlExtension returns the lowercase extension of a URL path, without the dot
+func urlExtension(u *url.URL) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(u.Path), "."))
+}
@@ -0,0 +1,109 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+)
+
+// negotiatingServer serves JSON, JavaScript or HTML depending on the Accept header
+func negotiatingServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accept := r.Header.Get("Accept")
+		switch {
+		case strings.Contains(accept, "application/json"):
+			w.Write([]byte(`{"variant":"json"}`))
+		case strings.Contains(accept, "javascript"):
+			w.Write([]byte("var variant = 'js';"))
+		default:
+			w.Write([]byte("<html>" + accept + "</html>"))
+		}
+	}))
+}
+
+func TestHTTPClient_Accept(t *testing.T) {
+	server := negotiatingServer()
+	defer server.Close()
+
+	headersProvider, err := auth.NewProvider(auth.Config{
+		Type:    auth.AuthTypeCustom,
+		Headers: map[string]string{"Accept": "text/html", "X-Trace": "1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		provider *auth.Provider
+		accept   string
+		byExt    map[string]string
+		path     string
+		want     string
+	}{
+		{"default", nil, "", nil, "/api/item", "<html>*/*</html>"},
+		{"explicit", nil, "application/json", nil, "/api/item", `{"variant":"json"}`},
+		{"by extension", nil, "application/json", map[string]string{"js": "application/javascript"}, "/static/app.js", "var variant = 'js';"},
+		{"extension not mapped", nil, "application/json", map[string]string{"js": "application/javascript"}, "/api/item", `{"variant":"json"}`},
+		{"custom header kept by default", headersProvider, "", nil, "/api/item", "<html>text/html</html>"},
+		{"explicit beats custom header", headersProvider, "application/json", nil, "/api/item", `{"variant":"json"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPClientWithAuth(5*time.Second, 0, tt.provider)
+			client.SetAccept(tt.accept, tt.byExt)
+
+			var buf bytes.Buffer
+			if _, err := client.DownloadToWriter(context.Background(), server.URL+tt.path, &buf); err != nil {
+				t.Fatalf("DownloadToWriter() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("DownloadToWriter() body = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPClient_AcceptOnHead(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Accept")
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetAccept("application/json", nil)
+
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got != "application/json" {
+		t.Errorf("HEAD Accept = %q, want %q", got, "application/json")
+	}
+}
+
+func TestParseAcceptMap(t *testing.T) {
+	got, err := ParseAcceptMap(" .JSON=application/json, js=application/javascript ,")
+	if err != nil {
+		t.Fatalf("ParseAcceptMap() error = %v", err)
+	}
+	if len(got) != 2 || got["json"] != "application/json" || got["js"] != "application/javascript" {
+		t.Errorf("ParseAcceptMap() = %v", got)
+	}
+
+	for _, bad := range []string{"json", "=application/json", "json="} {
+		if _, err := ParseAcceptMap(bad); err == nil {
+			t.Errorf("ParseAcceptMap(%q) expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_MaxTotalBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	var urls []string
+	for i := 0; i < 6; i++ {
+		urls = append(urls, fmt.Sprintf("%s/file%d.js", server.URL, i))
+	}
+
+	// One worker makes the order deterministic: 100 + 100 + 100 crosses 250
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+	dl.SetMaxTotalBytes(250)
+	results := dl.DownloadAll(context.Background(), urls)
+
+	var downloaded, skipped int
+	for _, r := range results {
+		switch {
+		case len(r.Failures) == 0:
+			downloaded++
+		case r.Failures[0].Category == models.ErrorCategoryCancelled && strings.Contains(r.Failures[0].Message, "budget"):
+			skipped++
+		default:
+			t.Errorf("%s: unexpected failure %+v", r.URL, r.Failures)
+		}
+	}
+	if downloaded != 3 || skipped != 3 {
+		t.Errorf("downloaded %d, not started %d; want 3 and 3", downloaded, skipped)
+	}
+	if !dl.BudgetReached() {
+		t.Error("BudgetReached() = false, want true")
+	}
+	if got := dl.TotalBytes(); got != 300 {
+		t.Errorf("TotalBytes() = %d, want 300", got)
+	}
+}
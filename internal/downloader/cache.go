@@ -0,0 +1,135 @@
+package downloader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/lcalzada-xor/downurl/internal/cache"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+)
+
+// ErrNotModified is returned for a 304 answer to a conditional request
+var ErrNotModified = errors.New("not modified")
+
+// SetCache enables a shared cache: GET responses carrying an ETag or
+// Last-Modified are stored, and later downloads of the same URL are
+// revalidated with a conditional GET and copied from the cache on 304
+func (d *Downloader) SetCache(c *cache.Cache) {
+	d.cache = c
+}
+
+// isConditional reports whether a request carries cache validators
+func isConditional(req *http.Request) bool {
+	return req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+}
+
+// cacheSink writes a download to storage and to the cache at once
+type cacheSink struct {
+	sink  saveSink
+	entry *cache.Writer
+}
+
+// Write passes data to both destinations
+func (s *cacheSink) Write(p []byte) (int, error) {
+	n, err := s.sink.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if _, err := s.entry.Write(p); err != nil {
+		return n, &WriteError{Err: fmt.Errorf("cache: %w", err)}
+	}
+	return n, nil
+}
+
+// Reset restarts both destinations for a retried download
+func (s *cacheSink) Reset() error {
+	if err := s.entry.Reset(); err != nil {
+		return err
+	}
+	return s.sink.Reset()
+}
+
+// downloadWithCache downloads a URL through the cache: a cached copy is
+// revalidated and reused on 304, and a fresh body is stored when the
+// response can be revalidated later
+func (d *Downloader) downloadWithCache(ctx context.Context, url, host, filename string, check ResponseCheck) (string, int64, error) {
+	entry, cached := d.cache.Lookup(url)
+	if cached {
+		conditional := make(http.Header)
+		if entry.ETag != "" {
+			conditional.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			conditional.Set("If-Modified-Since", entry.LastModified)
+		}
+		ctx = addContextHeaders(ctx, conditional)
+	}
+
+	writer, err := d.cache.NewWriter(url)
+	if err != nil {
+		log.Printf("[WARN] Cache unavailable for %s: %s", sanitize.Text(url), sanitize.Text(err.Error()))
+		return d.downloadToStorage(ctx, url, host, filename, check)
+	}
+
+	// Remember the validators of the response that gets saved
+	var etag, lastModified string
+	capture := func(resp *http.Response) error {
+		if check != nil {
+			if err := check(resp); err != nil {
+				return err
+			}
+		}
+		etag, lastModified = resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+		return nil
+	}
+
+	sink, capture := d.newSink(url, host, filename, capture)
+	_, downloadErr := d.client.DownloadToWriterWithCheck(ctx, url, &cacheSink{sink: sink, entry: writer}, capture)
+
+	if errors.Is(downloadErr, ErrNotModified) && cached {
+		writer.Abort()
+		return d.copyFromCache(url, sink)
+	}
+
+	saved := sink.Close(downloadErr)
+	if downloadErr == nil && saved.err == nil && (etag != "" || lastModified != "") {
+		if err := writer.Commit(etag, lastModified); err != nil {
+			log.Printf("[WARN] Failed to cache %s: %s", sanitize.Text(url), sanitize.Text(err.Error()))
+		}
+	} else {
+		writer.Abort()
+	}
+
+	if downloadErr != nil {
+		return "", 0, downloadErr
+	}
+	if saved.err != nil {
+		return "", saved.bytes, saved.err
+	}
+	return saved.path, saved.bytes, nil
+}
+
+// copyFromCache saves the cached body of url through sink
+func (d *Downloader) copyFromCache(url string, sink saveSink) (string, int64, error) {
+	body, err := d.cache.Open(url)
+	if err != nil {
+		sink.Close(err)
+		return "", 0, fmt.Errorf("cached copy vanished: %w", err)
+	}
+	_, copyErr := io.Copy(sink, body)
+	body.Close()
+
+	saved := sink.Close(copyErr)
+	if copyErr != nil {
+		return "", 0, fmt.Errorf("failed to copy from cache: %w", copyErr)
+	}
+	if saved.err != nil {
+		return "", saved.bytes, saved.err
+	}
+	log.Printf("[CACHE] %s not modified, copied from cache", sanitize.Text(url))
+	return saved.path, saved.bytes, nil
+}
@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/cache"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_CacheServesNotModified(t *testing.T) {
+	var full, notModified int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			atomic.AddInt32(&notModified, 1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&full, 1)
+		w.Header().Set("ETag", `"v1"`)
+		w.Write([]byte("console.log('cached');"))
+	}))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	url := server.URL + "/js/app.js"
+
+	// Each run gets a fresh downloader and output directory, sharing only the cache
+	run := func() string {
+		t.Helper()
+		c, err := cache.Open(cacheDir, 0)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+		dl.SetCache(c)
+
+		results := dl.DownloadAll(context.Background(), []string{url})
+		if !results[0].IsSuccess() {
+			t.Fatalf("download failed: %v", results[0].Errors)
+		}
+		data, err := os.ReadFile(results[0].Downloaded[0])
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		return string(data)
+	}
+
+	if got := run(); got != "console.log('cached');" {
+		t.Errorf("first run saved %q", got)
+	}
+	if got := run(); got != "console.log('cached');" {
+		t.Errorf("second run saved %q, want the cached body", got)
+	}
+
+	if full != 1 || notModified != 1 {
+		t.Errorf("server sent %d full responses and %d 304s, want 1 and 1", full, notModified)
+	}
+}
@@ -0,0 +1,162 @@
+package downloader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// Checkpoint records each result of a run in a JSON-lines file as it
+// completes, so an interrupted run can be resumed: URLs that finished are
+// skipped and their results reused. A URL finished if it was saved or
+// skipped by a filter; failed and cancelled URLs are tried again.
+type Checkpoint struct {
+	path     string
+	finished map[string]models.DownloadResult // Finished results loaded from the file
+
+	mu     sync.Mutex
+	file   *os.File
+	failed bool // A write failed; later results are not recorded
+}
+
+// OpenCheckpoint loads the results recorded in path, if it exists, and opens
+// it to append the results of this run
+func OpenCheckpoint(path string) (*Checkpoint, error) {
+	c := &Checkpoint{path: path, finished: make(map[string]models.DownloadResult)}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open checkpoint: %w", err)
+	}
+	c.file = file
+	return c, nil
+}
+
+// load reads the recorded results; a later line for a URL replaces earlier ones
+func (c *Checkpoint) load() error {
+	file, err := os.Open(c.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+	defer file.Close()
+
+	latest := make(map[string]models.DownloadResult)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var r models.DownloadResult
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil || r.URL == "" {
+			// The last line may have been cut off when the run was killed
+			log.Printf("[WARN] Ignoring unreadable checkpoint line %d in %s", lineNum, sanitize.Text(c.path))
+			continue
+		}
+		latest[r.URL] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	for url, r := range latest {
+		if isFinished(&r) {
+			c.finished[url] = r
+		}
+	}
+	return nil
+}
+
+// isFinished reports whether a result needs no new attempt: the file was
+// saved, or every failure was a filter skip
+func isFinished(r *models.DownloadResult) bool {
+	if r.IsSuccess() {
+		return true
+	}
+	if len(r.Failures) == 0 || len(r.Failures) != len(r.Errors) {
+		return false
+	}
+	for _, f := range r.Failures {
+		if f.Category != models.ErrorCategorySkipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Remaining returns the URLs of urls that did not finish in an earlier run
+func (c *Checkpoint) Remaining(urls []string) []string {
+	var remaining []string
+	for _, u := range urls {
+		if _, ok := c.finished[u]; !ok {
+			remaining = append(remaining, u)
+		}
+	}
+	return remaining
+}
+
+// RemainingJobs returns the jobs whose URL did not finish in an earlier run
+func (c *Checkpoint) RemainingJobs(jobs []Job) []Job {
+	var remaining []Job
+	for _, job := range jobs {
+		if _, ok := c.finished[job.URL]; !ok {
+			remaining = append(remaining, job)
+		}
+	}
+	return remaining
+}
+
+// Finished returns the results earlier runs recorded for jobs, in the jobs'
+// order: the counterpart of RemainingJobs. URLs the checkpoint finished that
+// are not among jobs are left out.
+func (c *Checkpoint) Finished(jobs []Job) []*models.DownloadResult {
+	var results []*models.DownloadResult
+	for _, job := range jobs {
+		if r, ok := c.finished[job.URL]; ok {
+			results = append(results, &r)
+		}
+	}
+	return results
+}
+
+// Record appends a result to the checkpoint file. Failures are logged once
+// and stop recording; they don't stop the run.
+func (c *Checkpoint) Record(r *models.DownloadResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.failed {
+		return
+	}
+
+	line, err := json.Marshal(r)
+	if err == nil {
+		_, err = c.file.Write(append(line, '\n'))
+	}
+	if err != nil {
+		c.failed = true
+		log.Printf("[WARN] Checkpoint %s is no longer updated: %s", sanitize.Text(c.path), sanitize.Text(err.Error()))
+	}
+}
+
+// Close closes the checkpoint file
+func (c *Checkpoint) Close() error {
+	return c.file.Close()
+}
+
+// SetCheckpoint records every result in c as soon as it completes
+func (d *Downloader) SetCheckpoint(c *Checkpoint) {
+	d.checkpoint = c
+}
@@ -0,0 +1,56 @@
+package downloader
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestCheckpoint_Resume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint")
+	cp, err := OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+
+	saved := &models.DownloadResult{URL: "https://a.example/saved.js", Downloaded: []string{"out/saved.js"}, Bytes: 10}
+	skipped := &models.DownloadResult{URL: "https://a.example/skipped.png"}
+	skipped.AddError(models.DownloadError{Category: models.ErrorCategorySkipped, Message: "skipped: extension"})
+	failed := &models.DownloadResult{URL: "https://a.example/failed.js"}
+	failed.AddError(models.DownloadError{Category: models.ErrorCategoryHTTP5xx, Message: "HTTP 503"})
+	for _, r := range []*models.DownloadResult{saved, skipped, failed} {
+		cp.Record(r)
+	}
+	cp.Close()
+
+	// A run killed mid-write leaves a cut-off last line
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	f.WriteString(`{"URL":"https://a.exam`)
+	f.Close()
+
+	cp, err = OpenCheckpoint(path)
+	if err != nil {
+		t.Fatalf("OpenCheckpoint() error = %v", err)
+	}
+	defer cp.Close()
+
+	urls := []string{saved.URL, skipped.URL, failed.URL, "https://a.example/new.js"}
+	want := []string{failed.URL, "https://a.example/new.js"}
+	if got := cp.Remaining(urls); !reflect.DeepEqual(got, want) {
+		t.Errorf("Remaining() = %v, want %v", got, want)
+	}
+
+	finished := cp.Finished(NewJobs(urls))
+	if len(finished) != 2 || finished[0].URL != saved.URL || finished[0].Bytes != 10 || finished[1].URL != skipped.URL {
+		t.Errorf("Finished() = %+v, want the saved and skipped results", finished)
+	}
+
+	// URLs finished earlier but not in this run are left out
+	finished = cp.Finished(NewJobs([]string{skipped.URL, "https://a.example/new.js"}))
+	if len(finished) != 1 || finished[0].URL != skipped.URL {
+		t.Errorf("Finished() = %+v, want only the skipped result", finished)
+	}
+}
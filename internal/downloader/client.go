@@ -2,9 +2,12 @@ package downloader
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"syscall"
 	"time"
 
 	"github.com/lcalzada-xor/downurl/internal/auth"
@@ -17,11 +20,31 @@ const (
 
 // HTTPClient wraps http.Client with retry logic and timeout
 type HTTPClient struct {
-	client        *http.Client
-	timeout       time.Duration
-	retryAttempts int
-	maxSize       int64
-	authProvider  *auth.Provider
+	client           *http.Client
+	timeout          time.Duration
+	retryAttempts    int
+	retryInterrupted bool
+	decompress       bool
+	maxSize          int64
+	authProvider     *auth.Provider
+	hostAuth         *auth.HostProviders
+	userAgent        string            // User-Agent for every request
+	accept           string            // Accept header for every request (empty = DefaultAccept)
+	acceptByExt      map[string]string // Accept header by URL path extension
+	previewBytes     int64             // Fetch only this many leading bytes of GET downloads (0 = whole files)
+	signer           RequestSigner     // Adds computed headers (e.g. an HMAC) to each request
+	scope            ScopeChecker      // Checks redirect targets (nil = follow any)
+	dns              *DNSCache         // Resolves and dials hosts (nil = the transport's own dialer)
+
+	// Vets each address before it is dialed (see SetScope)
+	dialControl func(network, address string, c syscall.RawConn) error
+}
+
+// ResettableWriter is a writer that can discard everything written so far.
+// Downloads interrupted mid-body are only retried when the writer can be reset.
+type ResettableWriter interface {
+	io.Writer
+	Reset() error
 }
 
 // NewHTTPClient creates a new HTTP client with specified timeout and retry attempts
@@ -31,21 +54,35 @@ func NewHTTPClient(timeout time.Duration, retryAttempts int) *HTTPClient {
 
 // NewHTTPClientWithAuth creates a new HTTP client with authentication support
 func NewHTTPClientWithAuth(timeout time.Duration, retryAttempts int, authProvider *auth.Provider) *HTTPClient {
-	return &HTTPClient{
+	c := &HTTPClient{
 		client: &http.Client{
 			Timeout: timeout,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("stopped after 10 redirects")
-				}
-				return nil
-			},
 		},
-		timeout:       timeout,
-		retryAttempts: retryAttempts,
-		maxSize:       MaxDownloadSize,
-		authProvider:  authProvider,
+		timeout:          timeout,
+		retryAttempts:    retryAttempts,
+		retryInterrupted: true,
+		decompress:       true,
+		maxSize:          MaxDownloadSize,
+		authProvider:     authProvider,
+		userAgent:        DefaultUserAgent,
+	}
+	c.client.CheckRedirect = c.checkRedirect
+	return c
+}
+
+// SetRetryInterrupted sets whether downloads cut off mid-body (connection reset,
+// unexpected EOF) are retried from scratch
+func (c *HTTPClient) SetRetryInterrupted(retry bool) {
+	c.retryInterrupted = retry
+}
+
+// SetMaxDownloadSize sets the largest single download; a bigger body fails
+// with a size error. Zero or less removes the limit.
+func (c *HTTPClient) SetMaxDownloadSize(n int64) {
+	if n <= 0 {
+		n = math.MaxInt64
 	}
+	c.maxSize = n
 }
 
 // Download downloads content from a URL with retry logic (legacy method)
@@ -55,10 +92,9 @@ func (c *HTTPClient) Download(ctx context.Context, url string) ([]byte, error) {
 
 	for attempt := 0; attempt <= c.retryAttempts; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt) * time.Second
+			// Back off, or wait as long as the server asked
 			select {
-			case <-time.After(backoff):
+			case <-time.After(retryDelay(attempt, lastErr)):
 			case <-ctx.Done():
 				return nil, ctx.Err()
 			}
@@ -71,8 +107,7 @@ func (c *HTTPClient) Download(ctx context.Context, url string) ([]byte, error) {
 
 		lastErr = err
 
-		// Don't retry on client errors (4xx)
-		if isClientError(err) {
+		if !c.isRetryable(err) {
 			break
 		}
 	}
@@ -80,59 +115,130 @@ func (c *HTTPClient) Download(ctx context.Context, url string) ([]byte, error) {
 	return nil, fmt.Errorf("failed after %d attempts: %w", c.retryAttempts+1, lastErr)
 }
 
+// ResponseCheck inspects a response before its body is consumed.
+// Returning an error aborts the download without retrying.
+type ResponseCheck func(resp *http.Response) error
+
 // DownloadToWriter downloads content from a URL and writes it to the provided writer
 func (c *HTTPClient) DownloadToWriter(ctx context.Context, url string, writer io.Writer) (int64, error) {
+	return c.DownloadToWriterWithCheck(ctx, url, writer, nil)
+}
+
+// DownloadToWriterWithCheck downloads content like DownloadToWriter, letting check reject the response first
+func (c *HTTPClient) DownloadToWriterWithCheck(ctx context.Context, url string, writer io.Writer, check ResponseCheck) (int64, error) {
 	var lastErr error
 
 	for attempt := 0; attempt <= c.retryAttempts; attempt++ {
 		if attempt > 0 {
-			// Exponential backoff
-			backoff := time.Duration(attempt) * time.Second
+			// Back off, or wait as long as the server asked
 			select {
-			case <-time.After(backoff):
+			case <-time.After(retryDelay(attempt, lastErr)):
 			case <-ctx.Done():
 				return 0, ctx.Err()
 			}
 		}
 
-		bytesWritten, err := c.doDownloadStream(ctx, url, writer)
+		bytesWritten, err := c.doDownloadStream(ctx, url, writer, check)
 		if err == nil {
 			return bytesWritten, nil
 		}
 
 		lastErr = err
 
-		// Don't retry on client errors (4xx)
-		if isClientError(err) {
+		if !c.isRetryable(err) {
 			break
 		}
+
+		// Part of the body already reached the writer: start over with an empty one, or give up
+		if bytesWritten > 0 {
+			resetter, ok := writer.(ResettableWriter)
+			if !ok {
+				break
+			}
+			if err := resetter.Reset(); err != nil {
+				return 0, fmt.Errorf("failed to reset writer for retry: %w", err)
+			}
+		}
+	}
+
+	// A rejected response or a current cached copy is not a failed attempt
+	if isSkipped(lastErr) || errors.Is(lastErr, ErrNotModified) {
+		return 0, lastErr
 	}
 
 	return 0, fmt.Errorf("failed after %d attempts: %w", c.retryAttempts+1, lastErr)
 }
 
-// Head performs a HEAD request to get metadata without downloading content
+// Head performs a HEAD request to get metadata without downloading content.
+// Transport failures and 5xx responses are retried like downloads; any other
+// response is returned as-is for the caller to judge.
 func (c *HTTPClient) Head(ctx context.Context, url string) (*http.Response, error) {
+	var lastErr error
+
+	for attempt := 0; attempt <= c.retryAttempts; attempt++ {
+		if attempt > 0 {
+			// Back off, or wait as long as the server asked
+			select {
+			case <-time.After(retryDelay(attempt, lastErr)):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		resp, err := c.doHead(ctx, url)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+
+		// Don't retry on cancellation
+		if isCancelled(err) {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("HEAD request failed after %d attempts: %w", c.retryAttempts+1, lastErr)
+}
+
+// send signs req, now that all its headers are set, records them and sends it
+func (c *HTTPClient) send(req *http.Request) (*http.Response, error) {
+	if err := c.sign(req); err != nil {
+		return nil, err
+	}
+	noteSentHeaders(req)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, wrapRequestError(err)
+	}
+	return resp, nil
+}
+
+// doHead performs a single HEAD attempt, treating 5xx and 429 responses as
+// errors so Head retries them as GET does, after any Retry-After delay
+func (c *HTTPClient) doHead(ctx context.Context, url string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create HEAD request: %w", err)
 	}
 
-	// Set default user agent
-	if c.authProvider == nil || req.Header.Get("User-Agent") == "" {
-		req.Header.Set("User-Agent", "downurl/1.0")
+	if err := c.prepareRequest(req); err != nil {
+		return nil, err
 	}
 
-	// Apply authentication if configured
-	if c.authProvider != nil {
-		if err := c.authProvider.ApplyAuth(req); err != nil {
-			return nil, fmt.Errorf("failed to apply authentication: %w", err)
-		}
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, err
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HEAD request failed: %w", err)
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		resp.Body.Close()
+		return nil, &HTTPError{
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			RetryAfter: retryAfter(resp),
+		}
 	}
 
 	return resp, nil
@@ -140,34 +246,24 @@ func (c *HTTPClient) Head(ctx context.Context, url string) (*http.Response, erro
 
 // doDownload performs a single download attempt
 func (c *HTTPClient) doDownload(ctx context.Context, url string) ([]byte, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	method, body := contextRequest(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set default user agent if no auth provider or no custom user agent
-	if c.authProvider == nil || req.Header.Get("User-Agent") == "" {
-		req.Header.Set("User-Agent", "downurl/1.0")
+	if err := c.prepareRequest(req); err != nil {
+		return nil, err
 	}
 
-	// Apply authentication if configured
-	if c.authProvider != nil {
-		if err := c.authProvider.ApplyAuth(req); err != nil {
-			return nil, fmt.Errorf("failed to apply authentication: %w", err)
-		}
-	}
-
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, &HTTPError{
-			StatusCode: resp.StatusCode,
-			Status:     resp.Status,
-		}
+	if err := statusError(resp); err != nil {
+		return nil, err
 	}
 
 	// Check content length if provided
@@ -191,57 +287,112 @@ func (c *HTTPClient) doDownload(ctx context.Context, url string) ([]byte, error)
 }
 
 // doDownloadStream performs a single download attempt with streaming
-func (c *HTTPClient) doDownloadStream(ctx context.Context, url string, writer io.Writer) (int64, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+func (c *HTTPClient) doDownloadStream(ctx context.Context, url string, writer io.Writer, check ResponseCheck) (int64, error) {
+	method, reqBody := contextRequest(ctx)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		return 0, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	// Set default user agent if no auth provider or no custom user agent
-	if c.authProvider == nil || req.Header.Get("User-Agent") == "" {
-		req.Header.Set("User-Agent", "downurl/1.0")
-	}
-
-	// Apply authentication if configured
-	if c.authProvider != nil {
-		if err := c.authProvider.ApplyAuth(req); err != nil {
-			return 0, fmt.Errorf("failed to apply authentication: %w", err)
-		}
+	if err := c.prepareRequest(req); err != nil {
+		return 0, err
 	}
+	preview := c.applyRange(req)
 
-	resp, err := c.client.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
-		return 0, fmt.Errorf("request failed: %w", err)
+		return 0, err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return 0, &HTTPError{
-			StatusCode: resp.StatusCode,
-			Status:     resp.Status,
+	// The caller's copy is still current
+	if resp.StatusCode == http.StatusNotModified && isConditional(req) {
+		return 0, ErrNotModified
+	}
+
+	// An empty resource has no first byte to return
+	if resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && preview {
+		return 0, nil
+	}
+
+	if err := statusError(resp); err != nil {
+		return 0, err
+	}
+
+	// Let the caller reject the response before anything is written
+	if check != nil {
+		if err := check(resp); err != nil {
+			return 0, err
 		}
 	}
 
-	// Check content length if provided
-	if resp.ContentLength > 0 && resp.ContentLength > c.maxSize {
+	// Check content length if provided (a preview only reads the start anyway)
+	if !preview && resp.ContentLength > 0 && resp.ContentLength > c.maxSize {
 		return 0, fmt.Errorf("file too large: %d bytes (max: %d bytes)", resp.ContentLength, c.maxSize)
 	}
 
-	// Stream response body to writer with size limit
-	limitedReader := io.LimitReader(resp.Body, c.maxSize)
-	bytesWritten, err := io.Copy(writer, limitedReader)
+	// Stream response body to writer with size limit, telling body read failures
+	// from decoding and write failures. The limit counts decoded bytes, so a small
+	// compressed body cannot expand past it.
+	limit, capped := c.bodyLimit(preview)
+	body := &bodyReader{r: resp.Body}
+	src, encoding, err := c.decodeBody(resp, body, preview)
 	if err != nil {
-		return bytesWritten, fmt.Errorf("failed to write response: %w", err)
+		if body.err != nil {
+			return 0, &InterruptedError{Err: wrapRequestError(body.err)}
+		}
+		return 0, err
+	}
+	decoded := &bodyReader{r: src}
+	bytesWritten, err := io.Copy(writer, io.LimitReader(decoded, limit))
+	if err != nil {
+		if body.err != nil {
+			return bytesWritten, &InterruptedError{Written: bytesWritten, Err: wrapRequestError(body.err)}
+		}
+		if decoded.err != nil {
+			return bytesWritten, &DecodeError{Encoding: encoding, Err: decoded.err}
+		}
+		return bytesWritten, &WriteError{Err: err}
 	}
 
 	// Check if we hit the limit
-	if bytesWritten >= c.maxSize {
+	if bytesWritten >= limit && !capped {
 		return bytesWritten, fmt.Errorf("file exceeded maximum size limit of %d bytes", c.maxSize)
 	}
 
 	return bytesWritten, nil
 }
 
+// bodyReader remembers the error a response body read failed with
+type bodyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (b *bodyReader) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err != nil && err != io.EOF {
+		b.err = err
+	}
+	return n, err
+}
+
+// isRetryable decides whether a failed attempt is worth repeating:
+// client errors (4xx but 429), cancellation, rejected responses and local
+// write failures are final; server errors, 429 and transport failures are retried.
+func (c *HTTPClient) isRetryable(err error) bool {
+	if isRateLimited(err) {
+		return true
+	}
+	if isClientError(err) || isRedirectError(err) || isCancelled(err) || isSkipped(err) || isWriteError(err) || isDecodeError(err) || errors.Is(err, ErrNotModified) {
+		return false
+	}
+	if isInterrupted(err) {
+		return c.retryInterrupted
+	}
+	return true
+}
+
 // isClientError checks if the error is a 4xx client error
 func isClientError(err error) bool {
 	if httpErr, ok := err.(*HTTPError); ok {
@@ -250,12 +401,85 @@ func isClientError(err error) bool {
 	return false
 }
 
+// isRedirectError checks if the server sent a redirect that cannot be followed
+func isRedirectError(err error) bool {
+	var redirectErr *RedirectError
+	return errors.As(err, &redirectErr)
+}
+
+// isCancelled checks if the error was caused by context cancellation
+func isCancelled(err error) bool {
+	var cancelledErr *CancelledError
+	return errors.As(err, &cancelledErr)
+}
+
+// isInterrupted checks if the body transfer was cut off mid-stream
+func isInterrupted(err error) bool {
+	var interruptedErr *InterruptedError
+	return errors.As(err, &interruptedErr)
+}
+
+// isDecodeError checks if a compressed body could not be decoded
+func isDecodeError(err error) bool {
+	var decodeErr *DecodeError
+	return errors.As(err, &decodeErr)
+}
+
+// isWriteError checks if the download failed writing to its destination
+func isWriteError(err error) bool {
+	var writeErr *WriteError
+	return errors.As(err, &writeErr)
+}
+
+// isSkipped checks if the response was rejected by a ResponseCheck
+func isSkipped(err error) bool {
+	var skipErr *SkipError
+	return errors.As(err, &skipErr)
+}
+
 // HTTPError represents an HTTP error response
 type HTTPError struct {
 	StatusCode int
 	Status     string
+	RetryAfter time.Duration // Delay asked for by a 429/503 Retry-After header (0 = none)
 }
 
 func (e *HTTPError) Error() string {
 	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Status)
 }
+
+// RedirectError is a redirect status the client could not follow because
+// the response has no Location header. It unwraps to the HTTPError for its status.
+type RedirectError struct {
+	StatusCode int
+	Status     string
+}
+
+func (e *RedirectError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s: redirect without a Location header, nothing to follow", e.StatusCode, e.Status)
+}
+
+func (e *RedirectError) Unwrap() error {
+	return &HTTPError{StatusCode: e.StatusCode, Status: e.Status}
+}
+
+// statusError returns the error for a final response that is not 2xx.
+// The client follows redirects itself, so a redirect status that reaches
+// here had no Location to follow; servers sending those are seen in the wild.
+func statusError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	switch resp.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		if resp.Header.Get("Location") == "" {
+			return &RedirectError{StatusCode: resp.StatusCode, Status: resp.Status}
+		}
+	}
+	return &HTTPError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		RetryAfter: retryAfter(resp),
+	}
+}
@@ -1,11 +1,19 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
 )
 
 func TestHTTPClient_Download_Success(t *testing.T) {
@@ -51,6 +59,38 @@ func TestHTTPClient_Download_404(t *testing.T) {
 	}
 }
 
+func TestHTTPClient_RedirectWithoutLocation(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusFound)
+		w.Write([]byte("<html>moved</html>"))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 2)
+	var buf bytes.Buffer
+	_, err := client.DownloadToWriter(context.Background(), server.URL+"/app.js", &buf)
+
+	var redirectErr *RedirectError
+	if !errors.As(err, &redirectErr) {
+		t.Fatalf("DownloadToWriter() error = %v, want a RedirectError", err)
+	}
+	if !strings.Contains(err.Error(), "302") || !strings.Contains(err.Error(), "without a Location header") {
+		t.Errorf("error = %q, want it to name the status and the missing Location", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("redirect body was written: %q", buf.String())
+	}
+	// The server will not change its mind: no retries
+	if n := requests.Load(); n != 1 {
+		t.Errorf("server got %d requests, want 1", n)
+	}
+	if got := Categorize(err); got != models.ErrorCategoryHTTP {
+		t.Errorf("Categorize() = %s, want %s", got, models.ErrorCategoryHTTP)
+	}
+}
+
 func TestHTTPClient_Download_Timeout(t *testing.T) {
 	// Create test server that delays response
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -87,3 +127,179 @@ func TestHTTPClient_Download_ContextCancellation(t *testing.T) {
 		t.Error("Download() expected error for cancelled context")
 	}
 }
+
+func TestHTTPClient_Head_RetriesServerErrors(t *testing.T) {
+	// Fail the first HEAD with 503, then succeed
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("unexpected %s request", r.Method)
+		}
+		if atomic.AddInt32(&heads, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/javascript")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 1)
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Head() status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if got := atomic.LoadInt32(&heads); got != 2 {
+		t.Errorf("HEAD requests = %d, want 2", got)
+	}
+}
+
+func TestHTTPClient_Head_RetriesTooManyRequests(t *testing.T) {
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&heads, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 1)
+	start := time.Now()
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || atomic.LoadInt32(&heads) != 2 {
+		t.Errorf("Head() status = %d after %d requests, want 200 after 2", resp.StatusCode, heads)
+	}
+	// The backoff alone would have retried after 1s
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("retried after %v, want the 2s asked by Retry-After", elapsed)
+	}
+}
+
+func TestHTTPClient_Head_GivesUpAfterRetries(t *testing.T) {
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&heads, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 1)
+	if _, err := client.Head(context.Background(), server.URL); err == nil {
+		t.Error("Head() expected error after exhausting retries")
+	}
+	if got := atomic.LoadInt32(&heads); got != 2 {
+		t.Errorf("HEAD requests = %d, want 2", got)
+	}
+}
+
+func TestHTTPClient_Head_ClientErrorNotRetried(t *testing.T) {
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&heads, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 2)
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Head() status = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	if got := atomic.LoadInt32(&heads); got != 1 {
+		t.Errorf("HEAD requests = %d, want 1", got)
+	}
+}
+
+// dropMidBody announces the full body but sends only its first n bytes before closing the connection
+func dropMidBody(t *testing.T, w http.ResponseWriter, body string, n int) {
+	t.Helper()
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		t.Fatal("response writer does not support hijacking")
+	}
+	conn, buf, err := hj.Hijack()
+	if err != nil {
+		t.Fatalf("Hijack() error = %v", err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(buf, "HTTP/1.1 200 OK\r\nContent-Length: %d\r\n\r\n%s", len(body), body[:n])
+	buf.Flush()
+}
+
+func TestHTTPClient_DownloadToWriter_InterruptedNeedsReset(t *testing.T) {
+	// A plain buffer can't drop the partial body, so the download must not be retried
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		dropMidBody(t, w, "0123456789abcdef", 8)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 2)
+	var buf bytes.Buffer
+	_, err := client.DownloadToWriter(context.Background(), server.URL, &buf)
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected error for truncated body")
+	}
+
+	var interruptedErr *InterruptedError
+	if !errors.As(err, &interruptedErr) {
+		t.Errorf("DownloadToWriter() error = %v, want *InterruptedError", err)
+	}
+	if got := Categorize(err); got != models.ErrorCategoryNetwork {
+		t.Errorf("Categorize() = %q, want %q", got, models.ErrorCategoryNetwork)
+	}
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("requests = %d, want 1", got)
+	}
+}
+
+func TestHTTPClient_IsRetryable(t *testing.T) {
+	client := NewHTTPClient(5*time.Second, 2)
+	interrupted := &InterruptedError{Written: 10, Err: wrapRequestError(io.ErrUnexpectedEOF)}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, true},
+		{"client error", &HTTPError{StatusCode: 404, Status: "404 Not Found"}, false},
+		{"network", wrapRequestError(errors.New("connection refused")), true},
+		{"interrupted", interrupted, true},
+		{"cancelled mid-body", &InterruptedError{Written: 10, Err: wrapRequestError(context.Canceled)}, false},
+		{"write failure", &WriteError{Err: errors.New("no space left on device")}, false},
+		{"skipped", &SkipError{Reason: "filtered"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.isRetryable(tt.err); got != tt.want {
+				t.Errorf("isRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+
+	client.SetRetryInterrupted(false)
+	if client.isRetryable(interrupted) {
+		t.Error("isRetryable() = true for interrupted download with retries disabled, want false")
+	}
+}
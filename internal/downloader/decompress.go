@@ -0,0 +1,95 @@
+package downloader
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+)
+
+// SetDecompress sets whether gzip, deflate and brotli response bodies are decoded
+// before they are written, so files are saved (and scanned) as plain content.
+// It is on by default.
+func (c *HTTPClient) SetDecompress(decompress bool) {
+	c.decompress = decompress
+}
+
+// decodeBody wraps body in a reader that undoes the response's Content-Encoding.
+// Identity bodies and encodings without a decoder (zstd, ...) are passed
+// through unchanged; the latter with a warning. It returns the decoded
+// encoding, or "" when the body is passed through.
+func (c *HTTPClient) decodeBody(resp *http.Response, body *bodyReader, preview bool) (io.Reader, string, error) {
+	// The transport already decoded the gzip it asked for itself
+	if !c.decompress || resp.Uncompressed {
+		return body, "", nil
+	}
+
+	decoded, encoding, err := decoderFor(resp, body)
+	if err != nil || !preview || encoding == "" {
+		return decoded, encoding, err
+	}
+	return &prefixReader{r: decoded, body: body}, encoding, nil
+}
+
+// decoderFor returns the decoder of the response's Content-Encoding
+func decoderFor(resp *http.Response, body io.Reader) (io.Reader, string, error) {
+
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	switch encoding {
+	case "", "identity":
+		return body, "", nil
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, encoding, &DecodeError{Encoding: encoding, Err: err}
+		}
+		return gz, encoding, nil
+	case "deflate":
+		fr, err := newDeflateReader(body)
+		if err != nil {
+			return nil, encoding, &DecodeError{Encoding: encoding, Err: err}
+		}
+		return fr, encoding, nil
+	case "br":
+		return brotli.NewReader(body), encoding, nil
+	default:
+		log.Printf("[WARN] %s: cannot decode Content-Encoding %q, saving the body as received",
+			sanitize.Text(resp.Request.URL.String()), sanitize.Text(encoding))
+		return body, "", nil
+	}
+}
+
+// prefixReader decodes the prefix of a body fetched with a Range request.
+// The encoded stream stops short, which the decoder reports as an unexpected
+// EOF; that is the expected end of a preview, unless the body itself failed.
+type prefixReader struct {
+	r    io.Reader
+	body *bodyReader
+}
+
+func (p *prefixReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if err == io.ErrUnexpectedEOF && p.body.err == nil {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// newDeflateReader decodes an HTTP deflate body. The spec says zlib format,
+// but some servers send raw deflate data; the zlib header tells them apart.
+func newDeflateReader(body io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(body)
+	header, _ := br.Peek(2)
+	if len(header) == 2 && header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
@@ -0,0 +1,217 @@
+package downloader
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"errors"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+const decompressContent = "var apiKey = 'abc'; fetch('/api/v1/users');"
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// encodedServer serves body with the given Content-Encoding and counts requests
+func encodedServer(encoding string, body []byte, hits *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		w.Header().Set("Content-Encoding", encoding)
+		w.Write(body)
+	}))
+}
+
+// rawClient returns a client whose transport neither asks for nor decodes
+// gzip itself, as when the request carries its own Accept-Encoding
+func rawClient(retries int) *HTTPClient {
+	client := NewHTTPClient(5*time.Second, retries)
+	client.client.Transport = &http.Transport{DisableCompression: true}
+	return client
+}
+
+func TestHTTPClient_Decompress(t *testing.T) {
+	var zlibBuf, flateBuf, brotliBuf bytes.Buffer
+	zw := zlib.NewWriter(&zlibBuf)
+	zw.Write([]byte(decompressContent))
+	zw.Close()
+	fw, _ := flate.NewWriter(&flateBuf, flate.DefaultCompression)
+	fw.Write([]byte(decompressContent))
+	fw.Close()
+	bw := brotli.NewWriter(&brotliBuf)
+	bw.Write([]byte(decompressContent))
+	bw.Close()
+
+	tests := []struct {
+		encoding string
+		body     []byte
+	}{
+		{"gzip", gzipBytes(t, []byte(decompressContent))},
+		{"x-gzip", gzipBytes(t, []byte(decompressContent))},
+		{"deflate", zlibBuf.Bytes()},
+		{"deflate", flateBuf.Bytes()},
+		{"br", brotliBuf.Bytes()},
+		{"identity", []byte(decompressContent)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			var hits int32
+			server := encodedServer(tt.encoding, tt.body, &hits)
+			defer server.Close()
+
+			var buf bytes.Buffer
+			n, err := rawClient(0).DownloadToWriter(context.Background(), server.URL, &buf)
+			if err != nil {
+				t.Fatalf("DownloadToWriter() error = %v", err)
+			}
+			if buf.String() != decompressContent || n != int64(len(decompressContent)) {
+				t.Errorf("DownloadToWriter() = %d bytes %q, want decoded content", n, buf.String())
+			}
+		})
+	}
+}
+
+func TestHTTPClient_NoDecompress(t *testing.T) {
+	body := gzipBytes(t, []byte(decompressContent))
+	var hits int32
+	server := encodedServer("gzip", body, &hits)
+	defer server.Close()
+
+	client := rawClient(0)
+	client.SetDecompress(false)
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), body) {
+		t.Error("DownloadToWriter() decoded the body despite SetDecompress(false)")
+	}
+}
+
+func TestHTTPClient_DecompressUnsupported(t *testing.T) {
+	body := []byte("\x28\xb5\x2f\xfdzstd-ish bytes")
+	var hits int32
+	server := encodedServer("zstd", body, &hits)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	if _, err := rawClient(0).DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	if !bytes.Equal(buf.Bytes(), body) {
+		t.Error("DownloadToWriter() should save an undecodable encoding as received")
+	}
+}
+
+func TestHTTPClient_DecompressLimit(t *testing.T) {
+	// A few KB of gzip that expand well past the limit
+	body := gzipBytes(t, []byte(strings.Repeat("\x00", 1<<20)))
+	var hits int32
+	server := encodedServer("gzip", body, &hits)
+	defer server.Close()
+
+	client := rawClient(0)
+	client.SetMaxDownloadSize(64 * 1024)
+	var buf bytes.Buffer
+	_, err := client.DownloadToWriter(context.Background(), server.URL, &buf)
+	if err == nil || !strings.Contains(err.Error(), "maximum size") {
+		t.Fatalf("DownloadToWriter() error = %v, want size limit error", err)
+	}
+	if buf.Len() > 64*1024 {
+		t.Errorf("wrote %d decoded bytes, want at most the limit", buf.Len())
+	}
+}
+
+func TestHTTPClient_DecompressCorrupt(t *testing.T) {
+	body := gzipBytes(t, []byte(decompressContent))
+	body[len(body)-5] ^= 0xff // break the checksum
+	var hits int32
+	server := encodedServer("gzip", body, &hits)
+	defer server.Close()
+
+	var buf bytes.Buffer
+	_, err := rawClient(2).DownloadToWriter(context.Background(), server.URL, &buf)
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		t.Fatalf("DownloadToWriter() error = %v, want DecodeError", err)
+	}
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("server hit %d times, want 1 (decode errors are not retried)", got)
+	}
+}
+
+func TestHTTPClient_DecompressPreview(t *testing.T) {
+	// Text that compresses too poorly for 4KB of it to hold the whole file
+	rng := rand.New(rand.NewSource(1))
+	content := make([]byte, 256*1024)
+	for i := range content {
+		content[i] = byte('a' + rng.Intn(26))
+	}
+	var flateBuf, brotliBuf bytes.Buffer
+	fw, _ := flate.NewWriter(&flateBuf, flate.DefaultCompression)
+	fw.Write(content)
+	fw.Close()
+	bw := brotli.NewWriter(&brotliBuf)
+	bw.Write(content)
+	bw.Close()
+
+	tests := []struct {
+		encoding string
+		body     []byte
+	}{
+		{"gzip", gzipBytes(t, content)},
+		{"deflate", flateBuf.Bytes()},
+		{"br", brotliBuf.Bytes()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.encoding, func(t *testing.T) {
+			// Range applies to the encoded bytes, so only a prefix of the stream arrives
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", tt.encoding)
+				http.ServeContent(w, r, "app.js", time.Time{}, bytes.NewReader(tt.body))
+			}))
+			defer server.Close()
+
+			client := rawClient(0)
+			client.SetPreviewBytes(4096)
+			var buf bytes.Buffer
+			n, err := client.DownloadToWriter(context.Background(), server.URL, &buf)
+			if err != nil {
+				t.Fatalf("DownloadToWriter() error = %v", err)
+			}
+			if n != 4096 || !bytes.Equal(buf.Bytes(), content[:4096]) {
+				t.Errorf("DownloadToWriter() = %d bytes, want the first 4096 decoded bytes", n)
+			}
+		})
+	}
+
+	// A stream shorter than the preview decodes whole
+	server := encodedServer("gzip", gzipBytes(t, []byte(decompressContent)), new(int32))
+	defer server.Close()
+	client := rawClient(0)
+	client.SetPreviewBytes(4096)
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil || buf.String() != decompressContent {
+		t.Errorf("DownloadToWriter() = %q, %v; want decoded content", buf.String(), err)
+	}
+}
@@ -0,0 +1,167 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+)
+
+// dnsLookupTimeout bounds a shared lookup, which runs apart from the context
+// of the caller that started it
+const dnsLookupTimeout = 30 * time.Second
+
+// Resolver looks up the IP addresses of a host (satisfied by *net.Resolver)
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// dnsEntry is a cached lookup result
+type dnsEntry struct {
+	addrs   []net.IPAddr
+	expires time.Time
+}
+
+// dnsCall is a lookup in progress that other callers wait for
+type dnsCall struct {
+	done  chan struct{}
+	addrs []net.IPAddr
+	err   error
+}
+
+// DNSCache resolves hosts once per TTL and limits how many lookups run at
+// once, so many workers hitting the same hosts don't flood the resolver.
+// Concurrent requests for a host share one lookup; failed lookups are not cached.
+type DNSCache struct {
+	resolver Resolver
+	ttl      time.Duration // 0 = don't cache, only limit concurrency
+	timeout  time.Duration // Limit on each shared lookup
+	slots    chan struct{} // nil = unlimited lookups
+	dialer   *net.Dialer
+	now      func() time.Time
+
+	mu       sync.Mutex
+	entries  map[string]dnsEntry
+	inflight map[string]*dnsCall
+}
+
+// NewDNSCache creates a caching resolver. If resolver is nil,
+// net.DefaultResolver is used; maxLookups <= 0 means no limit.
+func NewDNSCache(resolver Resolver, ttl time.Duration, maxLookups int) *DNSCache {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	r := &DNSCache{
+		resolver: resolver,
+		ttl:      ttl,
+		timeout:  dnsLookupTimeout,
+		dialer:   &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second},
+		now:      time.Now,
+		entries:  make(map[string]dnsEntry),
+		inflight: make(map[string]*dnsCall),
+	}
+	if maxLookups > 0 {
+		r.slots = make(chan struct{}, maxLookups)
+	}
+	return r
+}
+
+// LookupIPAddr returns the addresses of host, from the cache when fresh
+func (r *DNSCache) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.mu.Lock()
+	if entry, ok := r.entries[host]; ok && r.now().Before(entry.expires) {
+		r.mu.Unlock()
+		return entry.addrs, nil
+	}
+	call, ok := r.inflight[host]
+	if !ok {
+		call = &dnsCall{done: make(chan struct{})}
+		r.inflight[host] = call
+		go r.resolve(host, call)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.addrs, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// resolve runs the lookup every caller waiting for host shares. It has its
+// own context, so the caller that started it giving up does not fail the others.
+func (r *DNSCache) resolve(host string, call *dnsCall) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	call.addrs, call.err = r.lookup(ctx, host)
+
+	r.mu.Lock()
+	delete(r.inflight, host)
+	if call.err == nil && r.ttl > 0 {
+		r.entries[host] = dnsEntry{addrs: call.addrs, expires: r.now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	close(call.done)
+}
+
+// lookup queries the resolver once a lookup slot is free
+func (r *DNSCache) lookup(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if r.slots != nil {
+		select {
+		case r.slots <- struct{}{}:
+			defer func() { <-r.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	addrs, err := r.resolver.LookupIPAddr(ctx, host)
+	if err == nil && len(addrs) == 0 {
+		err = fmt.Errorf("no addresses found for %s", host)
+	}
+	return addrs, err
+}
+
+// DialContext connects to addr ("host:port"), resolving the host through
+// the cache and trying each address in turn
+func (r *DNSCache) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	// IP literals need no lookup
+	if net.ParseIP(host) != nil {
+		return r.dialer.DialContext(ctx, network, addr)
+	}
+
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastErr error
+	for _, ip := range addrs {
+		conn, err := r.dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+// SetDNSCache makes connections resolve their host through dns. Call it
+// after SetTLSOptions, which replaces the transport. With HTTP/3 only the
+// fallback transport uses dns; QUIC dials on its own.
+func (c *HTTPClient) SetDNSCache(dns *DNSCache) {
+	c.dns = dns
+	if c.dialControl != nil {
+		dns.dialer.Control = c.dialControl
+	}
+	c.setDialContext(dns.DialContext)
+}
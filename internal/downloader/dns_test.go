@@ -0,0 +1,153 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// stubResolver answers every lookup with loopback, counting lookups per host
+type stubResolver struct {
+	mu      sync.Mutex
+	lookups map[string]int
+	active  int32
+	peak    int32
+	delay   time.Duration
+}
+
+func newStubResolver(delay time.Duration) *stubResolver {
+	return &stubResolver{lookups: make(map[string]int), delay: delay}
+}
+
+func (r *stubResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	n := atomic.AddInt32(&r.active, 1)
+	defer atomic.AddInt32(&r.active, -1)
+	for {
+		peak := atomic.LoadInt32(&r.peak)
+		if n <= peak || atomic.CompareAndSwapInt32(&r.peak, peak, n) {
+			break
+		}
+	}
+	time.Sleep(r.delay)
+
+	r.mu.Lock()
+	r.lookups[host]++
+	r.mu.Unlock()
+	return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+}
+
+func TestDNSCache_ResolvesHostOnce(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+
+	resolver := newStubResolver(10 * time.Millisecond)
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetDNSCache(NewDNSCache(resolver, time.Minute, 0))
+
+	var urls []string
+	for i := 0; i < 20; i++ {
+		urls = append(urls, fmt.Sprintf("http://cdn.stub.test:%s/file%d.js", port, i))
+	}
+	dl := New(client, storage.NewInMemoryStorage("out", "flat"), 8)
+	for _, r := range dl.DownloadAll(context.Background(), urls) {
+		if !r.IsSuccess() {
+			t.Fatalf("%s failed: %v", r.URL, r.Errors)
+		}
+	}
+
+	if got := resolver.lookups["cdn.stub.test"]; got != 1 {
+		t.Errorf("cdn.stub.test resolved %d times, want 1", got)
+	}
+}
+
+func TestDNSCache_LimitsConcurrentLookups(t *testing.T) {
+	resolver := newStubResolver(20 * time.Millisecond)
+	dns := NewDNSCache(resolver, time.Minute, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := dns.LookupIPAddr(context.Background(), fmt.Sprintf("host%d.test", i)); err != nil {
+				t.Errorf("LookupIPAddr() error = %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if resolver.peak > 2 {
+		t.Errorf("%d lookups ran at once, want at most 2", resolver.peak)
+	}
+}
+
+func TestDNSCache_Expires(t *testing.T) {
+	resolver := newStubResolver(0)
+	dns := NewDNSCache(resolver, time.Minute, 0)
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	dns.now = func() time.Time { return clock }
+
+	dns.LookupIPAddr(context.Background(), "a.test")
+	dns.LookupIPAddr(context.Background(), "a.test")
+	clock = clock.Add(2 * time.Minute)
+	dns.LookupIPAddr(context.Background(), "a.test")
+
+	if got := resolver.lookups["a.test"]; got != 2 {
+		t.Errorf("a.test resolved %d times, want 2 (one before and one after the TTL)", got)
+	}
+
+	// Without a TTL every lookup reaches the resolver
+	uncached := NewDNSCache(resolver, 0, 1)
+	uncached.LookupIPAddr(context.Background(), "b.test")
+	uncached.LookupIPAddr(context.Background(), "b.test")
+	if got := resolver.lookups["b.test"]; got != 2 {
+		t.Errorf("b.test resolved %d times without a TTL, want 2", got)
+	}
+}
+
+// slowResolver answers with loopback after delay, or fails when ctx ends first
+type slowResolver struct {
+	delay time.Duration
+}
+
+func (r slowResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	select {
+	case <-time.After(r.delay):
+		return []net.IPAddr{{IP: net.IPv4(127, 0, 0, 1)}}, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestDNSCache_CancelledCallerKeepsSharedLookup(t *testing.T) {
+	dns := NewDNSCache(slowResolver{delay: 100 * time.Millisecond}, time.Minute, 0)
+
+	// The first caller starts the lookup and gives up on it
+	first, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	firstErr := make(chan error, 1)
+	go func() {
+		_, err := dns.LookupIPAddr(first, "shared.test")
+		firstErr <- err
+	}()
+	time.Sleep(5 * time.Millisecond)
+
+	addrs, err := dns.LookupIPAddr(context.Background(), "shared.test")
+	if err != nil || len(addrs) != 1 {
+		t.Fatalf("waiting caller got %v, %v; want the shared lookup's address", addrs, err)
+	}
+	if err := <-firstErr; err != context.DeadlineExceeded {
+		t.Errorf("cancelled caller error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -2,52 +2,232 @@ package downloader
 
 import (
 	"context"
-	"io"
+	"errors"
+	"fmt"
 	"log"
+	"log/slog"
+	"net/http"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/lcalzada-xor/downurl/internal/cache"
 	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/logging"
 	"github.com/lcalzada-xor/downurl/internal/parser"
 	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
 	"github.com/lcalzada-xor/downurl/internal/storage"
 	"github.com/lcalzada-xor/downurl/pkg/models"
 )
 
 // Downloader orchestrates the download process with worker pool
 type Downloader struct {
-	client       *HTTPClient
-	storage      *storage.FileStorage
-	workers      int
-	filter       *filter.ContentFilter
-	skipHeadReq  bool
+	client      *HTTPClient
+	storage     storage.Storage
+	workers     int
+	filter      *filter.ContentFilter
+	scope       ScopeChecker
+	skipHeadReq bool
+	observer    models.Observer
+	onResult    ResultHandler
+	cache       *cache.Cache
+	categorizer *Categorizer
+	hostPacer   *hostPacer
+	hostSlots   *hostSlots
+	normalize   bool // Strip BOMs and re-encode text files to UTF-8 before saving
+	indexNames  bool // Save directory URLs (/docs/, /?q=1) as index.<ext>
+	sentHeaders bool // Record each URL's request headers, redacted, in its result
+	dryRun      bool // Plan each download with a HEAD request instead of running it
+	checkpoint  *Checkpoint
+	hashes      *ExpectedHashes
+
+	removeMismatched bool // Delete files whose digest does not match hashes
+
+	maxTotalBytes int64        // Stop starting downloads once this many bytes are saved (0 = no limit)
+	totalBytes    atomic.Int64 // Bytes saved so far this run
 }
 
-// New creates a new Downloader instance
-func New(client *HTTPClient, storage *storage.FileStorage, workers int) *Downloader {
+// New creates a new Downloader instance writing to the given storage
+func New(client *HTTPClient, storage storage.Storage, workers int) *Downloader {
 	return &Downloader{
 		client:      client,
 		storage:     storage,
 		workers:     workers,
 		skipHeadReq: false,
+		categorizer: defaultCategorizer,
 	}
 }
 
+// SetMaxTotalBytes sets a byte budget for the run: once the files saved add
+// up to max, jobs not yet started are cancelled. Downloads already in
+// progress finish, so the total may end up somewhat above max.
+func (d *Downloader) SetMaxTotalBytes(max int64) {
+	d.maxTotalBytes = max
+}
+
+// BudgetReached reports whether the run stopped starting downloads because
+// the SetMaxTotalBytes budget was used up
+func (d *Downloader) BudgetReached() bool {
+	return d.maxTotalBytes > 0 && d.totalBytes.Load() >= d.maxTotalBytes
+}
+
+// TotalBytes returns the bytes saved so far
+func (d *Downloader) TotalBytes() int64 {
+	return d.totalBytes.Load()
+}
+
+// notStarted returns why a job should not be started, or "" to start it
+func (d *Downloader) notStarted(ctx context.Context) string {
+	switch {
+	case ctx.Err() != nil:
+		return "download cancelled by user"
+	case d.BudgetReached():
+		return fmt.Sprintf("not started: total byte budget of %d bytes reached", d.maxTotalBytes)
+	}
+	return ""
+}
+
+// SetHostDelays spaces requests to the given hosts (subdomains included) at
+// least their delay apart, whatever the number of workers. Other hosts are not delayed.
+func (d *Downloader) SetHostDelays(delays map[string]time.Duration) {
+	if len(delays) == 0 {
+		d.hostPacer = nil
+		return
+	}
+	d.hostPacer = newHostPacer(delays)
+}
+
+// SetPerHostLimit caps the jobs running against any one host at n, so the
+// workers cannot all hit the same target. Workers still cap the total; a
+// worker whose host is busy waits for a slot. Zero or less removes the cap.
+func (d *Downloader) SetPerHostLimit(n int) {
+	if n <= 0 {
+		d.hostSlots = nil
+		return
+	}
+	d.hostSlots = newHostSlots(n)
+}
+
+// hostSlots is a semaphore per host, keyed on parser.HostnameFromURL
+type hostSlots struct {
+	limit int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func newHostSlots(limit int) *hostSlots {
+	return &hostSlots{limit: limit, sems: make(map[string]chan struct{})}
+}
+
+// sem returns the semaphore for host, creating it on first use
+func (s *hostSlots) sem(host string) chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sem, ok := s.sems[host]
+	if !ok {
+		sem = make(chan struct{}, s.limit)
+		s.sems[host] = sem
+	}
+	return sem
+}
+
+// acquire blocks until a request to host may start or ctx is done
+func (s *hostSlots) acquire(ctx context.Context, host string) error {
+	select {
+	case s.sem(host) <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire
+func (s *hostSlots) release(host string) {
+	<-s.sem(host)
+}
+
+// SetReportRequestHeaders makes each result carry the headers its last
+// request was sent with and the authentication type applied. Credential
+// values (Authorization, Cookie values, the auth provider's headers and
+// names like X-Api-Key) are redacted; the names are kept.
+func (d *Downloader) SetReportRequestHeaders(report bool) {
+	d.sentHeaders = report
+}
+
+// SetIndexNames makes URLs whose path is empty or ends in "/" be saved as
+// index.<ext> in their directory, the extension following the Content-Type,
+// instead of under a hash name
+func (d *Downloader) SetIndexNames(indexNames bool) {
+	d.indexNames = indexNames
+}
+
+// SetNormalizeText makes text downloads be saved as UTF-8 without a BOM.
+// The encoding comes from a BOM or the Content-Type charset (UTF-16,
+// ISO-8859-1, windows-1252); binary content is saved untouched.
+func (d *Downloader) SetNormalizeText(normalize bool) {
+	d.normalize = normalize
+}
+
+// SetCategorizer sets how failed downloads are categorized in results
+func (d *Downloader) SetCategorizer(c *Categorizer) {
+	d.categorizer = c
+}
+
 // SetFilter sets the content filter for pre-download filtering
 func (d *Downloader) SetFilter(f *filter.ContentFilter) {
 	d.filter = f
 }
 
+// SetScope sets the scope check applied before any connection is made
+func (d *Downloader) SetScope(s ScopeChecker) {
+	d.scope = s
+}
+
 // SetSkipHeadRequest sets whether to skip HEAD requests
 func (d *Downloader) SetSkipHeadRequest(skip bool) {
 	d.skipHeadReq = skip
 }
 
-// Job represents a download job
+// SetObserver sets the observer notified when each URL starts and finishes.
+// Jobs cancelled before they start produce no events.
+func (d *Downloader) SetObserver(o models.Observer) {
+	d.observer = o
+}
+
+// SetResultHandler sets a function called with every result as it completes,
+// whichever of the Download methods is running. It is called on the goroutine
+// collecting results, before the method's own handling.
+func (d *Downloader) SetResultHandler(handle ResultHandler) {
+	d.onResult = handle
+}
+
+// Job represents a download job. Each job carries its own headers and
+// request, so input entries sharing a URL keep theirs.
 type Job struct {
-	URL   string
-	Index int
+	URL     string
+	Index   int         // Position in the list being downloaded (set when the job is queued)
+	Headers http.Header // Per-entry headers, overriding global ones (nil if none)
+	Request RequestSpec // Per-entry method and body (zero value = GET)
+}
+
+// NewJobs returns a plain GET job for each URL
+func NewJobs(urls []string) []Job {
+	jobs := make([]Job, len(urls))
+	for i, url := range urls {
+		jobs[i] = Job{URL: url}
+	}
+	return jobs
+}
+
+// JobURLs returns the URL of each job
+func JobURLs(jobs []Job) []string {
+	urls := make([]string, len(jobs))
+	for i, job := range jobs {
+		urls[i] = job.URL
+	}
+	return urls
 }
 
 // ProgressCallback is a function that's called when progress is made
@@ -63,61 +243,73 @@ func (d *Downloader) DownloadAll(ctx context.Context, urls []string) []*models.D
 
 // DownloadAllWithProgress downloads all URLs with progress callback
 func (d *Downloader) DownloadAllWithProgress(ctx context.Context, urls []string, callback ProgressCallback) []*models.DownloadResult {
-	jobs := make(chan Job, len(urls))
-	results := make(chan models.DownloadResult, len(urls))
-
-	var completed int32
-	totalJobs := len(urls)
+	return d.collect(ctx, urls, nil, callback)
+}
 
-	// Start worker pool
-	var wg sync.WaitGroup
-	for i := 0; i < d.workers; i++ {
-		wg.Add(1)
-		go d.workerWithCallback(ctx, &wg, jobs, results, &completed, totalJobs, callback)
-	}
+// DownloadAllWithRateLimit downloads all URLs, each one waiting for the
+// rate limiter of its host
+func (d *Downloader) DownloadAllWithRateLimit(ctx context.Context, urls []string, limiter *ratelimit.HostLimiter, callback ProgressCallback) []*models.DownloadResult {
+	return d.collect(ctx, urls, limiter, callback)
+}
 
-	// Send jobs to workers
-	for i, url := range urls {
-		jobs <- Job{URL: url, Index: i}
-	}
-	close(jobs)
+// DownloadJobs downloads jobs that carry their own headers and request (e.g.
+// JSON-lines records), each waiting for the rate limiter of its host unless
+// limiter is nil
+func (d *Downloader) DownloadJobs(ctx context.Context, jobs []Job, limiter *ratelimit.HostLimiter, callback ProgressCallback) []*models.DownloadResult {
+	allResults := make([]*models.DownloadResult, 0, len(jobs))
+	d.DownloadJobsStream(ctx, jobs, limiter, callback, func(result *models.DownloadResult) {
+		allResults = append(allResults, result)
+	})
+	return allResults
+}
 
-	// Wait for all workers to finish and close results channel
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
+// collect runs DownloadStream and gathers every result
+func (d *Downloader) collect(ctx context.Context, urls []string, limiter *ratelimit.HostLimiter, callback ProgressCallback) []*models.DownloadResult {
+	return d.DownloadJobs(ctx, NewJobs(urls), limiter, callback)
+}
 
-	// Collect results
-	allResults := make([]*models.DownloadResult, 0, len(urls))
-	for result := range results {
-		res := result
-		allResults = append(allResults, &res)
-	}
+// ResultHandler receives each download result as soon as it completes
+type ResultHandler func(result *models.DownloadResult)
 
-	return allResults
+// DownloadStream downloads all URLs and hands each result to handle as it completes.
+// Queues are sized by worker count rather than list length and results are not
+// retained, so memory stays bounded however many URLs there are. handle runs on
+// the calling goroutine; a slow handler holds back the workers. A nil limiter
+// means no rate limiting.
+func (d *Downloader) DownloadStream(ctx context.Context, urls []string, limiter *ratelimit.HostLimiter, callback ProgressCallback, handle ResultHandler) {
+	d.DownloadJobsStream(ctx, NewJobs(urls), limiter, callback, handle)
 }
 
-// DownloadAllWithRateLimit downloads all URLs with rate limiting
-func (d *Downloader) DownloadAllWithRateLimit(ctx context.Context, urls []string, limiter *ratelimit.Limiter, callback ProgressCallback) []*models.DownloadResult {
-	jobs := make(chan Job, len(urls))
-	results := make(chan models.DownloadResult, len(urls))
+// DownloadJobsStream is DownloadStream for jobs that carry their own
+// headers and request
+func (d *Downloader) DownloadJobsStream(ctx context.Context, list []Job, limiter *ratelimit.HostLimiter, callback ProgressCallback, handle ResultHandler) {
+	jobs := make(chan Job, d.workers)
+	results := make(chan models.DownloadResult, d.workers)
 
 	var completed int32
-	totalJobs := len(urls)
+	totalJobs := len(list)
 
 	// Start worker pool
 	var wg sync.WaitGroup
 	for i := 0; i < d.workers; i++ {
 		wg.Add(1)
-		go d.workerWithRateLimit(ctx, &wg, jobs, results, limiter, &completed, totalJobs, callback)
+		go d.workerWithCallback(ctx, &wg, jobs, results, &completed, totalJobs, callback)
 	}
 
-	// Send jobs to workers
-	for i, url := range urls {
-		jobs <- Job{URL: url, Index: i}
-	}
-	close(jobs)
+	// Feed jobs as workers free up, each once its host's rate limit allows.
+	// After cancellation workers turn each remaining job into a cancelled
+	// result, so every job gets one.
+	go func() {
+		defer close(jobs)
+		if limiter != nil {
+			feedByHost(ctx, list, limiter, jobs)
+			return
+		}
+		for i, job := range list {
+			job.Index = i
+			jobs <- job
+		}
+	}()
 
 	// Wait for all workers to finish and close results channel
 	go func() {
@@ -125,14 +317,16 @@ func (d *Downloader) DownloadAllWithRateLimit(ctx context.Context, urls []string
 		close(results)
 	}()
 
-	// Collect results
-	allResults := make([]*models.DownloadResult, 0, len(urls))
 	for result := range results {
 		res := result
-		allResults = append(allResults, &res)
+		if d.checkpoint != nil {
+			d.checkpoint.Record(&res)
+		}
+		if d.onResult != nil {
+			d.onResult(&res)
+		}
+		handle(&res)
 	}
-
-	return allResults
 }
 
 // worker processes download jobs from the jobs channel
@@ -140,34 +334,20 @@ func (d *Downloader) worker(ctx context.Context, wg *sync.WaitGroup, jobs <-chan
 	defer wg.Done()
 
 	for job := range jobs {
-		// Check if context was cancelled before processing
-		if ctx.Err() != nil {
+		// Check if context was cancelled or the byte budget used up before processing
+		if reason := d.notStarted(ctx); reason != "" {
 			// Create error result for cancelled job
-			result := models.DownloadResult{
-				URL:        job.URL,
-				Host:       parser.HostnameFromURL(job.URL),
-				Downloaded: []string{},
-				Errors:     []string{"download cancelled by user"},
-				Duration:   0,
-			}
+			result := cancelledResult(job.URL, reason)
 
-			// Try to send result, but don't block if context is done
-			select {
-			case results <- result:
-			case <-ctx.Done():
-				return
-			}
+			// Always sent: results are drained until every worker is done
+			results <- result
 			continue
 		}
 
 		result := d.processJob(ctx, job)
 
-		// Send result with context awareness
-		select {
-		case results <- result:
-		case <-ctx.Done():
-			return
-		}
+		// Send result
+		results <- result
 	}
 }
 
@@ -176,21 +356,11 @@ func (d *Downloader) workerWithCallback(ctx context.Context, wg *sync.WaitGroup,
 	defer wg.Done()
 
 	for job := range jobs {
-		// Check if context was cancelled before processing
-		if ctx.Err() != nil {
-			result := models.DownloadResult{
-				URL:        job.URL,
-				Host:       parser.HostnameFromURL(job.URL),
-				Downloaded: []string{},
-				Errors:     []string{"download cancelled by user"},
-				Duration:   0,
-			}
+		// Check if context was cancelled or the byte budget used up before processing
+		if reason := d.notStarted(ctx); reason != "" {
+			result := cancelledResult(job.URL, reason)
 
-			select {
-			case results <- result:
-			case <-ctx.Done():
-				return
-			}
+			results <- result
 
 			// Update progress
 			count := atomic.AddInt32(completed, 1)
@@ -202,12 +372,8 @@ func (d *Downloader) workerWithCallback(ctx context.Context, wg *sync.WaitGroup,
 
 		result := d.processJob(ctx, job)
 
-		// Send result with context awareness
-		select {
-		case results <- result:
-		case <-ctx.Done():
-			return
-		}
+		// Send result
+		results <- result
 
 		// Update progress
 		count := atomic.AddInt32(completed, 1)
@@ -217,78 +383,70 @@ func (d *Downloader) workerWithCallback(ctx context.Context, wg *sync.WaitGroup,
 	}
 }
 
-// workerWithRateLimit processes download jobs with rate limiting
-func (d *Downloader) workerWithRateLimit(ctx context.Context, wg *sync.WaitGroup, jobs <-chan Job, results chan<- models.DownloadResult, limiter *ratelimit.Limiter, completed *int32, total int, callback ProgressCallback) {
-	defer wg.Done()
-
-	for job := range jobs {
-		// Check if context was cancelled
-		if ctx.Err() != nil {
-			result := models.DownloadResult{
-				URL:        job.URL,
-				Host:       parser.HostnameFromURL(job.URL),
-				Downloaded: []string{},
-				Errors:     []string{"download cancelled by user"},
-				Duration:   0,
-			}
-
-			select {
-			case results <- result:
-			case <-ctx.Done():
-				return
-			}
-
-			count := atomic.AddInt32(completed, 1)
-			if callback != nil {
-				callback(int(count), total)
-			}
-			continue
-		}
-
-		// Wait for rate limiter
-		if err := limiter.Wait(ctx); err != nil {
-			// Rate limiter cancelled by context
-			result := models.DownloadResult{
-				URL:        job.URL,
-				Host:       parser.HostnameFromURL(job.URL),
-				Downloaded: []string{},
-				Errors:     []string{"rate limiter cancelled"},
-				Duration:   0,
-			}
-
-			select {
-			case results <- result:
-			case <-ctx.Done():
-				return
-			}
+// cancelledResult builds the result for a job that was never attempted
+func cancelledResult(url, reason string) models.DownloadResult {
+	result := models.DownloadResult{
+		URL:        url,
+		Host:       parser.HostnameFromURL(url),
+		Downloaded: []string{},
+		Errors:     []string{},
+	}
+	result.AddError(models.DownloadError{
+		Category: models.ErrorCategoryCancelled,
+		Message:  reason,
+	})
+	return result
+}
 
-			count := atomic.AddInt32(completed, 1)
-			if callback != nil {
-				callback(int(count), total)
-			}
-			continue
+// processJob downloads a single URL, notifying the observer around it
+func (d *Downloader) processJob(ctx context.Context, job Job) models.DownloadResult {
+	// Wait for the host to have a free slot before anything is sent
+	if d.hostSlots != nil {
+		host := parser.HostnameFromURL(job.URL)
+		if err := d.hostSlots.acquire(ctx, host); err != nil {
+			return cancelledResult(job.URL, "download cancelled by user")
 		}
+		defer d.hostSlots.release(host)
+	}
 
-		result := d.processJob(ctx, job)
+	var sent *sentHeaders
+	if d.sentHeaders {
+		ctx, sent = recordSentHeaders(ctx)
+	}
 
-		// Send result
-		select {
-		case results <- result:
-		case <-ctx.Done():
-			return
-		}
+	if d.observer != nil {
+		d.observer.OnEvent(models.Event{Type: models.EventStarted, URL: job.URL, Time: time.Now()})
+	}
+	result := d.runJob(ctx, job)
+	if sent != nil {
+		d.addSentHeaders(&result, sent)
+	}
+	if d.observer != nil {
+		d.observer.OnEvent(models.ResultEvent(&result))
+	}
+	return result
+}
 
-		// Update progress
-		count := atomic.AddInt32(completed, 1)
-		if callback != nil {
-			callback(int(count), total)
-		}
+// addSentHeaders copies the redacted request headers and the auth type into result
+func (d *Downloader) addSentHeaders(result *models.DownloadResult, sent *sentHeaders) {
+	provider := d.client.authForURL(result.URL)
+	secret := make(map[string]bool)
+	for _, name := range provider.HeaderNames() {
+		secret[name] = true
 	}
+	result.RequestHeaders = sent.redactedHeaders(secret)
+	result.AuthType = string(provider.GetType())
 }
 
-// processJob downloads a single URL and saves it to disk
-func (d *Downloader) processJob(ctx context.Context, job Job) models.DownloadResult {
+// runJob downloads a single URL and saves it to disk
+func (d *Downloader) runJob(ctx context.Context, job Job) models.DownloadResult {
 	start := time.Now()
+	if job.Headers != nil {
+		ctx = WithHeaders(ctx, job.Headers)
+	}
+	if !job.Request.isGet() {
+		ctx = WithRequest(ctx, job.Request)
+	}
 	result := models.DownloadResult{
 		URL:        job.URL,
 		Host:       parser.HostnameFromURL(job.URL),
@@ -296,32 +454,130 @@ func (d *Downloader) processJob(ctx context.Context, job Job) models.DownloadRes
 		Errors:     []string{},
 	}
 
-	// Pre-download filtering with HEAD request (if filter is set and HEAD not skipped)
-	if d.filter != nil && !d.skipHeadReq {
-		shouldDownload, reason := d.checkShouldDownload(ctx, job.URL)
-		if !shouldDownload {
-			result.Errors = append(result.Errors, "skipped: "+reason)
+	// Scope enforcement happens before any request is sent
+	if d.scope != nil {
+		if ok, reason := d.scope.InScope(ctx, job.URL); !ok {
+			result.AddError(models.DownloadError{
+				Category: models.ErrorCategoryScope,
+				Message:  "out of scope: " + reason,
+			})
+			result.Duration = time.Since(start)
+			logging.Info(fmt.Sprintf("[SCOPE] %s: %s", sanitize.Text(job.URL), sanitize.Text(reason)),
+				slog.String("url", job.URL), slog.String("reason", reason))
+			return result
+		}
+	}
+
+	skip := func(reason string) models.DownloadResult {
+		result.AddError(models.DownloadError{
+			Category: models.ErrorCategorySkipped,
+			Message:  "skipped: " + reason,
+		})
+		result.Duration = time.Since(start)
+		logging.Info(fmt.Sprintf("[SKIP] %s: %s", sanitize.Text(job.URL), sanitize.Text(reason)),
+			slog.String("url", job.URL), slog.String("reason", reason))
+		return result
+	}
+
+	// URL-only rules (extensions) are checked before any request, HEAD included
+	if d.filter != nil {
+		if ok, reason := d.filter.ShouldDownloadURL(job.URL); !ok {
+			return skip(reason)
+		}
+	}
+
+	// Per-host pacing applies to the first request of the job, HEAD included
+	if d.hostPacer != nil {
+		if err := d.hostPacer.Wait(ctx, job.URL); err != nil {
+			result.AddError(d.categorizer.NewDownloadError(err))
 			result.Duration = time.Since(start)
-			log.Printf("[SKIP] %s: %s", job.URL, reason)
 			return result
 		}
 	}
 
+	// A dry run stops at the HEAD request, whether or not a filter is set
+	if d.dryRun {
+		size, contentType, reason, err := d.probe(ctx, job)
+		if err != nil {
+			result.AddError(d.categorizer.NewDownloadError(err))
+			result.Duration = time.Since(start)
+			logging.Error(fmt.Sprintf("[ERROR] Failed to plan %s: %s", sanitize.Text(job.URL), sanitize.Text(err.Error())),
+				slog.String("url", job.URL), slog.String("error", err.Error()))
+			return result
+		}
+		if reason != "" {
+			return skip(reason)
+		}
+		path := d.plannedPath(job.URL, result.Host, parser.FilenameFromURL(job.URL), contentType)
+		result.Downloaded = append(result.Downloaded, path)
+		result.ContentType = contentType
+		result.Planned = true
+		result.PlannedSize = size
+		result.Duration = time.Since(start)
+		logging.Info(fmt.Sprintf("[PLAN] %s -> %s", sanitize.Text(job.URL), sanitize.Text(path)),
+			slog.String("url", job.URL), slog.String("path", path), slog.Int64("bytes", size))
+		return result
+	}
+
+	// Pre-download filtering with HEAD request (if filter is set and HEAD not skipped).
+	// A HEAD says nothing about what a POST would return, so only GETs are checked.
+	if d.filter != nil && !d.skipHeadReq && job.Request.isGet() {
+		if ok, reason := d.checkShouldDownload(ctx, job.URL); !ok {
+			return skip(reason)
+		}
+	}
+
 	// Generate filename
 	filename := parser.FilenameFromURL(job.URL)
 
 	// Download and save using streaming (no memory buffering)
-	filepath, bytesWritten, err := d.downloadAndSaveStream(ctx, job.URL, result.Host, filename)
+	filepath, bytesWritten, contentType, err := d.downloadAndSaveStream(ctx, job.URL, result.Host, filename)
 	if err != nil {
-		result.Errors = append(result.Errors, err.Error())
+		result.AddError(d.categorizer.NewDownloadError(err))
 		result.Duration = time.Since(start)
-		log.Printf("[ERROR] Failed to download %s: %v", job.URL, err)
+		// A file that failed verification but was kept is still listed
+		var mismatch *HashMismatchError
+		if errors.As(err, &mismatch) && !mismatch.Removed && mismatch.Path != "" {
+			result.Downloaded = append(result.Downloaded, mismatch.Path)
+			result.Bytes = bytesWritten
+			d.totalBytes.Add(bytesWritten)
+		}
+		if isSkipped(err) {
+			logging.Info(fmt.Sprintf("[SKIP] %s: %s", sanitize.Text(job.URL), sanitize.Text(err.Error())),
+				slog.String("url", job.URL), slog.String("reason", err.Error()))
+		} else {
+			logging.Error(fmt.Sprintf("[ERROR] Failed to download %s: %s", sanitize.Text(job.URL), sanitize.Text(err.Error())),
+				slog.String("url", job.URL), slog.String("error", err.Error()), slog.Int64("duration_ms", result.Duration.Milliseconds()))
+		}
 		return result
 	}
 
+	if recorder, ok := d.storage.(storage.MetadataRecorder); ok {
+		if err := recorder.RecordMetadata(filepath, job.URL, contentType); err != nil {
+			log.Printf("[WARN] Failed to record metadata for %s: %s", sanitize.Text(job.URL), sanitize.Text(err.Error()))
+		}
+	}
+
 	result.Downloaded = append(result.Downloaded, filepath)
+	result.Bytes = bytesWritten
 	result.Duration = time.Since(start)
-	log.Printf("[OK] Downloaded %s -> %s (%d bytes, %v)", job.URL, filepath, bytesWritten, result.Duration)
+	result.ContentType = contentType
+	_, result.Verified = d.expectedHash(job.URL)
+	d.totalBytes.Add(bytesWritten)
+	result.Partial = job.Request.isGet() && d.client.isPartial(bytesWritten)
+	fields := []slog.Attr{
+		slog.String("url", job.URL),
+		slog.String("path", filepath),
+		slog.Int64("bytes", bytesWritten),
+		slog.Int64("duration_ms", result.Duration.Milliseconds()),
+	}
+	if result.Partial {
+		logging.Info(fmt.Sprintf("[OK] Downloaded %s -> %s (first %d bytes, %v)", sanitize.Text(job.URL), sanitize.Text(filepath), bytesWritten, result.Duration),
+			append(fields, slog.Bool("partial", true))...)
+	} else {
+		logging.Info(fmt.Sprintf("[OK] Downloaded %s -> %s (%d bytes, %v)", sanitize.Text(job.URL), sanitize.Text(filepath), bytesWritten, result.Duration),
+			fields...)
+	}
 
 	return result
 }
@@ -330,17 +586,28 @@ func (d *Downloader) processJob(ctx context.Context, job Job) models.DownloadRes
 func (d *Downloader) checkShouldDownload(ctx context.Context, url string) (bool, string) {
 	resp, err := d.client.Head(ctx, url)
 	if err != nil {
-		// If HEAD fails, we still want to try downloading (some servers don't support HEAD)
-		log.Printf("[WARN] HEAD request failed for %s: %v, will attempt download", url, err)
+		// HEAD failed (even after retries): no decision can be made, so let the GET decide
+		log.Printf("[WARN] HEAD request failed for %s: %s, proceeding with download", sanitize.Text(url), sanitize.Text(err.Error()))
 		return true, ""
 	}
 	defer resp.Body.Close()
 
-	// Check HTTP status
+	// Some servers don't support HEAD at all
+	if resp.StatusCode == http.StatusMethodNotAllowed {
+		log.Printf("[WARN] HEAD not allowed for %s, proceeding with download", sanitize.Text(url))
+		return true, ""
+	}
+
+	// HEAD succeeded: its status is part of the skip decision
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		return false, "HTTP status: " + resp.Status
 	}
 
+	// Check header rules
+	if ok, reason := d.filter.ShouldDownloadHeaders(resp.Header); !ok {
+		return false, reason
+	}
+
 	// Get content type and length
 	contentType := resp.Header.Get("Content-Type")
 	contentLength := resp.ContentLength
@@ -349,39 +616,102 @@ func (d *Downloader) checkShouldDownload(ctx context.Context, url string) (bool,
 	return d.filter.ShouldDownload(url, contentType, contentLength)
 }
 
-// downloadAndSaveStream downloads a URL and saves it directly to disk using streaming
-func (d *Downloader) downloadAndSaveStream(ctx context.Context, url, host, filename string) (string, int64, error) {
-	// Create a pipe to connect download and storage
-	pr, pw := io.Pipe()
+// downloadAndSaveStream downloads a URL and saves it directly to disk using
+// streaming. It also returns the Content-Type the server declared.
+func (d *Downloader) downloadAndSaveStream(ctx context.Context, url, host, filename string) (string, int64, string, error) {
+	// Re-check header rules and a declared size against the actual response
+	// (HEAD may be skipped or unsupported)
+	var contentType string
+	check := func(resp *http.Response) error {
+		contentType = resp.Header.Get("Content-Type")
+		if d.filter != nil {
+			if ok, reason := d.filter.ShouldDownloadHeaders(resp.Header); !ok {
+				return &SkipError{Reason: reason}
+			}
+			if resp.ContentLength >= 0 {
+				if ok, reason := d.filter.CheckSize(resp.ContentLength); !ok {
+					return &SkipError{Reason: reason}
+				}
+			}
+		}
+		return nil
+	}
 
-	var downloadErr error
-	var bytesDownloaded int64
+	// Only GET responses are cacheable
+	var path string
+	var bytes int64
+	var err error
+	if d.cache != nil && isGetRequest(ctx) {
+		path, bytes, err = d.downloadWithCache(ctx, url, host, filename, check)
+	} else {
+		path, bytes, err = d.downloadToStorage(ctx, url, host, filename, check)
+	}
+	return path, bytes, contentType, err
+}
 
-	// Start downloading in a goroutine
-	go func() {
-		defer pw.Close()
-		bytes, err := d.client.DownloadToWriter(ctx, url, pw)
-		bytesDownloaded = bytes
-		downloadErr = err
-		if err != nil {
-			pw.CloseWithError(err)
-		}
-	}()
+// newSink returns the sink a download of url is saved through and the
+// response check to download with. With a minimum size filter, saving waits
+// until the body is known to be big enough.
+func (d *Downloader) newSink(url, host, filename string, check ResponseCheck) (saveSink, ResponseCheck) {
+	var sink saveSink
+	if d.indexNames && isDirectoryURL(url) {
+		// The name waits for the response's Content-Type
+		index := &indexSink{open: func(name string) saveSink {
+			return d.newStorageSink(url, host, name)
+		}}
+		sink, check = index, index.checkResponse(check)
+	} else {
+		sink = d.newStorageSink(url, host, filename)
+	}
+	if d.normalize {
+		// The normalizer needs the response's Content-Type
+		n := &textNormalizer{sink: sink, filename: filename}
+		sink, check = n, n.checkResponse(check)
+	}
+	// The digest is of the body as downloaded, before normalization
+	if want, ok := d.expectedHash(url); ok {
+		sink = &hashSink{sink: sink, want: want, hash: want.newHash(), remove: d.removeMismatched}
+	}
+	return sink, check
+}
+
+// newStorageSink returns the sink saving a download of url into storage
+func (d *Downloader) newStorageSink(url, host, filename string) saveSink {
+	open := func() *streamSink {
+		return newStreamSink(d.storage, host, parser.PathFromURL(url), filename)
+	}
 
-	// Extract URL path for storage strategy
-	urlPath := parser.PathFromURL(url)
+	var min int64
+	if d.filter != nil {
+		min = d.filter.MinBodySize()
+	}
+	if min <= 0 {
+		return open()
+	}
+	return &sizeGate{
+		min:  min,
+		open: open,
+		reject: func(size int64) error {
+			_, reason := d.filter.CheckSize(size)
+			return &SkipError{Reason: reason}
+		},
+	}
+}
 
-	// Save from the pipe reader
-	filepath, bytesWritten, err := d.storage.SaveFileFromReader(host, urlPath, filename, pr)
+// downloadToStorage streams a URL into storage
+func (d *Downloader) downloadToStorage(ctx context.Context, url, host, filename string, check ResponseCheck) (string, int64, error) {
+	// Stream into storage; the sink restarts the save if the client retries mid-body
+	sink, check := d.newSink(url, host, filename, check)
+	bytesDownloaded, downloadErr := d.client.DownloadToWriterWithCheck(ctx, url, sink, check)
+	saved := sink.Close(downloadErr)
 
-	// Check if download had an error
 	if downloadErr != nil {
 		return "", bytesDownloaded, downloadErr
 	}
 
-	if err != nil {
-		return "", bytesWritten, err
+	if saved.err != nil {
+		return "", saved.bytes, saved.err
 	}
 
-	return filepath, bytesWritten, nil
+	return saved.path, saved.bytes, nil
 }
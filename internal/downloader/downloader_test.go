@@ -0,0 +1,283 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_InMemoryStorage(t *testing.T) {
+	bodies := map[string]string{
+		"/js/app.js":    "console.log('app');",
+		"/css/site.css": "body { margin: 0; }",
+		"/other/app.js": "console.log('other');",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := bodies[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	mem := storage.NewInMemoryStorage("out", "flat")
+	dl := New(NewHTTPClient(5*time.Second, 0), mem, 1)
+
+	urls := []string{
+		server.URL + "/js/app.js",
+		server.URL + "/css/site.css",
+		server.URL + "/other/app.js",
+		server.URL + "/missing.js",
+	}
+	results := dl.DownloadAll(context.Background(), urls)
+
+	saved := 0
+	for _, r := range results {
+		if strings.HasSuffix(r.URL, "/missing.js") {
+			if r.IsSuccess() {
+				t.Errorf("%s should fail", r.URL)
+			}
+			continue
+		}
+		if !r.IsSuccess() {
+			t.Errorf("%s failed: %v", r.URL, r.Errors)
+			continue
+		}
+
+		path := r.Downloaded[0]
+		if !strings.HasPrefix(path, "out"+string(filepath.Separator)) {
+			t.Errorf("path %s is not under the storage base dir", path)
+		}
+
+		data, ok := mem.ReadFile(path)
+		if !ok {
+			t.Errorf("ReadFile(%s) not found", path)
+			continue
+		}
+		want := bodies[strings.TrimPrefix(r.URL, server.URL)]
+		if string(data) != want {
+			t.Errorf("ReadFile(%s) = %q, want %q", path, data, want)
+		}
+		saved++
+	}
+
+	if saved != 3 {
+		t.Errorf("saved %d files, want 3", saved)
+	}
+
+	// Both app.js downloads land in the flat host dir, so one is renamed
+	paths := mem.Paths()
+	if len(paths) != 3 {
+		t.Fatalf("Paths() = %v, want 3 entries", paths)
+	}
+	var renamed bool
+	for _, p := range paths {
+		if filepath.Base(p) == "app_1.js" {
+			renamed = true
+		}
+	}
+	if !renamed {
+		t.Errorf("Paths() = %v, want a collision-renamed app_1.js", paths)
+	}
+}
+
+func TestDownloader_ResultBytes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.js" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(strings.Repeat("x", len(r.URL.Path)*100)))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 2)
+	results := dl.DownloadAll(context.Background(), []string{
+		server.URL + "/a.js",
+		server.URL + "/bb.js",
+		server.URL + "/missing.js",
+	})
+
+	want := map[string]int64{"/a.js": 500, "/bb.js": 600, "/missing.js": 0}
+	for _, r := range results {
+		path := strings.TrimPrefix(r.URL, server.URL)
+		if r.Bytes != want[path] {
+			t.Errorf("%s: Bytes = %d, want %d", path, r.Bytes, want[path])
+		}
+	}
+
+	plain := make([]models.DownloadResult, len(results))
+	for i, r := range results {
+		plain[i] = *r
+	}
+	if summary := models.Summarize(plain); summary.Bytes != 1100 {
+		t.Errorf("Summarize().Bytes = %d, want 1100", summary.Bytes)
+	}
+}
+
+func TestDownloader_RetriesInterruptedDownload(t *testing.T) {
+	// The first response is cut off mid-body; the retry must replace, not extend, the partial file
+	const body = "console.log('complete file');"
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			dropMidBody(t, w, body, 10)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	dl := New(NewHTTPClient(5*time.Second, 2), storage.NewFileStorage(dir, "flat"), 1)
+
+	results := dl.DownloadAll(context.Background(), []string{server.URL + "/app.js"})
+	if len(results) != 1 || !results[0].IsSuccess() {
+		t.Fatalf("DownloadAll() = %+v, want one successful result", results)
+	}
+	if got := atomic.LoadInt32(&requests); got != 2 {
+		t.Errorf("requests = %d, want 2", got)
+	}
+
+	data, err := os.ReadFile(results[0].Downloaded[0])
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("saved content = %q, want %q", data, body)
+	}
+
+	// No leftover partial or collision-renamed copy
+	var files []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if len(files) != 1 {
+		t.Errorf("files on disk = %v, want exactly 1", files)
+	}
+}
+
+// rejectAll is a scope that refuses every URL, so jobs finish without any network I/O
+type rejectAll struct{}
+
+func (rejectAll) InScope(ctx context.Context, rawURL string) (bool, string) {
+	return false, "test scope rejects everything"
+}
+
+func TestDownloader_DownloadStreamBoundedMemory(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	const total = 200000
+	const warmup = 20000
+	urls := make([]string, total)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("https://example.com/assets/file-%d.js", i)
+	}
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 4)
+	dl.SetScope(rejectAll{})
+
+	heapAlloc := func() uint64 {
+		runtime.GC()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		return m.HeapAlloc
+	}
+
+	var summary models.RunSummary
+	var baseline uint64
+	dl.DownloadStream(context.Background(), urls, nil, nil, func(r *models.DownloadResult) {
+		summary.Add(r)
+		if summary.Total == warmup {
+			baseline = heapAlloc()
+		}
+	})
+	after := heapAlloc()
+
+	if summary.Total != total || summary.Failed != total {
+		t.Fatalf("summary = %+v, want %d failed results", summary, total)
+	}
+	if got := summary.ByCategory[models.ErrorCategoryScope]; got != total {
+		t.Errorf("ByCategory[scope] = %d, want %d", got, total)
+	}
+
+	// Retaining 180k results would take tens of MB; streaming should stay flat
+	const limit = 4 << 20
+	if after > baseline && after-baseline > limit {
+		t.Errorf("heap grew by %d bytes over %d results, want under %d", after-baseline, total-warmup, limit)
+	}
+}
+
+func TestDownloader_DownloadStreamCancelledCountsEveryURL(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	const total = 50
+	for _, limiter := range []*ratelimit.HostLimiter{nil, ratelimit.NewHostLimiter(ratelimit.NewLimiter(1000, time.Second), nil)} {
+		// Each run gets its own context and server, so the handler only
+		// ever sees the cancel func set before it started
+		ctx, cancel := context.WithCancel(context.Background())
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// The first request cancels the run while it is in flight
+			cancel()
+			w.Write([]byte("data"))
+		}))
+		urls := make([]string, total)
+		for i := range urls {
+			urls[i] = fmt.Sprintf("%s/file-%d.js", server.URL, i)
+		}
+
+		dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 2)
+		var summary models.RunSummary
+		dl.DownloadStream(ctx, urls, limiter, nil, func(r *models.DownloadResult) {
+			summary.Add(r)
+		})
+		cancel()
+		server.Close()
+
+		if summary.Total != total {
+			t.Errorf("limiter=%v: %d results, want one per URL (%d)", limiter != nil, summary.Total, total)
+		}
+		if summary.ByCategory[models.ErrorCategoryCancelled] == 0 {
+			t.Errorf("limiter=%v: no cancelled results in %+v", limiter != nil, summary.ByCategory)
+		}
+	}
+}
+
+func TestDownloader_DeclaredContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+		w.Write([]byte("console.log('app');"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 1)
+	results := dl.DownloadAll(context.Background(), []string{server.URL + "/app.js"})
+
+	if len(results) != 1 || !results[0].IsSuccess() {
+		t.Fatalf("DownloadAll() = %+v, want one success", results)
+	}
+	if got := results[0].ContentType; got != "application/javascript; charset=utf-8" {
+		t.Errorf("ContentType = %q, want the server's header", got)
+	}
+}
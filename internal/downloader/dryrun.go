@@ -0,0 +1,64 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+
+	"github.com/lcalzada-xor/downurl/internal/parser"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// SetDryRun turns downloads into plans: each GET URL gets a HEAD request,
+// goes through the content filter, and its result is marked Planned with the
+// path it would be saved at and the size the server declared. Nothing is
+// written to storage.
+func (d *Downloader) SetDryRun(dryRun bool) {
+	d.dryRun = dryRun
+}
+
+// probe sends the HEAD request a dry run stands on and applies the filter to
+// it. It returns the declared size (-1 if unknown), the Content-Type, and a
+// skip reason if the filter rejects the file. A HEAD says nothing about what
+// a POST would return, so other methods are planned without one.
+func (d *Downloader) probe(ctx context.Context, job Job) (int64, string, string, error) {
+	if !job.Request.isGet() {
+		return -1, "", "", nil
+	}
+
+	resp, err := d.client.Head(ctx, job.URL)
+	if err != nil {
+		return 0, "", "", err
+	}
+	defer resp.Body.Close()
+
+	// Without HEAD support only the GET would tell
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		return -1, "", "", nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, "", "", &HTTPError{StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if d.filter != nil {
+		if ok, reason := d.filter.ShouldDownloadHeaders(resp.Header); !ok {
+			return 0, "", reason, nil
+		}
+		if ok, reason := d.filter.ShouldDownload(job.URL, contentType, resp.ContentLength); !ok {
+			return 0, "", reason, nil
+		}
+	}
+	return resp.ContentLength, contentType, "", nil
+}
+
+// plannedPath returns where a download of url would be saved
+func (d *Downloader) plannedPath(url, host, filename, contentType string) string {
+	if d.indexNames && isDirectoryURL(url) {
+		filename = indexFilename(contentType)
+	}
+	if planner, ok := d.storage.(storage.Planner); ok {
+		return planner.PlanPath(host, parser.PathFromURL(url), filename)
+	}
+	return filepath.Join(d.storage.GetBaseDir(), host, filename)
+}
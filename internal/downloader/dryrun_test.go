@@ -0,0 +1,77 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_DryRun(t *testing.T) {
+	var gets atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			gets.Add(1)
+		}
+		switch r.URL.Path {
+		case "/js/app.js":
+			w.Header().Set("Content-Type", "application/javascript")
+			w.Header().Set("Content-Length", "2048")
+		case "/style.css":
+			w.Header().Set("Content-Type", "text/css")
+		case "/nohead.js":
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	outDir := filepath.Join(t.TempDir(), "out")
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(outDir, "path"), 2)
+	dl.SetFilter(filter.NewContentFilter(filter.FilterConfig{ExcludeType: "text/css"}))
+	dl.SetDryRun(true)
+
+	results := dl.DownloadAll(context.Background(), []string{
+		server.URL + "/js/app.js",
+		server.URL + "/style.css",
+		server.URL + "/nohead.js",
+		server.URL + "/missing.js",
+	})
+	byURL := make(map[string]*models.DownloadResult)
+	for _, r := range results {
+		byURL[r.URL] = r
+	}
+
+	app := byURL[server.URL+"/js/app.js"]
+	if !app.Planned || app.PlannedSize != 2048 || app.Bytes != 0 {
+		t.Errorf("app.js: planned=%v size=%d bytes=%d, want planned 2048 bytes, none written", app.Planned, app.PlannedSize, app.Bytes)
+	}
+	if len(app.Downloaded) != 1 || filepath.Base(filepath.Dir(app.Downloaded[0])) != "js" {
+		t.Errorf("app.js planned at %v, want a path mirroring /js/", app.Downloaded)
+	}
+	if r := byURL[server.URL+"/nohead.js"]; !r.Planned || r.PlannedSize != -1 {
+		t.Errorf("nohead.js: planned=%v size=%d, want planned with unknown size", r.Planned, r.PlannedSize)
+	}
+	if r := byURL[server.URL+"/style.css"]; r.Planned || len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategorySkipped {
+		t.Errorf("style.css = %+v, want skipped by the filter", r)
+	}
+	if r := byURL[server.URL+"/missing.js"]; r.Planned || len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategoryHTTP4xx {
+		t.Errorf("missing.js = %+v, want an HTTP 4xx failure", r)
+	}
+
+	if n := gets.Load(); n != 0 {
+		t.Errorf("%d non-HEAD requests sent, want none", n)
+	}
+	if _, err := os.Stat(outDir); !os.IsNotExist(err) {
+		t.Errorf("output directory exists after a dry run (stat error %v)", err)
+	}
+}
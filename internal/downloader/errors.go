@@ -0,0 +1,319 @@
+package downloader
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// NetworkError represents a transport failure (DNS, refused, reset, ...)
+type NetworkError struct {
+	Err error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("request failed: %v", e.Err)
+}
+
+func (e *NetworkError) Unwrap() error {
+	return e.Err
+}
+
+// TimeoutError represents a request that timed out
+type TimeoutError struct {
+	Err error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("request timeout: %v", e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error {
+	return e.Err
+}
+
+// TLSError represents a TLS handshake or certificate verification failure
+type TLSError struct {
+	Err error
+}
+
+func (e *TLSError) Error() string {
+	return fmt.Sprintf("TLS error: %v", e.Err)
+}
+
+func (e *TLSError) Unwrap() error {
+	return e.Err
+}
+
+// CancelledError represents a request aborted by context cancellation
+type CancelledError struct {
+	Err error
+}
+
+func (e *CancelledError) Error() string {
+	return fmt.Sprintf("download cancelled: %v", e.Err)
+}
+
+func (e *CancelledError) Unwrap() error {
+	return e.Err
+}
+
+// InterruptedError represents a response body cut off mid-transfer (connection reset, unexpected EOF)
+type InterruptedError struct {
+	Written int64
+	Err     error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("download interrupted after %d bytes: %v", e.Written, e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// WriteError represents a failure writing downloaded data to its destination
+type WriteError struct {
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write response: %v", e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError represents a compressed response body that could not be decoded
+type DecodeError struct {
+	Encoding string
+	Err      error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode %s response: %v", e.Encoding, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// HashMismatchError represents a download whose digest differs from the one
+// expected for it (see SetExpectedHashes)
+type HashMismatchError struct {
+	Algorithm string
+	Expected  string
+	Actual    string
+	Path      string // Where the file was saved
+	Removed   bool   // The file was deleted
+}
+
+func (e *HashMismatchError) Error() string {
+	msg := fmt.Sprintf("%s mismatch: expected %s, got %s", e.Algorithm, e.Expected, e.Actual)
+	if e.Removed {
+		msg += " (file removed)"
+	}
+	return msg
+}
+
+// SkipError represents a response rejected by a filter before its body was saved
+type SkipError struct {
+	Reason string
+}
+
+func (e *SkipError) Error() string {
+	return "skipped: " + e.Reason
+}
+
+// wrapRequestError converts an error returned by http.Client.Do into a typed error
+func wrapRequestError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return &CancelledError{Err: err}
+	case isTimeout(err):
+		return &TimeoutError{Err: err}
+	case isTLSFailure(err):
+		return &TLSError{Err: err}
+	default:
+		return &NetworkError{Err: err}
+	}
+}
+
+// isTimeout checks if the error was caused by a timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isTLSFailure checks if the error was caused by TLS negotiation or verification
+func isTLSFailure(err error) bool {
+	var verifyErr *tls.CertificateVerificationError
+	var headerErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	return errors.As(err, &verifyErr) ||
+		errors.As(err, &headerErr) ||
+		errors.As(err, &alertErr) ||
+		errors.As(err, &authorityErr) ||
+		errors.As(err, &hostnameErr) ||
+		errors.As(err, &invalidErr)
+}
+
+// CategoryRule assigns a failure category to the errors it matches
+type CategoryRule struct {
+	Category models.ErrorCategory
+	Match    func(err error) bool
+}
+
+// Categorizer assigns failure categories from the structured error types,
+// trying its rules in order; the first match wins
+type Categorizer struct {
+	rules []CategoryRule
+}
+
+// defaultCategoryRules classify the errors returned by HTTPClient and Downloader.
+// Skips and HTTP statuses come first: they are decisions, not transport failures.
+var defaultCategoryRules = []CategoryRule{
+	{models.ErrorCategorySkipped, func(err error) bool {
+		var skipErr *SkipError
+		return errors.As(err, &skipErr)
+	}},
+	{models.ErrorCategoryChecksum, func(err error) bool {
+		var mismatch *HashMismatchError
+		return errors.As(err, &mismatch)
+	}},
+	{models.ErrorCategoryHTTP4xx, statusBetween(400, 499)},
+	{models.ErrorCategoryHTTP5xx, statusBetween(500, 599)},
+	{models.ErrorCategoryHTTP, statusBetween(0, 999)},
+	{models.ErrorCategoryCancelled, func(err error) bool {
+		var cancelledErr *CancelledError
+		return errors.As(err, &cancelledErr) || errors.Is(err, context.Canceled)
+	}},
+	{models.ErrorCategoryTimeout, func(err error) bool {
+		var timeoutErr *TimeoutError
+		return errors.As(err, &timeoutErr) || errors.Is(err, context.DeadlineExceeded)
+	}},
+	{models.ErrorCategoryTLS, func(err error) bool {
+		var tlsErr *TLSError
+		return errors.As(err, &tlsErr)
+	}},
+	{models.ErrorCategoryDNS, func(err error) bool {
+		var dnsErr *net.DNSError
+		return errors.As(err, &dnsErr)
+	}},
+	{models.ErrorCategoryRefused, func(err error) bool {
+		return errors.Is(err, syscall.ECONNREFUSED)
+	}},
+	{models.ErrorCategoryNetwork, func(err error) bool {
+		var networkErr *NetworkError
+		return errors.As(err, &networkErr)
+	}},
+}
+
+// defaultCategorizer is used by Categorize and NewDownloadError
+var defaultCategorizer = NewCategorizer()
+
+// NewCategorizer returns a categorizer that tries rules before the default ones
+func NewCategorizer(rules ...CategoryRule) *Categorizer {
+	all := make([]CategoryRule, 0, len(rules)+len(defaultCategoryRules))
+	all = append(all, rules...)
+	all = append(all, defaultCategoryRules...)
+	return &Categorizer{rules: all}
+}
+
+// Categorize returns the failure category of a download error
+func (c *Categorizer) Categorize(err error) models.ErrorCategory {
+	if err == nil {
+		return ""
+	}
+	for _, rule := range c.rules {
+		if rule.Match(err) {
+			return rule.Category
+		}
+	}
+	return models.ErrorCategoryOther
+}
+
+// NewDownloadError builds the structured form of a download error
+func (c *Categorizer) NewDownloadError(err error) models.DownloadError {
+	de := models.DownloadError{
+		Category: c.Categorize(err),
+		Message:  err.Error(),
+	}
+
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		de.StatusCode = httpErr.StatusCode
+	}
+
+	return de
+}
+
+// Categorize returns the failure category of a download error using the default rules
+func Categorize(err error) models.ErrorCategory {
+	return defaultCategorizer.Categorize(err)
+}
+
+// NewDownloadError builds the structured form of a download error using the default rules
+func NewDownloadError(err error) models.DownloadError {
+	return defaultCategorizer.NewDownloadError(err)
+}
+
+// ParseCategoryRules parses a comma-separated list of "status=category"
+// pairs, where status is a code (429) or a class (4xx), into rules that
+// override the default categories of HTTP errors (e.g. "401=auth,403=auth").
+// Codes are tried before classes.
+func ParseCategoryRules(s string) ([]CategoryRule, error) {
+	var codes, classes []CategoryRule
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		status, category, ok := strings.Cut(item, "=")
+		status = strings.ToLower(strings.TrimSpace(status))
+		category = strings.TrimSpace(category)
+		if !ok || category == "" {
+			return nil, fmt.Errorf("invalid error category mapping: %q (expected 'status=category')", item)
+		}
+
+		rule := CategoryRule{Category: models.ErrorCategory(category)}
+		if len(status) == 3 && strings.HasSuffix(status, "xx") && status[0] >= '1' && status[0] <= '5' {
+			low := int(status[0]-'0') * 100
+			rule.Match = statusBetween(low, low+99)
+			classes = append(classes, rule)
+			continue
+		}
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid status in error category mapping: %q (expected a code like 429 or a class like 4xx)", item)
+		}
+		rule.Match = statusBetween(code, code)
+		codes = append(codes, rule)
+	}
+	return append(codes, classes...), nil
+}
+
+// statusBetween matches HTTP errors with a status code in [low, high]
+func statusBetween(low, high int) func(error) bool {
+	return func(err error) bool {
+		var httpErr *HTTPError
+		return errors.As(err, &httpErr) && httpErr.StatusCode >= low && httpErr.StatusCode <= high
+	}
+}
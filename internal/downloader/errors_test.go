@@ -0,0 +1,217 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestCategorize_HTTPStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	_, err := client.DownloadToWriter(context.Background(), server.URL, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected error for 404")
+	}
+
+	de := NewDownloadError(err)
+	if de.Category != models.ErrorCategoryHTTP4xx {
+		t.Errorf("Category = %q, want %q", de.Category, models.ErrorCategoryHTTP4xx)
+	}
+	if de.StatusCode != http.StatusNotFound {
+		t.Errorf("StatusCode = %d, want %d", de.StatusCode, http.StatusNotFound)
+	}
+}
+
+func TestCategorize_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(100*time.Millisecond, 0)
+	_, err := client.DownloadToWriter(context.Background(), server.URL, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected timeout error")
+	}
+
+	if got := Categorize(err); got != models.ErrorCategoryTimeout {
+		t.Errorf("Categorize() = %q, want %q (err: %v)", got, models.ErrorCategoryTimeout, err)
+	}
+}
+
+func TestCategorize_TLS(t *testing.T) {
+	// Self-signed certificate is not trusted by the default client
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	_, err := client.DownloadToWriter(context.Background(), server.URL, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected TLS error")
+	}
+
+	if got := Categorize(err); got != models.ErrorCategoryTLS {
+		t.Errorf("Categorize() = %q, want %q (err: %v)", got, models.ErrorCategoryTLS, err)
+	}
+}
+
+func TestCategorize_Refused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close() // Nothing is listening anymore
+
+	client := NewHTTPClient(5*time.Second, 0)
+	_, err := client.DownloadToWriter(context.Background(), url, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected connection error")
+	}
+
+	if got := Categorize(err); got != models.ErrorCategoryRefused {
+		t.Errorf("Categorize() = %q, want %q (err: %v)", got, models.ErrorCategoryRefused, err)
+	}
+}
+
+func TestCategorize_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(500 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	client := NewHTTPClient(5*time.Second, 3)
+	start := time.Now()
+	_, err := client.DownloadToWriter(ctx, server.URL, &bytes.Buffer{})
+	if err == nil {
+		t.Fatal("DownloadToWriter() expected cancellation error")
+	}
+
+	if got := Categorize(err); got != models.ErrorCategoryCancelled {
+		t.Errorf("Categorize() = %q, want %q (err: %v)", got, models.ErrorCategoryCancelled, err)
+	}
+
+	// Cancellation must not be retried
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled download took %v, expected no retries", elapsed)
+	}
+}
+
+func TestDownloader_ResultFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	fileStorage := storage.NewFileStorage(t.TempDir(), "flat")
+	dl := New(NewHTTPClient(5*time.Second, 0), fileStorage, 1)
+	results := dl.DownloadAll(context.Background(), []string{server.URL + "/secret.js"})
+	if len(results) != 1 {
+		t.Fatalf("DownloadAll() returned %d results, want 1", len(results))
+	}
+
+	result := results[0]
+	if len(result.Failures) != len(result.Errors) {
+		t.Fatalf("Failures (%d) out of sync with Errors (%d)", len(result.Failures), len(result.Errors))
+	}
+	if len(result.Failures) == 0 || result.Failures[0].Category != models.ErrorCategoryHTTP4xx {
+		t.Errorf("Failures = %+v, want one %q failure", result.Failures, models.ErrorCategoryHTTP4xx)
+	}
+}
+
+func TestCategorize_Breakdown(t *testing.T) {
+	refused := &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	dnsErr := &net.DNSError{Err: "no such host", Name: "missing.invalid", IsNotFound: true}
+
+	failures := []struct {
+		err  error
+		want models.ErrorCategory
+	}{
+		{&HTTPError{StatusCode: 404, Status: "404 Not Found"}, models.ErrorCategoryHTTP4xx},
+		{&HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, models.ErrorCategoryHTTP4xx},
+		{&HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, models.ErrorCategoryHTTP5xx},
+		{&HTTPError{StatusCode: 304, Status: "304 Not Modified"}, models.ErrorCategoryHTTP},
+		{fmt.Errorf("failed after 3 attempts: %w", wrapRequestError(dnsErr)), models.ErrorCategoryDNS},
+		{wrapRequestError(refused), models.ErrorCategoryRefused},
+		{wrapRequestError(errors.New("connection reset by peer")), models.ErrorCategoryNetwork},
+		{&InterruptedError{Written: 10, Err: wrapRequestError(io.ErrUnexpectedEOF)}, models.ErrorCategoryNetwork},
+		{wrapRequestError(context.DeadlineExceeded), models.ErrorCategoryTimeout},
+		{wrapRequestError(context.Canceled), models.ErrorCategoryCancelled},
+		{wrapRequestError(x509.UnknownAuthorityError{}), models.ErrorCategoryTLS},
+		{&SkipError{Reason: "file is empty"}, models.ErrorCategorySkipped},
+		{&WriteError{Err: errors.New("no space left on device")}, models.ErrorCategoryOther},
+	}
+
+	var results []models.DownloadResult
+	want := make(map[models.ErrorCategory]int)
+	for _, f := range failures {
+		de := NewDownloadError(f.err)
+		if de.Category != f.want {
+			t.Errorf("Categorize(%v) = %q, want %q", f.err, de.Category, f.want)
+		}
+		var r models.DownloadResult
+		r.AddError(de)
+		results = append(results, r)
+		want[f.want]++
+	}
+
+	if got := models.Summarize(results).ByCategory; !reflect.DeepEqual(got, want) {
+		t.Errorf("Summarize().ByCategory = %v, want %v", got, want)
+	}
+}
+
+func TestCategorizer_CustomRules(t *testing.T) {
+	rules, err := ParseCategoryRules("401=auth, 403=auth, 429=rate-limited, 5xx=upstream")
+	if err != nil {
+		t.Fatalf("ParseCategoryRules() error = %v", err)
+	}
+	c := NewCategorizer(rules...)
+
+	tests := []struct {
+		err  error
+		want models.ErrorCategory
+	}{
+		{&HTTPError{StatusCode: 403}, "auth"},
+		{&HTTPError{StatusCode: 429}, "rate-limited"},
+		{&HTTPError{StatusCode: 404}, models.ErrorCategoryHTTP4xx},
+		{&HTTPError{StatusCode: 502}, "upstream"},
+		{wrapRequestError(context.DeadlineExceeded), models.ErrorCategoryTimeout},
+	}
+	for _, tt := range tests {
+		if got := c.Categorize(tt.err); got != tt.want {
+			t.Errorf("Categorize(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"404", "4xx=", "600=odd", "abc=x", "6xx=x"} {
+		if _, err := ParseCategoryRules(bad); err == nil {
+			t.Errorf("ParseCategoryRules(%q) expected error", bad)
+		}
+	}
+}
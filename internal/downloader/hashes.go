@@ -0,0 +1,222 @@
+package downloader
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"log"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/lcalzada-xor/downurl/internal/parser"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+)
+
+// ExpectedHashes maps URLs and filenames to the digests their downloads
+// must have (see LoadExpectedHashes)
+type ExpectedHashes struct {
+	byURL  map[string]expectedHash
+	byName map[string]expectedHash
+}
+
+// expectedHash is a digest and the algorithm it was computed with
+type expectedHash struct {
+	algorithm string // sha256 or md5
+	digest    string // Lowercase hex
+}
+
+// newHash returns a hash computing the algorithm of e
+func (e expectedHash) newHash() hash.Hash {
+	if e.algorithm == "md5" {
+		return md5.New()
+	}
+	return sha256.New()
+}
+
+// LoadExpectedHashes reads a file of expected digests, one per line, with
+// the digest and a URL or filename separated by whitespace in either order:
+//
+//	e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855  app.js
+//	https://example.com/lib.js sha256:9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+//	d41d8cd98f00b204e9800998ecf8427e *vendor.js
+//
+// so sha256sum and md5sum output can be used as is. The algorithm follows
+// from the digest's length (64 hex digits for SHA-256, 32 for MD5) or a
+// sha256: / md5: prefix. Blank lines and lines starting with # are ignored.
+func LoadExpectedHashes(filePath string) (*ExpectedHashes, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open hashes file: %w", err)
+	}
+	defer file.Close()
+
+	h := &ExpectedHashes{
+		byURL:  make(map[string]expectedHash),
+		byName: make(map[string]expectedHash),
+	}
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, want, err := parseHashLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", filePath, lineNum, err)
+		}
+		if strings.Contains(key, "://") {
+			h.byURL[key] = want
+		} else {
+			h.byName[path.Base(key)] = want
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read hashes file: %w", err)
+	}
+	return h, nil
+}
+
+// parseHashLine splits a line into its URL or filename and its digest
+func parseHashLine(line string) (string, expectedHash, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", expectedHash{}, fmt.Errorf("expected a digest and a URL or filename")
+	}
+
+	first, last := fields[0], fields[len(fields)-1]
+	if want, ok := parseDigest(first); ok {
+		// sha256sum marks files read in binary mode with a leading *
+		key := strings.TrimPrefix(strings.TrimSpace(line[len(first):]), "*")
+		return key, want, nil
+	}
+	if want, ok := parseDigest(last); ok {
+		return strings.TrimSpace(line[:len(line)-len(last)]), want, nil
+	}
+	return "", expectedHash{}, fmt.Errorf("no SHA-256 or MD5 digest found")
+}
+
+// parseDigest parses a hex digest, with an optional algorithm prefix
+func parseDigest(s string) (expectedHash, bool) {
+	algorithm := ""
+	if i := strings.Index(s, ":"); i != -1 {
+		algorithm, s = strings.ToLower(s[:i]), s[i+1:]
+	}
+	if _, err := hex.DecodeString(s); err != nil {
+		return expectedHash{}, false
+	}
+
+	var want expectedHash
+	switch len(s) {
+	case sha256.Size * 2:
+		want.algorithm = "sha256"
+	case md5.Size * 2:
+		want.algorithm = "md5"
+	default:
+		return expectedHash{}, false
+	}
+	if algorithm != "" && algorithm != want.algorithm {
+		return expectedHash{}, false
+	}
+	want.digest = strings.ToLower(s)
+	return want, true
+}
+
+// Len returns the number of digests
+func (h *ExpectedHashes) Len() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.byURL) + len(h.byName)
+}
+
+// lookup returns the digest expected for a download of rawURL saved as
+// filename: the URL's own entry, or else the entry for its filename
+func (h *ExpectedHashes) lookup(rawURL, filename string) (expectedHash, bool) {
+	if h == nil {
+		return expectedHash{}, false
+	}
+	if want, ok := h.byURL[rawURL]; ok {
+		return want, true
+	}
+	if want, ok := h.byName[filename]; ok {
+		return want, true
+	}
+	// Files renamed on save (collisions, index names) still match by URL path
+	if u, err := url.Parse(rawURL); err == nil {
+		if want, ok := h.byName[path.Base(u.Path)]; ok {
+			return want, true
+		}
+	}
+	return expectedHash{}, false
+}
+
+// SetExpectedHashes makes every download listed in hashes be checked against
+// its digest. A download that does not match fails with a HashMismatchError;
+// with removeMismatched its file is deleted, otherwise it is kept.
+func (d *Downloader) SetExpectedHashes(hashes *ExpectedHashes, removeMismatched bool) {
+	d.hashes = hashes
+	d.removeMismatched = removeMismatched
+}
+
+// expectedHash returns the digest a download of url must have, if any
+func (d *Downloader) expectedHash(url string) (expectedHash, bool) {
+	return d.hashes.lookup(url, parser.FilenameFromURL(url))
+}
+
+// hashSink hashes a download on its way into storage and checks the digest
+// once the save completes
+type hashSink struct {
+	sink   saveSink
+	want   expectedHash
+	hash   hash.Hash
+	remove bool // Delete the saved file on a mismatch
+}
+
+// Write hashes data and passes it on
+func (s *hashSink) Write(p []byte) (int, error) {
+	n, err := s.sink.Write(p)
+	s.hash.Write(p[:n])
+	return n, err
+}
+
+// Reset discards the partial download and its hash
+func (s *hashSink) Reset() error {
+	s.hash.Reset()
+	return s.sink.Reset()
+}
+
+// Close finishes the save and fails it with a HashMismatchError if the
+// digest is not the expected one
+func (s *hashSink) Close(downloadErr error) saveResult {
+	saved := s.sink.Close(downloadErr)
+	if downloadErr != nil || saved.err != nil {
+		return saved
+	}
+
+	got := hex.EncodeToString(s.hash.Sum(nil))
+	if got == s.want.digest {
+		return saved
+	}
+
+	mismatch := &HashMismatchError{
+		Algorithm: s.want.algorithm,
+		Expected:  s.want.digest,
+		Actual:    got,
+		Path:      saved.path,
+	}
+	if s.remove && saved.path != "" {
+		if err := os.Remove(saved.path); err != nil && !os.IsNotExist(err) {
+			log.Printf("[WARN] Failed to remove %s after a hash mismatch: %s", sanitize.Text(saved.path), sanitize.Text(err.Error()))
+		} else {
+			mismatch.Removed = true
+		}
+	}
+	saved.err = mismatch
+	return saved
+}
@@ -0,0 +1,148 @@
+package downloader
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeHashes(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "hashes.txt")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadExpectedHashes(t *testing.T) {
+	md5Sum := md5.Sum([]byte("vendor"))
+	content := strings.Join([]string{
+		"# comment",
+		"",
+		sha256Hex("app") + "  app.js",
+		"https://example.com/lib.js sha256:" + strings.ToUpper(sha256Hex("lib")),
+		hex.EncodeToString(md5Sum[:]) + " *dist/vendor.js",
+	}, "\n")
+
+	hashes, err := LoadExpectedHashes(writeHashes(t, content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hashes.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", hashes.Len())
+	}
+
+	tests := []struct {
+		url, filename string
+		algorithm     string
+		digest        string
+	}{
+		{"https://example.com/lib.js", "lib.js", "sha256", sha256Hex("lib")},
+		{"https://cdn.example.com/static/app.js", "app.js", "sha256", sha256Hex("app")},
+		{"https://example.com/vendor.js?v=2", "vendor_1a2b.js", "md5", hex.EncodeToString(md5Sum[:])},
+	}
+	for _, tt := range tests {
+		want, ok := hashes.lookup(tt.url, tt.filename)
+		if !ok {
+			t.Errorf("lookup(%q) found nothing", tt.url)
+			continue
+		}
+		if want.algorithm != tt.algorithm || want.digest != tt.digest {
+			t.Errorf("lookup(%q) = %+v, want %s %s", tt.url, want, tt.algorithm, tt.digest)
+		}
+	}
+	if _, ok := hashes.lookup("https://example.com/other.js", "other.js"); ok {
+		t.Error("lookup found a digest for an unlisted file")
+	}
+}
+
+func TestLoadExpectedHashes_Invalid(t *testing.T) {
+	tests := []string{
+		"app.js",
+		"abc123 app.js",
+		"md5:" + sha256Hex("x") + " app.js",
+	}
+	for _, content := range tests {
+		if _, err := LoadExpectedHashes(writeHashes(t, content)); err == nil {
+			t.Errorf("LoadExpectedHashes(%q) succeeded, want an error", content)
+		} else if !strings.Contains(err.Error(), ":1:") {
+			t.Errorf("error %q does not name the line", err)
+		}
+	}
+}
+
+func TestDownloader_VerifyHashes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("body of " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	hashesFile := writeHashes(t, fmt.Sprintf("%s good.js\n%s bad.js\n",
+		sha256Hex("body of /good.js"), sha256Hex("something else")))
+	hashes, err := LoadExpectedHashes(hashesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, remove := range []bool{false, true} {
+		t.Run(fmt.Sprintf("remove=%v", remove), func(t *testing.T) {
+			outDir := t.TempDir()
+			dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(outDir, "flat"), 2)
+			dl.SetSkipHeadRequest(true)
+			dl.SetExpectedHashes(hashes, remove)
+
+			results := dl.DownloadAll(context.Background(), []string{
+				server.URL + "/good.js",
+				server.URL + "/bad.js",
+				server.URL + "/unlisted.js",
+			})
+			byURL := make(map[string]*models.DownloadResult)
+			for _, r := range results {
+				byURL[r.URL] = r
+			}
+
+			good := byURL[server.URL+"/good.js"]
+			if !good.IsSuccess() || !good.Verified {
+				t.Errorf("good.js: success=%v verified=%v, want both", good.IsSuccess(), good.Verified)
+			}
+			unlisted := byURL[server.URL+"/unlisted.js"]
+			if !unlisted.IsSuccess() || unlisted.Verified {
+				t.Errorf("unlisted.js: success=%v verified=%v, want success only", unlisted.IsSuccess(), unlisted.Verified)
+			}
+
+			bad := byURL[server.URL+"/bad.js"]
+			if len(bad.Failures) != 1 || bad.Failures[0].Category != models.ErrorCategoryChecksum {
+				t.Fatalf("bad.js failures = %+v, want one checksum failure", bad.Failures)
+			}
+			if !strings.Contains(bad.Errors[0], "sha256 mismatch") {
+				t.Errorf("error = %q, want a sha256 mismatch", bad.Errors[0])
+			}
+			_, statErr := os.Stat(filepath.Join(outDir, "bad.js"))
+			if remove {
+				if len(bad.Downloaded) != 0 || !os.IsNotExist(statErr) {
+					t.Errorf("mismatched file kept (downloaded %v, stat %v), want it removed", bad.Downloaded, statErr)
+				}
+			} else if len(bad.Downloaded) != 1 || statErr != nil {
+				t.Errorf("mismatched file not kept (downloaded %v, stat %v)", bad.Downloaded, statErr)
+			}
+		})
+	}
+}
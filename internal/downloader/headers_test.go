@@ -0,0 +1,128 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func newHeaderServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/express.js" {
+			w.Header().Set("X-Powered-By", "Express")
+		}
+		w.Header().Set("Content-Type", "application/javascript")
+		w.Write([]byte("console.log('ok');"))
+	}))
+}
+
+func TestDownloader_RequireHeader(t *testing.T) {
+	server := newHeaderServer()
+	defer server.Close()
+
+	for _, skipHead := range []bool{false, true} {
+		outputDir := t.TempDir()
+		dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(outputDir, "flat"), 2)
+		dl.SetFilter(filter.NewContentFilter(filter.FilterConfig{
+			RequireHeaders: []filter.HeaderRule{{Name: "X-Powered-By", Value: "Express"}},
+		}))
+		dl.SetSkipHeadRequest(skipHead)
+
+		results := dl.DownloadAll(context.Background(), []string{
+			server.URL + "/express.js",
+			server.URL + "/plain.js",
+		})
+
+		for _, r := range results {
+			switch r.URL {
+			case server.URL + "/express.js":
+				if !r.IsSuccess() {
+					t.Errorf("skipHead=%v: %s should be kept, got errors %v", skipHead, r.URL, r.Errors)
+				}
+			case server.URL + "/plain.js":
+				if len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategorySkipped {
+					t.Errorf("skipHead=%v: %s should be skipped, got %+v", skipHead, r.URL, r.Failures)
+				}
+			}
+		}
+
+		// Skipped responses must not leave files behind
+		entries, _ := os.ReadDir(outputDir)
+		if len(entries) != 1 {
+			t.Errorf("skipHead=%v: output has %d files, want 1", skipHead, len(entries))
+		}
+	}
+}
+
+func TestDownloader_SkipHeader(t *testing.T) {
+	server := newHeaderServer()
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+	dl.SetFilter(filter.NewContentFilter(filter.FilterConfig{
+		SkipHeaders: []filter.HeaderRule{{Name: "X-Powered-By"}},
+	}))
+
+	results := dl.DownloadAll(context.Background(), []string{server.URL + "/express.js"})
+	if len(results) != 1 {
+		t.Fatalf("DownloadAll() returned %d results, want 1", len(results))
+	}
+	if results[0].IsSuccess() {
+		t.Error("response with X-Powered-By should be skipped")
+	}
+}
+
+func TestDownloader_HeadDecision(t *testing.T) {
+	var heads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/flaky.css":
+			// HEAD fails once, then reports a filtered content type
+			if r.Method == http.MethodHead && atomic.AddInt32(&heads, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/css")
+		case "/broken.js":
+			// HEAD always fails, GET works
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/javascript")
+		}
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 1), storage.NewFileStorage(t.TempDir(), "flat"), 2)
+	dl.SetFilter(filter.NewContentFilter(filter.FilterConfig{ExcludeType: "text/css"}))
+
+	results := dl.DownloadAll(context.Background(), []string{
+		server.URL + "/flaky.css",
+		server.URL + "/broken.js",
+	})
+
+	for _, r := range results {
+		switch r.URL {
+		case server.URL + "/flaky.css":
+			// The retried HEAD succeeds and its skip decision is honored
+			if len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategorySkipped {
+				t.Errorf("%s should be skipped after HEAD retry, got %+v", r.URL, r.Failures)
+			}
+		case server.URL + "/broken.js":
+			// HEAD never succeeds, so the download proceeds
+			if !r.IsSuccess() {
+				t.Errorf("%s should be downloaded when HEAD fails, got errors %v", r.URL, r.Errors)
+			}
+		}
+	}
+}
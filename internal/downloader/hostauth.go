@@ -0,0 +1,31 @@
+package downloader
+
+import (
+	"net/url"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+)
+
+// SetHostAuth sets per-host credentials. A request to a host with its own
+// provider is authenticated with it instead of the client-wide one, so the
+// command-line credentials are not sent there.
+func (c *HTTPClient) SetHostAuth(hosts *auth.HostProviders) {
+	c.hostAuth = hosts
+}
+
+// authFor returns the provider for requests to host (without port)
+func (c *HTTPClient) authFor(host string) *auth.Provider {
+	if p := c.hostAuth.For(host); p != nil {
+		return p
+	}
+	return c.authProvider
+}
+
+// authForURL returns the provider for requests to rawURL
+func (c *HTTPClient) authForURL(rawURL string) *auth.Provider {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return c.authProvider
+	}
+	return c.authFor(u.Hostname())
+}
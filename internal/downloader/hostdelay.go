@@ -0,0 +1,95 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ParseHostDelays parses a --host-delay value like "fragile.com:2s,other.com:500ms".
+// Hosts are matched case-insensitively, subdomains included.
+func ParseHostDelays(s string) (map[string]time.Duration, error) {
+	delays := make(map[string]time.Duration)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.LastIndex(part, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid host delay %q (expected host:duration)", part)
+		}
+		host := strings.ToLower(strings.TrimSpace(part[:i]))
+		delay, err := time.ParseDuration(strings.TrimSpace(part[i+1:]))
+		if err != nil || delay < 0 {
+			return nil, fmt.Errorf("invalid delay in %q (expected a duration like 500ms or 2s)", part)
+		}
+		delays[host] = delay
+	}
+	return delays, nil
+}
+
+// hostPacer spaces requests to the configured hosts at least their delay apart
+type hostPacer struct {
+	delays map[string]time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time // Earliest start of the next request to each host
+}
+
+func newHostPacer(delays map[string]time.Duration) *hostPacer {
+	return &hostPacer{delays: delays, next: make(map[string]time.Time)}
+}
+
+// delayFor returns the delay configured for host (or a parent domain of it)
+func (p *hostPacer) delayFor(host string) (time.Duration, bool) {
+	for {
+		if d, ok := p.delays[host]; ok {
+			return d, true
+		}
+		i := strings.Index(host, ".")
+		if i == -1 {
+			return 0, false
+		}
+		host = host[i+1:]
+	}
+}
+
+// Wait blocks until a request to rawURL may start. Each caller reserves its
+// slot before sleeping, so concurrent workers queue up behind each other.
+func (p *hostPacer) Wait(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	host := strings.ToLower(u.Hostname())
+	delay, ok := p.delayFor(host)
+	if !ok || delay == 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+	now := time.Now()
+	start := p.next[host]
+	if start.Before(now) {
+		start = now
+	}
+	p.next[host] = start.Add(delay)
+	p.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
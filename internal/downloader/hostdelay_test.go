@@ -0,0 +1,80 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestParseHostDelays(t *testing.T) {
+	delays, err := ParseHostDelays("Fragile.com:2s, other.com:500ms")
+	if err != nil {
+		t.Fatalf("ParseHostDelays() error = %v", err)
+	}
+	if delays["fragile.com"] != 2*time.Second || delays["other.com"] != 500*time.Millisecond {
+		t.Errorf("ParseHostDelays() = %v", delays)
+	}
+
+	for _, bad := range []string{"fragile.com", "fragile.com:fast", ":2s"} {
+		if _, err := ParseHostDelays(bad); err == nil {
+			t.Errorf("ParseHostDelays(%q) expected error", bad)
+		}
+	}
+}
+
+func TestDownloader_HostDelay(t *testing.T) {
+	var mu sync.Mutex
+	starts := make(map[string][]time.Time)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host[:strings.LastIndex(r.Host, ":")]
+		mu.Lock()
+		starts[host] = append(starts[host], time.Now())
+		mu.Unlock()
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	// The same server under two names: only "localhost" is paced
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	var urls []string
+	for i := 0; i < 3; i++ {
+		urls = append(urls,
+			fmt.Sprintf("http://localhost:%s/slow%d.js", port, i),
+			fmt.Sprintf("http://127.0.0.1:%s/fast%d.js", port, i))
+	}
+
+	const delay = 150 * time.Millisecond
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 4)
+	dl.SetHostDelays(map[string]time.Duration{"localhost": delay})
+	for _, r := range dl.DownloadAll(context.Background(), urls) {
+		if len(r.Failures) > 0 {
+			t.Fatalf("%s failed: %+v", r.URL, r.Failures)
+		}
+	}
+
+	paced := starts["localhost"]
+	if len(paced) != 3 {
+		t.Fatalf("localhost got %d requests, want 3", len(paced))
+	}
+	for i := 1; i < len(paced); i++ {
+		// Allow for timer and scheduling jitter on the server side
+		if gap := paced[i].Sub(paced[i-1]); gap < delay-20*time.Millisecond {
+			t.Errorf("localhost requests %d and %d were %v apart, want at least %v", i-1, i, gap, delay)
+		}
+	}
+
+	free := starts["127.0.0.1"]
+	if len(free) != 3 {
+		t.Fatalf("127.0.0.1 got %d requests, want 3", len(free))
+	}
+	if span := free[len(free)-1].Sub(free[0]); span >= delay {
+		t.Errorf("unlisted host requests spread over %v, want no extra delay", span)
+	}
+}
@@ -0,0 +1,81 @@
+package downloader
+
+import (
+	"crypto/tls"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ErrHTTP3Unavailable is returned by SetHTTP3 in binaries built without the http3 tag
+var ErrHTTP3Unavailable = errors.New("HTTP/3 support is not compiled in (rebuild with -tags http3)")
+
+// SetHTTP3 makes HTTPS requests try HTTP/3 (QUIC) first. Hosts where the QUIC
+// handshake fails fall back to the existing HTTP/2 and HTTP/1.1 transport, so
+// call it after SetTLSOptions: both transports share the same TLS settings.
+// Auth, redirects and size limits live above the transport and apply to either.
+func (c *HTTPClient) SetHTTP3(enabled bool) error {
+	if !enabled {
+		return nil
+	}
+
+	fallback := c.client.Transport
+	if fallback == nil {
+		fallback = http.DefaultTransport
+	}
+
+	var tlsConfig *tls.Config
+	if t, ok := fallback.(*http.Transport); ok && t.TLSClientConfig != nil {
+		tlsConfig = t.TLSClientConfig.Clone()
+	}
+
+	h3, err := newHTTP3Transport(tlsConfig)
+	if err != nil {
+		return err
+	}
+	c.client.Transport = &fallbackTransport{primary: h3, fallback: fallback}
+	return nil
+}
+
+// fallbackTransport sends HTTPS requests over primary and resends them over
+// fallback when primary fails to connect. Hosts that failed once go straight
+// to fallback afterwards.
+type fallbackTransport struct {
+	primary  http.RoundTripper
+	fallback http.RoundTripper
+	failed   sync.Map // host -> struct{}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *fallbackTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return t.fallback.RoundTrip(req)
+	}
+	if _, failed := t.failed.Load(req.URL.Host); failed {
+		return t.fallback.RoundTrip(req)
+	}
+
+	resp, err := t.primary.RoundTrip(req)
+	if err == nil {
+		return resp, nil
+	}
+	if req.Context().Err() != nil {
+		return nil, err
+	}
+
+	// A request body already consumed by the first attempt must be rebuilt
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
+			return nil, err
+		}
+		req = req.Clone(req.Context())
+		req.Body = body
+	}
+
+	t.failed.Store(req.URL.Host, struct{}{})
+	return t.fallback.RoundTrip(req)
+}
@@ -0,0 +1,14 @@
+//go:build !http3
+
+package downloader
+
+import (
+	"crypto/tls"
+	"net/http"
+)
+
+// newHTTP3Transport is unavailable without the http3 build tag, which keeps
+// the default binary free of the QUIC dependency
+func newHTTP3Transport(*tls.Config) (http.RoundTripper, error) {
+	return nil, ErrHTTP3Unavailable
+}
@@ -0,0 +1,19 @@
+//go:build !http3
+
+package downloader
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_SetHTTP3Unavailable(t *testing.T) {
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetHTTP3(true); !errors.Is(err, ErrHTTP3Unavailable) {
+		t.Errorf("SetHTTP3() error = %v, want ErrHTTP3Unavailable", err)
+	}
+	if err := client.SetHTTP3(false); err != nil {
+		t.Errorf("SetHTTP3(false) error = %v", err)
+	}
+}
@@ -0,0 +1,15 @@
+//go:build http3
+
+package downloader
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// newHTTP3Transport creates a QUIC round tripper using tlsConfig (nil = defaults)
+func newHTTP3Transport(tlsConfig *tls.Config) (http.RoundTripper, error) {
+	return &http3.Transport{TLSClientConfig: tlsConfig}, nil
+}
@@ -0,0 +1,64 @@
+//go:build http3
+
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+func TestHTTPClient_HTTP3Download(t *testing.T) {
+	var proto string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto = r.Proto
+		w.Write([]byte("console.log('h3');"))
+	})
+
+	// The TLS test server only provides a certificate for the QUIC listener
+	certSource := httptest.NewTLSServer(handler)
+	defer certSource.Close()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	server := &http3.Server{
+		Handler:   handler,
+		TLSConfig: http3.ConfigureTLSConfig(&tls.Config{Certificates: certSource.TLS.Certificates}),
+	}
+	go server.Serve(conn)
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetTLSOptions(TLSOptions{}); err != nil {
+		t.Fatalf("SetTLSOptions() error = %v", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(certSource.Certificate())
+	client.client.Transport.(*http.Transport).TLSClientConfig.RootCAs = pool
+	if err := client.SetHTTP3(true); err != nil {
+		t.Fatalf("SetHTTP3() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	url := fmt.Sprintf("https://%s/app.js", conn.LocalAddr())
+	if _, err := client.DownloadToWriter(context.Background(), url, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	if buf.String() != "console.log('h3');" {
+		t.Errorf("body = %q", buf.String())
+	}
+	if proto != "HTTP/3.0" {
+		t.Errorf("request protocol = %s, want HTTP/3.0", proto)
+	}
+}
@@ -0,0 +1,57 @@
+package downloader
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// roundTripFunc adapts a function to http.RoundTripper
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFallbackTransport(t *testing.T) {
+	primaryCalls := 0
+	primary := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		primaryCalls++
+		return nil, errors.New("no QUIC listener")
+	})
+
+	fallbackCalls := 0
+	fallback := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		fallbackCalls++
+		rec := httptest.NewRecorder()
+		rec.WriteString("ok")
+		return rec.Result(), nil
+	})
+
+	transport := &fallbackTransport{primary: primary, fallback: fallback}
+	client := &http.Client{Transport: transport}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get("https://cdn.example.com/app.js")
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	// The failing host is remembered, so only the first request tries the primary
+	if primaryCalls != 1 || fallbackCalls != 2 {
+		t.Errorf("primary calls = %d, fallback calls = %d; want 1 and 2", primaryCalls, fallbackCalls)
+	}
+
+	// Plain HTTP never goes over the primary
+	resp, err := client.Get("http://other.example.com/app.js")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+	if primaryCalls != 1 {
+		t.Errorf("primary calls = %d after http:// request, want 1", primaryCalls)
+	}
+}
@@ -0,0 +1,100 @@
+package downloader
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// indexExtensions maps common Content-Types to the extension of an index
+// file; mime.ExtensionsByType covers the rest
+var indexExtensions = map[string]string{
+	"text/html":              ".html",
+	"application/xhtml+xml":  ".html",
+	"application/json":       ".json",
+	"application/javascript": ".js",
+	"text/javascript":        ".js",
+	"text/css":               ".css",
+	"text/plain":             ".txt",
+	"application/xml":        ".xml",
+	"text/xml":               ".xml",
+}
+
+// isDirectoryURL reports whether a URL names a directory rather than a
+// file: its path is empty or ends in "/", as in /docs/ or /?q=1
+func isDirectoryURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	return parsed.Path == "" || strings.HasSuffix(parsed.Path, "/")
+}
+
+// indexFilename returns the name a directory URL is saved as: index plus
+// the extension of its Content-Type. Without a Content-Type, web servers'
+// directory indexes are HTML.
+func indexFilename(contentType string) string {
+	if contentType == "" {
+		return "index.html"
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return "index"
+	}
+	if ext, ok := indexExtensions[mediaType]; ok {
+		return "index" + ext
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return "index" + exts[0]
+	}
+	return "index"
+}
+
+// indexSink saves a directory URL as index.<ext>. Storage is only opened at
+// the first write, once the response's Content-Type has picked the extension.
+type indexSink struct {
+	open        func(filename string) saveSink
+	contentType string // Set by the response check ("" for cached copies)
+	sink        saveSink
+}
+
+// checkResponse records the response's Content-Type after check accepts it
+func (s *indexSink) checkResponse(check ResponseCheck) ResponseCheck {
+	return func(resp *http.Response) error {
+		if check != nil {
+			if err := check(resp); err != nil {
+				return err
+			}
+		}
+		s.contentType = resp.Header.Get("Content-Type")
+		return nil
+	}
+}
+
+// Write opens storage if needed and passes data on to it
+func (s *indexSink) Write(p []byte) (int, error) {
+	if s.sink == nil {
+		s.sink = s.open(indexFilename(s.contentType))
+	}
+	return s.sink.Write(p)
+}
+
+// Reset discards everything written so far
+func (s *indexSink) Reset() error {
+	if s.sink == nil {
+		return nil
+	}
+	return s.sink.Reset()
+}
+
+// Close finishes the save; an empty body still becomes an (empty) file
+func (s *indexSink) Close(downloadErr error) saveResult {
+	if s.sink == nil {
+		if downloadErr != nil {
+			return saveResult{err: downloadErr}
+		}
+		s.sink = s.open(indexFilename(s.contentType))
+	}
+	return s.sink.Close(downloadErr)
+}
@@ -0,0 +1,93 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_IndexNames(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true}`))
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html>" + r.URL.Path + "</html>"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want string // Saved path below the host directory
+	}{
+		{"/docs/", filepath.Join("docs", "index.html")},
+		{"/docs/api/", filepath.Join("docs", "api", "index.html")},
+		{"/?format=json", "index.json"},
+		{"/app.js", "app.js"}, // Files keep their name
+	}
+
+	outputDir := t.TempDir()
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(outputDir, "path"), 2)
+	dl.SetIndexNames(true)
+
+	saved := make(map[string]string)
+	for _, tt := range tests {
+		r := dl.DownloadAll(context.Background(), []string{server.URL + tt.path})[0]
+		if !r.IsSuccess() {
+			t.Fatalf("%s failed: %v", tt.path, r.Errors)
+		}
+		saved[tt.path] = r.Downloaded[0]
+		if got := r.Downloaded[0]; !strings.HasSuffix(got, string(filepath.Separator)+tt.want) {
+			t.Errorf("%s saved to %s, want .../%s", tt.path, got, tt.want)
+		}
+	}
+
+	data, err := os.ReadFile(saved["/docs/"])
+	if err != nil || string(data) != "<html>/docs/</html>" {
+		t.Errorf("docs index = %q, %v; want the page body", data, err)
+	}
+}
+
+func TestDownloader_IndexNamesOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html></html>"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "path"), 1)
+	r := dl.DownloadAll(context.Background(), []string{server.URL + "/docs/"})[0]
+	if !r.IsSuccess() {
+		t.Fatalf("download failed: %v", r.Errors)
+	}
+	if base := filepath.Base(r.Downloaded[0]); strings.HasPrefix(base, "index") {
+		t.Errorf("saved as %s, want the default hash name", base)
+	}
+}
+
+func TestIndexFilename(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        string
+	}{
+		{"", "index.html"},
+		{"text/html; charset=utf-8", "index.html"},
+		{"application/json", "index.json"},
+		{"text/javascript", "index.js"},
+		{"application/x-unknown-thing", "index"},
+	}
+	for _, tt := range tests {
+		if got := indexFilename(tt.contentType); got != tt.want {
+			t.Errorf("indexFilename(%q) = %q, want %q", tt.contentType, got, tt.want)
+		}
+	}
+}
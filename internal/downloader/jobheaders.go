@@ -0,0 +1,41 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+)
+
+// headersKey is the context key for per-request headers
+type headersKey struct{}
+
+// WithHeaders returns a context whose requests carry extra headers.
+// They are applied after authentication and Accept, so they win on conflict.
+func WithHeaders(ctx context.Context, h http.Header) context.Context {
+	return context.WithValue(ctx, headersKey{}, h)
+}
+
+// addContextHeaders returns a context whose requests carry extra on top of
+// any headers already attached to ctx
+func addContextHeaders(ctx context.Context, extra http.Header) context.Context {
+	h, _ := ctx.Value(headersKey{}).(http.Header)
+	merged := h.Clone()
+	if merged == nil {
+		merged = make(http.Header)
+	}
+	for name, values := range extra {
+		merged[name] = values
+	}
+	return WithHeaders(ctx, merged)
+}
+
+// applyContextHeaders sets the headers attached to the request's context
+func applyContextHeaders(req *http.Request) {
+	h, _ := req.Context().Value(headersKey{}).(http.Header)
+	for name, values := range h {
+		if http.CanonicalHeaderKey(name) == "Host" && len(values) > 0 {
+			req.Host = values[0]
+			continue
+		}
+		req.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+	}
+}
@@ -0,0 +1,68 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_URLHeaders(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]http.Header)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path] = r.Header.Clone()
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// Global headers apply to every URL
+	provider, err := auth.NewProvider(auth.Config{
+		Type:    auth.AuthTypeCustom,
+		Headers: map[string]string{"X-Tenant": "global", "X-Client": "downurl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dl := New(NewHTTPClientWithAuth(5*time.Second, 0, provider), storage.NewInMemoryStorage("out", "flat"), 3)
+	results := dl.DownloadJobs(context.Background(), []Job{
+		{URL: server.URL + "/a.json", Headers: http.Header{"X-Tenant": {"a"}}},
+		{URL: server.URL + "/b.json", Headers: http.Header{"X-Tenant": {"b"}, "X-Key": {"kb"}}},
+		{URL: server.URL + "/c.json"},
+	}, nil, nil)
+	for _, r := range results {
+		if !r.IsSuccess() {
+			t.Fatalf("%s failed: %v", r.URL, r.Errors)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		tenant string
+		key    string
+	}{
+		{"/a.json", "a", ""},
+		{"/b.json", "b", "kb"},
+		{"/c.json", "global", ""},
+	}
+	for _, tt := range tests {
+		h := seen[tt.path]
+		if got := h.Values("X-Tenant"); len(got) != 1 || got[0] != tt.tenant {
+			t.Errorf("%s X-Tenant = %v, want [%s]", tt.path, got, tt.tenant)
+		}
+		if got := h.Get("X-Key"); got != tt.key {
+			t.Errorf("%s X-Key = %q, want %q", tt.path, got, tt.key)
+		}
+		if got := h.Get("X-Client"); got != "downurl" {
+			t.Errorf("%s X-Client = %q, want the global header", tt.path, got)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+)
+
+// RequestSpec overrides the method and body of a download request
+type RequestSpec struct {
+	Method string // HTTP method ("" = GET)
+	Body   []byte // Body sent with every attempt (nil = none)
+}
+
+// isGet reports whether the spec leaves the request a plain GET
+func (s RequestSpec) isGet() bool {
+	return (s.Method == "" || s.Method == http.MethodGet) && s.Body == nil
+}
+
+// requestKey is the context key for a per-request method and body
+type requestKey struct{}
+
+// WithRequest returns a context whose downloads use spec's method and body
+func WithRequest(ctx context.Context, spec RequestSpec) context.Context {
+	return context.WithValue(ctx, requestKey{}, spec)
+}
+
+// isGetRequest reports whether ctx leaves downloads a plain GET
+func isGetRequest(ctx context.Context) bool {
+	spec, _ := ctx.Value(requestKey{}).(RequestSpec)
+	return spec.isGet()
+}
+
+// contextRequest returns the method and a fresh body reader for the request
+// attached to ctx, defaulting to a bodiless GET. Each call yields a new
+// reader, so retries resend the whole body.
+func contextRequest(ctx context.Context) (string, io.Reader) {
+	spec, _ := ctx.Value(requestKey{}).(RequestSpec)
+	method := spec.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	if spec.Body == nil {
+		return method, nil
+	}
+	return method, bytes.NewReader(spec.Body)
+}
@@ -0,0 +1,112 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_URLRequests(t *testing.T) {
+	type seen struct{ method, body, tenant string }
+	var mu sync.Mutex
+	got := make(map[string]seen)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		got[r.URL.Path] = seen{r.Method, string(body), r.Header.Get("X-Tenant")}
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 2)
+	results := dl.DownloadJobs(context.Background(), []Job{
+		{URL: server.URL + "/search", Headers: http.Header{"X-Tenant": {"a"}}, Request: RequestSpec{Method: http.MethodPost, Body: []byte(`{"q":"js"}`)}},
+		{URL: server.URL + "/item", Request: RequestSpec{Method: http.MethodDelete}},
+		{URL: server.URL + "/app.js"},
+	}, nil, nil)
+	for _, r := range results {
+		if !r.IsSuccess() {
+			t.Errorf("%s failed: %v", r.URL, r.Errors)
+		}
+	}
+
+	want := map[string]seen{
+		"/search": {http.MethodPost, `{"q":"js"}`, "a"},
+		"/item":   {http.MethodDelete, "", ""},
+		"/app.js": {http.MethodGet, "", ""},
+	}
+	for path, w := range want {
+		if got[path] != w {
+			t.Errorf("%s received %+v, want %+v", path, got[path], w)
+		}
+	}
+}
+
+func TestDownloader_URLRequestsRetrySendsBody(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, string(body))
+		first := len(bodies) == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 1), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+	results := dl.DownloadJobs(context.Background(), []Job{
+		{URL: server.URL + "/search", Request: RequestSpec{Method: http.MethodPost, Body: []byte("q=js")}},
+	}, nil, nil)
+	if len(results) != 1 || !results[0].IsSuccess() {
+		t.Fatalf("DownloadAll() = %+v, want one success after retry", results)
+	}
+	if len(bodies) != 2 || bodies[0] != "q=js" || bodies[1] != "q=js" {
+		t.Errorf("bodies received = %q, want the body on both attempts", bodies)
+	}
+}
+
+func TestDownloader_JobsSharingURLKeepTheirRequests(t *testing.T) {
+	var mu sync.Mutex
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, r.Header.Get("X-Tenant")+":"+string(body))
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	// Two JSON-lines records posting different queries to one endpoint
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 2)
+	results := dl.DownloadJobs(context.Background(), []Job{
+		{URL: server.URL + "/search", Headers: http.Header{"X-Tenant": {"a"}}, Request: RequestSpec{Method: http.MethodPost, Body: []byte("q=1")}},
+		{URL: server.URL + "/search", Headers: http.Header{"X-Tenant": {"b"}}, Request: RequestSpec{Method: http.MethodPost, Body: []byte("q=2")}},
+	}, nil, nil)
+	for _, r := range results {
+		if !r.IsSuccess() {
+			t.Errorf("%s failed: %v", r.URL, r.Errors)
+		}
+	}
+
+	sort.Strings(received)
+	if want := []string{"a:q=1", "b:q=2"}; !reflect.DeepEqual(received, want) {
+		t.Errorf("requests received = %q, want %q", received, want)
+	}
+}
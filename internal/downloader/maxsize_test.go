@@ -1,7 +1,9 @@
 package downloader
 
 import (
+	"bytes"
 	"context"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -95,3 +97,35 @@ func TestHTTPClient_Download_ExactlyMaxSize(t *testing.T) {
 		t.Errorf("Download() data length = %d, want %d", len(data), int(MaxDownloadSize)-1)
 	}
 }
+
+func TestHTTPClient_SetMaxDownloadSize(t *testing.T) {
+	content := strings.Repeat("D", 2048)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetMaxDownloadSize(1024)
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(ctx, server.URL, &buf); err == nil {
+		t.Error("DownloadToWriter() should fail above the configured limit")
+	}
+
+	client.SetMaxDownloadSize(4096)
+	buf.Reset()
+	if n, err := client.DownloadToWriter(ctx, server.URL, &buf); err != nil || n != 2048 {
+		t.Errorf("DownloadToWriter() = %d, %v; want 2048, nil", n, err)
+	}
+
+	client.SetMaxDownloadSize(0)
+	if client.maxSize != math.MaxInt64 {
+		t.Errorf("SetMaxDownloadSize(0) left maxSize = %d, want no limit", client.maxSize)
+	}
+	buf.Reset()
+	if _, err := client.DownloadToWriter(ctx, server.URL, &buf); err != nil {
+		t.Errorf("DownloadToWriter() with no limit error = %v", err)
+	}
+}
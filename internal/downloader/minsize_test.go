@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// newChunkedServer serves bodies without Content-Length, so size filters
+// can only decide once the body has been read
+func newChunkedServer(bodies map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Flushing before the body forces chunked encoding, even for an empty body
+		w.(http.Flusher).Flush()
+		if r.Method != http.MethodHead {
+			w.Write([]byte(bodies[r.URL.Path]))
+		}
+	}))
+}
+
+func TestDownloader_MinSizeAfterDownload(t *testing.T) {
+	server := newChunkedServer(map[string]string{
+		"/empty.js": "",
+		"/tiny.js":  "x",
+		"/app.js":   strings.Repeat("console.log(1);", 10),
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		cfg  filter.FilterConfig
+		kept map[string]bool
+	}{
+		{"skip empty", filter.FilterConfig{SkipEmpty: true}, map[string]bool{"/tiny.js": true, "/app.js": true}},
+		{"min size", filter.FilterConfig{MinSize: 16}, map[string]bool{"/app.js": true}},
+	}
+
+	for _, tt := range tests {
+		for _, skipHead := range []bool{false, true} {
+			outputDir := t.TempDir()
+			dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(outputDir, "flat"), 2)
+			dl.SetFilter(filter.NewContentFilter(tt.cfg))
+			dl.SetSkipHeadRequest(skipHead)
+
+			results := dl.DownloadAll(context.Background(), []string{
+				server.URL + "/empty.js",
+				server.URL + "/tiny.js",
+				server.URL + "/app.js",
+			})
+
+			for _, r := range results {
+				path := strings.TrimPrefix(r.URL, server.URL)
+				if tt.kept[path] {
+					if !r.IsSuccess() {
+						t.Errorf("%s, skipHead=%v: %s should be kept, got %v", tt.name, skipHead, path, r.Errors)
+					}
+					continue
+				}
+				if len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategorySkipped {
+					t.Errorf("%s, skipHead=%v: %s should be skipped, got %+v", tt.name, skipHead, path, r.Failures)
+				}
+			}
+
+			// Skipped bodies never become files
+			entries, _ := os.ReadDir(outputDir)
+			if len(entries) != len(tt.kept) {
+				t.Errorf("%s, skipHead=%v: output has %d files, want %d", tt.name, skipHead, len(entries), len(tt.kept))
+			}
+		}
+	}
+}
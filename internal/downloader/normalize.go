@@ -0,0 +1,220 @@
+package downloader
+
+import (
+	"bytes"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+)
+
+// Byte order marks recognized at the start of text files
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// cp1252 maps bytes 0x80-0x9F of windows-1252 to runes; the rest of the
+// range is the same as ISO-8859-1 (Latin-1), which browsers decode as windows-1252
+var cp1252 = [32]rune{
+	'€', '\uFFFD', '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', '\uFFFD', 'Ž', '\uFFFD',
+	'\uFFFD', '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', '\uFFFD', 'ž', 'Ÿ',
+}
+
+// textEncoding is how a text body is converted to UTF-8
+type textEncoding int
+
+const (
+	encodingUTF8 textEncoding = iota // Already UTF-8 (or unknown): passed through
+	encodingUTF16LE
+	encodingUTF16BE
+	encodingCP1252
+	encodingBinary // Not text: left untouched, BOM included
+)
+
+// textNormalizer strips a leading BOM from text bodies and converts them to
+// UTF-8 on their way into a sink. The encoding comes from the BOM or else
+// from the charset of the response's Content-Type; bodies that are not text
+// are passed through as they are.
+type textNormalizer struct {
+	sink        saveSink
+	filename    string
+	contentType string // Set by the response check ("" for cached copies)
+
+	head     []byte // First bytes, held until the BOM is known
+	decided  bool
+	encoding textEncoding
+	pending  []byte // UTF-16 bytes not yet forming a whole character
+}
+
+// checkResponse records the response's Content-Type after check accepts it
+func (n *textNormalizer) checkResponse(check ResponseCheck) ResponseCheck {
+	return func(resp *http.Response) error {
+		if check != nil {
+			if err := check(resp); err != nil {
+				return err
+			}
+		}
+		n.contentType = resp.Header.Get("Content-Type")
+		return nil
+	}
+}
+
+// Write converts data and passes it on to the sink
+func (n *textNormalizer) Write(p []byte) (int, error) {
+	data := p
+	if !n.decided {
+		n.head = append(n.head, p...)
+		if len(n.head) < len(bomUTF8) {
+			return len(p), nil
+		}
+		data = n.decide()
+	}
+	if _, err := n.sink.Write(n.convert(data)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Reset discards everything written so far
+func (n *textNormalizer) Reset() error {
+	n.head, n.pending = nil, nil
+	n.decided = false
+	return n.sink.Reset()
+}
+
+// Close flushes what is still held back and finishes the save
+func (n *textNormalizer) Close(downloadErr error) saveResult {
+	if downloadErr == nil {
+		var rest []byte
+		if !n.decided {
+			rest = n.convert(n.decide())
+		}
+		if len(n.pending) > 0 {
+			// A truncated UTF-16 character
+			rest = utf8.AppendRune(rest, utf8.RuneError)
+			n.pending = nil
+		}
+		if len(rest) > 0 {
+			if _, err := n.sink.Write(rest); err != nil {
+				return n.sink.Close(err)
+			}
+		}
+	}
+	return n.sink.Close(downloadErr)
+}
+
+// decide picks the encoding from the held-back head and returns the head
+// without its BOM
+func (n *textNormalizer) decide() []byte {
+	head := n.head
+	n.head = nil
+	n.decided = true
+
+	contentType := n.contentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(strings.ToLower(filepath.Ext(n.filename)))
+	}
+	if !filter.IsText(contentType) {
+		n.encoding = encodingBinary
+		return head
+	}
+
+	// A BOM wins over the declared charset
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		n.encoding = encodingUTF8
+		return head[len(bomUTF8):]
+	case bytes.HasPrefix(head, bomUTF16LE):
+		n.encoding = encodingUTF16LE
+		return head[len(bomUTF16LE):]
+	case bytes.HasPrefix(head, bomUTF16BE):
+		n.encoding = encodingUTF16BE
+		return head[len(bomUTF16BE):]
+	}
+	n.encoding = charsetEncoding(contentType)
+	return head
+}
+
+// charsetEncoding returns the encoding named by a Content-Type's charset
+func charsetEncoding(contentType string) textEncoding {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return encodingUTF8
+	}
+	switch strings.ToLower(params["charset"]) {
+	case "utf-16le":
+		return encodingUTF16LE
+	case "utf-16be", "utf-16": // Without a BOM, UTF-16 is big-endian
+		return encodingUTF16BE
+	case "iso-8859-1", "latin1", "windows-1252", "cp1252":
+		return encodingCP1252
+	}
+	return encodingUTF8
+}
+
+// convert returns data as UTF-8
+func (n *textNormalizer) convert(data []byte) []byte {
+	switch n.encoding {
+	case encodingUTF16LE, encodingUTF16BE:
+		return n.convertUTF16(data)
+	case encodingCP1252:
+		out := make([]byte, 0, len(data)+len(data)/4)
+		for _, b := range data {
+			switch {
+			case b < 0x80:
+				out = append(out, b)
+			case b < 0xA0:
+				out = utf8.AppendRune(out, cp1252[b-0x80])
+			default:
+				out = utf8.AppendRune(out, rune(b))
+			}
+		}
+		return out
+	}
+	return data
+}
+
+// convertUTF16 decodes whole UTF-16 characters, holding back a trailing odd
+// byte or an unpaired high surrogate until the next write
+func (n *textNormalizer) convertUTF16(data []byte) []byte {
+	if len(n.pending) > 0 {
+		data = append(n.pending, data...)
+		n.pending = nil
+	}
+
+	out := make([]byte, 0, len(data))
+	i := 0
+	for ; i+1 < len(data); i += 2 {
+		r := n.unit(data[i:])
+		if utf16.IsSurrogate(r) && r < 0xDC00 {
+			if i+3 >= len(data) {
+				break // The low surrogate has not arrived yet
+			}
+			if r2 := n.unit(data[i+2:]); r2 >= 0xDC00 && r2 <= 0xDFFF {
+				out = utf8.AppendRune(out, utf16.DecodeRune(r, r2))
+				i += 2
+				continue
+			}
+			r = utf8.RuneError
+		}
+		out = utf8.AppendRune(out, r)
+	}
+	if i < len(data) {
+		n.pending = append([]byte(nil), data[i:]...)
+	}
+	return out
+}
+
+// unit reads one UTF-16 code unit in the body's byte order
+func (n *textNormalizer) unit(b []byte) rune {
+	if n.encoding == encodingUTF16LE {
+		return rune(b[0]) | rune(b[1])<<8
+	}
+	return rune(b[0])<<8 | rune(b[1])
+}
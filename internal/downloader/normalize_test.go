@@ -0,0 +1,93 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// utf16LE encodes s as UTF-16LE with a BOM
+func utf16LE(s string) []byte {
+	out := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		out = append(out, byte(u), byte(u>>8))
+	}
+	return out
+}
+
+func TestDownloader_NormalizeText(t *testing.T) {
+	const js = "var greeting = 'héllo 😀';\n"
+	png := append([]byte{0xFF, 0xFE, 0x89, 'P', 'N', 'G'}, make([]byte, 64)...)
+	bodies := map[string]struct {
+		contentType string
+		body        []byte
+	}{
+		"/utf16.js":  {"application/javascript", utf16LE(js)},
+		"/bom.json":  {"application/json; charset=utf-8", append([]byte{0xEF, 0xBB, 0xBF}, `{"a":1}`...)},
+		"/latin1.js": {"text/javascript; charset=ISO-8859-1", []byte("var s = 'caf\xe9 \x80';")},
+		"/logo.png":  {"image/png", png},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b := bodies[r.URL.Path]
+		w.Header().Set("Content-Type", b.contentType)
+		w.Write(b.body)
+	}))
+	defer server.Close()
+
+	mem := storage.NewInMemoryStorage("out", "flat")
+	dl := New(NewHTTPClient(5*time.Second, 0), mem, 2)
+	dl.SetNormalizeText(true)
+
+	want := map[string][]byte{
+		"/utf16.js":  []byte(js),
+		"/bom.json":  []byte(`{"a":1}`),
+		"/latin1.js": []byte("var s = 'café €';"),
+		"/logo.png":  png, // Binary: BOM-like bytes are kept
+	}
+	var urls []string
+	for path := range bodies {
+		urls = append(urls, server.URL+path)
+	}
+	for _, r := range dl.DownloadAll(context.Background(), urls) {
+		if !r.IsSuccess() {
+			t.Errorf("%s failed: %v", r.URL, r.Errors)
+			continue
+		}
+		data, _ := mem.ReadFile(r.Downloaded[0])
+		path := r.URL[len(server.URL):]
+		if !bytes.Equal(data, want[path]) {
+			t.Errorf("%s saved as %q, want %q", path, data, want[path])
+		}
+		if path != "/logo.png" && !utf8.Valid(data) {
+			t.Errorf("%s is not valid UTF-8", path)
+		}
+	}
+}
+
+func TestTextNormalizer_SplitWrites(t *testing.T) {
+	const text = "a😀b"
+	body := utf16LE(text)
+
+	// One byte at a time splits the BOM, code units and the surrogate pair
+	mem := storage.NewInMemoryStorage("out", "flat")
+	n := &textNormalizer{sink: newStreamSink(mem, "host", "/t.txt", "t.txt"), filename: "t.txt", contentType: "text/plain"}
+	for i := range body {
+		if _, err := n.Write(body[i : i+1]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	saved := n.Close(nil)
+	if saved.err != nil {
+		t.Fatalf("Close() error = %v", saved.err)
+	}
+	if data, _ := mem.ReadFile(saved.path); string(data) != text {
+		t.Errorf("saved %q, want %q", data, text)
+	}
+}
@@ -0,0 +1,83 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_PerHostLimit(t *testing.T) {
+	var mu sync.Mutex
+	inFlight := make(map[string]int)
+	peak := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight[r.Host]++
+		if inFlight[r.Host] > peak[r.Host] {
+			peak[r.Host] = inFlight[r.Host]
+		}
+		mu.Unlock()
+
+		time.Sleep(50 * time.Millisecond)
+
+		mu.Lock()
+		inFlight[r.Host]--
+		mu.Unlock()
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	// The same server under two names, so each name gets its own limit
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	var urls []string
+	for i := 0; i < 6; i++ {
+		urls = append(urls,
+			fmt.Sprintf("http://localhost:%s/a%d.js", port, i),
+			fmt.Sprintf("http://127.0.0.1:%s/b%d.js", port, i))
+	}
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 8)
+	dl.SetSkipHeadRequest(true)
+	dl.SetPerHostLimit(2)
+	for _, r := range dl.DownloadAll(context.Background(), urls) {
+		if len(r.Failures) > 0 {
+			t.Fatalf("%s failed: %+v", r.URL, r.Failures)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(peak) != 2 {
+		t.Fatalf("requests seen for hosts %v, want 2 hosts", peak)
+	}
+	for host, n := range peak {
+		if n > 2 {
+			t.Errorf("%s had %d requests in flight, want at most 2", host, n)
+		}
+	}
+}
+
+func TestDownloader_PerHostLimitCancelled(t *testing.T) {
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+	dl.SetPerHostLimit(1)
+
+	// Hold the only slot, then cancel the job waiting for it
+	if err := dl.hostSlots.acquire(context.Background(), "example.com"); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	result := dl.processJob(ctx, Job{URL: "http://example.com/app.js"})
+	if len(result.Failures) != 1 || result.Failures[0].Category != models.ErrorCategoryCancelled {
+		t.Errorf("processJob() failures = %+v, want one cancelled failure", result.Failures)
+	}
+}
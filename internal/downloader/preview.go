@@ -0,0 +1,38 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// SetPreviewBytes makes GET downloads fetch only the first n bytes: requests
+// carry "Range: bytes=0-(n-1)", and servers that ignore it have their full
+// response cut off after n bytes. 0 downloads whole files.
+func (c *HTTPClient) SetPreviewBytes(n int64) {
+	c.previewBytes = n
+}
+
+// applyRange asks for the preview prefix on GET requests when previews are on,
+// reporting whether it did
+func (c *HTTPClient) applyRange(req *http.Request) bool {
+	if c.previewBytes <= 0 || req.Method != http.MethodGet {
+		return false
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", c.previewBytes-1))
+	return true
+}
+
+// bodyLimit returns how much of a response body is read, and whether
+// reaching that much is fine (a preview) rather than a size error
+func (c *HTTPClient) bodyLimit(preview bool) (int64, bool) {
+	if preview && c.previewBytes < c.maxSize {
+		return c.previewBytes, true
+	}
+	return c.maxSize, preview
+}
+
+// isPartial reports whether a download of this many bytes may have been cut
+// short by the preview limit. A file exactly that long counts as partial.
+func (c *HTTPClient) isPartial(bytes int64) bool {
+	return c.previewBytes > 0 && bytes >= c.previewBytes
+}
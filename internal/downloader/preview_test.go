@@ -0,0 +1,79 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_PreviewBytes(t *testing.T) {
+	large := bytes.Repeat([]byte("0123456789"), 100000) // 1MB
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch r.URL.Path {
+		case "/ranged.bin":
+			http.ServeContent(w, r, "ranged.bin", time.Time{}, bytes.NewReader(large))
+		case "/norange.bin":
+			// Ignores Range and sends everything
+			w.Write(large)
+		case "/small.txt":
+			http.ServeContent(w, r, "small.txt", time.Time{}, strings.NewReader("tiny"))
+		case "/empty.txt":
+			http.ServeContent(w, r, "empty.txt", time.Time{}, strings.NewReader(""))
+		}
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetPreviewBytes(4096)
+	outputDir := t.TempDir()
+	dl := New(client, storage.NewFileStorage(outputDir, "flat"), 1)
+	dl.SetSkipHeadRequest(true)
+
+	tests := []struct {
+		path        string
+		wantSize    int
+		wantPartial bool
+	}{
+		{"/ranged.bin", 4096, true},
+		{"/norange.bin", 4096, true},
+		{"/small.txt", 4, false},
+		{"/empty.txt", 0, false},
+	}
+
+	for _, tt := range tests {
+		results := dl.DownloadAll(context.Background(), []string{server.URL + tt.path})
+		r := results[0]
+		if !r.IsSuccess() {
+			t.Errorf("%s: download failed: %v", tt.path, r.Errors)
+			continue
+		}
+		data, err := os.ReadFile(r.Downloaded[0])
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if len(data) != tt.wantSize {
+			t.Errorf("%s: stored %d bytes, want %d", tt.path, len(data), tt.wantSize)
+		}
+		if !bytes.Equal(data, large[:len(data)]) && tt.wantSize == 4096 {
+			t.Errorf("%s: stored bytes are not the start of the file", tt.path)
+		}
+		if r.Partial != tt.wantPartial {
+			t.Errorf("%s: Partial = %v, want %v", tt.path, r.Partial, tt.wantPartial)
+		}
+	}
+
+	for _, got := range ranges {
+		if got != "bytes=0-4095" {
+			t.Errorf("request Range = %q, want bytes=0-4095", got)
+		}
+	}
+}
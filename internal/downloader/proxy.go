@@ -0,0 +1,235 @@
+package downloader
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ProxyRotation selects how requests are spread over a proxy pool
+type ProxyRotation string
+
+const (
+	ProxyRoundRobin ProxyRotation = "round-robin" // Each request goes to the next proxy
+	ProxyPerHost    ProxyRotation = "per-host"    // All requests to a host go through the same proxy
+)
+
+// DefaultProxyCooldown is how long a proxy that failed to connect is skipped
+const DefaultProxyCooldown = 30 * time.Second
+
+// ParseProxyRotation parses a --proxy-rotation value; "" means round-robin
+func ParseProxyRotation(s string) (ProxyRotation, error) {
+	switch r := ProxyRotation(strings.ToLower(strings.TrimSpace(s))); r {
+	case "":
+		return ProxyRoundRobin, nil
+	case ProxyRoundRobin, ProxyPerHost:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown proxy rotation: %s (valid: round-robin, per-host)", s)
+}
+
+// LoadProxies reads proxy URLs (http, https or socks5) from a file, one per
+// line. Blank lines and lines starting with # are ignored.
+func LoadProxies(path string) ([]*url.URL, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open proxies file: %w", err)
+	}
+	defer file.Close()
+
+	var proxies []*url.URL
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		proxy, err := url.Parse(line)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("line %d: invalid proxy URL: %q", lineNum, line)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5":
+		default:
+			return nil, fmt.Errorf("line %d: unsupported proxy scheme %q (use http, https or socks5)", lineNum, proxy.Scheme)
+		}
+		proxies = append(proxies, proxy)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read proxies file: %w", err)
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("no proxies in %s", path)
+	}
+	return proxies, nil
+}
+
+// ProxyPool hands out proxies for requests, temporarily skipping proxies
+// that failed to connect
+type ProxyPool struct {
+	proxies  []*url.URL
+	rotation ProxyRotation
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	next      int            // Round-robin position
+	byHost    map[string]int // Per-host assignments
+	downUntil []time.Time    // When each failed proxy may be used again
+}
+
+// NewProxyPool creates a pool over proxies. cooldown <= 0 uses DefaultProxyCooldown.
+func NewProxyPool(proxies []*url.URL, rotation ProxyRotation, cooldown time.Duration) *ProxyPool {
+	if cooldown <= 0 {
+		cooldown = DefaultProxyCooldown
+	}
+	return &ProxyPool{
+		proxies:   proxies,
+		rotation:  rotation,
+		cooldown:  cooldown,
+		now:       time.Now,
+		byHost:    make(map[string]int),
+		downUntil: make([]time.Time, len(proxies)),
+	}
+}
+
+// pick returns the index of the proxy for a request to host
+func (p *ProxyPool) pick(host string) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	if p.rotation == ProxyPerHost {
+		if i, ok := p.byHost[host]; ok && !now.Before(p.downUntil[i]) {
+			return i
+		}
+	}
+
+	// The next proxy that is up; if all are down, the one back soonest
+	best := -1
+	for n := 0; n < len(p.proxies); n++ {
+		i := (p.next + n) % len(p.proxies)
+		if !now.Before(p.downUntil[i]) {
+			best = i
+			break
+		}
+		if best == -1 || p.downUntil[i].Before(p.downUntil[best]) {
+			best = i
+		}
+	}
+	p.next = (best + 1) % len(p.proxies)
+	if p.rotation == ProxyPerHost {
+		p.byHost[host] = best
+	}
+	return best
+}
+
+// markFailed skips proxy i until its cooldown has passed
+func (p *ProxyPool) markFailed(i int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.downUntil[i] = p.now().Add(p.cooldown)
+}
+
+// proxyKey is the context key carrying the proxy index chosen for a request
+type proxyKey struct{}
+
+// proxyTransport picks a proxy from the pool for every request
+type proxyTransport struct {
+	base *http.Transport // Its Proxy func reads the chosen proxy from the request context
+	pool *ProxyPool
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *proxyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	i := t.pool.pick(req.URL.Host)
+	resp, err := t.base.RoundTrip(req.WithContext(context.WithValue(req.Context(), proxyKey{}, i)))
+	if err != nil && req.Context().Err() == nil && !errors.Is(err, context.DeadlineExceeded) && isProxyFailure(err) {
+		// The proxy itself failed: let the retry use another one
+		t.pool.markFailed(i)
+	}
+	return resp, err
+}
+
+// ProxyConnectError is returned when a proxy refuses to open a tunnel (CONNECT)
+// to the target
+type ProxyConnectError struct {
+	Proxy  string // Proxy URL, password redacted
+	Status string
+}
+
+func (e *ProxyConnectError) Error() string {
+	return fmt.Sprintf("proxy %s refused CONNECT: %s", e.Proxy, e.Status)
+}
+
+// checkConnect is the base transport's OnProxyConnectResponse
+func checkConnect(ctx context.Context, proxy *url.URL, req *http.Request, resp *http.Response) error {
+	if resp.StatusCode != http.StatusOK {
+		return &ProxyConnectError{Proxy: proxy.Redacted(), Status: resp.Status}
+	}
+	return nil
+}
+
+// isProxyFailure reports whether err means the proxy could not be reached or
+// refused to connect to the target, rather than a failure of the target
+// behind it (a reset, a TLS error, ...)
+func isProxyFailure(err error) bool {
+	var connectErr *ProxyConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	// net/http reports failures to dial or set up the proxy as "proxyconnect",
+	// a SOCKS5 proxy that cannot reach the target as "socks connect"
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		switch opErr.Op {
+		case "dial", "proxyconnect", "socks connect":
+			return true
+		}
+	}
+	return false
+}
+
+// proxyFor is the base transport's Proxy func
+func (t *proxyTransport) proxyFor(req *http.Request) (*url.URL, error) {
+	i, ok := req.Context().Value(proxyKey{}).(int)
+	if !ok {
+		return nil, nil
+	}
+	return t.pool.proxies[i], nil
+}
+
+// SetProxies sends every request (GET and HEAD) through a proxy from pool.
+// Call it after SetTLSOptions and SetDNSCache, which configure the transport
+// it wraps. Proxies replace HTTP_PROXY and the other proxy environment variables.
+// Proxies only carry TCP, so after SetHTTP3 requests go through the proxied
+// HTTP/2 and HTTP/1.1 fallback and HTTP/3 is no longer tried.
+func (c *HTTPClient) SetProxies(pool *ProxyPool) {
+	var base *http.Transport
+	switch t := c.client.Transport.(type) {
+	case *http.Transport:
+		base = t
+	case *fallbackTransport:
+		if fallback, ok := t.fallback.(*http.Transport); ok && fallback != http.DefaultTransport {
+			base = fallback
+		}
+	}
+	if base == nil {
+		base = http.DefaultTransport.(*http.Transport).Clone()
+	}
+	t := &proxyTransport{base: base, pool: pool}
+	base.Proxy = t.proxyFor
+	base.OnProxyConnectResponse = checkConnect
+	c.client.Transport = t
+}
@@ -0,0 +1,69 @@
+package downloader
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxRetryAfter caps the wait a Retry-After header can impose on one retry
+const maxRetryAfter = 5 * time.Minute
+
+const (
+	retryBaseDelay = time.Second      // Backoff before the first retry
+	maxRetryDelay  = 30 * time.Second // Cap on the backoff, which doubles per retry
+)
+
+// parseRetryAfter reads a Retry-After value, either delay-seconds or an
+// HTTP-date, as a delay from now. It returns 0 if the value is missing or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	var delay time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(value); err == nil {
+		delay = at.Sub(now)
+	}
+	if delay < 0 {
+		return 0
+	}
+	if delay > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return delay
+}
+
+// retryAfter returns the delay a 429 or 503 response asks for, if any
+func retryAfter(resp *http.Response) time.Duration {
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0
+	}
+	return parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+}
+
+// isRateLimited checks if the server answered 429 Too Many Requests, the one
+// client error worth retrying
+func isRateLimited(err error) bool {
+	var httpErr *HTTPError
+	return errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusTooManyRequests
+}
+
+// retryDelay returns how long to wait before retry number attempt (from 1):
+// the delay the server asked for with Retry-After, or an exponential backoff
+// of 1s, 2s, 4s... capped at maxRetryDelay
+func retryDelay(attempt int, lastErr error) time.Duration {
+	var httpErr *HTTPError
+	if errors.As(lastErr, &httpErr) && httpErr.RetryAfter > 0 {
+		return httpErr.RetryAfter
+	}
+	delay := retryBaseDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryDelay)
+}
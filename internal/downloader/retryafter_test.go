@@ -0,0 +1,105 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"3", 3 * time.Second},
+		{" 0 ", 0},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"86400", maxRetryAfter},
+		{"-5", 0},
+		{"soon", 0},
+		{"", 0},
+	}
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempt int
+		lastErr error
+		want    time.Duration
+	}{
+		{1, nil, time.Second},
+		{2, nil, 2 * time.Second},
+		{3, errors.New("connection reset"), 4 * time.Second},
+		{5, nil, 16 * time.Second},
+		{6, nil, maxRetryDelay},
+		{100, nil, maxRetryDelay},
+		{4, &HTTPError{StatusCode: http.StatusTooManyRequests, RetryAfter: 3 * time.Second}, 3 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := retryDelay(tt.attempt, tt.lastErr); got != tt.want {
+			t.Errorf("retryDelay(%d, %v) = %v, want %v", tt.attempt, tt.lastErr, got, tt.want)
+		}
+	}
+}
+
+func TestHTTPClient_RetriesTooManyRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 2)
+	var buf bytes.Buffer
+	start := time.Now()
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+
+	// The fixed backoff would have retried after 1s
+	if elapsed := time.Since(start); elapsed < 2*time.Second {
+		t.Errorf("retried after %v, want the 2s asked by Retry-After", elapsed)
+	}
+	if requests != 2 || buf.String() != "ok" {
+		t.Errorf("requests = %d, body = %q; want 2, \"ok\"", requests, buf.String())
+	}
+}
+
+func TestHTTPClient_RetryAfterCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	client := NewHTTPClient(5*time.Second, 3)
+	var buf bytes.Buffer
+	start := time.Now()
+	_, err := client.DownloadToWriter(ctx, server.URL, &buf)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("DownloadToWriter() error = %v, want the context's", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("waited %v despite the cancelled context", elapsed)
+	}
+}
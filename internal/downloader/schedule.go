@@ -0,0 +1,101 @@
+package downloader
+
+import (
+	"context"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/parser"
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+)
+
+// hostQueue holds the jobs waiting for a token of one limiter
+type hostQueue struct {
+	limiter *ratelimit.Limiter
+	jobs    []Job
+	since   time.Time // When the first job started waiting
+}
+
+// feedByHost sends the jobs in list to the workers, each once it has taken a
+// token from its host's limiter. A job whose host is out of tokens waits in
+// that host's queue while the jobs of other hosts go ahead, so a throttled
+// host never holds up workers that could serve the others. Once ctx is done
+// the waiting and remaining jobs are sent without tokens, for the workers to
+// turn into cancelled results.
+func feedByHost(ctx context.Context, list []Job, limiter *ratelimit.HostLimiter, jobs chan<- Job) {
+	var queues []*hostQueue
+	byLimiter := make(map[*ratelimit.Limiter]*hostQueue)
+	next := 0
+
+	for ctx.Err() == nil {
+		// Waiting jobs go first, whenever their host has a token again
+		sent := false
+		wait := time.Duration(-1)
+		for _, q := range queues {
+			if len(q.jobs) == 0 {
+				continue
+			}
+			ok, w := q.limiter.TryAcquire()
+			if !ok {
+				if wait < 0 || w < wait {
+					wait = w
+				}
+				continue
+			}
+			q.limiter.RecordWait(q.since)
+			jobs <- q.jobs[0]
+			q.jobs = q.jobs[1:]
+			q.since = time.Now()
+			sent = true
+		}
+
+		if next < len(list) {
+			job := list[next]
+			job.Index = next
+			next++
+
+			l := limiter.For(parser.HostnameFromURL(job.URL))
+			if l == nil {
+				jobs <- job
+				continue
+			}
+			q := byLimiter[l]
+			if q == nil {
+				q = &hostQueue{limiter: l}
+				byLimiter[l] = q
+				queues = append(queues, q)
+			}
+			// Jobs of a host keep their order: only the first may skip the queue
+			if len(q.jobs) == 0 {
+				if ok, _ := l.TryAcquire(); ok {
+					jobs <- job
+					continue
+				}
+				q.since = time.Now()
+			}
+			q.jobs = append(q.jobs, job)
+			continue
+		}
+
+		if sent {
+			continue
+		}
+		if wait < 0 {
+			return // Every job was sent
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+
+	for _, q := range queues {
+		for _, job := range q.jobs {
+			jobs <- job
+		}
+	}
+	for ; next < len(list); next++ {
+		job := list[next]
+		job.Index = next
+		jobs <- job
+	}
+}
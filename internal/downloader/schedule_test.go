@@ -0,0 +1,62 @@
+package downloader
+
+import (
+	"context"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_ThrottledHostDoesNotBlockOthers(t *testing.T) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	// The same server under two host names: "localhost" allows one request
+	// an hour, 127.0.0.1 is not limited
+	slow := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	limiter := ratelimit.NewHostLimiter(nil, map[string]*ratelimit.Limiter{
+		"localhost": ratelimit.NewLimiter(1, time.Hour),
+	})
+	urls := []string{slow + "/a.js", slow + "/b.js", server.URL + "/c.js", server.URL + "/d.js"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// One worker: with a blocking wait, b.js would hold it for an hour
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 1)
+	var mu sync.Mutex
+	byURL := make(map[string]*models.DownloadResult)
+	dl.DownloadStream(ctx, urls, limiter, nil, func(r *models.DownloadResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		byURL[r.URL] = r
+		// Everything but b.js is done: give up on it
+		if len(byURL) == 3 {
+			cancel()
+		}
+	})
+
+	for _, u := range []string{urls[0], urls[2], urls[3]} {
+		if r := byURL[u]; r == nil || !r.IsSuccess() {
+			t.Errorf("%s: result %+v, want a success", u, r)
+		}
+	}
+	if r := byURL[urls[1]]; r == nil || len(r.Failures) == 0 || r.Failures[0].Category != models.ErrorCategoryCancelled {
+		t.Errorf("%s: result %+v, want it cancelled while waiting for its host", urls[1], r)
+	}
+}
@@ -0,0 +1,74 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ScopeChecker decides whether a URL may be contacted at all
+type ScopeChecker interface {
+	InScope(ctx context.Context, rawURL string) (bool, string)
+}
+
+// DialScope is a ScopeChecker that can also vet each address dialed
+type DialScope interface {
+	ScopeChecker
+
+	// CheckAddr returns an error if the "ip:port" address may not be connected to
+	CheckAddr(address string) error
+}
+
+// SetScope makes the client enforce s on the connections it opens: every
+// address is checked with CheckAddr just before connecting, so the IP really
+// dialed is vetted rather than an earlier lookup of the host, and redirects
+// are checked with InScope before they are followed. Call it after
+// SetTLSOptions, which replaces the transport. QUIC (--http3) dials on its
+// own and is not covered.
+func (c *HTTPClient) SetScope(s DialScope) {
+	c.scope = s
+	c.dialControl = func(network, address string, _ syscall.RawConn) error {
+		return s.CheckAddr(address)
+	}
+	if c.dns != nil {
+		c.dns.dialer.Control = c.dialControl
+		return
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: c.dialControl}
+	c.setDialContext(dialer.DialContext)
+}
+
+// checkRedirect is the client's CheckRedirect: it stops redirect loops and
+// redirects out of scope, and signs each redirected request again for its
+// own URL
+func (c *HTTPClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if c.scope != nil {
+		if ok, reason := c.scope.InScope(req.Context(), req.URL.String()); !ok {
+			return fmt.Errorf("redirect to %s out of scope: %s", req.URL.Redacted(), reason)
+		}
+	}
+	return c.sign(req)
+}
+
+// setDialContext makes the transport (or the fallback of an HTTP/3
+// transport) open connections with dial
+func (c *HTTPClient) setDialContext(dial func(ctx context.Context, network, addr string) (net.Conn, error)) {
+	switch t := c.client.Transport.(type) {
+	case nil:
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.DialContext = dial
+		c.client.Transport = transport
+	case *http.Transport:
+		t.DialContext = dial
+	case *fallbackTransport:
+		if fallback, ok := t.fallback.(*http.Transport); ok {
+			fallback.DialContext = dial
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package downloader
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/scope"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_ScopeSkipsWithoutConnecting(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	// Test server listens on 127.0.0.1, which is outside 10.0.0.0/8
+	networks, _ := scope.ParseCIDRs("10.0.0.0/8")
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 1)
+	dl.SetScope(scope.NewCIDRScope(networks, nil))
+
+	results := dl.DownloadAll(context.Background(), []string{server.URL + "/app.js"})
+	if len(results) != 1 {
+		t.Fatalf("DownloadAll() returned %d results, want 1", len(results))
+	}
+	if len(results[0].Failures) != 1 || results[0].Failures[0].Category != models.ErrorCategoryScope {
+		t.Errorf("Failures = %+v, want one %q failure", results[0].Failures, models.ErrorCategoryScope)
+	}
+	if atomic.LoadInt32(&hits) != 0 {
+		t.Errorf("server received %d requests for an out-of-scope URL", hits)
+	}
+
+	// Loopback in scope: download goes through
+	networks, _ = scope.ParseCIDRs("127.0.0.0/8")
+	dl.SetScope(scope.NewCIDRScope(networks, nil))
+	results = dl.DownloadAll(context.Background(), []string{server.URL + "/app.js"})
+	if !results[0].IsSuccess() {
+		t.Errorf("in-scope download failed: %v", results[0].Errors)
+	}
+}
+
+// rebindScope passes every URL but refuses to dial the listed addresses, as
+// if the host resolved elsewhere when checked than when connected to
+type rebindScope struct {
+	refuse     string
+	redirectTo string
+}
+
+func (s rebindScope) InScope(ctx context.Context, rawURL string) (bool, string) {
+	if s.redirectTo != "" && strings.HasPrefix(rawURL, s.redirectTo) {
+		return false, "redirect target out of scope"
+	}
+	return true, ""
+}
+
+func (s rebindScope) CheckAddr(address string) error {
+	if address == s.refuse {
+		return fmt.Errorf("connection to %s refused", address)
+	}
+	return nil
+}
+
+func TestHTTPClient_ScopeChecksDialedAddress(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	for _, dns := range []bool{false, true} {
+		client := NewHTTPClient(5*time.Second, 0)
+		if dns {
+			client.SetDNSCache(NewDNSCache(nil, time.Minute, 0))
+		}
+		client.SetScope(rebindScope{refuse: server.Listener.Addr().String()})
+
+		if _, err := client.Download(context.Background(), server.URL+"/app.js"); err == nil || !strings.Contains(err.Error(), "refused") {
+			t.Errorf("dns=%v: Download() error = %v, want the dial refused", dns, err)
+		}
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("server received %d requests over a refused connection", n)
+	}
+}
+
+func TestHTTPClient_ScopeChecksRedirects(t *testing.T) {
+	var hits int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Write([]byte("internal"))
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL+"/secret", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetScope(rebindScope{redirectTo: target.URL})
+	if _, err := client.Download(context.Background(), server.URL+"/app.js"); err == nil || !strings.Contains(err.Error(), "out of scope") {
+		t.Errorf("Download() error = %v, want the redirect refused", err)
+	}
+	if n := atomic.LoadInt32(&hits); n != 0 {
+		t.Errorf("out-of-scope redirect target received %d requests", n)
+	}
+}
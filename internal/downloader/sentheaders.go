@@ -0,0 +1,96 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// redacted replaces the value of a sensitive header in reports
+const redacted = "[REDACTED]"
+
+// sensitiveHeaderWords mark header names whose values are credentials
+var sensitiveHeaderWords = []string{"auth", "token", "key", "secret", "session", "password", "passwd", "signature", "credential", "csrf", "xsrf"}
+
+// sentHeadersKey is the context key for a sentHeaders recorder
+type sentHeadersKey struct{}
+
+// sentHeaders records the headers of the last request sent with its context
+type sentHeaders struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+// recordSentHeaders returns a context whose requests record their headers
+// in the returned recorder
+func recordSentHeaders(ctx context.Context) (context.Context, *sentHeaders) {
+	rec := &sentHeaders{}
+	return context.WithValue(ctx, sentHeadersKey{}, rec), rec
+}
+
+// noteSentHeaders records req's headers if its context asks for it
+func noteSentHeaders(req *http.Request) {
+	rec, ok := req.Context().Value(sentHeadersKey{}).(*sentHeaders)
+	if !ok {
+		return
+	}
+	h := req.Header.Clone()
+	if req.Host != "" {
+		h.Set("Host", req.Host)
+	}
+	rec.mu.Lock()
+	rec.header = h
+	rec.mu.Unlock()
+}
+
+// redactedHeaders returns the recorded headers, one value per name, with
+// credential values replaced: names in secret (canonical form) and names
+// that look like credentials. Cookie keeps its cookie names.
+func (r *sentHeaders) redactedHeaders(secret map[string]bool) map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.header) == 0 {
+		return nil
+	}
+
+	out := make(map[string]string, len(r.header))
+	for name, values := range r.header {
+		switch {
+		case name == "Cookie":
+			out[name] = redactCookies(values)
+		case secret[name] || isSensitiveHeader(name):
+			out[name] = redacted
+		default:
+			out[name] = strings.Join(values, ", ")
+		}
+	}
+	return out
+}
+
+// isSensitiveHeader reports whether a header name suggests its value is a credential
+func isSensitiveHeader(name string) bool {
+	lower := strings.ToLower(name)
+	for _, word := range sensitiveHeaderWords {
+		if strings.Contains(lower, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactCookies keeps the names of Cookie header values, hiding their values
+func redactCookies(values []string) string {
+	var names []string
+	for _, v := range values {
+		for _, pair := range strings.Split(v, ";") {
+			name, _, _ := strings.Cut(strings.TrimSpace(pair), "=")
+			if name != "" {
+				names = append(names, name+"="+redacted)
+			}
+		}
+	}
+	sort.Strings(names)
+	return strings.Join(names, "; ")
+}
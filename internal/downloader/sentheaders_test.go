@@ -0,0 +1,132 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+func TestDownloader_ReportRequestHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/denied.js" {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	provider, err := auth.NewProvider(auth.Config{
+		Type:    auth.AuthTypeBearer,
+		Token:   "s3cr3t-token",
+		Headers: map[string]string{"X-Tenant": "acme-private"},
+		Cookies: map[string]string{"session": "abc123"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	dl := New(NewHTTPClientWithAuth(5*time.Second, 0, provider), storage.NewInMemoryStorage("out", "flat"), 2)
+	dl.SetReportRequestHeaders(true)
+	jobs := []Job{
+		{URL: server.URL + "/app.js", Headers: http.Header{"X-Api-Key": {"k-123"}, "X-Trace": {"trace-1"}}},
+		{URL: server.URL + "/denied.js"},
+	}
+	for _, r := range dl.DownloadJobs(context.Background(), jobs, nil, nil) {
+		h := r.RequestHeaders
+		if h == nil {
+			t.Fatalf("%s: no request headers recorded", r.URL)
+		}
+		if r.AuthType != "bearer" {
+			t.Errorf("%s: AuthType = %q, want bearer", r.URL, r.AuthType)
+		}
+		for _, name := range []string{"Authorization", "X-Tenant"} {
+			if h[name] != redacted {
+				t.Errorf("%s: %s = %q, want %q", r.URL, name, h[name], redacted)
+			}
+		}
+		if h["Cookie"] != "session="+redacted {
+			t.Errorf("%s: Cookie = %q, want the name with its value redacted", r.URL, h["Cookie"])
+		}
+		if h["User-Agent"] != DefaultUserAgent {
+			t.Errorf("%s: User-Agent = %q, want %q", r.URL, h["User-Agent"], DefaultUserAgent)
+		}
+		for name, value := range h {
+			for _, secret := range []string{"s3cr3t", "acme-private", "abc123", "k-123"} {
+				if strings.Contains(value, secret) {
+					t.Errorf("%s: %s leaks %q", r.URL, name, secret)
+				}
+			}
+		}
+
+		if strings.HasSuffix(r.URL, "/app.js") {
+			if h["X-Api-Key"] != redacted || h["X-Trace"] != "trace-1" {
+				t.Errorf("per-URL headers = X-Api-Key %q, X-Trace %q; want redacted, trace-1", h["X-Api-Key"], h["X-Trace"])
+			}
+		}
+	}
+}
+
+func TestDownloader_ReportRequestHeadersOff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	dl := New(NewHTTPClient(5*time.Second, 0), storage.NewInMemoryStorage("out", "flat"), 1)
+	r := dl.DownloadAll(context.Background(), []string{server.URL + "/app.js"})[0]
+	if r.RequestHeaders != nil || r.AuthType != "" {
+		t.Errorf("RequestHeaders = %v, AuthType = %q; want nothing recorded", r.RequestHeaders, r.AuthType)
+	}
+}
+
+func TestHTTPClient_HostAuth(t *testing.T) {
+	var mu sync.Mutex
+	got := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got[strings.Split(r.Host, ":")[0]] = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	global, err := auth.NewProvider(auth.Config{Type: auth.AuthTypeBearer, Token: "global"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	local, err := auth.NewProvider(auth.Config{Type: auth.AuthTypeBearer, Token: "local"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewHTTPClientWithAuth(5*time.Second, 0, global)
+	client.SetHostAuth(auth.NewHostProviders(map[string]*auth.Provider{"localhost": local}))
+
+	// The same server under two names: only "localhost" has its own credentials
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+	dl := New(client, storage.NewInMemoryStorage("out", "flat"), 1)
+	dl.SetReportRequestHeaders(true)
+	for _, r := range dl.DownloadAll(context.Background(), []string{
+		"http://localhost:" + port + "/a.js",
+		"http://127.0.0.1:" + port + "/b.js",
+	}) {
+		if len(r.Failures) > 0 {
+			t.Fatalf("%s failed: %+v", r.URL, r.Failures)
+		}
+		if r.AuthType != "bearer" {
+			t.Errorf("%s: AuthType = %q, want bearer", r.URL, r.AuthType)
+		}
+	}
+
+	if got["localhost"] != "Bearer local" || got["127.0.0.1"] != "Bearer global" {
+		t.Errorf("Authorization by host = %v, want the per-host token for localhost only", got)
+	}
+}
@@ -0,0 +1,99 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPClient_HMACSigner(t *testing.T) {
+	var got http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	signer := NewHMACSigner("s3cret", "X-Api-Signature", `{method}\n{path}?{query}\n{timestamp}`)
+	signer.Now = func() time.Time { return time.Unix(1700000000, 0) }
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetSigner(signer)
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL+"/v1/files/app.js?id=7", &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte("GET\n/v1/files/app.js?id=7\n1700000000"))
+	want := hex.EncodeToString(mac.Sum(nil))
+	if sig := got.Get("X-Api-Signature"); sig != want {
+		t.Errorf("X-Api-Signature = %q, want %q", sig, want)
+	}
+	if ts := got.Get(DefaultTimestampHeader); ts != "1700000000" {
+		t.Errorf("%s = %q, want 1700000000", DefaultTimestampHeader, ts)
+	}
+}
+
+func TestHTTPClient_SignerError(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetSigner(RequestSignerFunc(func(req *http.Request) error {
+		return errors.New("no key")
+	}))
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err == nil {
+		t.Error("DownloadToWriter() expected an error from the signer")
+	}
+	if requests != 0 {
+		t.Errorf("server got %d requests, want none unsigned", requests)
+	}
+}
+
+func TestHTTPClient_SignerSeesRangeAndRedirects(t *testing.T) {
+	signed := make(map[string]string) // Path -> Range header seen by the signer
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.Path+" "+r.Header.Get("X-Signed-Path"))
+		if r.URL.Path == "/old.js" {
+			http.Redirect(w, r, "/new.js", http.StatusFound)
+			return
+		}
+		w.Write([]byte("console.log(1)"))
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(5*time.Second, 0)
+	client.SetPreviewBytes(4)
+	client.SetSigner(RequestSignerFunc(func(req *http.Request) error {
+		signed[req.URL.Path] = req.Header.Get("Range")
+		req.Header.Set("X-Signed-Path", req.URL.Path)
+		return nil
+	}))
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL+"/old.js", &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+
+	for _, path := range []string{"/old.js", "/new.js"} {
+		if rng, ok := signed[path]; !ok || rng != "bytes=0-3" {
+			t.Errorf("signer saw %s with Range %q (signed: %v), want bytes=0-3", path, rng, ok)
+		}
+	}
+	want := []string{"/old.js /old.js", "/new.js /new.js"}
+	if len(received) != len(want) || received[0] != want[0] || received[1] != want[1] {
+		t.Errorf("server received %v, want %v", received, want)
+	}
+}
@@ -0,0 +1,143 @@
+package downloader
+
+import (
+	"errors"
+	"io"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// errRestart aborts a partial save so the download can start over
+var errRestart = errors.New("download restarted")
+
+// saveResult is the outcome of a storage save running behind a pipe
+type saveResult struct {
+	path  string
+	bytes int64
+	err   error
+}
+
+// streamSink pipes downloaded data into storage. It implements
+// ResettableWriter: Reset discards the partial file and starts a fresh save,
+// so an interrupted download can be retried without corrupting the output.
+type streamSink struct {
+	storage  storage.Storage
+	host     string
+	urlPath  string
+	filename string
+
+	pw   *io.PipeWriter
+	done chan saveResult
+}
+
+// newStreamSink creates a sink and starts saving from it
+func newStreamSink(s storage.Storage, host, urlPath, filename string) *streamSink {
+	sink := &streamSink{
+		storage:  s,
+		host:     host,
+		urlPath:  urlPath,
+		filename: filename,
+	}
+	sink.start()
+	return sink
+}
+
+// start opens a new pipe and saves from it in the background
+func (s *streamSink) start() {
+	pr, pw := io.Pipe()
+	done := make(chan saveResult, 1)
+
+	go func() {
+		path, n, err := s.storage.SaveFileFromReader(s.host, s.urlPath, s.filename, pr)
+		// Unblock the writer if storage gave up early
+		pr.CloseWithError(err)
+		done <- saveResult{path: path, bytes: n, err: err}
+	}()
+
+	s.pw = pw
+	s.done = done
+}
+
+// Write passes data on to storage
+func (s *streamSink) Write(p []byte) (int, error) {
+	return s.pw.Write(p)
+}
+
+// Reset aborts the current save, letting storage remove the partial file, and starts a new one
+func (s *streamSink) Reset() error {
+	s.pw.CloseWithError(errRestart)
+	if res := <-s.done; res.err == nil {
+		// Storage finished before noticing the abort: the file is complete but stale
+		return errors.New("cannot restart a save that already completed")
+	}
+	s.start()
+	return nil
+}
+
+// Close finishes the save and returns its result. A non-nil downloadErr
+// aborts the save instead.
+func (s *streamSink) Close(downloadErr error) saveResult {
+	if downloadErr != nil {
+		s.pw.CloseWithError(downloadErr)
+	} else {
+		s.pw.Close()
+	}
+	return <-s.done
+}
+
+// saveSink is where a download is written: Close finishes the save, or
+// aborts it if downloadErr is set
+type saveSink interface {
+	ResettableWriter
+	Close(downloadErr error) saveResult
+}
+
+// sizeGate holds a body in memory until it reaches min bytes and only then
+// starts saving it, so bodies below the minimum never become files. Servers
+// often omit Content-Length, leaving the size unknown until the body ends.
+type sizeGate struct {
+	min    int64
+	buf    []byte
+	open   func() *streamSink
+	sink   *streamSink
+	reject func(size int64) error // Error for a complete body below min
+}
+
+// Write buffers data until the minimum is reached, then passes it through
+func (g *sizeGate) Write(p []byte) (int, error) {
+	if g.sink != nil {
+		return g.sink.Write(p)
+	}
+	g.buf = append(g.buf, p...)
+	if int64(len(g.buf)) < g.min {
+		return len(p), nil
+	}
+
+	g.sink = g.open()
+	buffered := g.buf
+	g.buf = nil
+	if _, err := g.sink.Write(buffered); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Reset discards everything written so far
+func (g *sizeGate) Reset() error {
+	g.buf = nil
+	if g.sink != nil {
+		return g.sink.Reset()
+	}
+	return nil
+}
+
+// Close finishes the save, or rejects a complete body that stayed below the minimum
+func (g *sizeGate) Close(downloadErr error) saveResult {
+	if g.sink != nil {
+		return g.sink.Close(downloadErr)
+	}
+	if downloadErr != nil {
+		return saveResult{err: downloadErr}
+	}
+	return saveResult{bytes: int64(len(g.buf)), err: g.reject(int64(len(g.buf)))}
+}
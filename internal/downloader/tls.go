@@ -0,0 +1,106 @@
+package downloader
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// TLSOptions restricts the TLS versions and cipher suites offered to servers,
+// and controls how their certificates are verified
+type TLSOptions struct {
+	MinVersion         uint16         // Lowest version to accept (0 = Go default, TLS 1.2)
+	MaxVersion         uint16         // Highest version to accept (0 = Go default, TLS 1.3)
+	CipherSuites       []uint16       // TLS 1.0-1.2 cipher suites; TLS 1.3 suites are not configurable
+	RootCAs            *x509.CertPool // Certificate authorities to trust (nil = system roots)
+	InsecureSkipVerify bool           // Accept any certificate, e.g. self-signed staging servers
+}
+
+// tlsVersions maps accepted version strings to tls.Version* constants
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2" or "TLS1.2".
+// An empty string returns 0, meaning the Go default.
+func ParseTLSVersion(s string) (uint16, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	name := strings.TrimPrefix(strings.ToLower(s), "tls")
+	name = strings.TrimPrefix(name, "v")
+	if version, ok := tlsVersions[name]; ok {
+		return version, nil
+	}
+	return 0, fmt.Errorf("unknown TLS version: %s (valid: 1.0, 1.1, 1.2, 1.3)", s)
+}
+
+// ParseCipherSuites parses a comma-separated list of cipher suite names
+// as printed by Go (e.g. "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256")
+func ParseCipherSuites(s string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+	for _, suite := range tls.InsecureCipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	var ids []uint16
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToUpper(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		id, ok := known[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown cipher suite: %s", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// LoadCACerts reads a PEM bundle and returns the system roots plus the
+// certificates in it, so private CAs are trusted alongside public ones
+func LoadCACerts(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// SetTLSOptions applies TLS version and cipher settings to every request (GET and HEAD)
+func (c *HTTPClient) SetTLSOptions(opts TLSOptions) error {
+	if opts.MinVersion != 0 && opts.MaxVersion != 0 && opts.MinVersion > opts.MaxVersion {
+		return fmt.Errorf("TLS min version is above max version")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         opts.MinVersion,
+		MaxVersion:         opts.MaxVersion,
+		CipherSuites:       opts.CipherSuites,
+		RootCAs:            opts.RootCAs,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	}
+	c.client.Transport = transport
+	return nil
+}
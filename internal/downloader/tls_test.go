@@ -0,0 +1,202 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tlsRecorder is a TLS server (offering TLS 1.2 and 1.3) that remembers the last negotiated state
+type tlsRecorder struct {
+	*httptest.Server
+	state tls.ConnectionState
+}
+
+func newTLSRecorder() *tlsRecorder {
+	rec := &tlsRecorder{}
+	rec.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.state = *r.TLS
+		w.Write([]byte("ok"))
+	}))
+	return rec
+}
+
+// trust makes the client accept the test server's certificate
+func (rec *tlsRecorder) trust(t *testing.T, c *HTTPClient) {
+	t.Helper()
+	pool := x509.NewCertPool()
+	pool.AddCert(rec.Certificate())
+	transport, ok := c.client.Transport.(*http.Transport)
+	if !ok {
+		t.Fatal("client has no custom transport")
+	}
+	transport.TLSClientConfig.RootCAs = pool
+}
+
+func TestHTTPClient_TLSVersion(t *testing.T) {
+	server := newTLSRecorder()
+	defer server.Close()
+
+	tests := []struct {
+		name string
+		opts TLSOptions
+		want uint16
+	}{
+		{"default negotiates 1.3", TLSOptions{}, tls.VersionTLS13},
+		{"forced 1.2", TLSOptions{MinVersion: tls.VersionTLS12, MaxVersion: tls.VersionTLS12}, tls.VersionTLS12},
+		{"max 1.2", TLSOptions{MaxVersion: tls.VersionTLS12}, tls.VersionTLS12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPClient(5*time.Second, 0)
+			if err := client.SetTLSOptions(tt.opts); err != nil {
+				t.Fatalf("SetTLSOptions() error = %v", err)
+			}
+			server.trust(t, client)
+
+			var buf bytes.Buffer
+			if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+				t.Fatalf("DownloadToWriter() error = %v", err)
+			}
+			if server.state.Version != tt.want {
+				t.Errorf("negotiated %s, want %s", tls.VersionName(server.state.Version), tls.VersionName(tt.want))
+			}
+		})
+	}
+}
+
+func TestHTTPClient_TLSCipherSuites(t *testing.T) {
+	server := newTLSRecorder()
+	defer server.Close()
+
+	want := tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetTLSOptions(TLSOptions{MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{want}}); err != nil {
+		t.Fatalf("SetTLSOptions() error = %v", err)
+	}
+	server.trust(t, client)
+
+	// HEAD goes through the same transport
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if server.state.CipherSuite != want {
+		t.Errorf("negotiated %s, want %s", tls.CipherSuiteName(server.state.CipherSuite), tls.CipherSuiteName(want))
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint16
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"TLS1.3", tls.VersionTLS13, false},
+		{"tlsv1.0", tls.VersionTLS10, false},
+		{"1.4", 0, true},
+		{"ssl3", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseTLSVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTLSVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseTLSVersion(%q) = %x, want %x", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	got, err := ParseCipherSuites("TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256, tls_ecdhe_rsa_with_aes_256_gcm_sha384")
+	if err != nil {
+		t.Fatalf("ParseCipherSuites() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 || got[1] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 {
+		t.Errorf("ParseCipherSuites() = %v", got)
+	}
+
+	if _, err := ParseCipherSuites("TLS_NOT_A_SUITE"); err == nil {
+		t.Error("ParseCipherSuites() expected error for unknown suite")
+	}
+
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetTLSOptions(TLSOptions{MinVersion: tls.VersionTLS13, MaxVersion: tls.VersionTLS12}); err == nil {
+		t.Error("SetTLSOptions() expected error for min above max")
+	}
+}
+
+func TestHTTPClient_TLSInsecure(t *testing.T) {
+	server := newTLSRecorder()
+	defer server.Close()
+
+	// The test certificate is self-signed, so verification fails by default
+	var buf bytes.Buffer
+	if _, err := NewHTTPClient(5*time.Second, 0).DownloadToWriter(context.Background(), server.URL, &buf); err == nil {
+		t.Fatal("DownloadToWriter() expected certificate error without --insecure")
+	}
+
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetTLSOptions(TLSOptions{InsecureSkipVerify: true}); err != nil {
+		t.Fatalf("SetTLSOptions() error = %v", err)
+	}
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	resp, err := client.Head(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestLoadCACerts(t *testing.T) {
+	server := newTLSRecorder()
+	defer server.Close()
+
+	dir := t.TempDir()
+	bundle := filepath.Join(dir, "ca.pem")
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(bundle, cert, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := LoadCACerts(bundle)
+	if err != nil {
+		t.Fatalf("LoadCACerts() error = %v", err)
+	}
+	client := NewHTTPClient(5*time.Second, 0)
+	if err := client.SetTLSOptions(TLSOptions{RootCAs: pool}); err != nil {
+		t.Fatalf("SetTLSOptions() error = %v", err)
+	}
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), server.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+
+	empty := filepath.Join(dir, "empty.pem")
+	if err := os.WriteFile(empty, []byte("not a certificate\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadCACerts(empty); err == nil {
+		t.Error("LoadCACerts() expected error for a file without certificates")
+	}
+	if _, err := LoadCACerts(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("LoadCACerts() expected error for a missing file")
+	}
+}
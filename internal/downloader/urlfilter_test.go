@@ -0,0 +1,48 @@
+package downloader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestDownloader_URLFilterSendsNoRequest(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte("data"))
+	}))
+	defer server.Close()
+
+	for _, skipHead := range []bool{false, true} {
+		requests.Store(0)
+		dl := New(NewHTTPClient(5*time.Second, 0), storage.NewFileStorage(t.TempDir(), "flat"), 2)
+		// A content-type rule makes the HEAD pre-check run for the URLs that pass
+		dl.SetFilter(filter.NewContentFilter(filter.FilterConfig{
+			ExcludeExt:  ".png",
+			ExcludeType: "image/*",
+		}))
+		dl.SetSkipHeadRequest(skipHead)
+
+		results := dl.DownloadAll(context.Background(), []string{
+			server.URL + "/logo.png",
+			server.URL + "/banner.PNG?v=2",
+		})
+
+		for _, r := range results {
+			if len(r.Failures) != 1 || r.Failures[0].Category != models.ErrorCategorySkipped {
+				t.Errorf("skipHead=%v: %s should be skipped, got %+v", skipHead, r.URL, r.Failures)
+			}
+		}
+		if n := requests.Load(); n != 0 {
+			t.Errorf("skipHead=%v: excluded extensions sent %d requests, want 0", skipHead, n)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package downloader
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// DefaultUserAgent is sent when no User-Agent is configured
+const DefaultUserAgent = "downurl/1.0"
+
+// BrowserUserAgent is a current desktop Chrome User-Agent, for servers that block unknown clients
+const BrowserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+
+// ParseUserAgent resolves a User-Agent setting: "" or "downurl" for
+// DefaultUserAgent, "browser" for BrowserUserAgent, or any other value as-is
+func ParseUserAgent(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "downurl":
+		return DefaultUserAgent
+	case "browser":
+		return BrowserUserAgent
+	}
+	return strings.TrimSpace(s)
+}
+
+// SetUserAgent sets the User-Agent sent by every request (GET and HEAD).
+// A User-Agent from custom headers or --user-agent still takes precedence.
+func (c *HTTPClient) SetUserAgent(ua string) {
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	c.userAgent = ua
+}
+
+// prepareRequest sets the headers every request carries: the User-Agent,
+// authentication, Accept and any headers attached to the request's context
+func (c *HTTPClient) prepareRequest(req *http.Request) error {
+	req.Header.Set("User-Agent", c.userAgent)
+
+	// Apply authentication if configured (its headers may replace the User-Agent)
+	if provider := c.authFor(req.URL.Hostname()); provider != nil {
+		if err := provider.ApplyAuth(req); err != nil {
+			return fmt.Errorf("failed to apply authentication: %w", err)
+		}
+	}
+	c.applyAccept(req)
+	applyContextHeaders(req)
+	return nil
+}
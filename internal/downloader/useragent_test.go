@@ -0,0 +1,86 @@
+package downloader
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/auth"
+)
+
+// uaRecorder remembers the User-Agent of each request method
+type uaRecorder struct {
+	*httptest.Server
+	mu     sync.Mutex
+	agents map[string]string
+}
+
+func newUARecorder() *uaRecorder {
+	rec := &uaRecorder{agents: make(map[string]string)}
+	rec.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec.mu.Lock()
+		rec.agents[r.Method] = r.Header.Get("User-Agent")
+		rec.mu.Unlock()
+		w.Write([]byte("ok"))
+	}))
+	return rec
+}
+
+// fetch sends a GET and a HEAD through client
+func (rec *uaRecorder) fetch(t *testing.T, client *HTTPClient) {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := client.DownloadToWriter(context.Background(), rec.URL, &buf); err != nil {
+		t.Fatalf("DownloadToWriter() error = %v", err)
+	}
+	resp, err := client.Head(context.Background(), rec.URL)
+	if err != nil {
+		t.Fatalf("Head() error = %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestHTTPClient_UserAgent(t *testing.T) {
+	server := newUARecorder()
+	defer server.Close()
+
+	overriding, err := auth.NewProvider(auth.Config{
+		Type:    auth.AuthTypeNone,
+		Headers: map[string]string{"User-Agent": "custom/2.0"},
+	})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		provider *auth.Provider
+		setting  string
+		want     string
+	}{
+		{"default", nil, "", DefaultUserAgent},
+		{"browser", nil, "browser", BrowserUserAgent},
+		{"literal", nil, "scanner/3.1", "scanner/3.1"},
+		{"header overrides default", overriding, "browser", "custom/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewHTTPClientWithAuth(5*time.Second, 0, tt.provider)
+			if tt.setting != "" {
+				client.SetUserAgent(ParseUserAgent(tt.setting))
+			}
+			server.fetch(t, client)
+
+			for _, method := range []string{http.MethodGet, http.MethodHead} {
+				if got := server.agents[method]; got != tt.want {
+					t.Errorf("%s User-Agent = %q, want %q", method, got, tt.want)
+				}
+			}
+		})
+	}
+}
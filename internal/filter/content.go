@@ -3,6 +3,8 @@ package filter
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"path"
 	"path/filepath"
 	"strings"
 )
@@ -16,25 +18,31 @@ type ContentFilter struct {
 	MinSize           int64
 	MaxSize           int64
 	SkipEmpty         bool
+	RequiredHeaders   []HeaderRule
+	SkippedHeaders    []HeaderRule
 }
 
 // FilterConfig represents filter configuration
 type FilterConfig struct {
-	FilterType     string // Comma-separated list of allowed types
-	ExcludeType    string // Comma-separated list of blocked types
-	FilterExt      string // Comma-separated list of allowed extensions
-	ExcludeExt     string // Comma-separated list of blocked extensions
-	MinSize        int64  // Minimum file size in bytes
-	MaxSize        int64  // Maximum file size in bytes
-	SkipEmpty      bool   // Skip empty files
+	FilterType     string       // Comma-separated list of allowed types
+	ExcludeType    string       // Comma-separated list of blocked types
+	FilterExt      string       // Comma-separated list of allowed extensions
+	ExcludeExt     string       // Comma-separated list of blocked extensions
+	MinSize        int64        // Minimum file size in bytes
+	MaxSize        int64        // Maximum file size in bytes
+	SkipEmpty      bool         // Skip empty files
+	RequireHeaders []HeaderRule // Headers that must be present (and match) to keep a response
+	SkipHeaders    []HeaderRule // Headers that cause a response to be skipped
 }
 
 // NewContentFilter creates a new content filter
 func NewContentFilter(cfg FilterConfig) *ContentFilter {
 	filter := &ContentFilter{
-		MinSize:   cfg.MinSize,
-		MaxSize:   cfg.MaxSize,
-		SkipEmpty: cfg.SkipEmpty,
+		MinSize:         cfg.MinSize,
+		MaxSize:         cfg.MaxSize,
+		SkipEmpty:       cfg.SkipEmpty,
+		RequiredHeaders: cfg.RequireHeaders,
+		SkippedHeaders:  cfg.SkipHeaders,
 	}
 
 	// Parse allowed types
@@ -93,53 +101,14 @@ func parseList(s string) []string {
 func (f *ContentFilter) ShouldDownload(url string, contentType string, contentLength int64) (bool, string) {
 	// Check content length
 	if contentLength >= 0 {
-		// Check if empty
-		if f.SkipEmpty && contentLength == 0 {
-			return false, "file is empty"
+		if ok, reason := f.CheckSize(contentLength); !ok {
+			return false, reason
 		}
-
-		// Check minimum size
-		if f.MinSize > 0 && contentLength < f.MinSize {
-			return false, fmt.Sprintf("file too small (%d bytes, min: %d)", contentLength, f.MinSize)
-		}
-
-		// Check maximum size
-		if f.MaxSize > 0 && contentLength > f.MaxSize {
-			return false, fmt.Sprintf("file too large (%d bytes, max: %d)", contentLength, f.MaxSize)
-		}
-	}
-
-	// Extract extension from URL
-	ext := filepath.Ext(url)
-	if ext != "" {
-		// Remove query parameters
-		if idx := strings.Index(ext, "?"); idx != -1 {
-			ext = ext[:idx]
-		}
-		ext = strings.ToLower(ext)
 	}
 
-	// Check blocked extensions first
-	if len(f.BlockedExtensions) > 0 {
-		for _, blockedExt := range f.BlockedExtensions {
-			if ext == strings.ToLower(blockedExt) {
-				return false, fmt.Sprintf("extension blocked: %s", ext)
-			}
-		}
-	}
-
-	// Check allowed extensions
-	if len(f.AllowedExtensions) > 0 {
-		allowed := false
-		for _, allowedExt := range f.AllowedExtensions {
-			if ext == strings.ToLower(allowedExt) {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return false, fmt.Sprintf("extension not in allowed list: %s", ext)
-		}
+	// Check extensions
+	if ok, reason := f.ShouldDownloadURL(url); !ok {
+		return false, reason
 	}
 
 	// Parse content type
@@ -177,6 +146,76 @@ func (f *ContentFilter) ShouldDownload(url string, contentType string, contentLe
 	return true, ""
 }
 
+// ShouldDownloadURL applies the rules that need only the URL (extensions),
+// so a URL they reject can be skipped without sending any request.
+// Extensions match the end of the URL's path, so "min.js" matches app.min.js.
+func (f *ContentFilter) ShouldDownloadURL(rawURL string) (bool, string) {
+	p := strings.ToLower(urlPath(rawURL))
+	ext := path.Ext(p)
+
+	// Check blocked extensions first
+	for _, blockedExt := range f.BlockedExtensions {
+		if strings.HasSuffix(p, strings.ToLower(blockedExt)) {
+			return false, fmt.Sprintf("extension blocked: %s", blockedExt)
+		}
+	}
+
+	// Check allowed extensions
+	if len(f.AllowedExtensions) > 0 {
+		for _, allowedExt := range f.AllowedExtensions {
+			if strings.HasSuffix(p, strings.ToLower(allowedExt)) {
+				return true, ""
+			}
+		}
+		return false, fmt.Sprintf("extension not in allowed list: %s", ext)
+	}
+
+	return true, ""
+}
+
+// urlPath returns the path of a URL without its query and fragment
+// ("/img/logo.png" for /img/logo.png?v=2, "" for https://example.com)
+func urlPath(rawURL string) string {
+	if parsed, err := url.Parse(rawURL); err == nil {
+		return parsed.Path
+	}
+	if idx := strings.IndexAny(rawURL, "?#"); idx != -1 {
+		return rawURL[:idx]
+	}
+	return rawURL
+}
+
+// CheckSize checks a file size against the empty, minimum and maximum size filters
+func (f *ContentFilter) CheckSize(size int64) (bool, string) {
+	// Check if empty
+	if f.SkipEmpty && size == 0 {
+		return false, "file is empty"
+	}
+
+	// Check minimum size
+	if f.MinSize > 0 && size < f.MinSize {
+		return false, fmt.Sprintf("file too small (%d bytes, min: %d)", size, f.MinSize)
+	}
+
+	// Check maximum size
+	if f.MaxSize > 0 && size > f.MaxSize {
+		return false, fmt.Sprintf("file too large (%d bytes, max: %d)", size, f.MaxSize)
+	}
+
+	return true, ""
+}
+
+// MinBodySize returns the smallest body the size filters keep (0 = no minimum)
+func (f *ContentFilter) MinBodySize() int64 {
+	if f.MinSize > 0 {
+		return f.MinSize
+	}
+	if f.SkipEmpty {
+		return 1
+	}
+	return 0
+}
+
 // matchContentType checks if contentType matches pattern (supports wildcards)
 func (f *ContentFilter) matchContentType(contentType, pattern string) bool {
 	// Exact match
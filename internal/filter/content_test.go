@@ -80,6 +80,35 @@ func TestContentFilter_ShouldDownload_Extension(t *testing.T) {
 	}
 }
 
+func TestContentFilter_ShouldDownloadURL(t *testing.T) {
+	filter := NewContentFilter(FilterConfig{ExcludeExt: "png, .GIF, min.js"})
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"http://example.com/logo.png", false},
+		{"http://example.com/logo.PNG?v=2", false},
+		{"http://example.com/anim.gif#frame", false},
+		{"http://example.com/image?name=logo.png", true},
+		{"http://example.com", true},
+		{"http://example.com/app.js", true},
+		{"http://example.com/app.min.js", false},
+	}
+
+	for _, tt := range tests {
+		if got, reason := filter.ShouldDownloadURL(tt.url); got != tt.want {
+			t.Errorf("ShouldDownloadURL(%q) = %v (%s), want %v", tt.url, got, reason, tt.want)
+		}
+	}
+
+	// --filter-ext alone rejects a bare host (no extension) without a request
+	allowJS := NewContentFilter(FilterConfig{FilterExt: "js"})
+	if ok, _ := allowJS.ShouldDownloadURL("http://example.com"); ok {
+		t.Error("ShouldDownloadURL() accepted a URL without extension despite --filter-ext")
+	}
+}
+
 func TestContentFilter_ShouldDownload_Size(t *testing.T) {
 	cfg := FilterConfig{
 		MinSize: 100,
@@ -135,6 +164,25 @@ func TestContentFilter_ShouldDownload_SkipEmpty(t *testing.T) {
 	}
 }
 
+func TestContentFilter_MinBodySize(t *testing.T) {
+	tests := []struct {
+		cfg  FilterConfig
+		want int64
+	}{
+		{FilterConfig{}, 0},
+		{FilterConfig{SkipEmpty: true}, 1},
+		{FilterConfig{MinSize: 1024}, 1024},
+		{FilterConfig{MinSize: 1024, SkipEmpty: true}, 1024},
+		{FilterConfig{MaxSize: 1024}, 0},
+	}
+
+	for _, tt := range tests {
+		if got := NewContentFilter(tt.cfg).MinBodySize(); got != tt.want {
+			t.Errorf("MinBodySize() with %+v = %d, want %d", tt.cfg, got, tt.want)
+		}
+	}
+}
+
 func TestContentFilter_WildcardMatch(t *testing.T) {
 	cfg := FilterConfig{
 		ExcludeType: "image/*,video/*",
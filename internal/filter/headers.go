@@ -0,0 +1,88 @@
+package filter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HeaderRule matches a response header by presence or by value
+type HeaderRule struct {
+	Name  string // Header name (case-insensitive)
+	Value string // Substring to match (case-insensitive); empty means presence only
+}
+
+// String returns the rule in "Name: value" form
+func (r HeaderRule) String() string {
+	if r.Value == "" {
+		return r.Name
+	}
+	return r.Name + ": " + r.Value
+}
+
+// Matches checks if the headers satisfy the rule
+func (r HeaderRule) Matches(h http.Header) bool {
+	values := h.Values(r.Name)
+	if len(values) == 0 {
+		return false
+	}
+	if r.Value == "" {
+		return true
+	}
+
+	want := strings.ToLower(r.Value)
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), want) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseHeaderRules parses "Name" or "Name: value" rules, one per item. Items
+// are not split on commas, since header values such as "text/html,
+// charset=utf-8" contain them.
+func ParseHeaderRules(items []string) ([]HeaderRule, error) {
+	var rules []HeaderRule
+	for _, item := range items {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		parts := strings.SplitN(item, ":", 2)
+		name := strings.TrimSpace(parts[0])
+		if name == "" {
+			return nil, fmt.Errorf("invalid header rule: %q (expected 'Name' or 'Name: value')", item)
+		}
+
+		rule := HeaderRule{Name: name}
+		if len(parts) == 2 {
+			rule.Value = strings.TrimSpace(parts[1])
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// HasHeaderRules returns true if any header rules are configured
+func (f *ContentFilter) HasHeaderRules() bool {
+	return len(f.RequiredHeaders) > 0 || len(f.SkippedHeaders) > 0
+}
+
+// ShouldDownloadHeaders determines if a response should be kept based on its headers
+func (f *ContentFilter) ShouldDownloadHeaders(h http.Header) (bool, string) {
+	// Check skip rules first
+	for _, rule := range f.SkippedHeaders {
+		if rule.Matches(h) {
+			return false, fmt.Sprintf("header matched skip rule: %s", rule)
+		}
+	}
+
+	// All required rules must match
+	for _, rule := range f.RequiredHeaders {
+		if !rule.Matches(h) {
+			return false, fmt.Sprintf("required header not matched: %s", rule)
+		}
+	}
+
+	return true, ""
+}
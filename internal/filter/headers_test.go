@@ -0,0 +1,95 @@
+package filter
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestParseHeaderRules(t *testing.T) {
+	rules, err := ParseHeaderRules([]string{"X-Powered-By: Express", "Server", "Cache-Control: no-cache, no-store"})
+	if err != nil {
+		t.Fatalf("ParseHeaderRules() error = %v", err)
+	}
+
+	want := []HeaderRule{
+		{Name: "X-Powered-By", Value: "Express"},
+		{Name: "Server"},
+		{Name: "Cache-Control", Value: "no-cache, no-store"},
+	}
+	if len(rules) != len(want) {
+		t.Fatalf("ParseHeaderRules() returned %d rules, want %d", len(rules), len(want))
+	}
+	for i := range want {
+		if rules[i] != want[i] {
+			t.Errorf("rule[%d] = %+v, want %+v", i, rules[i], want[i])
+		}
+	}
+
+	if _, err := ParseHeaderRules([]string{": value"}); err == nil {
+		t.Error("ParseHeaderRules() expected error for empty header name")
+	}
+}
+
+func TestContentFilter_ShouldDownloadHeaders(t *testing.T) {
+	tests := []struct {
+		name    string
+		require []HeaderRule
+		skip    []HeaderRule
+		headers http.Header
+		want    bool
+	}{
+		{
+			name:    "required value matches",
+			require: []HeaderRule{{Name: "X-Powered-By", Value: "express"}},
+			headers: http.Header{"X-Powered-By": []string{"Express 4.17"}},
+			want:    true,
+		},
+		{
+			name:    "required value differs",
+			require: []HeaderRule{{Name: "X-Powered-By", Value: "Express"}},
+			headers: http.Header{"X-Powered-By": []string{"PHP/8.1"}},
+			want:    false,
+		},
+		{
+			name:    "required header missing",
+			require: []HeaderRule{{Name: "X-Powered-By"}},
+			headers: http.Header{},
+			want:    false,
+		},
+		{
+			name:    "required presence only",
+			require: []HeaderRule{{Name: "x-powered-by"}},
+			headers: http.Header{"X-Powered-By": []string{"anything"}},
+			want:    true,
+		},
+		{
+			name:    "skip value matches",
+			skip:    []HeaderRule{{Name: "Content-Type", Value: "text/html"}},
+			headers: http.Header{"Content-Type": []string{"text/html; charset=utf-8"}},
+			want:    false,
+		},
+		{
+			name:    "skip value differs",
+			skip:    []HeaderRule{{Name: "Content-Type", Value: "text/html"}},
+			headers: http.Header{"Content-Type": []string{"application/javascript"}},
+			want:    true,
+		},
+		{
+			name:    "skip wins over require",
+			require: []HeaderRule{{Name: "Server"}},
+			skip:    []HeaderRule{{Name: "Server", Value: "cloudflare"}},
+			headers: http.Header{"Server": []string{"cloudflare"}},
+			want:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewContentFilter(FilterConfig{RequireHeaders: tt.require, SkipHeaders: tt.skip})
+			got, reason := f.ShouldDownloadHeaders(tt.headers)
+			if got != tt.want {
+				t.Errorf("ShouldDownloadHeaders() = %v (%s), want %v", got, reason, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package jsanalyzer
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"regexp"
@@ -23,125 +24,147 @@ func NewBeautifier() *Beautifier {
 	}
 }
 
-// Beautify beautifies minified JavaScript code
+// Beautify beautifies minified JavaScript code. String, template and regular
+// expression literals and comments are copied verbatim, template ${}
+// substitutions included, so the output parses like the input.
 func (b *Beautifier) Beautify(code string) string {
-	var result strings.Builder
+	out := make([]byte, 0, len(code)+len(code)/4)
 	indentLevel := 0
-	inString := false
-	inComment := false
-	inRegex := false
-	stringChar := rune(0)
-	prevChar := rune(0)
-
-	indent := func() string {
-		return strings.Repeat(b.indentChar, indentLevel*b.indentSize)
+	parens := 0 // Open ( and [: a ; inside for(...) does not end a line
+
+	// prev is the last significant token written, for telling regular
+	// expressions from divisions as the lexer does
+	var prev []token
+
+	// newline ends the current line, unless it is already empty
+	newline := func() {
+		out = bytes.TrimRight(out, " \t")
+		if len(out) > 0 && out[len(out)-1] != '\n' {
+			out = append(out, '\n')
+		}
+		out = append(out, strings.Repeat(b.indentChar, indentLevel*b.indentSize)...)
+	}
+	// space writes a single space, unless one is already there
+	space := func() {
+		if len(out) > 0 && out[len(out)-1] != ' ' && out[len(out)-1] != '\n' {
+			out = append(out, ' ')
+		}
 	}
 
-	for i, char := range code {
-		// Handle string literals
-		if !inComment && !inRegex {
-			if (char == '"' || char == '\'' || char == '`') && prevChar != '\\' {
-				if !inString {
-					inString = true
-					stringChar = char
-				} else if char == stringChar {
-					inString = false
-					stringChar = 0
-				}
+	for i := 0; i < len(code); {
+		c := code[i]
+		switch {
+		case strings.HasPrefix(code[i:], "//"):
+			end := strings.IndexByte(code[i:], '\n')
+			if end == -1 {
+				end = len(code) - i
 			}
-		}
+			out = append(out, code[i:i+end]...)
+			i += end
+			newline()
+			continue
 
-		// Handle comments
-		if !inString && !inRegex {
-			if i < len(code)-1 {
-				nextChar := rune(code[i+1])
-				if char == '/' && nextChar == '/' {
-					inComment = true
-				}
+		case strings.HasPrefix(code[i:], "/*"):
+			end := strings.Index(code[i+2:], "*/")
+			next := len(code)
+			if end != -1 {
+				next = i + 2 + end + 2
 			}
-		}
+			out = append(out, code[i:next]...)
+			i = next
+			continue
 
-		// End of line comment
-		if inComment && char == '\n' {
-			inComment = false
-		}
+		case c == '"' || c == '\'':
+			_, next := readString(code, i)
+			out = append(out, code[i:next]...)
+			prev = []token{{kind: tokenString}}
+			i = next
+			continue
+
+		case c == '`':
+			_, next, _ := readTemplate(code, i)
+			out = append(out, code[i:next]...)
+			prev = []token{{kind: tokenString}}
+			i = next
+			continue
+
+		case c == '/' && regexAllowed(prev):
+			next := readRegex(code, i)
+			out = append(out, code[i:next]...)
+			prev = []token{{kind: tokenRegex}}
+			i = next
+			continue
 
-		// Skip processing if in string, comment, or regex
-		if inString || inComment {
-			result.WriteRune(char)
-			prevChar = char
+		case isIdentPart(c):
+			start := i
+			for i < len(code) && (isIdentPart(code[i]) || (code[i] == '.' && !isIdentStart(code[start]))) {
+				i++
+			}
+			out = append(out, code[start:i]...)
+			kind := tokenIdent
+			if !isIdentStart(code[start]) {
+				kind = tokenNumber
+			}
+			prev = []token{{kind: kind, text: code[start:i]}}
 			continue
 		}
 
 		// Handle different characters
-		switch char {
+		switch c {
 		case '{':
-			result.WriteRune(char)
+			out = append(out, c)
 			indentLevel++
-			result.WriteString("\n")
-			result.WriteString(indent())
+			newline()
 
 		case '}':
 			indentLevel--
 			if indentLevel < 0 {
 				indentLevel = 0
 			}
-			// Remove trailing whitespace before }
-			resultStr := result.String()
-			resultStr = strings.TrimRight(resultStr, " \t")
-			result.Reset()
-			result.WriteString(resultStr)
-			result.WriteString("\n")
-			result.WriteString(indent())
-			result.WriteRune(char)
+			newline()
+			out = append(out, c)
 
-		case ';':
-			result.WriteRune(char)
-			// Add newline after semicolon (if not in for loop)
-			if prevChar != ')' || !b.isInForLoop(code, i) {
-				result.WriteString("\n")
-				result.WriteString(indent())
+		case '(', '[':
+			parens++
+			out = append(out, c)
+
+		case ')', ']':
+			if parens > 0 {
+				parens--
 			}
+			out = append(out, c)
 
-		case ',':
-			result.WriteRune(char)
-			result.WriteRune(' ')
+		case ';':
+			out = append(out, c)
+			if parens == 0 {
+				newline()
+			} else {
+				space()
+			}
 
-		case ':':
-			result.WriteRune(char)
-			result.WriteRune(' ')
+		case ',', ':':
+			out = append(out, c)
+			space()
 
 		case '\n', '\r':
-			// Skip original newlines
-			continue
+			// Line breaks may end statements (automatic semicolon insertion)
+			newline()
 
-		case ' ', '\t':
+		case ' ', '\t', '\f', '\v':
 			// Normalize whitespace
-			if prevChar != ' ' && prevChar != '\t' {
-				result.WriteRune(' ')
-			}
+			space()
 
 		default:
-			result.WriteRune(char)
+			out = append(out, c)
 		}
 
-		prevChar = char
-	}
-
-	return result.String()
-}
-
-// isInForLoop checks if position is inside a for loop declaration
-func (b *Beautifier) isInForLoop(code string, pos int) bool {
-	// Simple heuristic: look back for 'for' keyword
-	lookBack := 50
-	start := pos - lookBack
-	if start < 0 {
-		start = 0
+		if c > ' ' {
+			prev = []token{{kind: tokenPunct, text: string(c)}}
+		}
+		i++
 	}
 
-	snippet := code[start:pos]
-	return strings.Contains(snippet, "for")
+	return string(out)
 }
 
 // IsMinified checks if JavaScript code appears to be minified
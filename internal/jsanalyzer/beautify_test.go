@@ -0,0 +1,73 @@
+package jsanalyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+// Previews (--preview-bytes) cut bundles anywhere: mid-string, mid-regex,
+// with blocks left open
+func TestBeautify_Truncated(t *testing.T) {
+	inputs := []string{
+		"function a(){if(x){var s='abc",
+		"}}}}))",
+		"var r=/ab[",
+		"a=`x${b",
+		"/* unterminated",
+		"((((",
+		"a?b:",
+		"'\\\\",
+	}
+	b := NewBeautifier()
+	for _, src := range inputs {
+		out := b.Beautify(src)
+		if strings.TrimSpace(out) == "" {
+			t.Errorf("Beautify(%q) = %q, want the code kept", src, out)
+		}
+	}
+}
+
+// Literals must come out exactly as they went in, however many braces,
+// semicolons and slashes they contain
+func TestBeautify_LiteralsVerbatim(t *testing.T) {
+	tests := []struct {
+		src     string
+		literal string
+	}{
+		{"var r=/[/]{2};\\/x/g;", "/[/]{2};\\/x/g"},
+		{"if(ok)s=s.replace(/;\\s*{/,'');", "/;\\s*{/"},
+		{"return/a{1,2}b/.test(s)", "/a{1,2}b/"},
+		{"const h=`<div class=\"${c?{a:1}.a:'x'}\">{${n};}</div>`;", "`<div class=\"${c?{a:1}.a:'x'}\">{${n};}</div>`"},
+		{"var s='{;}',t=\"//not a comment\";", "'{;}'"},
+		{"var s='{;}',t=\"//not a comment\";", "\"//not a comment\""},
+		{"/* {a;b} */x()", "/* {a;b} */"},
+	}
+	b := NewBeautifier()
+	for _, tt := range tests {
+		if out := b.Beautify(tt.src); !strings.Contains(out, tt.literal) {
+			t.Errorf("Beautify(%q) = %q, want %q kept verbatim", tt.src, out, tt.literal)
+		}
+	}
+}
+
+func TestBeautify_Division(t *testing.T) {
+	// After a value, / divides: the code between the slashes is still formatted
+	out := NewBeautifier().Beautify("x=a/b;y=(c)/2;z=arr[0]/n;")
+	want := "x=a/b;\ny=(c)/2;\nz=arr[0]/n;\n"
+	if out != want {
+		t.Errorf("Beautify() = %q, want %q", out, want)
+	}
+}
+
+func TestBeautify_Structure(t *testing.T) {
+	src := "function f(a){for(var i=0;i<a;i++){g(i)}return a}// done\nf(1)"
+	want := "function f(a){\n  for(var i=0; i<a; i++){\n    g(i)\n  }return a\n}// done\nf(1)"
+	if out := NewBeautifier().Beautify(src); out != want {
+		t.Errorf("Beautify() =\n%s\nwant\n%s", out, want)
+	}
+
+	// A line break may end a statement, so it is kept
+	if out := NewBeautifier().Beautify("a=1\nb=2"); out != "a=1\nb=2" {
+		t.Errorf("Beautify() = %q, want the line break kept", out)
+	}
+}
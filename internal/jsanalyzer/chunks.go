@@ -0,0 +1,327 @@
+package jsanalyzer
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ExtractChunkURLs finds lazily loaded chunks referenced by a JavaScript
+// bundle and resolves them against the bundle's URL. It recognises:
+//
+//	import('/chunks/x.js')                        // dynamic imports with a literal specifier
+//	__webpack_require__.u = (id) => "js/" + id + "." + {"12":"ab3f"}[id] + ".js"
+//	__webpack_require__.p = "/static/"            // webpack chunk filename map and public path
+//	function jsonpScriptSrc(id) { return __webpack_require__.p + "static/js/" + ... }  // webpack 4
+//
+// Minified webpack runtimes (o.u=e=>..., function i(e){return a.p+...}) are recognised too. Only URLs on
+// the bundle's own host are returned, sorted and without duplicates; bare
+// module specifiers (import('react')) are skipped.
+func ExtractChunkURLs(bundleURL string, src []byte) []string {
+	base, err := url.Parse(bundleURL)
+	if err != nil {
+		return nil
+	}
+
+	tokens := tokenize(string(src))
+	var refs []string
+	refs = append(refs, dynamicImports(tokens)...)
+	refs = append(refs, webpackChunks(tokens)...)
+	refs = append(refs, webpack4Chunks(tokens)...)
+
+	seen := make(map[string]bool)
+	var urls []string
+	for _, ref := range refs {
+		resolved, err := base.Parse(ref)
+		if err != nil || resolved.Host != base.Host {
+			continue
+		}
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+		resolved.Fragment = ""
+		if u := resolved.String(); !seen[u] && u != bundleURL {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+
+	sort.Strings(urls)
+	return urls
+}
+
+// dynamicImports returns the literal specifiers of import() calls that
+// look like paths or URLs
+func dynamicImports(tokens []token) []string {
+	var specs []string
+	for i := 0; i+3 < len(tokens); i++ {
+		if !tokens[i].is("import") || !tokens[i+1].is("(") || tokens[i+2].kind != tokenString {
+			continue
+		}
+		// obj.import(...) is a method call, not a dynamic import
+		if i > 0 && tokens[i-1].is(".") {
+			continue
+		}
+		if next := tokens[i+3]; !next.is(")") && !next.is(",") {
+			continue
+		}
+		if spec := tokens[i+2].text; isPathSpecifier(spec) {
+			specs = append(specs, spec)
+		}
+	}
+	return specs
+}
+
+// isPathSpecifier reports whether an import specifier is a path or URL
+// rather than a bare package name
+func isPathSpecifier(spec string) bool {
+	return strings.HasPrefix(spec, "/") || strings.HasPrefix(spec, "./") ||
+		strings.HasPrefix(spec, "../") || strings.Contains(spec, "://")
+}
+
+// webpackChunks evaluates webpack's chunk filename function (R.u) for every
+// chunk id it maps, prefixing the public path (R.p) when one is set
+func webpackChunks(tokens []token) []string {
+	var chunks []string
+	for i := 0; i+3 < len(tokens); i++ {
+		if tokens[i].kind != tokenIdent || !tokens[i+1].is(".") || !tokens[i+2].is("u") || !tokens[i+3].is("=") {
+			continue
+		}
+		param, expr := chunkFunction(tokens[i+4:])
+		if expr == nil {
+			continue
+		}
+		files := evalChunkNames(expr, param)
+		if len(files) == 0 {
+			continue
+		}
+
+		publicPath := publicPathFor(tokens, tokens[i].text)
+		for _, f := range files {
+			chunks = append(chunks, publicPath+f)
+		}
+	}
+	return chunks
+}
+
+// webpack4Chunks evaluates webpack 4's jsonpScriptSrc, a named function
+// returning R.p + "static/js/" + ... + ".chunk.js" where R.p is the public path
+func webpack4Chunks(tokens []token) []string {
+	var chunks []string
+	for i := 0; i+4 < len(tokens); i++ {
+		if !tokens[i].is("function") || tokens[i+1].kind != tokenIdent {
+			continue
+		}
+		param, expr := chunkFunction(tokens[i:])
+		if len(expr) < 4 || expr[0].kind != tokenIdent || !expr[1].is(".") || !expr[2].is("p") || !expr[3].is("+") {
+			continue
+		}
+		files := evalChunkNames(expr[4:], param)
+		if len(files) == 0 {
+			continue
+		}
+
+		publicPath := publicPathFor(tokens, expr[0].text)
+		for _, f := range files {
+			chunks = append(chunks, publicPath+f)
+		}
+	}
+	return chunks
+}
+
+// chunkFunction parses "function(id){return EXPR}" (the function may be
+// named), "(id)=>{return EXPR}" or "id=>EXPR" and returns the parameter name
+// and EXPR's tokens
+func chunkFunction(tokens []token) (string, []token) {
+	i := 0
+	var param string
+
+	// The name of a named function, if any, is skipped
+	name := 0
+	if len(tokens) > 1 && tokens[0].is("function") && tokens[1].kind == tokenIdent {
+		name = 1
+	}
+
+	switch {
+	case len(tokens) > 4+name && tokens[0].is("function") && tokens[1+name].is("(") && tokens[2+name].kind == tokenIdent && tokens[3+name].is(")"):
+		param, i = tokens[2+name].text, 4+name
+	case len(tokens) > 4 && tokens[0].is("(") && tokens[1].kind == tokenIdent && tokens[2].is(")") && tokens[3].is("=>"):
+		param, i = tokens[1].text, 4
+	case len(tokens) > 2 && tokens[0].kind == tokenIdent && tokens[1].is("=>"):
+		param, i = tokens[0].text, 2
+	default:
+		return "", nil
+	}
+
+	// A block body must start with its return statement
+	if tokens[i].is("{") {
+		if i+1 >= len(tokens) || !tokens[i+1].is("return") {
+			return "", nil
+		}
+		i += 2
+	}
+
+	// The expression ends at the first ; , or closing brace at depth 0
+	depth := 0
+	for j := i; j < len(tokens); j++ {
+		t := tokens[j]
+		switch {
+		case t.is("(") || t.is("[") || t.is("{"):
+			depth++
+		case t.is(")") || t.is("]") || t.is("}"):
+			if depth == 0 {
+				return param, tokens[i:j]
+			}
+			depth--
+		case (t.is(";") || t.is(",")) && depth == 0:
+			return param, tokens[i:j]
+		}
+	}
+	return param, tokens[i:]
+}
+
+// chunkTerm is one operand of the chunk filename concatenation
+type chunkTerm struct {
+	literal  string            // Fixed text
+	isID     bool              // The chunk id itself
+	lookup   map[string]string // {id: value}[id]
+	fallback bool              // ({...}[id] || id): unmapped ids use the id
+}
+
+// evalChunkNames evaluates a concatenation of string literals, the chunk id
+// parameter and {id: value}[id] lookups for every chunk id named by a lookup
+// without fallback (webpack's content-hash map lists every chunk)
+func evalChunkNames(expr []token, param string) []string {
+	var terms []chunkTerm
+	for _, operand := range splitTopLevel(expr, "+") {
+		term, ok := parseChunkTerm(operand, param)
+		if !ok {
+			return nil
+		}
+		terms = append(terms, term)
+	}
+
+	ids := make(map[string]bool)
+	for _, t := range terms {
+		if t.lookup != nil && !t.fallback {
+			for id := range t.lookup {
+				ids[id] = true
+			}
+		}
+	}
+
+	var names []string
+	for id := range ids {
+		var sb strings.Builder
+		complete := true
+		for _, t := range terms {
+			switch {
+			case t.isID:
+				sb.WriteString(id)
+			case t.lookup != nil:
+				value, ok := t.lookup[id]
+				if !ok && !t.fallback {
+					complete = false
+				} else if !ok {
+					value = id
+				}
+				sb.WriteString(value)
+			default:
+				sb.WriteString(t.literal)
+			}
+		}
+		if complete {
+			names = append(names, sb.String())
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseChunkTerm recognises one operand of the filename expression
+func parseChunkTerm(operand []token, param string) (chunkTerm, bool) {
+	// Parenthesised: ({...}[id] || id) or (EXPR)
+	if len(operand) >= 2 && operand[0].is("(") && operand[len(operand)-1].is(")") {
+		inner := operand[1 : len(operand)-1]
+		alts := splitTopLevel(inner, "||")
+		if len(alts) == 2 && len(alts[1]) == 1 && alts[1][0].is(param) {
+			term, ok := parseChunkTerm(alts[0], param)
+			if ok && term.lookup != nil {
+				term.fallback = true
+				return term, true
+			}
+			return chunkTerm{}, false
+		}
+		if len(alts) == 1 {
+			return parseChunkTerm(inner, param)
+		}
+		return chunkTerm{}, false
+	}
+
+	switch {
+	case len(operand) == 1 && operand[0].kind == tokenString:
+		return chunkTerm{literal: operand[0].text}, true
+	case len(operand) == 1 && operand[0].is(param):
+		return chunkTerm{isID: true}, true
+	case len(operand) >= 5 && operand[0].is("{") &&
+		operand[len(operand)-3].is("[") && operand[len(operand)-2].is(param) && operand[len(operand)-1].is("]"):
+		lookup, ok := parseObjectLiteral(operand[:len(operand)-3])
+		return chunkTerm{lookup: lookup}, ok
+	}
+	return chunkTerm{}, false
+}
+
+// parseObjectLiteral parses {key: "value", ...} with string, number or
+// identifier keys and string values
+func parseObjectLiteral(tokens []token) (map[string]string, bool) {
+	if len(tokens) < 2 || !tokens[0].is("{") || !tokens[len(tokens)-1].is("}") {
+		return nil, false
+	}
+
+	values := make(map[string]string)
+	for _, entry := range splitTopLevel(tokens[1:len(tokens)-1], ",") {
+		if len(entry) == 0 {
+			continue // Trailing comma
+		}
+		if len(entry) != 3 || !entry[1].is(":") || entry[2].kind != tokenString {
+			return nil, false
+		}
+		switch entry[0].kind {
+		case tokenString, tokenNumber, tokenIdent:
+			values[entry[0].text] = entry[2].text
+		default:
+			return nil, false
+		}
+	}
+	return values, true
+}
+
+// splitTopLevel splits tokens on a separator outside brackets
+func splitTopLevel(tokens []token, sep string) [][]token {
+	var parts [][]token
+	depth, start := 0, 0
+	for i, t := range tokens {
+		switch {
+		case t.is("(") || t.is("[") || t.is("{"):
+			depth++
+		case t.is(")") || t.is("]") || t.is("}"):
+			depth--
+		case t.is(sep) && depth == 0:
+			parts = append(parts, tokens[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+// publicPathFor returns the string assigned to R.p for the runtime object R,
+// or "" (chunks relative to the bundle) if there is none
+func publicPathFor(tokens []token, runtime string) string {
+	for i := 0; i+4 < len(tokens); i++ {
+		if tokens[i].is(runtime) && tokens[i+1].is(".") && tokens[i+2].is("p") &&
+			tokens[i+3].is("=") && tokens[i+4].kind == tokenString {
+			return tokens[i+4].text
+		}
+	}
+	return ""
+}
@@ -0,0 +1,83 @@
+package jsanalyzer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractChunkURLs_DynamicImport(t *testing.T) {
+	src := `
+const Admin = () => import('/chunks/admin.js');
+const Page = lazy(() => import("./pages/settings.js", { with: {} }));
+import('react'); // bare specifier, not a URL
+loader.import('/not/a/dynamic/import.js');
+// import('/commented/out.js')
+const re = /import\('\/in\/regex.js'\)/;
+const s = "import('/in/string.js')";
+import('https://other.example.com/x.js');
+import('https://example.com/abs.js');
+`
+	got := ExtractChunkURLs("https://example.com/static/app.js", []byte(src))
+	want := []string{
+		"https://example.com/abs.js",
+		"https://example.com/chunks/admin.js",
+		"https://example.com/static/pages/settings.js",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExtractChunkURLs() = %v, want %v", got, want)
+	}
+}
+
+func TestExtractChunkURLs_Webpack(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want []string
+	}{
+		{
+			"runtime with public path",
+			`__webpack_require__.u = (chunkId) => {
+	return "js/" + chunkId + "." + {"179":"3e1f","532":"8a7c"}[chunkId] + ".js";
+};
+__webpack_require__.p = "/assets/";`,
+			[]string{"https://example.com/assets/js/179.3e1f.js", "https://example.com/assets/js/532.8a7c.js"},
+		},
+		{
+			"minified with named chunks",
+			`o.u=e=>"static/js/"+({12:"vendors"}[e]||e)+"."+{12:"abc",34:"def"}[e]+".chunk.js",o.miniCssF=e=>{};`,
+			[]string{"https://example.com/static/static/js/34.def.chunk.js", "https://example.com/static/static/js/vendors.abc.chunk.js"},
+		},
+		{
+			"function expression",
+			`r.p="https://example.com/cdn/";r.u=function(e){return e+".bundle.js?v="+{"1":"x"}[e]};`,
+			[]string{"https://example.com/cdn/1.bundle.js?v=x"},
+		},
+		{
+			"webpack 4 jsonpScriptSrc",
+			`function jsonpScriptSrc(chunkId) {
+	return __webpack_require__.p + "static/js/" + ({}[chunkId]||chunkId) + "." + {"0":"9f3a","1":"c41e"}[chunkId] + ".chunk.js"
+}
+__webpack_require__.p = "/";`,
+			[]string{"https://example.com/static/js/0.9f3a.chunk.js", "https://example.com/static/js/1.c41e.chunk.js"},
+		},
+		{
+			"webpack 4 minified",
+			`!function(e){function i(e){return a.p+"static/js/"+({2:"admin"}[e]||e)+"."+{0:"a1b2",2:"c3d4"}[e]+".chunk.js"}var a;a.p="/app/";}([]);`,
+			[]string{"https://example.com/app/static/js/0.a1b2.chunk.js", "https://example.com/app/static/js/admin.c3d4.chunk.js"},
+		},
+		{
+			"unsupported expression is ignored",
+			`r.u=function(e){return getName(e)+".js"};`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ExtractChunkURLs("https://example.com/static/runtime.js", []byte(tt.src))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ExtractChunkURLs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package jsanalyzer
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// BeautifyCSS beautifies minified CSS: one declaration per line, rules
+// indented by block depth (so @media and other at-rule blocks nest) and a
+// blank line after each top-level block. Strings, comments and anything in
+// parentheses, such as url(data:...;base64,...), are passed through as is.
+func (b *Beautifier) BeautifyCSS(code string) string {
+	var out, line strings.Builder
+	level := 0
+
+	// flush ends the current line, indented by the block depth
+	flush := func() {
+		text := strings.TrimSpace(line.String())
+		line.Reset()
+		if text != "" {
+			out.WriteString(strings.Repeat(b.indentChar, level*b.indentSize))
+			out.WriteString(text)
+			out.WriteString("\n")
+		}
+	}
+
+	runes := []rune(code)
+	quote := rune(0)
+	inComment, commentAlone := false, false
+	commentStart := 0
+	parens := 0
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+
+		if inComment {
+			line.WriteRune(char)
+			if char == '/' && runes[i-1] == '*' && i-1 > commentStart {
+				inComment = false
+				// A comment on its own line stays on its own line
+				if commentAlone {
+					flush()
+				}
+			}
+			continue
+		}
+		if quote != 0 {
+			line.WriteRune(char)
+			if char == '\\' && i+1 < len(runes) {
+				i++
+				line.WriteRune(runes[i])
+			} else if char == quote {
+				quote = 0
+			}
+			continue
+		}
+
+		switch char {
+		case '/':
+			if i+1 < len(runes) && runes[i+1] == '*' {
+				inComment = true
+				commentAlone = strings.TrimSpace(line.String()) == ""
+				commentStart = i + 1
+				line.WriteString("/*")
+				i++
+				continue
+			}
+			line.WriteRune(char)
+
+		case '"', '\'':
+			quote = char
+			line.WriteRune(char)
+
+		case '(':
+			parens++
+			line.WriteRune(char)
+
+		case ')':
+			if parens > 0 {
+				parens--
+			}
+			line.WriteRune(char)
+
+		case '{':
+			selector := strings.TrimSpace(line.String())
+			line.Reset()
+			if selector != "" {
+				line.WriteString(selector + " ")
+			}
+			line.WriteRune(char)
+			flush()
+			level++
+
+		case '}':
+			flush()
+			level--
+			if level < 0 {
+				level = 0
+			}
+			line.WriteRune(char)
+			flush()
+			if level == 0 {
+				out.WriteString("\n")
+			}
+
+		case ';':
+			line.WriteRune(char)
+			if parens == 0 {
+				flush()
+			}
+
+		case ' ', '\t', '\n', '\r', '\f':
+			// Normalize whitespace
+			if s := line.String(); s != "" && !strings.HasSuffix(s, " ") {
+				line.WriteRune(' ')
+			}
+
+		default:
+			line.WriteRune(char)
+		}
+	}
+	flush()
+
+	return strings.TrimRight(out.String(), "\n") + "\n"
+}
+
+// BeautifyJSON indents compact JSON. It fails if data is not valid JSON,
+// e.g. a preview cut off mid-document.
+func (b *Beautifier) BeautifyJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", strings.Repeat(b.indentChar, b.indentSize)); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,53 @@
+package jsanalyzer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBeautifyCSS(t *testing.T) {
+	src := `/* banner */a,b:hover{color:red;background:url("data:image/png;base64,AA==")}` +
+		`@media (max-width:600px){.x{margin:0 auto;content:"{;}"}}.y{}`
+	want := `/* banner */
+a,b:hover {
+  color:red;
+  background:url("data:image/png;base64,AA==")
+}
+
+@media (max-width:600px) {
+  .x {
+    margin:0 auto;
+    content:"{;}"
+  }
+}
+
+.y {
+}
+`
+	if got := NewBeautifier().BeautifyCSS(src); got != want {
+		t.Errorf("BeautifyCSS() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBeautifyCSS_Truncated(t *testing.T) {
+	for _, src := range []string{"a{color:red", "}}}", `a{content:"x`, "/* open", "a{b:url(x;"} {
+		if out := NewBeautifier().BeautifyCSS(src); strings.TrimSpace(out) == "" {
+			t.Errorf("BeautifyCSS(%q) = %q, want the code kept", src, out)
+		}
+	}
+}
+
+func TestBeautifyJSON(t *testing.T) {
+	got, err := NewBeautifier().BeautifyJSON([]byte(`{"a":[1,2],"b":{"c":"</x>"}}`))
+	if err != nil {
+		t.Fatalf("BeautifyJSON() error = %v", err)
+	}
+	want := "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {\n    \"c\": \"</x>\"\n  }\n}\n"
+	if string(got) != want {
+		t.Errorf("BeautifyJSON() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := NewBeautifier().BeautifyJSON([]byte(`{"a":[1,`)); err == nil {
+		t.Error("BeautifyJSON() of truncated JSON expected error")
+	}
+}
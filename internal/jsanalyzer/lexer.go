@@ -0,0 +1,239 @@
+package jsanalyzer
+
+import "strings"
+
+// tokenKind classifies a JavaScript token
+type tokenKind int
+
+const (
+	tokenIdent    tokenKind = iota // Identifier or keyword
+	tokenString                    // String literal, or template literal without ${}
+	tokenTemplate                  // Template literal with ${} substitutions
+	tokenNumber                    // Numeric literal
+	tokenRegex                     // Regular expression literal
+	tokenPunct                     // Operator or punctuation
+)
+
+// token is one lexical token. For string literals text is the unquoted value.
+type token struct {
+	kind tokenKind
+	text string
+}
+
+// is reports whether the token is the given punctuation or identifier
+func (t token) is(text string) bool {
+	return (t.kind == tokenPunct || t.kind == tokenIdent) && t.text == text
+}
+
+// regexKeywords are keywords after which a '/' starts a regular expression
+var regexKeywords = map[string]bool{
+	"return": true, "typeof": true, "case": true, "do": true, "else": true,
+	"in": true, "instanceof": true, "new": true, "delete": true, "void": true,
+	"throw": true, "yield": true, "await": true,
+}
+
+// tokenize splits JavaScript source into tokens, dropping whitespace and
+// comments. It is not a full parser: it only needs to keep string literals,
+// regular expressions and comments apart, so that quotes inside one of them
+// don't derail the scan of the rest of a bundle.
+func tokenize(src string) []token {
+	var tokens []token
+	i := 0
+
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			end := strings.IndexByte(src[i:], '\n')
+			if end == -1 {
+				return tokens
+			}
+			i += end + 1
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return tokens
+			}
+			i += end + 4
+
+		case c == '"' || c == '\'':
+			text, next := readString(src, i)
+			tokens = append(tokens, token{tokenString, text})
+			i = next
+
+		case c == '`':
+			text, next, plain := readTemplate(src, i)
+			kind := tokenString
+			if !plain {
+				kind = tokenTemplate
+			}
+			tokens = append(tokens, token{kind, text})
+			i = next
+
+		case c == '/' && regexAllowed(tokens):
+			next := readRegex(src, i)
+			tokens = append(tokens, token{tokenRegex, src[i:next]})
+			i = next
+
+		case isIdentStart(c):
+			start := i
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			tokens = append(tokens, token{tokenIdent, src[start:i]})
+
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (isIdentPart(src[i]) || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{tokenNumber, src[start:i]})
+
+		default:
+			// Multi-character operators matter only for "=>", "||" and "..."
+			op := string(c)
+			for _, multi := range []string{"=>", "||", "..."} {
+				if strings.HasPrefix(src[i:], multi) {
+					op = multi
+					break
+				}
+			}
+			tokens = append(tokens, token{tokenPunct, op})
+			i += len(op)
+		}
+	}
+
+	return tokens
+}
+
+// regexAllowed reports whether a '/' after tokens starts a regular
+// expression rather than a division
+func regexAllowed(tokens []token) bool {
+	if len(tokens) == 0 {
+		return true
+	}
+	prev := tokens[len(tokens)-1]
+	switch prev.kind {
+	case tokenIdent:
+		return regexKeywords[prev.text]
+	case tokenPunct:
+		return prev.text != ")" && prev.text != "]" && prev.text != "}"
+	}
+	return false
+}
+
+// readString reads a quoted string starting at src[start], returning its
+// unescaped value and the index after the closing quote
+func readString(src string, start int) (string, int) {
+	quote := src[start]
+	var sb strings.Builder
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == quote:
+			return sb.String(), i + 1
+		case c == '\\' && i+1 < len(src):
+			sb.WriteByte(unescape(src[i+1]))
+			i += 2
+		case c == '\n':
+			// Unterminated string: stop at the end of the line
+			return sb.String(), i
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String(), i
+}
+
+// readTemplate reads a template literal starting at src[start]. plain is
+// false if it has ${} substitutions, whose contents are skipped.
+func readTemplate(src string, start int) (string, int, bool) {
+	var sb strings.Builder
+	plain := true
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '`':
+			return sb.String(), i + 1, plain
+		case c == '\\' && i+1 < len(src):
+			sb.WriteByte(unescape(src[i+1]))
+			i += 2
+		case c == '$' && i+1 < len(src) && src[i+1] == '{':
+			plain = false
+			depth := 0
+			for i < len(src) {
+				if src[i] == '{' {
+					depth++
+				} else if src[i] == '}' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+				i++
+			}
+			if i < len(src) {
+				i++
+			}
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return sb.String(), i, plain
+}
+
+// readRegex returns the index after the regular expression literal at src[start]
+func readRegex(src string, start int) int {
+	inClass := false
+	i := start + 1
+	for i < len(src) {
+		c := src[i]
+		switch {
+		case c == '\\' && i+1 < len(src):
+			i++
+		case c == '[':
+			inClass = true
+		case c == ']':
+			inClass = false
+		case c == '/' && !inClass:
+			i++
+			for i < len(src) && isIdentPart(src[i]) {
+				i++
+			}
+			return i
+		case c == '\n':
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+// unescape returns the character for a simple backslash escape
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	}
+	return c
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || c == '$' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9')
+}
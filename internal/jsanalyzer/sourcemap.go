@@ -0,0 +1,175 @@
+package jsanalyzer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// SourceMap is the part of a version 3 source map needed to recover the
+// original sources
+type SourceMap struct {
+	Version        int       `json:"version"`
+	File           string    `json:"file,omitempty"`
+	SourceRoot     string    `json:"sourceRoot,omitempty"`
+	Sources        []string  `json:"sources"`
+	SourcesContent []*string `json:"sourcesContent,omitempty"` // nil entries have no content
+	Sections       []struct {
+		Map *SourceMap `json:"map"`
+	} `json:"sections,omitempty"` // Index maps: the sections are merged by ParseSourceMap
+}
+
+// sourceMapComments are the comments naming a bundle's source map; //@ is
+// the deprecated form still emitted by some tools
+var sourceMapComments = []string{"//# sourceMappingURL=", "//@ sourceMappingURL="}
+
+// SourceMappingURL returns the URL of the source map named by the last
+// sourceMappingURL comment of a bundle, resolved against the bundle's URL,
+// or "" if there is none. Inline maps are returned as their data: URL
+// (see DecodeInlineSourceMap).
+func SourceMappingURL(bundleURL string, src []byte) string {
+	// The comment ends the file; only the tail is searched
+	if len(src) > 4096 {
+		src = src[len(src)-4096:]
+	}
+
+	at, prefix := -1, 0
+	for _, comment := range sourceMapComments {
+		if i := bytes.LastIndex(src, []byte(comment)); i > at {
+			at, prefix = i, len(comment)
+		}
+	}
+	if at == -1 {
+		return ""
+	}
+	ref := string(src[at+prefix:])
+	if end := strings.IndexAny(ref, " \t\r\n"); end != -1 {
+		ref = ref[:end]
+	}
+	if ref == "" {
+		return ""
+	}
+	if strings.HasPrefix(ref, "data:") {
+		return ref
+	}
+
+	base, err := url.Parse(bundleURL)
+	if err != nil {
+		return ""
+	}
+	resolved, err := base.Parse(ref)
+	if err != nil || (resolved.Scheme != "http" && resolved.Scheme != "https") {
+		return ""
+	}
+	return resolved.String()
+}
+
+// DecodeInlineSourceMap returns the JSON of a source map embedded in a
+// base64 data: URL
+func DecodeInlineSourceMap(dataURL string) ([]byte, error) {
+	meta, data, ok := strings.Cut(strings.TrimPrefix(dataURL, "data:"), ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return nil, fmt.Errorf("unsupported inline source map (expected a base64 data: URL)")
+	}
+	decoded, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid inline source map: %w", err)
+	}
+	return decoded, nil
+}
+
+// ParseSourceMap parses a version 3 source map. The )]}' prefix some
+// servers add against JSON hijacking is skipped, and the sections of an
+// index map are merged into one list of sources.
+func ParseSourceMap(data []byte) (*SourceMap, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte(")]}'")) {
+		data = data[4:]
+	}
+
+	var m SourceMap
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid source map: %w", err)
+	}
+	if m.Version != 3 {
+		return nil, fmt.Errorf("unsupported source map version %d", m.Version)
+	}
+
+	for _, section := range m.Sections {
+		if section.Map == nil {
+			continue
+		}
+		for i, source := range section.Map.Sources {
+			m.Sources = append(m.Sources, joinSourceRoot(section.Map.SourceRoot, source))
+			var content *string
+			if i < len(section.Map.SourcesContent) {
+				content = section.Map.SourcesContent[i]
+			}
+			m.SourcesContent = append(m.SourcesContent, content)
+		}
+	}
+	m.Sections = nil
+	return &m, nil
+}
+
+// ExtractSources writes every source whose content the map embeds under
+// destDir, at its path in the map (webpack:// and similar prefixes, query
+// strings and .. segments removed). Every write goes through
+// storage.WriteFileWithin, so neither a crafted name nor a symlink under
+// destDir can lead outside it. It returns the paths written; a map without
+// sourcesContent writes nothing. A source that cannot be written does not
+// stop the others; the first failure is returned.
+func ExtractSources(m *SourceMap, destDir string) ([]string, error) {
+	var written []string
+	var firstErr error
+	seen := make(map[string]bool)
+	for i, source := range m.Sources {
+		if i >= len(m.SourcesContent) || m.SourcesContent[i] == nil {
+			continue
+		}
+		name := sourcePath(joinSourceRoot(m.SourceRoot, source))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		target, err := storage.WriteFileWithin(destDir, name, strings.NewReader(*m.SourcesContent[i]))
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to write source %s: %w", name, err)
+			}
+			continue
+		}
+		written = append(written, target)
+	}
+	return written, firstErr
+}
+
+// joinSourceRoot prefixes a source with the map's sourceRoot
+func joinSourceRoot(root, source string) string {
+	if root == "" || strings.Contains(source, "://") {
+		return source
+	}
+	return strings.TrimSuffix(root, "/") + "/" + source
+}
+
+// sourcePath turns a source URL such as "webpack:///./src/app.js?5a1b" into
+// a clean relative path ("src/app.js"), or "" if nothing is left
+func sourcePath(source string) string {
+	if i := strings.Index(source, "://"); i != -1 {
+		source = source[i+3:]
+	}
+	if i := strings.IndexAny(source, "?#"); i != -1 {
+		source = source[:i]
+	}
+	// Windows separators would hide .. segments from Clean
+	source = strings.ReplaceAll(source, "\\", "/")
+	// Rooting the path first makes Clean drop every leading ..
+	return strings.TrimPrefix(path.Clean("/"+source), "/")
+}
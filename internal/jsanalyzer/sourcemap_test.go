@@ -0,0 +1,174 @@
+package jsanalyzer
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSourceMappingURL(t *testing.T) {
+	const bundle = "https://cdn.example.com/static/js/app.js"
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"relative", "a();\n//# sourceMappingURL=app.js.map\n", "https://cdn.example.com/static/js/app.js.map"},
+		{"rooted", "a();\n//# sourceMappingURL=/maps/app.map", "https://cdn.example.com/maps/app.map"},
+		{"absolute", "a();\n//# sourceMappingURL=https://maps.example.com/app.map", "https://maps.example.com/app.map"},
+		{"deprecated form", "a();\n//@ sourceMappingURL=app.map", "https://cdn.example.com/static/js/app.map"},
+		{"last comment wins", "//# sourceMappingURL=old.map\na();\n//# sourceMappingURL=new.map", "https://cdn.example.com/static/js/new.map"},
+		{"inline", "a();\n//# sourceMappingURL=data:application/json;base64,e30=", "data:application/json;base64,e30="},
+		{"none", "a();", ""},
+		{"empty", "a();\n//# sourceMappingURL=\n", ""},
+		{"non-http scheme", "a();\n//# sourceMappingURL=file:///etc/passwd", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SourceMappingURL(bundle, []byte(tt.src)); got != tt.want {
+				t.Errorf("SourceMappingURL() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeInlineSourceMap(t *testing.T) {
+	want := `{"version":3,"sources":["a.js"]}`
+	got, err := DecodeInlineSourceMap("data:application/json;charset=utf-8;base64," + base64.StdEncoding.EncodeToString([]byte(want)))
+	if err != nil || string(got) != want {
+		t.Errorf("DecodeInlineSourceMap() = %q, %v; want %q", got, err, want)
+	}
+
+	if _, err := DecodeInlineSourceMap("data:application/json,{}"); err == nil {
+		t.Error("DecodeInlineSourceMap() should reject data: URLs that are not base64")
+	}
+}
+
+func TestParseSourceMap(t *testing.T) {
+	m, err := ParseSourceMap([]byte(`)]}'
+{"version":3,"sourceRoot":"src","sources":["a.js"],"sourcesContent":["a"]}`))
+	if err != nil {
+		t.Fatalf("ParseSourceMap() error = %v", err)
+	}
+	if len(m.Sources) != 1 || m.SourceRoot != "src" {
+		t.Errorf("ParseSourceMap() = %+v", m)
+	}
+
+	if _, err := ParseSourceMap([]byte(`{"version":2,"sources":[]}`)); err == nil {
+		t.Error("ParseSourceMap() should reject version 2 maps")
+	}
+	if _, err := ParseSourceMap([]byte(`<html>`)); err == nil {
+		t.Error("ParseSourceMap() should reject invalid JSON")
+	}
+}
+
+func TestParseSourceMap_Sections(t *testing.T) {
+	m, err := ParseSourceMap([]byte(`{"version":3,"sections":[
+		{"offset":{"line":0,"column":0},"map":{"version":3,"sourceRoot":"one","sources":["a.js"],"sourcesContent":["a"]}},
+		{"offset":{"line":9,"column":0},"map":{"version":3,"sources":["b.js","c.js"],"sourcesContent":["b"]}}
+	]}`))
+	if err != nil {
+		t.Fatalf("ParseSourceMap() error = %v", err)
+	}
+
+	wantSources := []string{"one/a.js", "b.js", "c.js"}
+	if len(m.Sources) != len(wantSources) || len(m.SourcesContent) != len(wantSources) {
+		t.Fatalf("ParseSourceMap() sources = %v, content = %d entries", m.Sources, len(m.SourcesContent))
+	}
+	for i, want := range wantSources {
+		if m.Sources[i] != want {
+			t.Errorf("source %d = %q, want %q", i, m.Sources[i], want)
+		}
+	}
+	if m.SourcesContent[2] != nil {
+		t.Error("a source without content should have a nil entry")
+	}
+}
+
+func TestExtractSources(t *testing.T) {
+	content := func(s string) *string { return &s }
+	m := &SourceMap{
+		Version: 3,
+		Sources: []string{
+			"webpack:///./src/app.js?5a1b",
+			"webpack:///../../../etc/evil.js",
+			"webpack:///./src/app.js",
+			"no-content.js",
+			"webpack:///",
+		},
+		SourcesContent: []*string{content("app"), content("evil"), content("again"), nil, content("empty name")},
+	}
+
+	dest := t.TempDir()
+	written, err := ExtractSources(m, dest)
+	if err != nil {
+		t.Fatalf("ExtractSources() error = %v", err)
+	}
+	if len(written) != 2 {
+		t.Fatalf("ExtractSources() wrote %v, want 2 files", written)
+	}
+
+	for name, want := range map[string]string{"src/app.js": "app", "etc/evil.js": "evil"} {
+		data, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dest, "no-content.js")); err == nil {
+		t.Error("a source without content should not be written")
+	}
+}
+
+func TestExtractSources_StaysInDest(t *testing.T) {
+	content := func(s string) *string { return &s }
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "dest")
+	outside := filepath.Join(dir, "outside")
+	if err := os.MkdirAll(dest, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(outside, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// Left by an earlier map or planted by the host: a symlinked directory
+	// and a symlink in place of a source file, both pointing outside dest
+	if err := os.Symlink(outside, filepath.Join(dest, "linked")); err != nil {
+		t.Skipf("symlinks unsupported: %v", err)
+	}
+	victim := filepath.Join(outside, "victim.js")
+	if err := os.WriteFile(victim, []byte("untouched"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(victim, filepath.Join(dest, "app.js")); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &SourceMap{
+		Version: 3,
+		Sources: []string{
+			"webpack:///..\\..\\outside\\evil.js",
+			"webpack:///linked/evil.js",
+			"webpack:///app.js",
+		},
+		SourcesContent: []*string{content("a"), content("b"), content("c")},
+	}
+	written, err := ExtractSources(m, dest)
+	if err == nil {
+		t.Error("ExtractSources() should report the sources it refused to write")
+	}
+
+	for _, path := range written {
+		if !strings.HasPrefix(path, dest+string(filepath.Separator)) {
+			t.Errorf("ExtractSources() wrote %s outside %s", path, dest)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(outside, "evil.js")); err == nil {
+		t.Error("a source was written outside dest")
+	}
+	if data, _ := os.ReadFile(victim); string(data) != "untouched" {
+		t.Errorf("a source was written through a symlink: victim = %q", data)
+	}
+}
@@ -0,0 +1,170 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Output formats
+const (
+	FormatText = "text" // The standard logger's lines, unchanged
+	FormatJSON = "json" // One JSON object per line
+)
+
+// ParseLevel parses a --log-level value: debug, info, warn or error ("" means info)
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q (valid: debug, info, warn, error)", s)
+	}
+}
+
+// ParseFormat parses a --log-format value: text or json ("" means text)
+func ParseFormat(s string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(s)); format {
+	case "":
+		return FormatText, nil
+	case FormatText, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q (valid: text, json)", s)
+	}
+}
+
+// Setup sends the standard logger's output, and the events given to Log,
+// to w at the given level and format. Log lines keep their "[TAG] message"
+// form: the tag sets the level ([ERROR], [WARN], [DEBUG]; anything else is
+// info), and in JSON format it becomes a field of the line's object.
+func Setup(w io.Writer, level, format string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	format, err = ParseFormat(format)
+	if err != nil {
+		return err
+	}
+
+	h := &handler{out: w, level: lvl}
+	if format == FormatJSON {
+		h.json = slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl}))
+	}
+	// The handler writes its own timestamps
+	log.SetFlags(0)
+	log.SetOutput(h)
+	return nil
+}
+
+// Log logs an event with fields. The message is what the text format
+// prints; the JSON format adds the fields. Without Setup, or while the
+// standard logger writes elsewhere (e.g. io.Discard), only the message is
+// passed to it.
+func Log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if h, ok := log.Writer().(*handler); ok {
+		h.log(level, msg, attrs)
+		return
+	}
+	log.Print(msg)
+}
+
+// Debug logs an event at debug level
+func Debug(msg string, attrs ...slog.Attr) { Log(slog.LevelDebug, msg, attrs...) }
+
+// Info logs an event at info level
+func Info(msg string, attrs ...slog.Attr) { Log(slog.LevelInfo, msg, attrs...) }
+
+// Warn logs an event at warn level
+func Warn(msg string, attrs ...slog.Attr) { Log(slog.LevelWarn, msg, attrs...) }
+
+// Error logs an event at error level
+func Error(msg string, attrs ...slog.Attr) { Log(slog.LevelError, msg, attrs...) }
+
+// handler is the standard logger's output once Setup has run
+type handler struct {
+	mu    sync.Mutex
+	out   io.Writer
+	level slog.Level
+	json  *slog.Logger // nil in text format
+}
+
+// Write logs one line of the standard logger at the level of its tag
+func (h *handler) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	tag, _ := splitTag(msg)
+	h.log(tagLevel(tag), msg, nil)
+	return len(p), nil
+}
+
+func (h *handler) log(level slog.Level, msg string, attrs []slog.Attr) {
+	if level < h.level {
+		return
+	}
+
+	if h.json != nil {
+		tag, text := splitTag(msg)
+		if text == "" {
+			// Blank and separator lines only space out the text format
+			return
+		}
+		if tag != "" {
+			attrs = append([]slog.Attr{slog.String("tag", tag)}, attrs...)
+		}
+		h.json.LogAttrs(context.Background(), level, text, attrs...)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	fmt.Fprintf(h.out, "%s %s\n", time.Now().Format("2006/01/02 15:04:05"), msg)
+}
+
+// splitTag splits "[WARN] message" into its tag and message. Leading
+// blank lines are dropped; bracketed text that is not an uppercase word,
+// such as a step counter ("[3/5]"), is not a tag.
+func splitTag(msg string) (tag, text string) {
+	text = strings.TrimSpace(msg)
+	if strings.Trim(text, "=-") == "" {
+		return "", ""
+	}
+	if !strings.HasPrefix(text, "[") {
+		return "", text
+	}
+	end := strings.Index(text, "]")
+	if end < 2 {
+		return "", text
+	}
+	for _, r := range text[1:end] {
+		if (r < 'A' || r > 'Z') && r != '_' {
+			return "", text
+		}
+	}
+	return text[1:end], strings.TrimSpace(text[end+1:])
+}
+
+// tagLevel returns the level of a line by its tag
+func tagLevel(tag string) slog.Level {
+	switch tag {
+	case "DEBUG":
+		return slog.LevelDebug
+	case "WARN":
+		return slog.LevelWarn
+	case "ERROR":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
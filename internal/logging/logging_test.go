@@ -0,0 +1,150 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+// setup routes the standard logger to a buffer for the test
+func setup(t *testing.T, level, format string) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Setup(&buf, level, format); err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+	t.Cleanup(func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+	return &buf
+}
+
+func TestSetup_TextLevels(t *testing.T) {
+	buf := setup(t, "warn", "text")
+
+	log.Printf("[OK] Downloaded https://a.example/app.js -> app.js (10 bytes, 1ms)")
+	log.Printf("\n[3/5] Downloading files with 2 workers...")
+	log.Printf("[WARN] HEAD not allowed for https://a.example/x")
+	log.Printf("[ERROR] Failed to download https://a.example/y: 404")
+	Info("[OK] Downloaded https://a.example/b.js -> b.js (5 bytes, 1ms)", slog.String("url", "https://a.example/b.js"))
+
+	out := buf.String()
+	for _, hidden := range []string{"[OK]", "[3/5]"} {
+		if strings.Contains(out, hidden) {
+			t.Errorf("--log-level warn should hide %s lines, got:\n%s", hidden, out)
+		}
+	}
+	for _, shown := range []string{"[WARN] HEAD not allowed for https://a.example/x\n", "[ERROR] Failed to download"} {
+		if !strings.Contains(out, shown) {
+			t.Errorf("output should contain %q, got:\n%s", shown, out)
+		}
+	}
+	// Fields stay out of text lines
+	if strings.Contains(out, "url=") {
+		t.Errorf("text format should print only the message, got:\n%s", out)
+	}
+}
+
+func TestSetup_JSON(t *testing.T) {
+	buf := setup(t, "info", "json")
+
+	Info("[OK] Downloaded https://a.example/app.js -> app.js (10 bytes, 1ms)",
+		slog.String("url", "https://a.example/app.js"),
+		slog.String("path", "app.js"),
+		slog.Int64("bytes", 10),
+		slog.Int64("duration_ms", 1))
+	log.Printf("[WARN] Cache unavailable")
+	log.Println("\n" + strings.Repeat("=", 60))
+	log.Printf("\n[3/5] Downloading files with 2 workers...")
+	Debug("[DEBUG] hidden at info")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d JSON lines, want 3:\n%s", len(lines), buf.String())
+	}
+
+	var entries []map[string]interface{}
+	for _, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("line is not JSON: %v\n%s", err, line)
+		}
+		entries = append(entries, entry)
+	}
+
+	ok := entries[0]
+	if ok["level"] != "INFO" || ok["tag"] != "OK" || ok["url"] != "https://a.example/app.js" ||
+		ok["path"] != "app.js" || ok["bytes"] != float64(10) || ok["duration_ms"] != float64(1) {
+		t.Errorf("download entry = %v", ok)
+	}
+	if msg, _ := ok["msg"].(string); !strings.HasPrefix(msg, "Downloaded ") {
+		t.Errorf("msg = %q, want the message without its tag", msg)
+	}
+	if entries[1]["level"] != "WARN" || entries[1]["msg"] != "Cache unavailable" {
+		t.Errorf("warning entry = %v", entries[1])
+	}
+	if entries[2]["msg"] != "[3/5] Downloading files with 2 workers..." || entries[2]["tag"] != nil {
+		t.Errorf("step entry = %v", entries[2])
+	}
+}
+
+func TestLog_WithoutSetup(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	Info("[OK] Downloaded x", slog.String("url", "x"))
+	if !strings.Contains(buf.String(), "[OK] Downloaded x\n") || strings.Contains(buf.String(), "url") {
+		t.Errorf("without Setup only the message should be logged, got %q", buf.String())
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		in   string
+		want slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{"error", slog.LevelError},
+	}
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("ParseLevel(verbose) should fail")
+	}
+	if _, err := ParseFormat("xml"); err == nil {
+		t.Error("ParseFormat(xml) should fail")
+	}
+}
+
+func TestSplitTag(t *testing.T) {
+	tests := []struct {
+		msg, tag, text string
+	}{
+		{"[WARN] something", "WARN", "something"},
+		{"[DUPLICATE] a", "DUPLICATE", "a"},
+		{"\n[3/5] Step", "", "[3/5] Step"},
+		{"[] empty", "", "[] empty"},
+		{"plain", "", "plain"},
+		{"=====", "", ""},
+	}
+	for _, tt := range tests {
+		tag, text := splitTag(tt.msg)
+		if tag != tt.tag || text != tt.text {
+			t.Errorf("splitTag(%q) = %q, %q; want %q, %q", tt.msg, tag, text, tt.tag, tt.text)
+		}
+	}
+}
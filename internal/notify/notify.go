@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Summary is what a notification tells about a completed run
+type Summary struct {
+	TotalURLs   int      `json:"total_urls"`
+	Successful  int      `json:"successful"`
+	Failed      int      `json:"failed"`
+	Skipped     int      `json:"skipped"` // Failed results that were only skipped by a filter
+	Secrets     int      `json:"secrets"`
+	DurationMs  int64    `json:"duration_ms"`
+	OutputDir   string   `json:"output_dir"`
+	FailedSteps []string `json:"failed_steps,omitempty"` // Steps after downloading that failed, such as reports or the archive
+}
+
+// Text is the one-line form of the summary used for chat webhooks
+func (s Summary) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "downurl run finished in %s: %d URLs, %d succeeded, %d failed",
+		(time.Duration(s.DurationMs) * time.Millisecond).Round(100*time.Millisecond), s.TotalURLs, s.Successful, s.Failed)
+	if s.Skipped > 0 {
+		fmt.Fprintf(&b, " (%d skipped)", s.Skipped)
+	}
+	if s.Secrets > 0 {
+		fmt.Fprintf(&b, ", *%d secrets found*", s.Secrets)
+	}
+	if len(s.FailedSteps) > 0 {
+		fmt.Fprintf(&b, "; failed steps: %s", strings.Join(s.FailedSteps, "; "))
+	}
+	fmt.Fprintf(&b, " (output: %s)", s.OutputDir)
+	return b.String()
+}
+
+// Notifier posts run summaries to a webhook. Slack incoming webhooks (and
+// Slack-compatible endpoints such as Discord's .../slack) get a message in
+// a "text" field; any other URL gets the Summary as JSON.
+type Notifier struct {
+	url    string
+	host   string // Scheme and host of url, the only part errors show
+	slack  bool
+	client *http.Client
+}
+
+// New returns a notifier for an http(s) webhook URL, giving up on a
+// request after timeout. Webhook URLs are credentials (the path of a Slack
+// webhook is its secret), so errors never include more than their host.
+func New(webhookURL string, timeout time.Duration) (*Notifier, error) {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook URL %s is not an http(s) URL", redact(u))
+	}
+	return &Notifier{
+		url:    webhookURL,
+		host:   redact(u),
+		slack:  isSlack(u),
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// redact returns the scheme and host of a webhook URL, without the path,
+// query or user info that may hold its secret
+func redact(u *url.URL) string {
+	if u.Scheme == "" {
+		return "(no scheme)"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// isSlack reports whether a webhook expects Slack's message payload
+func isSlack(u *url.URL) bool {
+	return strings.EqualFold(u.Hostname(), "hooks.slack.com") || strings.HasSuffix(u.Path, "/slack")
+}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify posts the summary of a run
+func (n *Notifier) Notify(ctx context.Context, s Summary) error {
+	var body []byte
+	var err error
+	if n.slack {
+		body, err = json.Marshal(slackMessage{Text: s.Text()})
+	} else {
+		body, err = json.Marshal(s)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request for %s", n.host)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// A *url.Error names the full webhook URL: keep only its cause
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("failed to send notification to %s: %w", n.host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// captureServer records the body of the last request and answers with status
+func captureServer(t *testing.T, status int, body *[]byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		buf := make([]byte, 4096)
+		n, _ := r.Body.Read(buf)
+		*body = buf[:n]
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+var testSummary = Summary{
+	TotalURLs:  10,
+	Successful: 8,
+	Failed:     2,
+	Secrets:    3,
+	DurationMs: 12345,
+	OutputDir:  "output",
+}
+
+func TestNotify_Generic(t *testing.T) {
+	var body []byte
+	server := captureServer(t, http.StatusNoContent, &body)
+
+	n, err := New(server.URL+"/hook", time.Second)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := n.Notify(context.Background(), testSummary); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	var got Summary
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("payload is not a summary: %v (%s)", err, body)
+	}
+	if got.TotalURLs != 10 || got.Successful != 8 || got.Failed != 2 || got.Secrets != 3 || got.DurationMs != 12345 {
+		t.Errorf("payload = %+v, want %+v", got, testSummary)
+	}
+}
+
+func TestNotify_Slack(t *testing.T) {
+	var body []byte
+	server := captureServer(t, http.StatusOK, &body)
+
+	// Slack-compatible endpoints end in /slack (Discord)
+	n, err := New(server.URL+"/api/webhooks/1/abc/slack", time.Second)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := n.Notify(context.Background(), testSummary); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(body, &got); err != nil {
+		t.Fatalf("payload is not JSON: %v (%s)", err, body)
+	}
+	text := got["text"]
+	if len(got) != 1 || text != testSummary.Text() {
+		t.Errorf("payload = %s, want only a text field", body)
+	}
+	for _, want := range []string{"10 URLs", "8 succeeded", "2 failed", "3 secrets found", "12.3s"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text %q does not mention %q", text, want)
+		}
+	}
+}
+
+func TestNotify_ErrorStatus(t *testing.T) {
+	var body []byte
+	server := captureServer(t, http.StatusForbidden, &body)
+
+	n, err := New(server.URL, time.Second)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := n.Notify(context.Background(), testSummary); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Notify() error = %v, want the 403 status", err)
+	}
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	for _, raw := range []string{"hooks.slack.com/services/x", "ftp://example.com/hook", "https://", "://bad"} {
+		if _, err := New(raw, time.Second); err == nil {
+			t.Errorf("New(%q) should fail", raw)
+		}
+	}
+}
+
+func TestIsSlack(t *testing.T) {
+	n, err := New("https://hooks.slack.com/services/T0/B0/xyz", time.Second)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if !n.slack {
+		t.Error("hooks.slack.com should get Slack messages")
+	}
+}
+
+func TestNotify_ErrorsHideWebhookPath(t *testing.T) {
+	// Nothing listens on the closed server, so the request fails
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	secret := "/services/T000/B000/s3cr3tT0k3n"
+
+	n, err := New(server.URL+secret, time.Second)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	err = n.Notify(context.Background(), testSummary)
+	if err == nil {
+		t.Fatal("Notify() expected an error")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || !strings.Contains(err.Error(), server.Listener.Addr().String()) {
+		t.Errorf("Notify() error = %q, want only the webhook's host", err)
+	}
+
+	for _, raw := range []string{"ftp://example.com" + secret, "https://example.com" + secret + "\x7f"} {
+		if _, err := New(raw, time.Second); err == nil || strings.Contains(err.Error(), "s3cr3t") {
+			t.Errorf("New(%q) error = %v, want an error without the path", raw, err)
+		}
+	}
+}
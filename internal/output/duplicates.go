@@ -0,0 +1,50 @@
+package output
+
+// DuplicateCluster is a set of URLs that returned identical bytes
+type DuplicateCluster struct {
+	SHA256    string   `json:"sha256"`
+	SizeBytes int64    `json:"size_bytes"`
+	URLs      []string `json:"urls"`  // In download order
+	Paths     []string `json:"paths"` // Where each URL was saved
+}
+
+// GroupDuplicates groups the successful downloads by SHA256 and records every
+// hash shared by more than one URL as a cluster, e.g. a bundle served under
+// several names or a CDN alias. Partial downloads are left out: their hash
+// only covers a prefix. Call it once all downloads are added.
+func (r *Reporter) GroupDuplicates() {
+	byHash := make(map[string]*DuplicateCluster)
+	var order []string
+	for _, d := range r.report.Downloads {
+		if d.Status != "success" || d.SHA256 == "" || d.Partial {
+			continue
+		}
+		c, ok := byHash[d.SHA256]
+		if !ok {
+			c = &DuplicateCluster{SHA256: d.SHA256, SizeBytes: d.SizeBytes}
+			byHash[d.SHA256] = c
+			order = append(order, d.SHA256)
+		}
+		c.URLs = append(c.URLs, d.URL)
+		c.Paths = append(c.Paths, d.Path)
+	}
+
+	stats := &r.report.Statistics
+	r.report.Duplicates = nil
+	stats.DuplicateClusters, stats.DuplicateFiles, stats.DuplicateBytes = 0, 0, 0
+	for _, sum := range order {
+		c := byHash[sum]
+		if len(c.URLs) < 2 {
+			continue
+		}
+		r.report.Duplicates = append(r.report.Duplicates, *c)
+		stats.DuplicateClusters++
+		stats.DuplicateFiles += len(c.URLs) - 1
+		stats.DuplicateBytes += int64(len(c.URLs)-1) * c.SizeBytes
+	}
+}
+
+// Duplicates returns the clusters found by GroupDuplicates
+func (r *Reporter) Duplicates() []DuplicateCluster {
+	return r.report.Duplicates
+}
@@ -4,10 +4,12 @@ import (
 	"encoding/csv"
 	"encoding/json"
 	"fmt"
-	"os"
+	"io"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
 	"github.com/lcalzada-xor/downurl/internal/scanner"
 )
 
@@ -19,14 +21,79 @@ const (
 	FormatJSON     Format = "json"
 	FormatCSV      Format = "csv"
 	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+	FormatNDJSON   Format = "ndjson" // One JSON object per result, written as each download completes
 )
 
+// ParseFormats parses a comma-separated list of output formats
+func ParseFormats(s string) ([]Format, error) {
+	var formats []Format
+	seen := make(map[Format]bool)
+
+	for _, part := range strings.Split(s, ",") {
+		format := Format(strings.ToLower(strings.TrimSpace(part)))
+		if format == "" {
+			continue
+		}
+
+		switch format {
+		case FormatText, FormatJSON, FormatCSV, FormatMarkdown, FormatHTML, FormatNDJSON:
+		default:
+			return nil, fmt.Errorf("unsupported output format: %s (use text, json, csv, markdown, html, ndjson)", format)
+		}
+
+		if !seen[format] {
+			seen[format] = true
+			formats = append(formats, format)
+		}
+	}
+
+	if len(formats) == 0 {
+		return []Format{FormatText}, nil
+	}
+	return formats, nil
+}
+
+// Extension returns the default file extension for the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatJSON:
+		return ".json"
+	case FormatCSV:
+		return ".csv"
+	case FormatMarkdown:
+		return ".md"
+	case FormatHTML:
+		return ".html"
+	case FormatNDJSON:
+		return ".ndjson"
+	default:
+		return ".txt"
+	}
+}
+
+// ReportPath returns where a report in the given format should be written.
+// If override is set it is used as-is for a single format, or as the base name
+// (extension swapped per format) when several formats are generated.
+// An override of "-" (stdout) is kept as-is.
+func ReportPath(format Format, outputDir, override string, multiple bool) string {
+	if override == "" {
+		return filepath.Join(outputDir, "report"+format.Extension())
+	}
+	if !multiple || override == "-" {
+		return override
+	}
+	return strings.TrimSuffix(override, filepath.Ext(override)) + format.Extension()
+}
+
 // ScanReport represents a complete scan report
 type ScanReport struct {
-	Metadata  Metadata                  `json:"metadata"`
-	Downloads []DownloadInfo            `json:"downloads"`
-	Findings  Findings                  `json:"findings"`
-	Statistics Statistics               `json:"statistics"`
+	Metadata     Metadata           `json:"metadata"`
+	Downloads    []DownloadInfo     `json:"downloads"`
+	Findings     Findings           `json:"findings"`
+	Statistics   Statistics         `json:"statistics"`
+	Duplicates   []DuplicateCluster `json:"duplicates,omitempty"`    // URLs that returned identical bytes
+	PhaseTimings map[string]float64 `json:"phase_timings,omitempty"` // Seconds spent in each pipeline phase
 }
 
 // Metadata contains scan metadata
@@ -41,35 +108,72 @@ type Metadata struct {
 
 // DownloadInfo contains download information
 type DownloadInfo struct {
-	URL          string    `json:"url"`
-	Path         string    `json:"path"`
-	SizeBytes    int64     `json:"size_bytes"`
-	ContentType  string    `json:"content_type"`
-	SHA256       string    `json:"sha256,omitempty"`
-	DownloadedAt time.Time `json:"downloaded_at"`
-	Status       string    `json:"status"`
-	Error        string    `json:"error,omitempty"`
+	URL                 string    `json:"url"`
+	Path                string    `json:"path"`
+	SizeBytes           int64     `json:"size_bytes"`
+	ContentType         string    `json:"content_type"`
+	DeclaredContentType string    `json:"declared_content_type,omitempty"` // Content-Type sent by the server
+	SniffedContentType  string    `json:"sniffed_content_type,omitempty"`  // Type detected from the body and extension
+	Category            string    `json:"category,omitempty"`              // Category of the sniffed type (JavaScript, HTML, Image, ...)
+	SHA256              string    `json:"sha256,omitempty"`
+	GzipSizeBytes       int64     `json:"gzip_size_bytes,omitempty"` // Size after gzip (text files, with --measure-gzip)
+	DownloadedAt        time.Time `json:"downloaded_at"`
+	Status              string    `json:"status"`
+	Error               string    `json:"error,omitempty"`
+	ErrorCategory       string    `json:"error_category,omitempty"`
+	Note                string    `json:"note,omitempty"`          // Why the file was not processed (e.g. "skipped: binary")
+	Partial             bool      `json:"partial,omitempty"`       // Only the first --preview-bytes were downloaded
+	HashVerified        bool      `json:"hash_verified,omitempty"` // Matched its digest in the --verify-hashes file
+
+	// Request audit (--report-request-headers)
+	RequestHeaders map[string]string `json:"request_headers,omitempty"` // Headers sent, credential values redacted
+	AuthType       string            `json:"auth_type,omitempty"`       // Authentication applied: none, bearer, basic, custom
 }
 
 // Findings contains all findings
 type Findings struct {
-	Secrets   []scanner.SecretFinding   `json:"secrets,omitempty"`
-	Endpoints []scanner.EndpointFinding `json:"endpoints,omitempty"`
+	Secrets      []scanner.SecretFinding       `json:"secrets,omitempty"`
+	Entropy      []scanner.SecretFinding       `json:"entropy,omitempty"` // High-entropy strings kept apart from Secrets (--entropy-output)
+	Endpoints    []scanner.EndpointFinding     `json:"endpoints,omitempty"`
+	MixedContent []scanner.MixedContentFinding `json:"mixed_content,omitempty"`
+	Custom       []CustomFinding               `json:"custom,omitempty"` // Added by registered file processors
+}
+
+// CustomFinding is a finding reported by a file processor outside the
+// built-in scanners
+type CustomFinding struct {
+	Processor string `json:"processor"`      // Name of the processor that made it
+	Type      string `json:"type"`           // What was found, as the processor names it
+	URL       string `json:"url"`            // URL the file was downloaded from
+	File      string `json:"file"`           // Saved file
+	Line      int    `json:"line,omitempty"` // Line in the file, if known
+	Detail    string `json:"detail,omitempty"`
 }
 
 // Statistics contains download statistics
 type Statistics struct {
-	TotalFiles         int            `json:"total_files"`
-	TotalSizeBytes     int64          `json:"total_size_bytes"`
-	ByContentType      map[string]int `json:"by_content_type"`
-	SecretsCount       int            `json:"secrets_count"`
-	EndpointsCount     int            `json:"endpoints_count"`
-	HighConfidenceSecrets int         `json:"high_confidence_secrets"`
+	TotalFiles            int            `json:"total_files"`
+	TotalSizeBytes        int64          `json:"total_size_bytes"`
+	ByContentType         map[string]int `json:"by_content_type"`
+	SecretsCount          int            `json:"secrets_count"`
+	EndpointsCount        int            `json:"endpoints_count"`
+	HighConfidenceSecrets int            `json:"high_confidence_secrets"`
+	EntropyCount          int            `json:"entropy_count,omitempty"` // High-entropy strings moved out of the secrets
+	MixedContentCount     int            `json:"mixed_content_count"`
+	CustomCount           int            `json:"custom_count,omitempty"`          // Findings of registered file processors
+	GzipFiles             int            `json:"gzip_files,omitempty"`            // Files with a measured gzip size
+	GzipRawSizeBytes      int64          `json:"gzip_raw_size_bytes,omitempty"`   // Raw size of those files
+	TotalGzipSizeBytes    int64          `json:"total_gzip_size_bytes,omitempty"` // Their combined gzip size
+	ByErrorCategory       map[string]int `json:"by_error_category,omitempty"`
+	DuplicateClusters     int            `json:"duplicate_clusters,omitempty"` // Hashes shared by several URLs
+	DuplicateFiles        int            `json:"duplicate_files,omitempty"`    // Downloads beyond the first of each cluster
+	DuplicateBytes        int64          `json:"duplicate_bytes,omitempty"`    // Their combined size
 }
 
 // Reporter generates output in different formats
 type Reporter struct {
-	report ScanReport
+	report      ScanReport
+	summaryOnly bool // Count downloads into the statistics without keeping them
 }
 
 // NewReporter creates a new reporter
@@ -82,7 +186,8 @@ func NewReporter() *Reporter {
 				Endpoints: []scanner.EndpointFinding{},
 			},
 			Statistics: Statistics{
-				ByContentType: make(map[string]int),
+				ByContentType:   make(map[string]int),
+				ByErrorCategory: make(map[string]int),
 			},
 		},
 	}
@@ -93,9 +198,23 @@ func (r *Reporter) SetMetadata(meta Metadata) {
 	r.report.Metadata = meta
 }
 
+// SetPhaseTimings sets the per-phase durations, in seconds
+func (r *Reporter) SetPhaseTimings(timings map[string]float64) {
+	r.report.PhaseTimings = timings
+}
+
+// SetSummaryOnly makes AddDownload only update the statistics, so memory
+// stays flat however many files a run downloads (--stream-results). Reports
+// then have no per-download entries and no duplicate clusters.
+func (r *Reporter) SetSummaryOnly(summaryOnly bool) {
+	r.summaryOnly = summaryOnly
+}
+
 // AddDownload adds a download to the report
 func (r *Reporter) AddDownload(info DownloadInfo) {
-	r.report.Downloads = append(r.report.Downloads, info)
+	if !r.summaryOnly {
+		r.report.Downloads = append(r.report.Downloads, info)
+	}
 
 	// Update statistics
 	if info.Status == "success" {
@@ -105,12 +224,23 @@ func (r *Reporter) AddDownload(info DownloadInfo) {
 		if info.ContentType != "" {
 			r.report.Statistics.ByContentType[info.ContentType]++
 		}
+		if info.GzipSizeBytes > 0 {
+			r.report.Statistics.GzipFiles++
+			r.report.Statistics.GzipRawSizeBytes += info.SizeBytes
+			r.report.Statistics.TotalGzipSizeBytes += info.GzipSizeBytes
+		}
+	} else if info.ErrorCategory != "" {
+		r.report.Statistics.ByErrorCategory[info.ErrorCategory]++
 	}
 }
 
 // AddSecrets adds secret findings
 func (r *Reporter) AddSecrets(secrets []scanner.SecretFinding) {
-	r.report.Findings.Secrets = append(r.report.Findings.Secrets, secrets...)
+	for _, s := range secrets {
+		s.Severity = scanner.SecretSeverity(s)
+		r.report.Findings.Secrets = append(r.report.Findings.Secrets, s)
+	}
+	scanner.SortSecretsBySeverity(r.report.Findings.Secrets)
 	r.report.Statistics.SecretsCount = len(r.report.Findings.Secrets)
 
 	// Count high confidence secrets
@@ -121,21 +251,108 @@ func (r *Reporter) AddSecrets(secrets []scanner.SecretFinding) {
 	}
 }
 
+// SetSecrets replaces the secret findings, e.g. with only the new ones of a diffed run
+func (r *Reporter) SetSecrets(secrets []scanner.SecretFinding) {
+	r.report.Findings.Secrets = []scanner.SecretFinding{}
+	r.report.Statistics.SecretsCount = 0
+	r.report.Statistics.HighConfidenceSecrets = 0
+	r.AddSecrets(secrets)
+}
+
+// SeparateEntropy moves the high-entropy string findings out of the secrets
+// into their own section, so the secrets keep only pattern matches
+func (r *Reporter) SeparateEntropy() {
+	var secrets []scanner.SecretFinding
+	for _, s := range r.report.Findings.Secrets {
+		if s.SecretType == scanner.SecretTypeGenericHigh {
+			r.report.Findings.Entropy = append(r.report.Findings.Entropy, s)
+		} else {
+			secrets = append(secrets, s)
+		}
+	}
+	r.report.Statistics.EntropyCount = len(r.report.Findings.Entropy)
+	r.SetSecrets(secrets)
+}
+
 // AddEndpoints adds endpoint findings
 func (r *Reporter) AddEndpoints(endpoints []scanner.EndpointFinding) {
-	r.report.Findings.Endpoints = append(r.report.Findings.Endpoints, endpoints...)
+	for _, e := range endpoints {
+		e.Severity = scanner.EndpointSeverity(e)
+		r.report.Findings.Endpoints = append(r.report.Findings.Endpoints, e)
+	}
+	scanner.SortEndpointsBySeverity(r.report.Findings.Endpoints)
 	r.report.Statistics.EndpointsCount = len(r.report.Findings.Endpoints)
 }
 
-// GenerateJSON generates JSON output
-func (r *Reporter) GenerateJSON(filepath string, pretty bool) error {
-	file, err := os.Create(filepath)
+// AddMixedContent adds mixed content findings
+func (r *Reporter) AddMixedContent(findings []scanner.MixedContentFinding) {
+	r.report.Findings.MixedContent = append(r.report.Findings.MixedContent, findings...)
+	r.report.Statistics.MixedContentCount = len(r.report.Findings.MixedContent)
+}
+
+// AddFinding adds a finding of a registered file processor
+func (r *Reporter) AddFinding(f CustomFinding) {
+	r.report.Findings.Custom = append(r.report.Findings.Custom, f)
+	r.report.Statistics.CustomCount = len(r.report.Findings.Custom)
+}
+
+// Generate writes the report in the given format to a file, or to stdout if filepath is "-".
+// FormatText is not handled here; plain text reports come from the reporter package.
+func (r *Reporter) Generate(format Format, filepath string, pretty bool) error {
+	switch format {
+	case FormatJSON, FormatCSV, FormatMarkdown, FormatHTML:
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
+	}
+
+	w, err := createOutput(filepath)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return err
+	}
+	defer w.Close()
+
+	return r.Write(format, w, pretty)
+}
+
+// Write writes the report in the given format to w
+func (r *Reporter) Write(format Format, w io.Writer, pretty bool) error {
+	switch format {
+	case FormatJSON:
+		return r.writeJSON(w, pretty)
+	case FormatCSV:
+		return r.writeCSV(w)
+	case FormatMarkdown:
+		return r.writeMarkdown(w)
+	case FormatHTML:
+		return r.writeHTML(w)
+	default:
+		return fmt.Errorf("unsupported report format: %s", format)
 	}
-	defer file.Close()
+}
 
-	encoder := json.NewEncoder(file)
+// GenerateJSON generates JSON output
+func (r *Reporter) GenerateJSON(filepath string, pretty bool) error {
+	return r.Generate(FormatJSON, filepath, pretty)
+}
+
+// GenerateCSV generates CSV output
+func (r *Reporter) GenerateCSV(filepath string) error {
+	return r.Generate(FormatCSV, filepath, false)
+}
+
+// GenerateMarkdown generates Markdown output
+func (r *Reporter) GenerateMarkdown(filepath string) error {
+	return r.Generate(FormatMarkdown, filepath, false)
+}
+
+// GenerateHTML generates a self-contained HTML report
+func (r *Reporter) GenerateHTML(filepath string) error {
+	return r.Generate(FormatHTML, filepath, false)
+}
+
+// writeJSON writes the report as JSON
+func (r *Reporter) writeJSON(w io.Writer, pretty bool) error {
+	encoder := json.NewEncoder(w)
 	if pretty {
 		encoder.SetIndent("", "  ")
 	}
@@ -147,19 +364,13 @@ func (r *Reporter) GenerateJSON(filepath string, pretty bool) error {
 	return nil
 }
 
-// GenerateCSV generates CSV output
-func (r *Reporter) GenerateCSV(filepath string) error {
-	file, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
+// writeCSV writes the downloads as CSV rows
+func (r *Reporter) writeCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
 	defer writer.Flush()
 
 	// Write header
-	header := []string{"URL", "Path", "Size", "ContentType", "SHA256", "Status", "Error"}
+	header := []string{"URL", "Path", "Size", "ContentType", "SHA256", "Status", "Error", "ErrorCategory", "GzipSize"}
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
@@ -174,6 +385,8 @@ func (r *Reporter) GenerateCSV(filepath string) error {
 			download.SHA256,
 			download.Status,
 			download.Error,
+			download.ErrorCategory,
+			gzipSizeField(download.GzipSizeBytes),
 		}
 		if err := writer.Write(row); err != nil {
 			return fmt.Errorf("failed to write row: %w", err)
@@ -183,14 +396,8 @@ func (r *Reporter) GenerateCSV(filepath string) error {
 	return nil
 }
 
-// GenerateMarkdown generates Markdown output
-func (r *Reporter) GenerateMarkdown(filepath string) error {
-	file, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
+// writeMarkdown writes the report as Markdown
+func (r *Reporter) writeMarkdown(w io.Writer) error {
 	var md strings.Builder
 
 	// Title
@@ -209,15 +416,32 @@ func (r *Reporter) GenerateMarkdown(filepath string) error {
 	md.WriteString("## Statistics\n\n")
 	md.WriteString(fmt.Sprintf("- **Total Files**: %d\n", r.report.Statistics.TotalFiles))
 	md.WriteString(fmt.Sprintf("- **Total Size**: %s\n", formatBytes(r.report.Statistics.TotalSizeBytes)))
+	if stats := r.report.Statistics; stats.GzipFiles > 0 {
+		md.WriteString(fmt.Sprintf("- **Gzip Size**: %s of %s across %d text files (%.1f%%)\n",
+			formatBytes(stats.TotalGzipSizeBytes), formatBytes(stats.GzipRawSizeBytes), stats.GzipFiles,
+			float64(stats.TotalGzipSizeBytes)/float64(stats.GzipRawSizeBytes)*100))
+	}
 	md.WriteString(fmt.Sprintf("- **Secrets Found**: %d (High Confidence: %d)\n",
 		r.report.Statistics.SecretsCount, r.report.Statistics.HighConfidenceSecrets))
-	md.WriteString(fmt.Sprintf("- **Endpoints Found**: %d\n\n", r.report.Statistics.EndpointsCount))
+	if r.report.Statistics.EntropyCount > 0 {
+		md.WriteString(fmt.Sprintf("- **High-Entropy Strings**: %d (not counted as secrets)\n", r.report.Statistics.EntropyCount))
+	}
+	md.WriteString(fmt.Sprintf("- **Endpoints Found**: %d\n", r.report.Statistics.EndpointsCount))
+	md.WriteString(fmt.Sprintf("- **Mixed Content**: %d\n", r.report.Statistics.MixedContentCount))
+	if r.report.Statistics.CustomCount > 0 {
+		md.WriteString(fmt.Sprintf("- **Custom Findings**: %d\n", r.report.Statistics.CustomCount))
+	}
+	if stats := r.report.Statistics; stats.DuplicateClusters > 0 {
+		md.WriteString(fmt.Sprintf("- **Duplicate Content**: %d clusters, %d redundant files (%s)\n",
+			stats.DuplicateClusters, stats.DuplicateFiles, formatBytes(stats.DuplicateBytes)))
+	}
+	md.WriteString("\n")
 
 	// Content Types
 	if len(r.report.Statistics.ByContentType) > 0 {
 		md.WriteString("### Files by Content Type\n\n")
 		for contentType, count := range r.report.Statistics.ByContentType {
-			md.WriteString(fmt.Sprintf("- %s: %d files\n", contentType, count))
+			md.WriteString(fmt.Sprintf("- %s: %d files\n", sanitize.Text(contentType), count))
 		}
 		md.WriteString("\n")
 	}
@@ -245,9 +469,13 @@ func (r *Reporter) GenerateMarkdown(filepath string) error {
 		if len(highConfidence) > 0 {
 			md.WriteString("### ⚠️ High Confidence\n\n")
 			for _, secret := range highConfidence {
-				md.WriteString(fmt.Sprintf("- **%s**\n", secret.SecretType))
-				md.WriteString(fmt.Sprintf("  - File: `%s:%d`\n", secret.File, secret.Line))
-				md.WriteString(fmt.Sprintf("  - Match: `%s`\n", secret.Match))
+				md.WriteString(fmt.Sprintf("- **%s** (severity %d)\n", secret.SecretType, secret.Severity))
+				if secret.Partial {
+					md.WriteString(fmt.Sprintf("  - File: `%s:%d` (partial download)\n", sanitize.Text(secret.File), secret.Line))
+				} else {
+					md.WriteString(fmt.Sprintf("  - File: `%s:%d`\n", sanitize.Text(secret.File), secret.Line))
+				}
+				md.WriteString(fmt.Sprintf("  - Match: `%s`\n", sanitize.Text(secret.Match)))
 				md.WriteString("\n")
 			}
 		}
@@ -255,8 +483,8 @@ func (r *Reporter) GenerateMarkdown(filepath string) error {
 		if len(mediumConfidence) > 0 {
 			md.WriteString("### ⚡ Medium Confidence\n\n")
 			for _, secret := range mediumConfidence {
-				md.WriteString(fmt.Sprintf("- **%s**: `%s` in `%s:%d`\n",
-					secret.SecretType, secret.Match, secret.File, secret.Line))
+				md.WriteString(fmt.Sprintf("- **%s** (severity %d): `%s` in `%s:%d`\n",
+					secret.SecretType, secret.Severity, sanitize.Text(secret.Match), sanitize.Text(secret.File), secret.Line))
 			}
 			md.WriteString("\n")
 		}
@@ -270,33 +498,87 @@ func (r *Reporter) GenerateMarkdown(filepath string) error {
 	if len(r.report.Findings.Endpoints) > 0 {
 		md.WriteString("## 🌐 Endpoints Discovered\n\n")
 
-		// Group by type
+		// Group by type; findings are sorted by severity, so the group
+		// holding the most severe endpoint comes first
 		byType := make(map[scanner.EndpointType][]scanner.EndpointFinding)
+		var types []scanner.EndpointType
 		for _, endpoint := range r.report.Findings.Endpoints {
+			if _, ok := byType[endpoint.Type]; !ok {
+				types = append(types, endpoint.Type)
+			}
 			byType[endpoint.Type] = append(byType[endpoint.Type], endpoint)
 		}
 
-		for endpointType, endpoints := range byType {
+		for _, endpointType := range types {
+			endpoints := byType[endpointType]
 			md.WriteString(fmt.Sprintf("### %s (%d)\n\n", endpointType, len(endpoints)))
 			for _, endpoint := range endpoints {
 				method := string(endpoint.Method)
 				if method == "" {
 					method = "GET"
 				}
-				md.WriteString(fmt.Sprintf("- `%s %s`\n", method, endpoint.Endpoint))
+				md.WriteString(fmt.Sprintf("- `%s %s` (severity %d)\n", sanitize.Text(method), sanitize.Text(endpoint.Endpoint), endpoint.Severity))
+			}
+			md.WriteString("\n")
+		}
+	}
+
+	// Mixed content
+	if len(r.report.Findings.MixedContent) > 0 {
+		md.WriteString("## 🔓 Mixed Content\n\n")
+		for _, m := range r.report.Findings.MixedContent {
+			kind := "passive"
+			if m.Active {
+				kind = "active"
+			}
+			md.WriteString(fmt.Sprintf("- `%s` (%s, `<%s %s>`) on %s line %d\n", sanitize.Text(m.Resource), kind, m.Tag, m.Attribute, sanitize.Text(m.URL), m.Line))
+		}
+		md.WriteString("\n")
+	}
+
+	// Duplicate content
+	if len(r.report.Duplicates) > 0 {
+		md.WriteString("## 📑 Duplicate Content\n\n")
+		for _, c := range r.report.Duplicates {
+			md.WriteString(fmt.Sprintf("- `%s` (%s, %d URLs)\n", c.SHA256[:12], formatBytes(c.SizeBytes), len(c.URLs)))
+			for _, u := range c.URLs {
+				md.WriteString(fmt.Sprintf("  - %s\n", sanitize.Text(u)))
+			}
+		}
+		md.WriteString("\n")
+	}
+
+	// Findings of registered file processors
+	if len(r.report.Findings.Custom) > 0 {
+		md.WriteString("## 🧩 Custom Findings\n\n")
+		for _, f := range r.report.Findings.Custom {
+			md.WriteString(fmt.Sprintf("- **%s** (%s) in %s", sanitize.Text(f.Type), sanitize.Text(f.Processor), sanitize.Text(f.URL)))
+			if f.Line > 0 {
+				md.WriteString(fmt.Sprintf(" line %d", f.Line))
+			}
+			if f.Detail != "" {
+				md.WriteString(": " + sanitize.Text(f.Detail))
 			}
 			md.WriteString("\n")
 		}
+		md.WriteString("\n")
 	}
 
-	// Write to file
-	if _, err := file.WriteString(md.String()); err != nil {
+	if _, err := io.WriteString(w, md.String()); err != nil {
 		return fmt.Errorf("failed to write markdown: %w", err)
 	}
 
 	return nil
 }
 
+// gzipSizeField renders a gzip size for CSV, leaving it empty when not measured
+func gzipSizeField(size int64) string {
+	if size == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", size)
+}
+
 // formatBytes formats bytes into human-readable format
 func formatBytes(bytes int64) string {
 	const unit = 1024
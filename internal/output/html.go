@@ -0,0 +1,150 @@
+package output
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// htmlStyle is the inline stylesheet for HTML reports
+const htmlStyle = `body { font-family: -apple-system, Segoe UI, Helvetica, Arial, sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 2em; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 6px 10px; text-align: left; font-size: 14px; }
+th { background: #f4f4f4; }
+code { background: #f6f8fa; padding: 1px 4px; }
+.high { color: #b00020; font-weight: bold; }
+.medium { color: #c77700; }
+.low { color: #666; }`
+
+// writeHTML writes the report as a self-contained HTML page
+func (r *Reporter) writeHTML(w io.Writer) error {
+	var sb strings.Builder
+	esc := html.EscapeString
+
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>Download Scan Report</title>\n")
+	sb.WriteString("<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	sb.WriteString("<h1>Download Scan Report</h1>\n")
+
+	// Metadata
+	meta := r.report.Metadata
+	sb.WriteString("<h2>Scan Information</h2>\n<ul>\n")
+	sb.WriteString(fmt.Sprintf("<li><b>Start Time</b>: %s</li>\n", meta.StartTime.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("<li><b>End Time</b>: %s</li>\n", meta.EndTime.Format(time.RFC3339)))
+	sb.WriteString(fmt.Sprintf("<li><b>Duration</b>: %.2f seconds</li>\n", meta.DurationSeconds))
+	sb.WriteString(fmt.Sprintf("<li><b>Total URLs</b>: %d</li>\n", meta.TotalURLs))
+	sb.WriteString(fmt.Sprintf("<li><b>Successful</b>: %d</li>\n", meta.Successful))
+	sb.WriteString(fmt.Sprintf("<li><b>Failed</b>: %d</li>\n</ul>\n", meta.Failed))
+
+	// Statistics
+	stats := r.report.Statistics
+	sb.WriteString("<h2>Statistics</h2>\n<ul>\n")
+	sb.WriteString(fmt.Sprintf("<li><b>Total Files</b>: %d</li>\n", stats.TotalFiles))
+	sb.WriteString(fmt.Sprintf("<li><b>Total Size</b>: %s</li>\n", formatBytes(stats.TotalSizeBytes)))
+	if stats.GzipFiles > 0 {
+		sb.WriteString(fmt.Sprintf("<li><b>Gzip Size</b>: %s of %s across %d text files</li>\n",
+			formatBytes(stats.TotalGzipSizeBytes), formatBytes(stats.GzipRawSizeBytes), stats.GzipFiles))
+	}
+	sb.WriteString(fmt.Sprintf("<li><b>Secrets Found</b>: %d (High Confidence: %d)</li>\n",
+		stats.SecretsCount, stats.HighConfidenceSecrets))
+	sb.WriteString(fmt.Sprintf("<li><b>Endpoints Found</b>: %d</li>\n", stats.EndpointsCount))
+	sb.WriteString(fmt.Sprintf("<li><b>Mixed Content</b>: %d</li>\n", stats.MixedContentCount))
+	if stats.CustomCount > 0 {
+		sb.WriteString(fmt.Sprintf("<li><b>Custom Findings</b>: %d</li>\n", stats.CustomCount))
+	}
+	if stats.DuplicateClusters > 0 {
+		sb.WriteString(fmt.Sprintf("<li><b>Duplicate Content</b>: %d clusters, %d redundant files (%s)</li>\n",
+			stats.DuplicateClusters, stats.DuplicateFiles, formatBytes(stats.DuplicateBytes)))
+	}
+	sb.WriteString("</ul>\n")
+
+	// Downloads
+	if len(r.report.Downloads) > 0 {
+		sb.WriteString("<h2>Downloads</h2>\n<table>\n")
+		sb.WriteString("<tr><th>URL</th><th>Path</th><th>Size</th><th>Content Type</th><th>Status</th><th>Error</th></tr>\n")
+		for _, d := range r.report.Downloads {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				esc(d.URL), esc(d.Path), formatBytes(d.SizeBytes), esc(d.ContentType), esc(d.Status), esc(d.Error)))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	// Secrets
+	if len(r.report.Findings.Secrets) > 0 {
+		sb.WriteString("<h2>Secrets Found</h2>\n<table>\n")
+		sb.WriteString("<tr><th>Severity</th><th>Confidence</th><th>Type</th><th>Match</th><th>Location</th></tr>\n")
+		for _, s := range r.report.Findings.Secrets {
+			sb.WriteString(fmt.Sprintf("<tr><td>%d</td><td class=\"%s\">%s</td><td>%s</td><td><code>%s</code></td><td><code>%s:%d</code></td></tr>\n",
+				s.Severity, esc(string(s.Confidence)), esc(string(s.Confidence)), esc(string(s.SecretType)), esc(s.Match), esc(s.File), s.Line))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	// Endpoints
+	if len(r.report.Findings.Endpoints) > 0 {
+		sb.WriteString("<h2>Endpoints Discovered</h2>\n<table>\n")
+		sb.WriteString("<tr><th>Severity</th><th>Method</th><th>Endpoint</th><th>Type</th><th>Source</th></tr>\n")
+
+		// Already sorted by severity
+		for _, e := range r.report.Findings.Endpoints {
+			method := string(e.Method)
+			if method == "" {
+				method = "GET"
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td>%d</td><td>%s</td><td><code>%s</code></td><td>%s</td><td>%s</td></tr>\n",
+				e.Severity, esc(method), esc(e.Endpoint), esc(string(e.Type)), esc(e.URL)))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	// Mixed content
+	if len(r.report.Findings.MixedContent) > 0 {
+		sb.WriteString("<h2>Mixed Content</h2>\n<table>\n")
+		sb.WriteString("<tr><th>Resource</th><th>Kind</th><th>Element</th><th>Page</th><th>Line</th></tr>\n")
+		for _, m := range r.report.Findings.MixedContent {
+			kind, class := "passive", "medium"
+			if m.Active {
+				kind, class = "active", "high"
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td><code>%s</code></td><td class=\"%s\">%s</td><td><code>&lt;%s %s&gt;</code></td><td>%s</td><td>%d</td></tr>\n",
+				esc(m.Resource), class, kind, esc(m.Tag), esc(m.Attribute), esc(m.URL), m.Line))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	// Duplicate content
+	if len(r.report.Duplicates) > 0 {
+		sb.WriteString("<h2>Duplicate Content</h2>\n<table>\n")
+		sb.WriteString("<tr><th>SHA256</th><th>Size</th><th>URLs</th></tr>\n")
+		for _, c := range r.report.Duplicates {
+			urls := make([]string, len(c.URLs))
+			for i, u := range c.URLs {
+				urls[i] = esc(u)
+			}
+			sb.WriteString(fmt.Sprintf("<tr><td><code>%s</code></td><td>%s</td><td>%s</td></tr>\n",
+				esc(c.SHA256), formatBytes(c.SizeBytes), strings.Join(urls, "<br>")))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	// Findings of registered file processors
+	if len(r.report.Findings.Custom) > 0 {
+		sb.WriteString("<h2>Custom Findings</h2>\n<table>\n")
+		sb.WriteString("<tr><th>Processor</th><th>Type</th><th>Detail</th><th>Location</th></tr>\n")
+		for _, f := range r.report.Findings.Custom {
+			sb.WriteString(fmt.Sprintf("<tr><td>%s</td><td>%s</td><td>%s</td><td><code>%s:%d</code></td></tr>\n",
+				esc(f.Processor), esc(f.Type), esc(f.Detail), esc(f.File), f.Line))
+		}
+		sb.WriteString("</table>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return fmt.Errorf("failed to write html: %w", err)
+	}
+
+	return nil
+}
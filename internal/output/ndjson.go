@@ -0,0 +1,85 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// ResultRecord is the NDJSON form of a download result
+type ResultRecord struct {
+	URL          string                 `json:"url"`
+	Host         string                 `json:"host"`
+	Status       string                 `json:"status"` // success, failed or skipped
+	Paths        []string               `json:"paths"`
+	Bytes        int64                  `json:"bytes"`
+	ContentType  string                 `json:"content_type,omitempty"`
+	DurationMs   int64                  `json:"duration_ms"`
+	Errors       []models.DownloadError `json:"errors,omitempty"`
+	Partial      bool                   `json:"partial,omitempty"`
+	HashVerified bool                   `json:"hash_verified,omitempty"`
+}
+
+// NewResultRecord builds the NDJSON record of a download result
+func NewResultRecord(r *models.DownloadResult) ResultRecord {
+	rec := ResultRecord{
+		URL:          r.URL,
+		Host:         r.Host,
+		Status:       "failed",
+		Paths:        r.Downloaded,
+		Bytes:        r.Bytes,
+		ContentType:  r.ContentType,
+		DurationMs:   r.Duration.Milliseconds(),
+		Errors:       r.Failures,
+		Partial:      r.Partial,
+		HashVerified: r.Verified,
+	}
+	switch models.ResultEvent(r).Type {
+	case models.EventSucceeded:
+		rec.Status = "success"
+	case models.EventSkipped:
+		rec.Status = "skipped"
+	}
+	if rec.Paths == nil {
+		rec.Paths = []string{}
+	}
+	return rec
+}
+
+// NDJSONWriter writes one JSON object per line for each download result,
+// as soon as it is added, so other tools can consume a run while it goes on
+type NDJSONWriter struct {
+	w   io.WriteCloser
+	enc *json.Encoder
+}
+
+// CreateNDJSON opens an NDJSON destination: a new file, or stdout if filepath is "-"
+func CreateNDJSON(filepath string) (*NDJSONWriter, error) {
+	w, err := createOutput(filepath)
+	if err != nil {
+		return nil, err
+	}
+	return NewNDJSONWriter(w), nil
+}
+
+// NewNDJSONWriter returns a writer emitting records to w, which is closed by Close
+func NewNDJSONWriter(w io.WriteCloser) *NDJSONWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &NDJSONWriter{w: w, enc: enc}
+}
+
+// Add writes the record of one result, unbuffered
+func (n *NDJSONWriter) Add(r *models.DownloadResult) error {
+	if err := n.enc.Encode(NewResultRecord(r)); err != nil {
+		return fmt.Errorf("failed to write NDJSON: %w", err)
+	}
+	return nil
+}
+
+// Close closes the destination
+func (n *NDJSONWriter) Close() error {
+	return n.w.Close()
+}
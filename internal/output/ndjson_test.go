@@ -0,0 +1,69 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestNDJSONWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.ndjson")
+	w, err := CreateNDJSON(path)
+	if err != nil {
+		t.Fatalf("CreateNDJSON() error = %v", err)
+	}
+
+	ok := &models.DownloadResult{
+		URL:        "https://example.com/app.js",
+		Host:       "example.com",
+		Downloaded: []string{"output/app.js"},
+		Bytes:      42,
+		Duration:   1500 * time.Millisecond,
+	}
+	failed := &models.DownloadResult{URL: "https://example.com/missing.js", Host: "example.com"}
+	failed.AddError(models.DownloadError{Category: models.ErrorCategoryHTTP4xx, Message: "HTTP 404", StatusCode: 404})
+	skipped := &models.DownloadResult{URL: "https://example.com/logo.png", Host: "example.com"}
+	skipped.AddError(models.DownloadError{Category: models.ErrorCategorySkipped, Message: "skipped: extension"})
+
+	for _, r := range []*models.DownloadResult{ok, failed, skipped} {
+		if err := w.Add(r); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	var records []ResultRecord
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var rec ResultRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			t.Fatalf("line %q is not JSON: %v", scanner.Text(), err)
+		}
+		records = append(records, rec)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	if r := records[0]; r.Status != "success" || r.Bytes != 42 || r.DurationMs != 1500 || len(r.Paths) != 1 || r.Host != "example.com" {
+		t.Errorf("success record = %+v", r)
+	}
+	if r := records[1]; r.Status != "failed" || len(r.Errors) != 1 || r.Errors[0].StatusCode != 404 || r.Paths == nil {
+		t.Errorf("failed record = %+v", r)
+	}
+	if r := records[2]; r.Status != "skipped" {
+		t.Errorf("skipped record status = %q", r.Status)
+	}
+}
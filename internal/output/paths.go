@@ -0,0 +1,63 @@
+package output
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// WritePaths writes one "url<TAB>path" line per successfully downloaded file
+func WritePaths(w io.Writer, results []models.DownloadResult) error {
+	bw := bufio.NewWriter(w)
+	for _, r := range results {
+		if !r.IsSuccess() {
+			continue
+		}
+		for _, path := range r.Downloaded {
+			if _, err := fmt.Fprintf(bw, "%s\t%s\n", r.URL, path); err != nil {
+				return fmt.Errorf("failed to write paths: %w", err)
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// SavePaths writes the path list to a file, or to stdout if filepath is "-"
+func SavePaths(filepath string, results []models.DownloadResult) error {
+	w, err := CreatePaths(filepath)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	return WritePaths(w, results)
+}
+
+// CreatePaths opens a path list destination for incremental writes:
+// a new file, or stdout if filepath is "-"
+func CreatePaths(filepath string) (io.WriteCloser, error) {
+	return createOutput(filepath)
+}
+
+// createOutput creates a file, or returns stdout if filepath is "-"
+func createOutput(filepath string) (io.WriteCloser, error) {
+	if filepath == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	file, err := os.Create(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	return file, nil
+}
+
+// nopCloser keeps stdout open when an output is closed
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,42 @@
+package output
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestSavePaths(t *testing.T) {
+	results := []models.DownloadResult{
+		{URL: "https://example.com/app.js", Downloaded: []string{"output/example.com/app.js"}},
+		{URL: "https://example.com/missing.js", Errors: []string{"HTTP 404"}},
+		{URL: "https://cdn.example.com/style.css", Downloaded: []string{"output/cdn.example.com/style.css"}},
+	}
+
+	path := filepath.Join(t.TempDir(), "paths.tsv")
+	if err := SavePaths(path, results); err != nil {
+		t.Fatalf("SavePaths() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read paths file: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	want := []string{
+		"https://example.com/app.js\toutput/example.com/app.js",
+		"https://cdn.example.com/style.css\toutput/cdn.example.com/style.css",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("SavePaths() wrote %d lines, want %d: %q", len(lines), len(want), lines)
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i+1, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Byte order marks recognised at the start of URL lists
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// decodeInput returns a UTF-8 reader for input that may start with a byte order mark.
+// UTF-8 BOMs are stripped; UTF-16 (LE/BE) input is transcoded to UTF-8.
+func decodeInput(reader io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(reader)
+
+	// Peek returns fewer bytes (and an error) for short inputs, which is fine here
+	head, _ := br.Peek(3)
+
+	switch {
+	case bytes.HasPrefix(head, bomUTF8):
+		br.Discard(len(bomUTF8))
+		return br, nil
+	case bytes.HasPrefix(head, bomUTF16LE):
+		br.Discard(len(bomUTF16LE))
+		return decodeUTF16(br, false)
+	case bytes.HasPrefix(head, bomUTF16BE):
+		br.Discard(len(bomUTF16BE))
+		return decodeUTF16(br, true)
+	default:
+		return br, nil
+	}
+}
+
+// decodeUTF16 reads the remaining UTF-16 input and transcodes it to UTF-8
+func decodeUTF16(reader io.Reader, bigEndian bool) (io.Reader, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read UTF-16 input: %w", err)
+	}
+	if len(data)%2 != 0 {
+		return nil, fmt.Errorf("invalid UTF-16 input: odd number of bytes")
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+
+	return strings.NewReader(string(utf16.Decode(units))), nil
+}
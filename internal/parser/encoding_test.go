@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, bigEndian bool) []byte {
+	var buf bytes.Buffer
+	if bigEndian {
+		buf.Write(bomUTF16BE)
+	} else {
+		buf.Write(bomUTF16LE)
+	}
+	for _, u := range utf16.Encode([]rune(s)) {
+		if bigEndian {
+			buf.WriteByte(byte(u >> 8))
+			buf.WriteByte(byte(u))
+		} else {
+			buf.WriteByte(byte(u))
+			buf.WriteByte(byte(u >> 8))
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestParseURLsFromFile_Encodings(t *testing.T) {
+	const list = "https://example.com/app.js\r\n# comment\r\n\r\nhttp://cdn.example.com/style.css\r\n"
+	want := []string{"https://example.com/app.js", "http://cdn.example.com/style.css"}
+
+	tests := []struct {
+		name    string
+		content []byte
+	}{
+		{"plain CRLF", []byte(list)},
+		{"UTF-8 BOM", append(append([]byte{}, bomUTF8...), list...)},
+		{"UTF-8 BOM with LF", append(append([]byte{}, bomUTF8...), strings.ReplaceAll(list, "\r\n", "\n")...)},
+		{"UTF-16 LE", encodeUTF16(list, false)},
+		{"UTF-16 BE", encodeUTF16(list, true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "urls.txt")
+			if err := os.WriteFile(path, tt.content, 0644); err != nil {
+				t.Fatalf("Failed to create test file: %v", err)
+			}
+
+			urls, err := ParseURLsFromFile(path)
+			if err != nil {
+				t.Fatalf("ParseURLsFromFile() error = %v", err)
+			}
+			if len(urls) != len(want) {
+				t.Fatalf("ParseURLsFromFile() = %q, want %q", urls, want)
+			}
+			for i := range want {
+				if urls[i] != want[i] {
+					t.Errorf("url[%d] = %q, want %q", i, urls[i], want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseURLsFromReader_BOMOnly(t *testing.T) {
+	urls, err := parseURLsFromReader(bytes.NewReader(bomUTF8), "test")
+	if err != nil {
+		t.Fatalf("parseURLsFromReader() error = %v", err)
+	}
+	if len(urls) != 0 {
+		t.Errorf("parseURLsFromReader() = %q, want no URLs", urls)
+	}
+}
+
+func TestParseURLsFromReader_OddUTF16(t *testing.T) {
+	data := append(append([]byte{}, bomUTF16LE...), 'h', 0, 't')
+	if _, err := parseURLsFromReader(bytes.NewReader(data), "test"); err == nil {
+		t.Error("parseURLsFromReader() expected error for truncated UTF-16 input")
+	}
+}
@@ -0,0 +1,136 @@
+package parser
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Entry is one input line: a URL plus the headers annotated on it.
+// JSON-lines records can also set the method and body.
+type Entry struct {
+	URL     string
+	Headers http.Header // Per-URL headers from -H annotations or a record's "headers" (nil if none)
+	Method  string      // HTTP method from a JSON record ("" = GET)
+	Body    string      // Request body from a JSON record
+	HasBody bool        // Body was given, even if empty
+}
+
+// ParseEntriesFromFile reads URLs and their per-line header annotations from a file.
+// If some lines are invalid, the valid entries are returned with a ParseErrors
+// error listing each invalid line.
+func ParseEntriesFromFile(filepath string) ([]Entry, error) {
+	file, err := os.Open(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return parseEntriesFromReader(file, filepath)
+}
+
+// ParseEntriesFromStdin reads URLs and their per-line header annotations from stdin
+func ParseEntriesFromStdin() ([]Entry, error) {
+	return parseEntriesFromReader(os.Stdin, "stdin")
+}
+
+// URLs returns the URL of every entry
+func URLs(entries []Entry) []string {
+	urls := make([]string, len(entries))
+	for i, e := range entries {
+		urls[i] = e.URL
+	}
+	return urls
+}
+
+// splitLine separates a line into its URL and -H header annotations:
+//
+//	https://api.example.com/v1/data  -H "X-Tenant: a" -H 'X-Key: abc'
+//
+// A line without a -H token is returned whole as the URL.
+func splitLine(line string) (string, http.Header, error) {
+	start := annotationStart(line)
+	if start == -1 {
+		return line, nil, nil
+	}
+
+	rawURL := strings.TrimSpace(line[:start])
+	tokens, err := tokenize(line[start:])
+	if err != nil {
+		return "", nil, err
+	}
+
+	headers := make(http.Header)
+	for i := 0; i < len(tokens); i += 2 {
+		if tokens[i] != "-H" {
+			return "", nil, fmt.Errorf("unexpected %q (expected -H)", tokens[i])
+		}
+		if i+1 >= len(tokens) {
+			return "", nil, fmt.Errorf("-H without a header")
+		}
+
+		name, value, ok := strings.Cut(tokens[i+1], ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return "", nil, fmt.Errorf("invalid header %q (expected 'Name: value')", tokens[i+1])
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+
+	return rawURL, headers, nil
+}
+
+// annotationStart returns the index of the first whitespace-separated -H token, or -1
+func annotationStart(line string) int {
+	for i := 1; i+1 < len(line); i++ {
+		if line[i] != '-' || line[i+1] != 'H' {
+			continue
+		}
+		before := line[i-1]
+		afterOK := i+2 == len(line) || line[i+2] == ' ' || line[i+2] == '\t'
+		if (before == ' ' || before == '\t') && afterOK {
+			return i
+		}
+	}
+	return -1
+}
+
+// tokenize splits a line on whitespace, keeping single- or double-quoted text together
+func tokenize(line string) ([]string, error) {
+	var tokens []string
+	var cur strings.Builder
+	var quote rune
+	inToken := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+	if inToken {
+		tokens = append(tokens, cur.String())
+	}
+	return tokens, nil
+}
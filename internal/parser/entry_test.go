@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		wantURL string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"plain", "https://example.com/a.js", "https://example.com/a.js", nil, false},
+		{"double quoted", `https://example.com/a.js  -H "X-Tenant: a"`, "https://example.com/a.js", map[string]string{"X-Tenant": "a"}, false},
+		{"single quoted and several", `https://example.com/a.js -H 'X-Tenant: b' -H "Authorization: Bearer t 1"`, "https://example.com/a.js",
+			map[string]string{"X-Tenant": "b", "Authorization": "Bearer t 1"}, false},
+		{"tab separated", "https://example.com/a.js\t-H\tX-Key:abc", "https://example.com/a.js", map[string]string{"X-Key": "abc"}, false},
+		{"-H inside URL is not an annotation", "https://example.com/a-H.js?q=-H", "https://example.com/a-H.js?q=-H", nil, false},
+		{"quote in plain URL", "https://example.com/it's.js", "https://example.com/it's.js", nil, false},
+		{"missing header", "https://example.com/a.js -H", "", nil, true},
+		{"not name: value", `https://example.com/a.js -H "novalue"`, "", nil, true},
+		{"unterminated quote", `https://example.com/a.js -H "X-Tenant: a`, "", nil, true},
+		{"stray token", `https://example.com/a.js -H "X-A: 1" extra`, "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotURL, headers, err := splitLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitLine() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotURL != tt.wantURL {
+				t.Errorf("splitLine() URL = %q, want %q", gotURL, tt.wantURL)
+			}
+			if len(headers) != len(tt.want) {
+				t.Errorf("splitLine() headers = %v, want %v", headers, tt.want)
+			}
+			for name, value := range tt.want {
+				if got := headers.Get(name); got != value {
+					t.Errorf("splitLine() header %s = %q, want %q", name, got, value)
+				}
+			}
+		})
+	}
+}
+
+func TestParseEntriesFromFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "urls.txt")
+
+	content := `https://api.example.com/tenant-a  -H "X-Tenant: a"
+https://api.example.com/tenant-b  -H "X-Tenant: b" -H "X-Key: kb"
+https://cdn.example.com/app.js
+`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	entries, err := ParseEntriesFromFile(testFile)
+	if err != nil {
+		t.Fatalf("ParseEntriesFromFile() error = %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("ParseEntriesFromFile() got %d entries, want 3", len(entries))
+	}
+
+	if got := entries[0].Headers.Get("X-Tenant"); got != "a" {
+		t.Errorf("tenant-a X-Tenant = %q, want %q", got, "a")
+	}
+	if got := entries[1].Headers.Get("X-Key"); got != "kb" {
+		t.Errorf("tenant-b X-Key = %q, want %q", got, "kb")
+	}
+	if entries[2].Headers != nil {
+		t.Errorf("unannotated entry headers = %v, want nil", entries[2].Headers)
+	}
+
+	// The plain URL list drops the annotations
+	urls, err := ParseURLsFromFile(testFile)
+	if err != nil {
+		t.Fatalf("ParseURLsFromFile() error = %v", err)
+	}
+	if urls[0] != "https://api.example.com/tenant-a" {
+		t.Errorf("ParseURLsFromFile()[0] = %q, want the bare URL", urls[0])
+	}
+
+	// Bad annotations are reported with their line
+	bad := filepath.Join(tmpDir, "bad.txt")
+	os.WriteFile(bad, []byte("https://example.com/a.js\nhttps://example.com/b.js -H nope\n"), 0644)
+	if _, err := ParseEntriesFromFile(bad); err == nil {
+		t.Error("ParseEntriesFromFile() expected error for invalid annotation")
+	}
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LineError is an input line that could not be parsed
+type LineError struct {
+	Line int    // 1-based line number
+	Text string // The line, trimmed
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *LineError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors lists every invalid line of an input, in order
+type ParseErrors []*LineError
+
+func (e ParseErrors) Error() string {
+	lines := make([]string, len(e))
+	for i, le := range e {
+		lines[i] = le.Error()
+	}
+	return fmt.Sprintf("%d invalid line(s):\n  %s", len(e), strings.Join(lines, "\n  "))
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckEntries_ReportsEveryInvalidLine(t *testing.T) {
+	input := `https://example.com/a.js
+ftp://example.com/file.zip
+# comment
+http://
+https://example.com/b.js -H "broken
+{"url": "https://example.com/c.js", "header": {}}
+https://example.com/d.js
+`
+	entries, invalid, err := CheckEntries(strings.NewReader(input), "test")
+	if err != nil {
+		t.Fatalf("CheckEntries() error = %v", err)
+	}
+
+	if got := URLs(entries); len(got) != 2 || got[0] != "https://example.com/a.js" || got[1] != "https://example.com/d.js" {
+		t.Errorf("CheckEntries() valid URLs = %v, want a.js and d.js", got)
+	}
+
+	wantLines := []int{2, 4, 5, 6}
+	if len(invalid) != len(wantLines) {
+		t.Fatalf("CheckEntries() reported %d invalid lines, want %d: %v", len(invalid), len(wantLines), invalid)
+	}
+	for i, le := range invalid {
+		if le.Line != wantLines[i] {
+			t.Errorf("invalid[%d].Line = %d, want %d", i, le.Line, wantLines[i])
+		}
+	}
+	if msg := invalid.Error(); !strings.Contains(msg, "line 2: invalid URL scheme") || !strings.Contains(msg, "line 4: invalid URL (missing host)") {
+		t.Errorf("ParseErrors.Error() = %q, want each line and reason", msg)
+	}
+
+}
@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// record is one JSON-lines input entry:
+//
+//	{"url": "https://api.example.com/search", "method": "POST", "headers": {"Content-Type": "application/json"}, "body": "{\"q\":\"x\"}"}
+type record struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Body    *string           `json:"body"`
+}
+
+// isRecord reports whether an input line is a JSON record rather than a URL
+func isRecord(line string) bool {
+	return strings.HasPrefix(line, "{")
+}
+
+// parseRecord decodes a JSON-lines entry. Only "url" is required; a record
+// with a body but no method is sent as POST, and one with neither as GET.
+// Unknown fields are rejected so typos such as "header" don't go unnoticed.
+func parseRecord(line string) (Entry, error) {
+	dec := json.NewDecoder(strings.NewReader(line))
+	dec.DisallowUnknownFields()
+
+	var rec record
+	if err := dec.Decode(&rec); err != nil {
+		return Entry{}, fmt.Errorf("invalid JSON record: %w", err)
+	}
+	if dec.More() {
+		return Entry{}, fmt.Errorf("invalid JSON record: more than one object on the line")
+	}
+	return rec.entry()
+}
+
+// entry validates a decoded record and turns it into an Entry
+func (rec record) entry() (Entry, error) {
+	entry := Entry{URL: strings.TrimSpace(rec.URL)}
+	if entry.URL == "" {
+		return Entry{}, fmt.Errorf("JSON record has no \"url\"")
+	}
+
+	entry.Method = strings.ToUpper(strings.TrimSpace(rec.Method))
+	if entry.Method != "" && !isToken(entry.Method) {
+		return Entry{}, fmt.Errorf("invalid method %q", rec.Method)
+	}
+	if rec.Body != nil {
+		entry.Body = *rec.Body
+		entry.HasBody = true
+		if entry.Method == "" {
+			entry.Method = http.MethodPost
+		}
+	}
+
+	if len(rec.Headers) > 0 {
+		entry.Headers = make(http.Header)
+		for name, value := range rec.Headers {
+			name = strings.TrimSpace(name)
+			if !isToken(name) {
+				return Entry{}, fmt.Errorf("invalid header name %q", name)
+			}
+			entry.Headers.Set(name, value)
+		}
+	}
+
+	return entry, nil
+}
+
+// isToken reports whether s is a valid HTTP token (method or header name)
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r <= ' ' || r >= 0x7f || strings.ContainsRune(`()<>@,;:\"/[]?={}`, r) {
+			return false
+		}
+	}
+	return true
+}
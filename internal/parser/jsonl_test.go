@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseEntriesFromFile_JSONL(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "jobs.jsonl")
+
+	content := `{"url":"https://api.example.com/search","method":"post","headers":{"Content-Type":"application/json","X-Tenant":"a"},"body":"{\"q\":\"js\"}"}
+{"url":"https://api.example.com/items","body":"id=1"}
+{"url":"https://api.example.com/item/1","method":"DELETE"}
+# plain lines still work alongside records
+https://cdn.example.com/app.js -H "X-Key: k"
+`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	entries, err := ParseEntriesFromFile(testFile)
+	if err != nil {
+		t.Fatalf("ParseEntriesFromFile() error = %v", err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("ParseEntriesFromFile() got %d entries, want 4", len(entries))
+	}
+
+	search := entries[0]
+	if search.Method != "POST" || search.Body != `{"q":"js"}` || !search.HasBody {
+		t.Errorf("search entry = %+v, want POST with JSON body", search)
+	}
+	if got := search.Headers.Get("Content-Type"); got != "application/json" {
+		t.Errorf("search Content-Type = %q, want application/json", got)
+	}
+	if got := search.Headers.Get("X-Tenant"); got != "a" {
+		t.Errorf("search X-Tenant = %q, want a", got)
+	}
+
+	// A body without a method is a POST
+	if items := entries[1]; items.Method != "POST" || items.Body != "id=1" {
+		t.Errorf("items entry = %+v, want POST id=1", items)
+	}
+	if del := entries[2]; del.Method != "DELETE" || del.HasBody {
+		t.Errorf("delete entry = %+v, want DELETE without body", del)
+	}
+	if plain := entries[3]; plain.Method != "" || plain.Headers.Get("X-Key") != "k" {
+		t.Errorf("plain entry = %+v, want GET with X-Key", plain)
+	}
+}
+
+func TestParseRecord_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{"missing url", `{"method":"POST"}`, "no \"url\""},
+		{"bad JSON", `{"url":"https://a.com/x"`, "invalid JSON"},
+		{"unknown field", `{"url":"https://a.com/x","header":{"A":"b"}}`, "unknown field"},
+		{"bad method", `{"url":"https://a.com/x","method":"GE T"}`, "invalid method"},
+		{"bad header name", `{"url":"https://a.com/x","headers":{"X A":"b"}}`, "invalid header name"},
+		{"two objects", `{"url":"https://a.com/x"} {"url":"https://a.com/y"}`, "more than one"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseRecord(tt.line)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseRecord() error = %v, want it to mention %q", err, tt.want)
+			}
+		})
+	}
+
+	// Records go through the same scheme and host checks as plain lines
+	tmpDir := t.TempDir()
+	for _, line := range []string{`{"url":"ftp://a.com/x"}`, `{"url":"https:///x"}`} {
+		bad := filepath.Join(tmpDir, "bad.jsonl")
+		os.WriteFile(bad, []byte(line+"\n"), 0644)
+		if _, err := ParseEntriesFromFile(bad); err == nil {
+			t.Errorf("ParseEntriesFromFile(%s) expected error", line)
+		}
+	}
+}
@@ -14,14 +14,48 @@ func ParseURLsFromStdin() ([]string, error) {
 	return parseURLsFromReader(os.Stdin, "stdin")
 }
 
-// ParseURLsFromReader reads URLs from any reader
+// parseURLsFromReader reads URLs from any reader, ignoring header annotations.
+// Like parseEntriesFromReader, it returns the valid URLs along with any ParseErrors.
 func parseURLsFromReader(reader io.Reader, source string) ([]string, error) {
-	var urls []string
+	entries, err := parseEntriesFromReader(reader, source)
+	return URLs(entries), err
+}
+
+// parseEntriesFromReader reads URLs and their -H header annotations from any
+// reader. Invalid lines don't stop it: the valid entries are returned along
+// with a ParseErrors error listing every invalid line, so a whole list can be
+// fixed in one go (or its valid part used anyway).
+func parseEntriesFromReader(reader io.Reader, source string) ([]Entry, error) {
+	entries, invalid, err := CheckEntries(reader, source)
+	if err != nil {
+		return nil, err
+	}
+	if len(invalid) > 0 {
+		return entries, invalid
+	}
+	return entries, nil
+}
+
+// CheckEntries reads URLs and their -H header annotations from any reader,
+// returning the valid entries and every invalid line rather than stopping at
+// the first. The error is only set if the input could not be read.
+// Lines starting with '{' are JSON records (see parseRecord), so plain and
+// JSON-lines input can be mixed.
+func CheckEntries(reader io.Reader, source string) ([]Entry, ParseErrors, error) {
+	// Handle BOM-prefixed input (e.g. lists saved by Windows tools)
+	reader, err := decodeInput(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
+
+	var entries []Entry
+	var invalid ParseErrors
 	scanner := bufio.NewScanner(reader)
 	lineNum := 0
 
 	for scanner.Scan() {
 		lineNum++
+		// TrimSpace also drops the '\r' left over from CRLF line endings
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip empty lines and comments
@@ -29,30 +63,43 @@ func parseURLsFromReader(reader io.Reader, source string) ([]string, error) {
 			continue
 		}
 
-		// Validate URL
-		parsedURL, err := url.Parse(line)
+		entry, err := parseLine(line)
 		if err != nil {
-			return nil, fmt.Errorf("invalid URL at line %d: %s", lineNum, line)
+			invalid = append(invalid, &LineError{Line: lineNum, Text: line, Err: err})
+			continue
 		}
+		entries = append(entries, entry)
+	}
 
-		// Validate URL scheme (only http and https allowed)
-		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-			return nil, fmt.Errorf("invalid URL scheme at line %d: %s (only http/https allowed)", lineNum, parsedURL.Scheme)
-		}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
 
-		// Validate hostname exists
-		if parsedURL.Host == "" {
-			return nil, fmt.Errorf("invalid URL (missing host) at line %d: %s", lineNum, line)
-		}
+	return entries, invalid, nil
+}
 
-		urls = append(urls, line)
+// parseLine parses one non-empty input line and validates its URL
+func parseLine(line string) (Entry, error) {
+	var entry Entry
+	var err error
+	if isRecord(line) {
+		// JSON record carrying url, method, headers and body
+		entry, err = parseRecord(line)
+		if err != nil {
+			return Entry{}, err
+		}
+	} else {
+		// Split off per-URL header annotations
+		entry.URL, entry.Headers, err = splitLine(line)
+		if err != nil {
+			return Entry{}, fmt.Errorf("invalid header annotation: %w", err)
+		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading from %s: %w", source, err)
+	if _, err := ParseSingleURL(entry.URL); err != nil {
+		return Entry{}, err
 	}
-
-	return urls, nil
+	return entry, nil
 }
 
 // IsStdinAvailable checks if there's data available on stdin
@@ -0,0 +1,234 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Input formats recognised from a file's extension
+const (
+	FormatText = "text" // One URL per line, with -H annotations and JSON records
+	FormatJSON = "json" // A JSON array of URLs or {"url": ...} objects
+	FormatCSV  = "csv"  // A CSV file with the URLs in one column
+)
+
+// InputFormat returns the format of an input file from its extension:
+// .json is FormatJSON, .csv is FormatCSV and anything else FormatText
+func InputFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatText
+	}
+}
+
+// ParseEntriesFromInput reads an input file in the format given by its
+// extension (see InputFormat). csvColumn picks the URL column of a CSV file.
+// Like ParseEntriesFromFile, invalid entries are listed in a ParseErrors
+// error returned along with the valid ones.
+func ParseEntriesFromInput(path, csvColumn string) ([]Entry, error) {
+	format := InputFormat(path)
+	if format == FormatText {
+		return ParseEntriesFromFile(path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	var invalid ParseErrors
+	if format == FormatJSON {
+		entries, invalid, err = CheckJSON(file, path)
+	} else {
+		entries, invalid, err = CheckCSV(file, path, csvColumn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(invalid) > 0 {
+		return entries, invalid
+	}
+	return entries, nil
+}
+
+// ParseURLsFromJSON reads the URLs of a JSON array file (see CheckJSON)
+func ParseURLsFromJSON(path string) ([]string, error) {
+	entries, err := ParseEntriesFromInput(path, "")
+	return URLs(entries), err
+}
+
+// ParseURLsFromCSV reads the URLs in one column of a CSV file (see CheckCSV)
+func ParseURLsFromCSV(path, column string) ([]string, error) {
+	entries, err := ParseEntriesFromInput(path, column)
+	return URLs(entries), err
+}
+
+// CheckJSON reads a JSON array whose elements are URL strings or objects
+// with at least a "url", and optionally the "method", "headers" and "body"
+// of the JSON-lines record format:
+//
+//	["https://example.com/app.js", {"url": "https://example.com/api", "method": "POST"}]
+//
+// Like CheckEntries, it returns the valid entries and every invalid element,
+// numbered by the line it starts on. The error is only set if the input
+// could not be read or is not a JSON array.
+func CheckJSON(reader io.Reader, source string) ([]Entry, ParseErrors, error) {
+	reader, err := decodeInput(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return nil, nil, fmt.Errorf("invalid JSON in %s: expected an array of URLs", source)
+	}
+
+	var entries []Entry
+	var invalid ParseErrors
+	for dec.More() {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, nil, fmt.Errorf("invalid JSON in %s: %w", source, err)
+		}
+		// The element ends at the decoder's offset and starts len(raw) before it
+		lineNum := 1 + bytes.Count(data[:dec.InputOffset()-int64(len(raw))], []byte("\n"))
+
+		entry, err := parseJSONElement(raw)
+		if err == nil {
+			_, err = ParseSingleURL(entry.URL)
+		}
+		if err != nil {
+			invalid = append(invalid, &LineError{Line: lineNum, Text: string(raw), Err: err})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, nil, fmt.Errorf("invalid JSON in %s: %w", source, err)
+	}
+
+	return entries, invalid, nil
+}
+
+// parseJSONElement turns one element of a JSON input array into an entry
+func parseJSONElement(raw json.RawMessage) (Entry, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return Entry{URL: strings.TrimSpace(s)}, nil
+	}
+	// Unlike JSON-lines records, exported inventories often carry fields of
+	// their own (name, owner, ...), so unknown fields are ignored here
+	var rec record
+	if err := json.Unmarshal(raw, &rec); err == nil {
+		return rec.entry()
+	}
+	return Entry{}, fmt.Errorf("expected a URL string or an object with \"url\"")
+}
+
+// CheckCSV reads the URLs in one column of CSV input. column is a header
+// name or a 1-based column number; empty means the column headed "url" if
+// there is one, or else the first. A first row that names the column rather
+// than holding a URL is taken as the header and skipped.
+//
+// Like CheckEntries, it returns the valid entries and every invalid row.
+// Empty cells are skipped. The error is only set if the input could not be
+// read, is not valid CSV, or has no such column.
+func CheckCSV(reader io.Reader, source, column string) ([]Entry, ParseErrors, error) {
+	reader, err := decodeInput(reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error reading from %s: %w", source, err)
+	}
+
+	r := csv.NewReader(reader)
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+
+	var entries []Entry
+	var invalid ParseErrors
+	col := -1
+	for first := true; ; first = false {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid CSV in %s: %w", source, err)
+		}
+
+		if first {
+			var header bool
+			if col, header, err = csvColumn(row, column); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", source, err)
+			}
+			if header {
+				continue
+			}
+		}
+
+		if col >= len(row) {
+			continue
+		}
+		cell := strings.TrimSpace(row[col])
+		if cell == "" {
+			continue
+		}
+		if _, err := ParseSingleURL(cell); err != nil {
+			line, _ := r.FieldPos(col)
+			invalid = append(invalid, &LineError{Line: line, Text: cell, Err: err})
+			continue
+		}
+		entries = append(entries, Entry{URL: cell})
+	}
+
+	return entries, invalid, nil
+}
+
+// csvColumn finds the URL column from the first row and reports whether that
+// row is a header rather than data
+func csvColumn(first []string, column string) (int, bool, error) {
+	column = strings.TrimSpace(column)
+	if n, err := strconv.Atoi(column); err == nil {
+		if n < 1 {
+			return 0, false, fmt.Errorf("invalid CSV column %d (columns are numbered from 1)", n)
+		}
+		return n - 1, n <= len(first) && !isURLCell(first[n-1]), nil
+	}
+
+	name := column
+	if name == "" {
+		name = "url"
+	}
+	for i, cell := range first {
+		if strings.EqualFold(strings.TrimSpace(cell), name) {
+			return i, true, nil
+		}
+	}
+	if column != "" {
+		return 0, false, fmt.Errorf("no CSV column named %q", column)
+	}
+	// No "url" header: the first column, under whatever header it has
+	return 0, len(first) > 0 && !isURLCell(first[0]), nil
+}
+
+// isURLCell reports whether a CSV cell holds a valid URL
+func isURLCell(cell string) bool {
+	_, err := ParseSingleURL(strings.TrimSpace(cell))
+	return err == nil
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInputFormat(t *testing.T) {
+	tests := map[string]string{
+		"urls.txt":       FormatText,
+		"urls":           FormatText,
+		"inventory.json": FormatJSON,
+		"EXPORT.CSV":     FormatCSV,
+		"jobs.jsonl":     FormatText,
+	}
+	for path, want := range tests {
+		if got := InputFormat(path); got != want {
+			t.Errorf("InputFormat(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParseURLsFromJSON(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "inventory.json")
+	content := `[
+  "https://example.com/app.js",
+  {"url": "https://example.com/api", "method": "POST", "owner": "team-a"},
+  "ftp://example.com/file",
+  42
+]`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	urls, err := ParseURLsFromJSON(testFile)
+	want := []string{"https://example.com/app.js", "https://example.com/api"}
+	if !reflect.DeepEqual(urls, want) {
+		t.Errorf("ParseURLsFromJSON() = %v, want %v", urls, want)
+	}
+
+	var invalid ParseErrors
+	if !errors.As(err, &invalid) || len(invalid) != 2 {
+		t.Fatalf("ParseURLsFromJSON() error = %v, want 2 invalid elements", err)
+	}
+	if invalid[0].Line != 4 || invalid[1].Line != 5 {
+		t.Errorf("invalid lines = %d, %d, want 4, 5", invalid[0].Line, invalid[1].Line)
+	}
+}
+
+func TestCheckJSON_Records(t *testing.T) {
+	entries, invalid, err := CheckJSON(strings.NewReader(`[{"url":"https://example.com/search","headers":{"X-Tenant":"a"},"body":"q=1"}]`), "test")
+	if err != nil || len(invalid) != 0 {
+		t.Fatalf("CheckJSON() = %v, %v", invalid, err)
+	}
+	if len(entries) != 1 || entries[0].Method != "POST" || entries[0].Headers.Get("X-Tenant") != "a" {
+		t.Errorf("CheckJSON() entries = %+v, want a POST with X-Tenant", entries)
+	}
+
+	for _, bad := range []string{`{"url":"https://example.com"}`, `["https://example.com"`, `not json`} {
+		if _, _, err := CheckJSON(strings.NewReader(bad), "test"); err == nil {
+			t.Errorf("CheckJSON(%q) expected error", bad)
+		}
+	}
+}
+
+func TestCheckCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		column  string
+		want    []string
+	}{
+		{
+			name:    "url header",
+			content: "name,url\napp,https://example.com/app.js\nlib, https://example.com/lib.js\n",
+			want:    []string{"https://example.com/app.js", "https://example.com/lib.js"},
+		},
+		{
+			name:    "no header, first column",
+			content: "https://example.com/a.js,x\nhttps://example.com/b.js,y\n",
+			want:    []string{"https://example.com/a.js", "https://example.com/b.js"},
+		},
+		{
+			name:    "other header, first column",
+			content: "endpoint,owner\nhttps://example.com/a.js,x\n",
+			want:    []string{"https://example.com/a.js"},
+		},
+		{
+			name:    "column by name",
+			content: "id,Endpoint\n1,https://example.com/a.js\n2,\n",
+			column:  "endpoint",
+			want:    []string{"https://example.com/a.js"},
+		},
+		{
+			name:    "column by number",
+			content: "1,https://example.com/a.js\n2,https://example.com/b.js\n",
+			column:  "2",
+			want:    []string{"https://example.com/a.js", "https://example.com/b.js"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, invalid, err := CheckCSV(strings.NewReader(tt.content), "test", tt.column)
+			if err != nil || len(invalid) != 0 {
+				t.Fatalf("CheckCSV() = %v, %v", invalid, err)
+			}
+			if got := URLs(entries); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckCSV() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckCSV_Invalid(t *testing.T) {
+	entries, invalid, err := CheckCSV(strings.NewReader("url\nhttps://example.com/a.js\nnot-a-url\n"), "test", "")
+	if err != nil {
+		t.Fatalf("CheckCSV() error = %v", err)
+	}
+	if len(entries) != 1 || len(invalid) != 1 || invalid[0].Line != 3 {
+		t.Errorf("CheckCSV() = %v, %v, want 1 entry and line 3 invalid", entries, invalid)
+	}
+
+	if _, _, err := CheckCSV(strings.NewReader("id,name\n1,x\n"), "test", "endpoint"); err == nil {
+		t.Error("CheckCSV() expected error for a missing column")
+	}
+	if _, _, err := CheckCSV(strings.NewReader("a\n"), "test", "0"); err == nil {
+		t.Error("CheckCSV() expected error for column 0")
+	}
+}
@@ -1,7 +1,6 @@
 package parser
 
 import (
-	"bufio"
 	"crypto/sha1"
 	"fmt"
 	"net/url"
@@ -11,7 +10,9 @@ import (
 	"unicode"
 )
 
-// ParseURLsFromFile reads URLs from a file and returns them as a slice
+// ParseURLsFromFile reads URLs from a file and returns them as a slice.
+// If some lines are invalid, the valid URLs are returned with a ParseErrors
+// error listing each invalid line.
 func ParseURLsFromFile(filepath string) ([]string, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
@@ -19,43 +20,7 @@ func ParseURLsFromFile(filepath string) ([]string, error) {
 	}
 	defer file.Close()
 
-	var urls []string
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Validate URL
-		parsedURL, err := url.Parse(line)
-		if err != nil {
-			return nil, fmt.Errorf("invalid URL at line %d: %s", lineNum, line)
-		}
-
-		// Validate URL scheme (only http and https allowed)
-		if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-			return nil, fmt.Errorf("invalid URL scheme at line %d: %s (only http/https allowed)", lineNum, parsedURL.Scheme)
-		}
-
-		// Validate hostname exists
-		if parsedURL.Host == "" {
-			return nil, fmt.Errorf("invalid URL (missing host) at line %d: %s", lineNum, line)
-		}
-
-		urls = append(urls, line)
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
-	}
-
-	return urls, nil
+	return parseURLsFromReader(file, filepath)
 }
 
 // FilenameFromURL generates a safe filename from a URL
@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -193,6 +194,42 @@ https://cdn.example.net/lib.min.js
 	}
 }
 
+func TestParseURLsFromFile_ReportsAllInvalidLines(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "messy.txt")
+
+	content := `https://example.com/one.js
+file:///etc/passwd
+https://example.com/two.js
+http://
+ftp://example.com/file.zip
+https://example.com/three.js
+`
+
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	urls, err := ParseURLsFromFile(testFile)
+	var invalid ParseErrors
+	if !errors.As(err, &invalid) {
+		t.Fatalf("ParseURLsFromFile() error = %v, want ParseErrors", err)
+	}
+	if len(invalid) != 3 || invalid[0].Line != 2 || invalid[1].Line != 4 || invalid[2].Line != 5 {
+		t.Errorf("ParseURLsFromFile() invalid lines = %v, want lines 2, 4 and 5", err)
+	}
+	for _, want := range []string{"3 invalid line(s)", "line 2:", "line 4:", "line 5:"} {
+		if !contains(err.Error(), want) {
+			t.Errorf("error %q should mention %q", err, want)
+		}
+	}
+
+	// The valid URLs are still returned
+	if len(urls) != 3 || urls[0] != "https://example.com/one.js" || urls[2] != "https://example.com/three.js" {
+		t.Errorf("ParseURLsFromFile() urls = %v, want the 3 valid ones", urls)
+	}
+}
+
 func contains(s, substr string) bool {
 	return len(s) > 0 && len(substr) > 0 &&
 		   (s == substr || (len(s) >= len(substr) &&
@@ -0,0 +1,32 @@
+package processor
+
+import (
+	"compress/gzip"
+	"fmt"
+
+	"github.com/lcalzada-xor/downurl/internal/storage"
+)
+
+// byteCounter is a writer that only counts what is written to it
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
+
+// gzipSize returns the size of data once gzipped at the archive compression level
+func gzipSize(data []byte) (int64, error) {
+	var n byteCounter
+	gz, err := gzip.NewWriterLevel(&n, storage.CompressionLevel)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	if _, err := gz.Write(data); err != nil {
+		return 0, fmt.Errorf("failed to compress: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return 0, fmt.Errorf("failed to compress: %w", err)
+	}
+	return int64(n), nil
+}
@@ -0,0 +1,83 @@
+package processor
+
+import (
+	"context"
+
+	"github.com/lcalzada-xor/downurl/internal/filter"
+	"github.com/lcalzada-xor/downurl/internal/output"
+)
+
+// FileProcessor analyzes one downloaded file. data is the file's content;
+// findings go into report (see output.Reporter.AddFinding). An error is
+// reported for the file without stopping the other processors.
+type FileProcessor interface {
+	ProcessFile(path, url string, data []byte, report *output.Reporter) error
+}
+
+// FileProcessorFunc adapts a function to a FileProcessor
+type FileProcessorFunc func(path, url string, data []byte, report *output.Reporter) error
+
+// ProcessFile calls f
+func (f FileProcessorFunc) ProcessFile(path, url string, data []byte, report *output.Reporter) error {
+	return f(path, url, data, report)
+}
+
+// Register adds a processor run on every saved file after the built-in
+// ones, in the order registered. Unlike the built-in scanners, registered
+// processors also see files skipped as binary, and are not bound by the
+// per-file timeout.
+func (p *Processor) Register(fp FileProcessor) {
+	p.plugins = append(p.plugins, fp)
+}
+
+// builtins returns the built-in steps that apply to a file of the given
+// content type. They only return errors when ctx is done.
+// A partial file (a --preview-bytes prefix) marks its findings partial.
+func (p *Processor) builtins(ctx context.Context, filePath, contentType string, isJS, partial bool) []FileProcessor {
+	var steps []FileProcessor
+
+	if isJS {
+		steps = append(steps, FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+			// Other failures are skipped like scan failures
+			if err := p.processJavaScript(ctx, path, url, data, report, partial); isTimeout(err) {
+				return err
+			}
+			return nil
+		}))
+	}
+
+	// Beautified copies are written but not scanned: the original already is
+	if p.cssBeautify && isCSS(contentType, filePath) {
+		steps = append(steps, FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+			p.processStylesheet(path, data)
+			return nil
+		}))
+	}
+	if p.jsonBeautify && isJSON(contentType, filePath) {
+		steps = append(steps, FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+			p.processJSON(path, data)
+			return nil
+		}))
+	}
+
+	if p.mixedScanner != nil && isHTML(contentType, filePath) {
+		steps = append(steps, FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+			findings, err := p.mixedScanner.ScanDataContext(ctx, path, url, data)
+			if isTimeout(err) {
+				return err
+			}
+			if err == nil && len(findings) > 0 {
+				report.AddMixedContent(findings)
+			}
+			return nil
+		}))
+	}
+
+	if filter.IsText(contentType) {
+		steps = append(steps, FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+			return p.scanText(ctx, path, url, data, report, partial)
+		}))
+	}
+
+	return steps
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/internal/output"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// todoFinder reports every line containing TODO
+type todoFinder struct{}
+
+func (todoFinder) ProcessFile(path, url string, data []byte, report *output.Reporter) error {
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if bytes.Contains(line, []byte("TODO")) {
+			report.AddFinding(output.CustomFinding{
+				Processor: "todo",
+				Type:      "todo-comment",
+				URL:       url,
+				File:      path,
+				Line:      i + 1,
+				Detail:    string(bytes.TrimSpace(line)),
+			})
+		}
+	}
+	return nil
+}
+
+func TestProcessor_Register(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.js")
+	src := "var a = 1;\n// TODO: remove debug key\nfetch('/api/users');\n"
+	if err := os.WriteFile(file, []byte(src), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	p := NewProcessor(Config{ScanEndpoints: true})
+	p.Register(todoFinder{})
+
+	var calls int
+	p.Register(FileProcessorFunc(func(path, url string, data []byte, report *output.Reporter) error {
+		calls++
+		return errors.New("boom")
+	}))
+
+	err := p.ProcessResult(models.DownloadResult{
+		URL:        "https://example.com/app.js",
+		Downloaded: []string{file},
+	}, dir)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("ProcessResult() error = %v, want the failing processor's error", err)
+	}
+	if calls != 1 {
+		t.Errorf("failing processor ran %d times, want 1", calls)
+	}
+
+	report := p.GetReporter().GetReport()
+	if len(report.Findings.Custom) != 1 {
+		t.Fatalf("report has %d custom findings, want 1", len(report.Findings.Custom))
+	}
+	got := report.Findings.Custom[0]
+	if got.Processor != "todo" || got.Line != 2 || got.URL != "https://example.com/app.js" || got.Detail != "// TODO: remove debug key" {
+		t.Errorf("custom finding = %+v", got)
+	}
+	if report.Statistics.CustomCount != 1 {
+		t.Errorf("Statistics.CustomCount = %d, want 1", report.Statistics.CustomCount)
+	}
+
+	// The built-in steps still ran
+	if len(report.Findings.Endpoints) == 0 {
+		t.Error("built-in endpoint scan found nothing, want /api/users")
+	}
+}
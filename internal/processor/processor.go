@@ -1,12 +1,15 @@
 package processor
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/lcalzada-xor/downurl/internal/filter"
 	"github.com/lcalzada-xor/downurl/internal/jsanalyzer"
@@ -20,18 +23,48 @@ type Processor struct {
 	scanSecrets     bool
 	scanEndpoints   bool
 	jsBeautify      bool
+	cssBeautify     bool
+	jsonBeautify    bool
+	measureGzip     bool
+	scanBinary      bool
 	secretScanner   *scanner.SecretScanner
 	endpointScanner *scanner.EndpointScanner
+	mixedScanner    *scanner.MixedContentScanner
 	beautifier      *jsanalyzer.Beautifier
 	reporter        *output.Reporter
+	fileTimeout     time.Duration
+	minConfidence   scanner.Confidence // Lowest confidence of ActionableSecrets
+	observer        models.Observer
+	plugins         []FileProcessor // Registered processors, run after the built-in ones
+	downloaded      map[string]bool // URLs downloaded this run, whose files were scanned
+
+	// Reads saved files (os.ReadFile unless set with SetFileReader)
+	readFile func(path string) ([]byte, error)
 }
 
 // Config represents processor configuration
 type Config struct {
-	ScanSecrets    bool
-	ScanEndpoints  bool
-	JSBeautify     bool
-	SecretsEntropy float64
+	ScanSecrets          bool
+	ScanEndpoints        bool
+	JSBeautify           bool
+	BeautifyCSS          bool // Save minified stylesheets beautified alongside, as .beautified.css
+	BeautifyJSON         bool // Save compact JSON indented alongside, as .beautified.json
+	SecretsEntropy       float64
+	SecretsAssigned      bool                  // Report entropy hits only in assignment contexts
+	EntropyDebug         bool                  // Record entropy candidates near the threshold (see SaveEntropyCandidates)
+	ScanMixedContent     bool                  // Report http:// subresources on https:// HTML pages
+	FileTimeout          time.Duration         // Maximum time spent scanning one file (0 = no limit)
+	MeasureGzip          bool                  // Record each text file's gzip-compressed size
+	ScanBinary           bool                  // Scan files that look binary instead of skipping them
+	EndpointScope        scanner.EndpointScope // Keep relative endpoints, absolute ones or both
+	MaxLineLength        int                   // Scan longer lines in overlapping chunks (0 = scan every line whole)
+	SecretsMinConfidence scanner.Confidence    // Lowest confidence SaveSecrets writes and new secrets are counted at ("" = all)
+
+	// Custom secret patterns looked for after the built-in ones (see scanner.LoadSecretRules)
+	SecretRules []scanner.SecretPattern
+
+	// Aggregate downloads into the report statistics without keeping each one
+	SummaryOnly bool
 }
 
 // NewProcessor creates a new processor
@@ -40,45 +73,107 @@ func NewProcessor(cfg Config) *Processor {
 		scanSecrets:   cfg.ScanSecrets,
 		scanEndpoints: cfg.ScanEndpoints,
 		jsBeautify:    cfg.JSBeautify,
+		cssBeautify:   cfg.BeautifyCSS,
+		jsonBeautify:  cfg.BeautifyJSON,
 		reporter:      output.NewReporter(),
+		fileTimeout:   cfg.FileTimeout,
+		minConfidence: cfg.SecretsMinConfidence,
+		measureGzip:   cfg.MeasureGzip,
+		scanBinary:    cfg.ScanBinary,
+		readFile:      os.ReadFile,
+		downloaded:    make(map[string]bool),
 	}
+	p.reporter.SetSummaryOnly(cfg.SummaryOnly)
 
 	if cfg.ScanSecrets {
-		p.secretScanner = scanner.NewSecretScanner(cfg.SecretsEntropy)
+		p.secretScanner = scanner.NewSecretScannerWithRules(cfg.SecretsEntropy, cfg.SecretRules)
+		p.secretScanner.SetAssignmentOnly(cfg.SecretsAssigned)
+		if cfg.EntropyDebug {
+			p.secretScanner.SetEntropyDebug(scanner.DefaultEntropyDebugMargin)
+		}
+		p.secretScanner.SetScanBinary(cfg.ScanBinary)
+		p.secretScanner.SetMaxLineLength(cfg.MaxLineLength)
 	}
 
 	if cfg.ScanEndpoints {
 		p.endpointScanner = scanner.NewEndpointScanner()
+		p.endpointScanner.SetScanBinary(cfg.ScanBinary)
+		p.endpointScanner.SetScope(cfg.EndpointScope)
+		p.endpointScanner.SetMaxLineLength(cfg.MaxLineLength)
 	}
 
-	if cfg.JSBeautify {
+	if cfg.JSBeautify || cfg.BeautifyCSS || cfg.BeautifyJSON {
 		p.beautifier = jsanalyzer.NewBeautifier()
 	}
 
+	if cfg.ScanMixedContent {
+		p.mixedScanner = scanner.NewMixedContentScanner()
+		p.mixedScanner.SetScanBinary(cfg.ScanBinary)
+	}
+
 	return p
 }
 
-// ProcessResult processes a single download result
+// SetObserver sets the observer notified of findings as files are scanned
+func (p *Processor) SetObserver(o models.Observer) {
+	p.observer = o
+}
+
+// SetFileReader sets how saved files are read, for storages that do not
+// write them to disk (see storage.SQLiteStorage.ReadFile)
+func (p *Processor) SetFileReader(read func(path string) ([]byte, error)) {
+	p.readFile = read
+}
+
+// ProcessResult processes a single download result.
+// A file that fails or times out does not stop the others; their errors are returned together.
 func (p *Processor) ProcessResult(result models.DownloadResult, outputDir string) error {
 	if !result.IsSuccess() {
+		p.recordFailure(result)
 		return nil
 	}
+	p.downloaded[result.URL] = true
 
 	// Process each downloaded file
+	var errs []error
 	for _, filePath := range result.Downloaded {
-		if err := p.processFile(filePath, result.URL, outputDir); err != nil {
-			// Log error but continue
-			continue
+		if err := p.processFile(filePath, outputDir, result); err != nil {
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return errors.Join(errs...)
+}
+
+// recordFailure adds a failed download to the report with its error category
+func (p *Processor) recordFailure(result models.DownloadResult) {
+	info := output.DownloadInfo{
+		URL:            result.URL,
+		Status:         "failed",
+		Error:          strings.Join(result.Errors, "; "),
+		RequestHeaders: result.RequestHeaders,
+		AuthType:       result.AuthType,
+	}
+	if len(result.Failures) > 0 {
+		info.ErrorCategory = string(result.Failures[0].Category)
+	}
+	p.reporter.AddDownload(info)
 }
 
-// processFile processes a single file
-func (p *Processor) processFile(filePath, url, outputDir string) error {
+// processFile processes a single file saved for result
+func (p *Processor) processFile(filePath, outputDir string, result models.DownloadResult) error {
+	url := result.URL
+
+	// Bound the time scanners may spend on this file
+	ctx := context.Background()
+	if p.fileTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.fileTimeout)
+		defer cancel()
+	}
+
 	// Read file
-	data, err := os.ReadFile(filePath)
+	data, err := p.readFile(filePath)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
@@ -92,47 +187,183 @@ func (p *Processor) processFile(filePath, url, outputDir string) error {
 
 	// Add to reporter
 	downloadInfo := output.DownloadInfo{
-		URL:         url,
-		Path:        filePath,
-		SizeBytes:   int64(len(data)),
-		ContentType: contentType,
-		SHA256:      sha256Hash,
-		Status:      "success",
+		URL:                 url,
+		Path:                filePath,
+		SizeBytes:           int64(len(data)),
+		ContentType:         contentType,
+		DeclaredContentType: result.ContentType,
+		SniffedContentType:  contentType,
+		Category:            filter.ClassifyContent(contentType),
+		SHA256:              sha256Hash,
+		Status:              "success",
+		Partial:             result.Partial,
+		HashVerified:        result.Verified,
+		RequestHeaders:      result.RequestHeaders,
+		AuthType:            result.AuthType,
+	}
+	if p.measureGzip && filter.IsText(contentType) {
+		size, err := gzipSize(data)
+		if err != nil {
+			return err
+		}
+		downloadInfo.GzipSizeBytes = size
 	}
-	p.reporter.AddDownload(downloadInfo)
 
 	// Process based on content type
 	isJS := filter.IsJavaScript(contentType) || strings.HasSuffix(filePath, ".js") || strings.HasSuffix(filePath, ".mjs")
 
-	if isJS {
-		// JS-specific processing
-		if err := p.processJavaScript(filePath, url, data, outputDir); err != nil {
-			// Log error but continue
+	// Text by name or header but binary inside (e.g. a .js that is really an image): leave it alone
+	var steps []FileProcessor
+	scannable := isJS || isHTML(contentType, filePath) || filter.IsText(contentType)
+	if scannable && !p.scanBinary && scanner.IsBinary(data) {
+		downloadInfo.Note = scanner.SkipBinary
+	} else {
+		steps = p.builtins(ctx, filePath, contentType, isJS, result.Partial)
+	}
+	p.reporter.AddDownload(downloadInfo)
+
+	for _, step := range steps {
+		if err := step.ProcessFile(filePath, url, data, p.reporter); isTimeout(err) {
+			return p.timeoutError(filePath)
+		}
+	}
+
+	// Registered processors see every file
+	var errs []error
+	for _, plugin := range p.plugins {
+		if err := plugin.ProcessFile(filePath, url, data, p.reporter); err != nil {
+			errs = append(errs, fmt.Errorf("processing %s: %w", filePath, err))
 		}
 	}
 
-	// General text file processing
-	if filter.IsText(contentType) {
-		if p.scanSecrets {
-			secrets, err := p.secretScanner.ScanFile(filePath, url)
-			if err == nil && len(secrets) > 0 {
-				p.reporter.AddSecrets(secrets)
+	return errors.Join(errs...)
+}
+
+// scanText runs the secret and endpoint scanners over the content of a file;
+// partial marks the findings of a file that is only a prefix of the resource.
+// Scan failures are skipped; only context errors are returned.
+func (p *Processor) scanText(ctx context.Context, filePath, url string, data []byte, report *output.Reporter, partial bool) error {
+	if p.scanSecrets {
+		secrets, err := p.secretScanner.ScanDataContext(ctx, filePath, url, data)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && len(secrets) > 0 {
+			for i := range secrets {
+				secrets[i].Partial = partial
 			}
+			report.AddSecrets(secrets)
+			p.notifySecrets(url, filePath, secrets)
 		}
+	}
 
-		if p.scanEndpoints {
-			endpoints, err := p.endpointScanner.ScanFile(filePath, url)
-			if err == nil && len(endpoints) > 0 {
-				p.reporter.AddEndpoints(endpoints)
+	if p.scanEndpoints {
+		endpoints, err := p.endpointScanner.ScanDataContext(ctx, filePath, url, data)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && len(endpoints) > 0 {
+			for i := range endpoints {
+				endpoints[i].Partial = partial
 			}
+			report.AddEndpoints(endpoints)
 		}
 	}
 
 	return nil
 }
 
+// notifySecrets sends a secret_found event per finding
+func (p *Processor) notifySecrets(url, filePath string, secrets []scanner.SecretFinding) {
+	if p.observer == nil {
+		return
+	}
+	for _, s := range secrets {
+		p.observer.OnEvent(models.Event{
+			Type: models.EventSecretFound,
+			URL:  url,
+			Path: filePath,
+			Secret: &models.SecretInfo{
+				Type:       string(s.SecretType),
+				Confidence: string(s.Confidence),
+				Match:      s.Match,
+				Line:       s.Line,
+			},
+			Time: time.Now(),
+		})
+	}
+}
+
+// isTimeout checks if a scan was abandoned because its deadline passed
+func isTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// timeoutError describes a file whose scan was abandoned
+func (p *Processor) timeoutError(filePath string) error {
+	return fmt.Errorf("processing %s timed out after %v", filePath, p.fileTimeout)
+}
+
+// isHTML checks if a file is an HTML document by content type or extension
+func isHTML(contentType, filePath string) bool {
+	if strings.HasPrefix(strings.ToLower(contentType), "text/html") {
+		return true
+	}
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".html" || ext == ".htm"
+}
+
+// isCSS checks if a file is a stylesheet by content type or extension
+func isCSS(contentType, filePath string) bool {
+	return strings.HasPrefix(strings.ToLower(contentType), "text/css") ||
+		strings.EqualFold(filepath.Ext(filePath), ".css")
+}
+
+// isJSON checks if a file is a JSON document by content type or extension
+func isJSON(contentType, filePath string) bool {
+	ct := strings.ToLower(contentType)
+	if i := strings.Index(ct, ";"); i != -1 {
+		ct = strings.TrimSpace(ct[:i])
+	}
+	return ct == "application/json" || strings.HasSuffix(ct, "+json") ||
+		strings.EqualFold(filepath.Ext(filePath), ".json")
+}
+
+// beautifiedName returns where the beautified copy of filePath is saved
+func beautifiedName(filePath, ext string) string {
+	return strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".beautified" + ext
+}
+
+// processStylesheet saves a beautified copy of a minified stylesheet
+func (p *Processor) processStylesheet(filePath string, data []byte) error {
+	code := string(data)
+	if !jsanalyzer.IsMinified(code) {
+		return nil
+	}
+	if err := os.WriteFile(beautifiedName(filePath, ".css"), []byte(p.beautifier.BeautifyCSS(code)), 0644); err != nil {
+		return fmt.Errorf("failed to write beautified file: %w", err)
+	}
+	return nil
+}
+
+// processJSON saves an indented copy of a compact JSON document. Documents
+// that do not parse, such as truncated previews, are left alone.
+func (p *Processor) processJSON(filePath string, data []byte) error {
+	if !jsanalyzer.IsMinified(string(data)) {
+		return nil
+	}
+	indented, err := p.beautifier.BeautifyJSON(data)
+	if err != nil {
+		return nil
+	}
+	if err := os.WriteFile(beautifiedName(filePath, ".json"), indented, 0644); err != nil {
+		return fmt.Errorf("failed to write beautified file: %w", err)
+	}
+	return nil
+}
+
 // processJavaScript processes JavaScript files
-func (p *Processor) processJavaScript(filePath, url string, data []byte, outputDir string) error {
+func (p *Processor) processJavaScript(ctx context.Context, filePath, url string, data []byte, report *output.Reporter, partial bool) error {
 	code := string(data)
 
 	// Check if minified
@@ -141,24 +372,14 @@ func (p *Processor) processJavaScript(filePath, url string, data []byte, outputD
 		beautified := p.beautifier.Beautify(code)
 
 		// Save beautified version
-		beautifiedPath := strings.TrimSuffix(filePath, filepath.Ext(filePath)) + ".beautified.js"
+		beautifiedPath := beautifiedName(filePath, ".js")
 		if err := os.WriteFile(beautifiedPath, []byte(beautified), 0644); err != nil {
 			return fmt.Errorf("failed to write beautified file: %w", err)
 		}
 
 		// Scan beautified version instead
-		if p.scanSecrets {
-			secrets, err := p.secretScanner.ScanFile(beautifiedPath, url)
-			if err == nil && len(secrets) > 0 {
-				p.reporter.AddSecrets(secrets)
-			}
-		}
-
-		if p.scanEndpoints {
-			endpoints, err := p.endpointScanner.ScanFile(beautifiedPath, url)
-			if err == nil && len(endpoints) > 0 {
-				p.reporter.AddEndpoints(endpoints)
-			}
+		if err := p.scanText(ctx, beautifiedPath, url, []byte(beautified), report, partial); err != nil {
+			return err
 		}
 	}
 
@@ -172,31 +393,110 @@ func (p *Processor) GetReporter() *output.Reporter {
 
 // SaveSecrets saves secrets to JSON file
 func (p *Processor) SaveSecrets(filepath string) error {
+	secrets := p.ActionableSecrets()
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	return scanner.SaveSecrets(filepath, secrets)
+}
+
+// ActionableSecrets returns the report's secrets at or above the minimum
+// confidence. The report itself keeps every finding.
+func (p *Processor) ActionableSecrets() []scanner.SecretFinding {
+	return scanner.FilterByConfidence(p.reporter.GetReport().Findings.Secrets, p.minConfidence)
+}
+
+// SeparateEntropy moves high-entropy string findings out of the secrets,
+// so SaveSecrets writes only pattern matches and SaveEntropy the rest
+func (p *Processor) SeparateEntropy() {
+	p.reporter.SeparateEntropy()
+}
+
+// SaveEntropy saves the findings moved by SeparateEntropy to a JSON file
+func (p *Processor) SaveEntropy(filepath string) error {
 	report := p.reporter.GetReport()
-	if len(report.Findings.Secrets) == 0 {
+	if len(report.Findings.Entropy) == 0 {
 		return nil
 	}
 
-	data, err := json.MarshalIndent(report.Findings.Secrets, "", "  ")
+	return scanner.SaveSecrets(filepath, report.Findings.Entropy)
+}
+
+// DiffSecrets narrows the report's secrets to those missing from previous,
+// the findings of an earlier run. It returns this run's full set, to diff
+// the next run against, and the previous findings that are gone. Previous
+// findings of URLs that did not download this run were not looked for
+// again: they are carried into the full set rather than counted as gone.
+func (p *Processor) DiffSecrets(previous []scanner.SecretFinding) (current, resolved []scanner.SecretFinding) {
+	current = p.reporter.GetReport().Findings.Secrets
+	added, gone := scanner.DiffSecrets(previous, current)
+	p.reporter.SetSecrets(added)
+
+	var carried []scanner.SecretFinding
+	for _, s := range gone {
+		if p.downloaded[s.URL] {
+			resolved = append(resolved, s)
+		} else {
+			carried = append(carried, s)
+		}
+	}
+	if len(carried) > 0 {
+		current = append(append([]scanner.SecretFinding(nil), current...), carried...)
+	}
+	return current, resolved
+}
+
+// SaveEntropyCandidates saves the entropy candidates recorded with
+// EntropyDebug to a JSON file, highest entropy first
+func (p *Processor) SaveEntropyCandidates(filepath string) error {
+	if p.secretScanner == nil {
+		return nil
+	}
+
+	candidates := p.secretScanner.EntropyCandidates()
+	data, err := json.MarshalIndent(candidates, "", "  ")
 	if err != nil {
-		return fmt.Errorf("failed to marshal secrets: %w", err)
+		return fmt.Errorf("failed to marshal entropy candidates: %w", err)
 	}
 
 	if err := os.WriteFile(filepath, data, 0644); err != nil {
-		return fmt.Errorf("failed to write secrets file: %w", err)
+		return fmt.Errorf("failed to write entropy debug file: %w", err)
 	}
 
 	return nil
 }
 
-// SaveEndpoints saves endpoints to JSON file
-func (p *Processor) SaveEndpoints(filepath string) error {
+// SaveNucleiTemplate writes a Nuclei template requesting every relative
+// endpoint found, grouped by HTTP method. Nothing is written if there are none.
+func (p *Processor) SaveNucleiTemplate(filepath string, tmpl scanner.NucleiTemplate) error {
+	template := scanner.FormatNuclei(p.reporter.GetReport().Findings.Endpoints, tmpl)
+	if template == "" {
+		return nil
+	}
+
+	if err := os.WriteFile(filepath, []byte(template+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write nuclei template: %w", err)
+	}
+
+	return nil
+}
+
+// SaveEndpoints saves endpoints to JSON file. With grouped set, each unique
+// endpoint is listed once with its occurrence count, sources and methods.
+func (p *Processor) SaveEndpoints(filepath string, grouped bool) error {
 	report := p.reporter.GetReport()
 	if len(report.Findings.Endpoints) == 0 {
 		return nil
 	}
 
-	data, err := json.MarshalIndent(report.Findings.Endpoints, "", "  ")
+	var data []byte
+	var err error
+	if grouped {
+		data, err = json.MarshalIndent(scanner.GroupEndpoints(report.Findings.Endpoints), "", "  ")
+	} else {
+		data, err = json.MarshalIndent(report.Findings.Endpoints, "", "  ")
+	}
 	if err != nil {
 		return fmt.Errorf("failed to marshal endpoints: %w", err)
 	}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// HostLimiter picks the rate limiter for a request by its host. A host
+// listed with a limiter of its own (subdomains included) is only held to
+// that limit; every other host shares the default one.
+type HostLimiter struct {
+	def   *Limiter            // nil = other hosts are not limited
+	hosts map[string]*Limiter // Keyed by lowercase host
+}
+
+// NewHostLimiter returns a limiter applying def to every host not in hosts
+// (nil for no limit) and each limiter in hosts to its host and subdomains
+func NewHostLimiter(def *Limiter, hosts map[string]*Limiter) *HostLimiter {
+	h := &HostLimiter{def: def, hosts: make(map[string]*Limiter, len(hosts))}
+	for host, l := range hosts {
+		h.hosts[strings.ToLower(strings.TrimSpace(host))] = l
+	}
+	return h
+}
+
+// For returns the limiter for host (or a parent domain of it), or the
+// default if none is listed. A port in host is ignored: limits apply to
+// every port of a host.
+func (h *HostLimiter) For(host string) *Limiter {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.ToLower(host)
+	for {
+		if l, ok := h.hosts[host]; ok {
+			return l
+		}
+		i := strings.Index(host, ".")
+		if i == -1 {
+			return h.def
+		}
+		host = host[i+1:]
+	}
+}
+
+// WaitForHost blocks until a request to host may start
+func (h *HostLimiter) WaitForHost(ctx context.Context, host string) error {
+	l := h.For(host)
+	if l == nil {
+		return nil
+	}
+	return l.Wait(ctx)
+}
+
+// Len returns the number of hosts with a limiter of their own
+func (h *HostLimiter) Len() int {
+	if h == nil {
+		return 0
+	}
+	return len(h.hosts)
+}
+
+// Metrics adds up how much the default and per-host limiters throttled callers
+func (h *HostLimiter) Metrics() Metrics {
+	var total Metrics
+	add := func(l *Limiter) {
+		m := l.Metrics()
+		total.Blocked += m.Blocked
+		total.WaitTime += m.WaitTime
+	}
+	if h.def != nil {
+		add(h.def)
+	}
+	for _, l := range h.hosts {
+		add(l)
+	}
+	return total
+}
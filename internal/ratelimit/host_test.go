@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestHostLimiter_For(t *testing.T) {
+	def := NewLimiter(10, time.Second)
+	api := NewLimiter(1, time.Second)
+	h := NewHostLimiter(def, map[string]*Limiter{"API.example.com": api})
+
+	tests := []struct {
+		host string
+		want *Limiter
+	}{
+		{"api.example.com", api},
+		{"v2.api.example.com", api},
+		{"API.EXAMPLE.COM", api},
+		{"api.example.com:8443", api},
+		{"v2.api.example.com:80", api},
+		{"example.com", def},
+		{"cdn.example.com", def},
+	}
+	for _, tt := range tests {
+		if got := h.For(tt.host); got != tt.want {
+			t.Errorf("For(%q) = %p, want %p", tt.host, got, tt.want)
+		}
+	}
+
+	// Without a default, unlisted hosts are not limited at all
+	h = NewHostLimiter(nil, map[string]*Limiter{"api.example.com": api})
+	if err := h.WaitForHost(context.Background(), "other.com"); err != nil {
+		t.Errorf("WaitForHost(other.com) error = %v", err)
+	}
+	if h.For("other.com") != nil {
+		t.Error("For(other.com) should be nil without a default")
+	}
+}
+
+func TestHostLimiter_Independent(t *testing.T) {
+	slow := NewLimiter(1, time.Minute)
+	fast := NewLimiter(100, time.Second)
+	fast.tokens = fast.maxTokens
+	h := NewHostLimiter(fast, map[string]*Limiter{"slow.example.com": slow})
+
+	ctx := context.Background()
+	if err := h.WaitForHost(ctx, "slow.example.com"); err != nil {
+		t.Fatalf("WaitForHost() error = %v", err)
+	}
+
+	// The slow host is out of tokens, but other hosts are not held back by it
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		if err := h.WaitForHost(ctx, "fast.example.com"); err != nil {
+			t.Fatalf("WaitForHost() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("fast host waited %v behind the slow one", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Millisecond)
+	defer cancel()
+	if err := h.WaitForHost(ctx, "slow.example.com"); err == nil {
+		t.Error("WaitForHost(slow) error = nil, want it to wait past the deadline")
+	}
+
+	// Metrics cover every limiter
+	if m := h.Metrics(); m.Blocked != 1 {
+		t.Errorf("Metrics().Blocked = %d, want 1", m.Blocked)
+	}
+}
@@ -3,49 +3,107 @@ package ratelimit
 import (
 	"context"
 	"fmt"
+	"math"
 	"sync"
 	"time"
 )
 
-// Limiter implements token bucket rate limiting
+// Limiter implements token bucket rate limiting: tokens refill continuously,
+// at rate per period, up to a burst of maxTokens
 type Limiter struct {
-	rate     int           // requests per period
-	period   time.Duration // time period
-	tokens   int           // current available tokens
-	maxTokens int          // maximum tokens
-	mu       sync.Mutex
+	rate       int           // requests per period
+	period     time.Duration // time period
+	tokens     float64       // current available tokens, fractions included
+	maxTokens  float64       // maximum tokens
+	mu         sync.Mutex
 	lastRefill time.Time
+
+	blocked  int64         // Wait calls that had to wait for a token
+	waitTime time.Duration // Total time spent waiting in those calls
+}
+
+// Metrics reports how much a limiter has throttled callers
+type Metrics struct {
+	Blocked  int64         // Acquisitions that had to wait for a token
+	WaitTime time.Duration // Cumulative time spent waiting
+}
+
+// AvgWait returns the average wait of a blocked acquisition
+func (m Metrics) AvgWait() time.Duration {
+	if m.Blocked == 0 {
+		return 0
+	}
+	return m.WaitTime / time.Duration(m.Blocked)
 }
 
 // NewLimiter creates a new rate limiter
 // rate: number of requests per period
 // period: time period (e.g., time.Minute)
+// The bucket starts with a single token, so a run is paced from its first
+// request instead of opening with a burst of rate; only time left idle
+// builds tokens up to the full rate again.
 func NewLimiter(rate int, period time.Duration) *Limiter {
 	return &Limiter{
-		rate:      rate,
-		period:    period,
-		tokens:    rate,
-		maxTokens: rate,
+		rate:       rate,
+		period:     period,
+		tokens:     math.Min(1, float64(rate)),
+		maxTokens:  float64(rate),
 		lastRefill: time.Now(),
 	}
 }
 
 // Wait blocks until a token is available
 func (l *Limiter) Wait(ctx context.Context) error {
+	var start time.Time
 	for {
 		if allowed, waitTime := l.tryAcquire(); allowed {
+			l.record(start)
 			return nil
 		} else if waitTime > 0 {
+			if start.IsZero() {
+				start = time.Now()
+			}
 			select {
 			case <-time.After(waitTime):
 				// Continue to next iteration
 			case <-ctx.Done():
+				l.record(start)
 				return ctx.Err()
 			}
 		}
 	}
 }
 
+// TryAcquire takes a token if one is available, without blocking. When none
+// is, it returns false and how long until the next one refills.
+func (l *Limiter) TryAcquire() (bool, time.Duration) {
+	return l.tryAcquire()
+}
+
+// RecordWait counts an acquisition made with TryAcquire that had to wait
+// since start in Metrics
+func (l *Limiter) RecordWait(start time.Time) {
+	l.record(start)
+}
+
+// record adds a wait that started at start (zero if Wait never blocked)
+func (l *Limiter) record(start time.Time) {
+	if start.IsZero() {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.blocked++
+	l.waitTime += time.Since(start)
+}
+
+// Metrics returns how often and how long Wait has blocked so far
+func (l *Limiter) Metrics() Metrics {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Metrics{Blocked: l.blocked, WaitTime: l.waitTime}
+}
+
 // tryAcquire attempts to acquire a token
 // Returns (allowed, waitTime)
 func (l *Limiter) tryAcquire() (bool, time.Duration) {
@@ -54,28 +112,38 @@ func (l *Limiter) tryAcquire() (bool, time.Duration) {
 
 	l.refill()
 
-	if l.tokens > 0 {
+	if l.tokens >= 1 {
 		l.tokens--
 		return true, 0
 	}
 
-	// Calculate wait time until next refill
-	elapsed := time.Since(l.lastRefill)
-	waitTime := l.period - elapsed
+	// Wait until the missing fraction of the next token has refilled
+	if l.rate <= 0 || l.period <= 0 {
+		return false, l.period
+	}
+	perToken := float64(l.period) / float64(l.rate)
+	waitTime := time.Duration(math.Ceil((1 - l.tokens) * perToken))
+	if waitTime <= 0 {
+		waitTime = time.Nanosecond
+	}
 
 	return false, waitTime
 }
 
-// refill adds tokens based on time elapsed
+// refill adds tokens in proportion to the time elapsed since the last
+// refill (elapsed/period * rate), capped at maxTokens
 func (l *Limiter) refill() {
 	now := time.Now()
 	elapsed := now.Sub(l.lastRefill)
+	if elapsed <= 0 || l.period <= 0 {
+		return
+	}
 
-	if elapsed >= l.period {
-		// Refill all tokens
+	l.tokens += float64(elapsed) / float64(l.period) * float64(l.rate)
+	if l.tokens > l.maxTokens {
 		l.tokens = l.maxTokens
-		l.lastRefill = now
 	}
+	l.lastRefill = now
 }
 
 // GetStatus returns current limiter status
@@ -85,7 +153,7 @@ func (l *Limiter) GetStatus() string {
 
 	l.refill()
 
-	return fmt.Sprintf("%d/%d tokens available", l.tokens, l.maxTokens)
+	return fmt.Sprintf("%d/%d tokens available", int(l.tokens), int(l.maxTokens))
 }
 
 // ParseRateLimit parses rate limit string like "10/minute", "100/hour"
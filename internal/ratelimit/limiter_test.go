@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Metrics(t *testing.T) {
+	l := NewLimiter(2, 50*time.Millisecond)
+
+	// The first call uses the initial token; the other three each wait for
+	// one token to refill, 25ms apiece
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	m := l.Metrics()
+	if m.Blocked != 3 {
+		t.Errorf("Metrics().Blocked = %d, want 3", m.Blocked)
+	}
+	if m.WaitTime < 50*time.Millisecond || m.WaitTime > time.Second {
+		t.Errorf("Metrics().WaitTime = %v, want about 75ms", m.WaitTime)
+	}
+	if m.AvgWait() <= 0 || m.AvgWait() > m.WaitTime {
+		t.Errorf("Metrics().AvgWait() = %v, want in (0, %v]", m.AvgWait(), m.WaitTime)
+	}
+}
+
+func TestLimiter_MetricsUnthrottled(t *testing.T) {
+	// A bucket filled up while idle
+	l := NewLimiter(10, time.Minute)
+	l.tokens = l.maxTokens
+	for i := 0; i < 5; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+
+	if m := l.Metrics(); m.Blocked != 0 || m.WaitTime != 0 {
+		t.Errorf("Metrics() = %+v, want no waits", m)
+	}
+}
+
+func TestLimiter_MetricsCancelled(t *testing.T) {
+	l := NewLimiter(1, time.Minute)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err == nil {
+		t.Fatal("Wait() error = nil, want context deadline")
+	}
+
+	// A wait cut short still counts
+	m := l.Metrics()
+	if m.Blocked != 1 {
+		t.Errorf("Metrics().Blocked = %d, want 1", m.Blocked)
+	}
+	if m.WaitTime < 20*time.Millisecond {
+		t.Errorf("Metrics().WaitTime = %v, want at least 20ms", m.WaitTime)
+	}
+}
+
+func TestLimiter_RefillsProportionally(t *testing.T) {
+	l := NewLimiter(10, time.Second)
+	l.tokens = 0
+	l.lastRefill = time.Now().Add(-250 * time.Millisecond)
+
+	// A quarter of the period refills a quarter of the tokens, not all or none
+	l.refill()
+	if l.tokens < 2.4 || l.tokens > 2.7 {
+		t.Errorf("tokens after 250ms = %.2f, want about 2.5", l.tokens)
+	}
+
+	// Refilling never goes past the burst size
+	l.lastRefill = time.Now().Add(-time.Hour)
+	l.refill()
+	if l.tokens != l.maxTokens {
+		t.Errorf("tokens after an hour = %.2f, want %.0f", l.tokens, l.maxTokens)
+	}
+}
+
+func TestLimiter_WaitForNextToken(t *testing.T) {
+	l := NewLimiter(10, time.Second)
+	l.tokens = 0.5
+	l.lastRefill = time.Now()
+
+	// Half a token is missing and one takes 100ms to refill
+	allowed, wait := l.tryAcquire()
+	if allowed {
+		t.Fatal("tryAcquire() allowed with half a token")
+	}
+	if wait < 40*time.Millisecond || wait > 50*time.Millisecond {
+		t.Errorf("tryAcquire() wait = %v, want about 50ms", wait)
+	}
+}
+
+func TestLimiter_Smooth(t *testing.T) {
+	// 20/second releases the first request at once, then one about every 50ms
+	l := NewLimiter(20, time.Second)
+	start := time.Now()
+	if allowed, _ := l.tryAcquire(); !allowed {
+		t.Fatal("tryAcquire() denied the first request")
+	}
+	if allowed, _ := l.tryAcquire(); allowed {
+		t.Fatal("tryAcquire() allowed a second request at once, want no initial burst")
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := l.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 120*time.Millisecond || elapsed > 500*time.Millisecond {
+		t.Errorf("3 requests after the first took %v, want about 150ms", elapsed)
+	}
+}
+
+func TestLimiter_TryAcquire(t *testing.T) {
+	l := NewLimiter(1, time.Hour)
+	if ok, _ := l.TryAcquire(); !ok {
+		t.Fatal("TryAcquire() on a full bucket = false, want true")
+	}
+	ok, wait := l.TryAcquire()
+	if ok || wait <= 0 || wait > time.Hour {
+		t.Errorf("TryAcquire() on an empty bucket = %v, %v; want false and a wait of up to an hour", ok, wait)
+	}
+
+	l.RecordWait(time.Now().Add(-time.Second))
+	if m := l.Metrics(); m.Blocked != 1 || m.WaitTime < time.Second {
+		t.Errorf("Metrics() = %+v, want one wait of at least 1s", m)
+	}
+}
@@ -2,12 +2,14 @@ package reporter
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
 	"time"
 
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
 	"github.com/lcalzada-xor/downurl/pkg/models"
 )
 
@@ -38,42 +40,35 @@ func (r *Reporter) AddBatch(results []models.DownloadResult) {
 	r.results = append(r.results, results...)
 }
 
-// Generate creates a text report file
+// Generate creates a text report file, or writes the report to stdout if outputPath is "-"
 func (r *Reporter) Generate(outputPath string) error {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	// Ensure directory exists
-	dir := filepath.Dir(outputPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// Create file
-	file, err := os.Create(outputPath)
+	file, err := createReport(outputPath)
 	if err != nil {
-		return fmt.Errorf("failed to create report file: %w", err)
+		return err
 	}
 	defer file.Close()
 
+	r.Write(file)
+	return nil
+}
+
+// Write writes the text report to w
+func (r *Reporter) Write(w io.Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	// Write header
-	fmt.Fprintf(file, "Download Report\n")
-	fmt.Fprintf(file, "Generated: %s\n", time.Now().Format(time.RFC3339))
-	fmt.Fprintf(file, "Total URLs: %d\n", len(r.results))
-	fmt.Fprintf(file, "%s\n\n", separator(60))
+	fmt.Fprintf(w, "Download Report\n")
+	fmt.Fprintf(w, "Generated: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(w, "Total URLs: %d\n", len(r.results))
+	fmt.Fprintf(w, "%s\n\n", separator(60))
 
 	// Calculate statistics
-	stats := r.calculateStats()
-	fmt.Fprintf(file, "Statistics:\n")
-	fmt.Fprintf(file, "  Successful: %d\n", stats.Successful)
-	fmt.Fprintf(file, "  Failed: %d\n", stats.Failed)
-	fmt.Fprintf(file, "  Total Downloaded: %d files\n", stats.TotalDownloaded)
-	fmt.Fprintf(file, "  Total Errors: %d\n", stats.TotalErrors)
-	fmt.Fprintf(file, "  Average Duration: %v\n", stats.AvgDuration)
-	fmt.Fprintf(file, "%s\n\n", separator(60))
+	writeStats(w, r.calculateStats())
+	fmt.Fprintf(w, "%s\n\n", separator(60))
 
 	// Write individual results
-	fmt.Fprintf(file, "Detailed Results:\n\n")
+	fmt.Fprintf(w, "Detailed Results:\n\n")
 
 	// Sort results by URL for consistent output
 	sortedResults := make([]models.DownloadResult, len(r.results))
@@ -83,31 +78,41 @@ func (r *Reporter) Generate(outputPath string) error {
 	})
 
 	for i, result := range sortedResults {
-		fmt.Fprintf(file, "[%d] URL: %s\n", i+1, result.URL)
-		fmt.Fprintf(file, "    Host: %s\n", result.Host)
-		fmt.Fprintf(file, "    Duration: %v\n", result.Duration)
-		fmt.Fprintf(file, "    Downloaded: %d files\n", len(result.Downloaded))
+		writeResult(w, i+1, result)
+	}
+}
 
-		for _, path := range result.Downloaded {
-			fmt.Fprintf(file, "      - %s\n", path)
-		}
+// createReport creates a report file and its directory, or returns stdout if outputPath is "-"
+func createReport(outputPath string) (io.WriteCloser, error) {
+	if outputPath == "-" {
+		return nopCloser{os.Stdout}, nil
+	}
 
-		fmt.Fprintf(file, "    Errors: %d\n", len(result.Errors))
-		for _, errMsg := range result.Errors {
-			fmt.Fprintf(file, "      - %s\n", errMsg)
-		}
+	// Ensure directory exists
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
 
-		fmt.Fprintf(file, "\n")
+	file, err := os.Create(outputPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report file: %w", err)
 	}
+	return file, nil
+}
 
-	return nil
+// nopCloser keeps stdout open when a report is closed
+type nopCloser struct {
+	io.Writer
 }
 
+func (nopCloser) Close() error { return nil }
+
 // Stats holds aggregated statistics
 type Stats struct {
 	Successful      int
 	Failed          int
 	TotalDownloaded int
+	TotalBytes      int64
 	TotalErrors     int
 	AvgDuration     time.Duration
 }
@@ -125,6 +130,7 @@ func (r *Reporter) calculateStats() Stats {
 		}
 
 		stats.TotalDownloaded += len(result.Downloaded)
+		stats.TotalBytes += result.Bytes
 		stats.TotalErrors += len(result.Errors)
 		totalDuration += result.Duration
 	}
@@ -146,6 +152,35 @@ func (r *Reporter) GetResults() []models.DownloadResult {
 	return results
 }
 
+// writeStats writes the statistics block of a text report
+func writeStats(w io.Writer, stats Stats) {
+	fmt.Fprintf(w, "Statistics:\n")
+	fmt.Fprintf(w, "  Successful: %d\n", stats.Successful)
+	fmt.Fprintf(w, "  Failed: %d\n", stats.Failed)
+	fmt.Fprintf(w, "  Total Downloaded: %d files (%d bytes)\n", stats.TotalDownloaded, stats.TotalBytes)
+	fmt.Fprintf(w, "  Total Errors: %d\n", stats.TotalErrors)
+	fmt.Fprintf(w, "  Average Duration: %v\n", stats.AvgDuration)
+}
+
+// writeResult writes one numbered result entry of a text report
+func writeResult(w io.Writer, n int, result models.DownloadResult) {
+	fmt.Fprintf(w, "[%d] URL: %s\n", n, sanitize.Text(result.URL))
+	fmt.Fprintf(w, "    Host: %s\n", sanitize.Text(result.Host))
+	fmt.Fprintf(w, "    Duration: %v\n", result.Duration)
+	fmt.Fprintf(w, "    Downloaded: %d files (%d bytes)\n", len(result.Downloaded), result.Bytes)
+
+	for _, path := range result.Downloaded {
+		fmt.Fprintf(w, "      - %s\n", sanitize.Text(path))
+	}
+
+	fmt.Fprintf(w, "    Errors: %d\n", len(result.Errors))
+	for _, errMsg := range result.Errors {
+		fmt.Fprintf(w, "      - %s\n", sanitize.Text(errMsg))
+	}
+
+	fmt.Fprintf(w, "\n")
+}
+
 // separator generates a separator line
 func separator(length int) string {
 	result := ""
@@ -0,0 +1,69 @@
+package reporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+// StreamWriter writes a text report incrementally: each result is written as
+// it arrives, in completion order, and the statistics follow at Close.
+// Unlike Reporter it keeps no results in memory.
+type StreamWriter struct {
+	file    io.WriteCloser
+	w       *bufio.Writer
+	summary models.RunSummary
+	mu      sync.Mutex
+}
+
+// NewStreamWriter creates the report file, or uses stdout if outputPath is "-", and writes its header
+func NewStreamWriter(outputPath string) (*StreamWriter, error) {
+	file, err := createReport(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &StreamWriter{file: file, w: bufio.NewWriter(file)}
+	fmt.Fprintf(s.w, "Download Report\n")
+	fmt.Fprintf(s.w, "Generated: %s\n", time.Now().Format(time.RFC3339))
+	fmt.Fprintf(s.w, "%s\n\n", separator(60))
+	fmt.Fprintf(s.w, "Detailed Results (completion order):\n\n")
+
+	return s, nil
+}
+
+// Add writes a result to the report (thread-safe)
+func (s *StreamWriter) Add(result models.DownloadResult) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.summary.Add(&result)
+	writeResult(s.w, s.summary.Total, result)
+}
+
+// Close writes the statistics and closes the report file
+func (s *StreamWriter) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintf(s.w, "%s\n\n", separator(60))
+	fmt.Fprintf(s.w, "Total URLs: %d\n", s.summary.Total)
+	writeStats(s.w, Stats{
+		Successful:      s.summary.Successful,
+		Failed:          s.summary.Failed,
+		TotalDownloaded: s.summary.Downloaded,
+		TotalBytes:      s.summary.Bytes,
+		TotalErrors:     s.summary.Errors,
+		AvgDuration:     s.summary.AvgDuration(),
+	})
+
+	if err := s.w.Flush(); err != nil {
+		s.file.Close()
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return s.file.Close()
+}
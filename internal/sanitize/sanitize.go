@@ -0,0 +1,61 @@
+package sanitize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Text makes an untrusted value (URL, server-provided name, error message)
+// safe to print to a terminal or write into a line-based report.
+// Control characters such as newlines and ANSI escapes, invisible format
+// characters such as bidi overrides, and invalid UTF-8 are replaced with
+// Go-style escapes (\n, \x1b, \u202e), so one value cannot forge extra log
+// lines or restyle the terminal. Printable text is returned unchanged.
+func Text(s string) string {
+	if isSafe(s) {
+		return s
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&sb, `\x%02x`, s[i])
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case needsEscape(r) && r < 0x80:
+			fmt.Fprintf(&sb, `\x%02x`, r)
+		case needsEscape(r):
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteRune(r)
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// isSafe reports whether s can be printed as-is
+func isSafe(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || needsEscape(r) {
+			return false
+		}
+		i += size
+	}
+	return true
+}
+
+// needsEscape reports whether r is a control or invisible format character
+func needsEscape(r rune) bool {
+	return unicode.IsControl(r) || unicode.Is(unicode.Cf, r)
+}
@@ -0,0 +1,29 @@
+package sanitize
+
+import "testing"
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"plain URL", "https://example.com/app.js?q=1", "https://example.com/app.js?q=1"},
+		{"unicode kept", "https://example.com/café/日本.js", "https://example.com/café/日本.js"},
+		{"forged log line", "https://example.com/a\n[OK] Downloaded https://evil", `https://example.com/a\n[OK] Downloaded https://evil`},
+		{"carriage return", "https://example.com/a\rX", `https://example.com/a\rX`},
+		{"ANSI escape", "https://example.com/\x1b[2J\x1b[31mred", `https://example.com/\x1b[2J\x1b[31mred`},
+		{"tab and NUL", "a\tb\x00c", `a\tb\x00c`},
+		{"DEL and C1", "a\x7fb\u009bc", `a\x7fb\u009bc`},
+		{"bidi override", "https://example.com/\u202egpj.exe", `https://example.com/\u202egpj.exe`},
+		{"invalid UTF-8", "a\xffb", `a\xffb`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Text(tt.in); got != tt.want {
+				t.Errorf("Text(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package scanner
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// SkipBinary is the note recorded for files not scanned because they look binary
+const SkipBinary = "skipped: binary"
+
+const (
+	binarySampleSize    = 8192 // Bytes inspected at the start of a file
+	maxInvalidUTF8Ratio = 0.1  // Share of invalid UTF-8 bytes above which content is binary
+)
+
+// IsBinary reports whether data looks like binary rather than text: it
+// contains a NUL byte, or more than 10% of its bytes are not valid UTF-8.
+// Only the first 8 KiB are inspected.
+func IsBinary(data []byte) bool {
+	if len(data) > binarySampleSize {
+		data = data[:binarySampleSize]
+	}
+	if len(data) == 0 {
+		return false
+	}
+	if bytes.IndexByte(data, 0) != -1 {
+		return true
+	}
+
+	invalid := 0
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		if r == utf8.RuneError && size == 1 {
+			// A multi-byte character cut off by the sample boundary is not invalid
+			if len(data)-i < utf8.UTFMax && !utf8.FullRune(data[i:]) {
+				break
+			}
+			invalid++
+		}
+		i += size
+	}
+	return float64(invalid)/float64(len(data)) > maxInvalidUTF8Ratio
+}
+
+// isBinaryFile checks the start of f with IsBinary and rewinds it
+func isBinaryFile(f *os.File) (bool, error) {
+	sample := make([]byte, binarySampleSize)
+	n, err := io.ReadFull(f, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return false, err
+	}
+	return IsBinary(sample[:n]), nil
+}
@@ -0,0 +1,69 @@
+package scanner
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want bool
+	}{
+		{"empty", nil, false},
+		{"ascii", []byte("const a = 1;\n"), false},
+		{"utf-8", []byte("// café 日本語 ✓\n"), false},
+		{"NUL byte", []byte("abc\x00def"), true},
+		{"PNG header", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), true},
+		{"mostly invalid UTF-8", bytes.Repeat([]byte{0xff, 0xfe, 'a'}, 100), true},
+		{"a few Latin-1 bytes", []byte(strings.Repeat("text ", 100) + "caf\xe9"), false},
+		{"rune cut at sample end", append(bytes.Repeat([]byte("a"), binarySampleSize-1), "é"...), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsBinary(tt.data); got != tt.want {
+				t.Errorf("IsBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanners_SkipBinary(t *testing.T) {
+	// A long binary blob without newlines would exceed bufio.Scanner's line limit,
+	// and carries text that the scanners would otherwise report
+	blob := bytes.Repeat([]byte{0x00, 0x9c, 0xff, 0x10}, 40000)
+	blob = append(blob, []byte(` fetch("/api/v1/users") apiKey = 'aB3dEf5gH7iJ9kL1mN3oP5qR7sT9uV1wX3yZ5' <script src="http://cdn.example.com/a.js"></script>`)...)
+
+	path := filepath.Join(t.TempDir(), "image.js")
+	if err := os.WriteFile(path, blob, 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	url := "https://example.com/image.js"
+
+	secrets, err := NewSecretScanner(4.5).ScanFile(path, url)
+	if err != nil || len(secrets) != 0 {
+		t.Errorf("SecretScanner.ScanFile() = %d findings, %v; want 0, nil", len(secrets), err)
+	}
+
+	endpoints, err := NewEndpointScanner().ScanFile(path, url)
+	if err != nil || len(endpoints) != 0 {
+		t.Errorf("EndpointScanner.ScanFile() = %d findings, %v; want 0, nil", len(endpoints), err)
+	}
+
+	mixed, err := NewMixedContentScanner().ScanFile(path, url)
+	if err != nil || len(mixed) != 0 {
+		t.Errorf("MixedContentScanner.ScanFile() = %d findings, %v; want 0, nil", len(mixed), err)
+	}
+
+	// Opting in scans the file as text
+	m := NewMixedContentScanner()
+	m.SetScanBinary(true)
+	if mixed, err := m.ScanFile(path, url); err != nil || len(mixed) != 1 {
+		t.Errorf("MixedContentScanner.ScanFile() with SetScanBinary = %d findings, %v; want 1, nil", len(mixed), err)
+	}
+}
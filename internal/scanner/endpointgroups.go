@@ -0,0 +1,74 @@
+package scanner
+
+import "sort"
+
+// EndpointGroup is one unique endpoint with every place it was found
+type EndpointGroup struct {
+	Endpoint string       `json:"endpoint"`
+	Type     EndpointType `json:"type"`
+	Count    int          `json:"count"`             // Findings merged into the group (one per file and method)
+	Methods  []HTTPMethod `json:"methods,omitempty"` // Methods seen, sorted; empty if none was known
+	Files    []string     `json:"files"`             // Files it was found in, sorted
+	URLs     []string     `json:"urls"`              // URLs of those files, sorted
+	Severity int          `json:"severity"`          // Highest severity among the findings
+}
+
+// GroupEndpoints merges findings of the same endpoint into one group each,
+// most frequent first (ties by endpoint)
+func GroupEndpoints(findings []EndpointFinding) []EndpointGroup {
+	type groupSets struct {
+		group   *EndpointGroup
+		methods map[HTTPMethod]bool
+		files   map[string]bool
+		urls    map[string]bool
+	}
+
+	byEndpoint := make(map[string]*groupSets)
+	var order []*groupSets
+	for _, f := range findings {
+		g, ok := byEndpoint[f.Endpoint]
+		if !ok {
+			g = &groupSets{
+				group:   &EndpointGroup{Endpoint: f.Endpoint, Type: f.Type},
+				methods: make(map[HTTPMethod]bool),
+				files:   make(map[string]bool),
+				urls:    make(map[string]bool),
+			}
+			byEndpoint[f.Endpoint] = g
+			order = append(order, g)
+		}
+
+		g.group.Count++
+		if f.Severity > g.group.Severity {
+			g.group.Severity = f.Severity
+		}
+		if f.Method != MethodAny && !g.methods[f.Method] {
+			g.methods[f.Method] = true
+			g.group.Methods = append(g.group.Methods, f.Method)
+		}
+		if f.File != "" && !g.files[f.File] {
+			g.files[f.File] = true
+			g.group.Files = append(g.group.Files, f.File)
+		}
+		if f.URL != "" && !g.urls[f.URL] {
+			g.urls[f.URL] = true
+			g.group.URLs = append(g.group.URLs, f.URL)
+		}
+	}
+
+	groups := make([]EndpointGroup, len(order))
+	for i, g := range order {
+		sort.Slice(g.group.Methods, func(a, b int) bool { return g.group.Methods[a] < g.group.Methods[b] })
+		sort.Strings(g.group.Files)
+		sort.Strings(g.group.URLs)
+		groups[i] = *g.group
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].Endpoint < groups[j].Endpoint
+	})
+	return groups
+}
@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGroupEndpoints(t *testing.T) {
+	tmpDir := t.TempDir()
+	files := map[string]string{
+		"app.js":    "axios.get('/api/users');\naxios.post('/api/users');\nfetch('/api/items');\n",
+		"admin.js":  "axios.delete('/api/users');\n",
+		"vendor.js": "axios.get('/api/users');\n",
+	}
+
+	s := NewEndpointScanner()
+	var findings []EndpointFinding
+	for _, name := range []string{"app.js", "admin.js", "vendor.js"} {
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		found, err := s.ScanFile(path, "https://example.com/"+name)
+		if err != nil {
+			t.Fatalf("ScanFile() error = %v", err)
+		}
+		findings = append(findings, found...)
+	}
+
+	groups := GroupEndpoints(findings)
+	byEndpoint := make(map[string]EndpointGroup)
+	for _, g := range groups {
+		byEndpoint[g.Endpoint] = g
+	}
+
+	users, ok := byEndpoint["/api/users"]
+	if !ok {
+		t.Fatalf("GroupEndpoints() has no /api/users group: %+v", groups)
+	}
+	if groups[0].Endpoint != "/api/users" {
+		t.Errorf("first group = %s, want the most frequent /api/users", groups[0].Endpoint)
+	}
+
+	// Each file reports /api/users once per method plus once for the generic
+	// "api path" pattern: 3 in app.js, 2 in admin.js, 2 in vendor.js
+	if users.Count != 7 {
+		t.Errorf("/api/users count = %d, want 7", users.Count)
+	}
+	if wantMethods := []HTTPMethod{MethodDELETE, MethodGET, MethodPOST}; !reflect.DeepEqual(users.Methods, wantMethods) {
+		t.Errorf("/api/users methods = %v, want %v", users.Methods, wantMethods)
+	}
+	wantFiles := []string{filepath.Join(tmpDir, "admin.js"), filepath.Join(tmpDir, "app.js"), filepath.Join(tmpDir, "vendor.js")}
+	if !reflect.DeepEqual(users.Files, wantFiles) {
+		t.Errorf("/api/users files = %v, want %v", users.Files, wantFiles)
+	}
+	wantURLs := []string{"https://example.com/admin.js", "https://example.com/app.js", "https://example.com/vendor.js"}
+	if !reflect.DeepEqual(users.URLs, wantURLs) {
+		t.Errorf("/api/users urls = %v, want %v", users.URLs, wantURLs)
+	}
+
+	items := byEndpoint["/api/items"]
+	if len(items.URLs) != 1 || items.URLs[0] != "https://example.com/app.js" {
+		t.Errorf("/api/items urls = %v, want only app.js", items.URLs)
+	}
+	if len(items.Methods) != 0 {
+		t.Errorf("/api/items methods = %v, want none (fetch without options)", items.Methods)
+	}
+}
@@ -1,10 +1,14 @@
 package scanner
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -41,6 +45,8 @@ type EndpointFinding struct {
 	Line       int          `json:"line"`
 	Context    string       `json:"context,omitempty"`
 	Parameters []string     `json:"parameters,omitempty"`
+	Severity   int          `json:"severity"`          // 0-100, see EndpointSeverity
+	Partial    bool         `json:"partial,omitempty"` // Found in a file cut short by --preview-bytes
 }
 
 // EndpointPattern defines a pattern for detecting endpoints
@@ -55,6 +61,9 @@ type EndpointPattern struct {
 type EndpointScanner struct {
 	patterns       []EndpointPattern
 	includeContext bool
+	scanBinary     bool          // Scan files that look binary instead of skipping them
+	scope          EndpointScope // Which endpoints to report (zero value = all)
+	maxLineLength  int           // Lines longer than this are scanned in overlapping chunks (0 = never)
 }
 
 // NewEndpointScanner creates a new endpoint scanner
@@ -62,9 +71,28 @@ func NewEndpointScanner() *EndpointScanner {
 	return &EndpointScanner{
 		patterns:       buildEndpointPatterns(),
 		includeContext: true,
+		maxLineLength:  DefaultMaxLineLength,
 	}
 }
 
+// SetScanBinary controls whether files that look binary (see IsBinary) are
+// scanned anyway. By default they are skipped without findings or errors.
+func (e *EndpointScanner) SetScanBinary(enabled bool) {
+	e.scanBinary = enabled
+}
+
+// SetScope restricts findings to relative paths, absolute URLs or both,
+// so every export built from them covers the same endpoints
+func (e *EndpointScanner) SetScope(scope EndpointScope) {
+	e.scope = scope
+}
+
+// SetMaxLineLength sets the longest line scanned in one piece; see
+// SecretScanner.SetMaxLineLength
+func (e *EndpointScanner) SetMaxLineLength(n int) {
+	e.maxLineLength = n
+}
+
 // buildEndpointPatterns creates the list of endpoint patterns
 func buildEndpointPatterns() []EndpointPattern {
 	return []EndpointPattern{
@@ -177,68 +205,110 @@ func buildEndpointPatterns() []EndpointPattern {
 
 // ScanFile scans a single file for endpoints
 func (e *EndpointScanner) ScanFile(filepath, url string) ([]EndpointFinding, error) {
+	return e.ScanFileContext(context.Background(), filepath, url)
+}
+
+// ScanFileContext scans a file for endpoints, giving up once ctx is done
+func (e *EndpointScanner) ScanFileContext(ctx context.Context, filepath, url string) ([]EndpointFinding, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	if !e.scanBinary {
+		if binary, err := isBinaryFile(file); err != nil {
+			return nil, fmt.Errorf("error reading file: %w", err)
+		} else if binary {
+			return nil, nil
+		}
+	}
+	return e.scan(ctx, file, filepath, url)
+}
+
+// ScanDataContext scans content already in memory, reporting findings
+// against filepath, giving up once ctx is done
+func (e *EndpointScanner) ScanDataContext(ctx context.Context, filepath, url string, data []byte) ([]EndpointFinding, error) {
+	if !e.scanBinary && IsBinary(data) {
+		return nil, nil
+	}
+	return e.scan(ctx, bytes.NewReader(data), filepath, url)
+}
+
+// scan scans the lines read from r
+func (e *EndpointScanner) scan(ctx context.Context, r io.Reader, filepath, url string) ([]EndpointFinding, error) {
 	var findings []EndpointFinding
-	scanner := bufio.NewScanner(file)
+	scanner := newLineScanner(r)
 	lineNum := 0
 
 	// Track seen endpoints to avoid duplicates
 	seen := make(map[string]bool)
 
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		lineNum++
 		line := scanner.Text()
+		chunks := splitLine(line, e.maxLineLength)
 
-		// Check each pattern
-		for _, pattern := range e.patterns {
-			matches := pattern.Regex.FindAllStringSubmatch(line, -1)
-
-			for _, match := range matches {
-				if len(match) < 2 {
-					continue
-				}
-
-				endpoint := ""
-				method := pattern.Method
-
-				// Handle xhr.open special case (has method in capture group)
-				if pattern.Name == "xhr.open" && len(match) >= 3 {
-					method = HTTPMethod(match[1])
-					endpoint = match[2]
-				} else {
-					endpoint = match[1]
-				}
-
-				// Skip if already seen
-				key := fmt.Sprintf("%s:%s", method, endpoint)
-				if seen[key] {
-					continue
+		for _, chunk := range chunks {
+			// Check each pattern
+			for _, pattern := range e.patterns {
+				if err := ctx.Err(); err != nil {
+					return nil, err
 				}
-				seen[key] = true
-
-				// Extract parameters from endpoint
-				params := extractParameters(endpoint)
-
-				finding := EndpointFinding{
-					File:       filepath,
-					URL:        url,
-					Endpoint:   endpoint,
-					Method:     method,
-					Type:       pattern.Type,
-					Line:       lineNum,
-					Parameters: params,
+				for _, loc := range pattern.Regex.FindAllStringSubmatchIndex(chunk.text, -1) {
+					if len(loc) < 4 || !chunk.keeps(loc[0], loc[1]) {
+						continue
+					}
+					match := submatches(chunk.text, loc)
+
+					endpoint := ""
+					method := pattern.Method
+
+					// Handle xhr.open special case (has method in capture group)
+					if pattern.Name == "xhr.open" && len(match) >= 3 {
+						method = HTTPMethod(match[1])
+						endpoint = match[2]
+					} else {
+						endpoint = match[1]
+					}
+
+					if !e.scope.Includes(endpoint) {
+						continue
+					}
+
+					// Skip if already seen
+					key := fmt.Sprintf("%s:%s", method, endpoint)
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+
+					// Extract parameters from endpoint
+					params := extractParameters(endpoint)
+
+					finding := EndpointFinding{
+						File:       filepath,
+						URL:        url,
+						Endpoint:   endpoint,
+						Method:     method,
+						Type:       pattern.Type,
+						Line:       lineNum,
+						Parameters: params,
+					}
+					finding.Severity = EndpointSeverity(finding)
+
+					if e.includeContext {
+						finding.Context = line
+						if len(chunks) > 1 {
+							finding.Context = snippet(line, chunk.offset+loc[0], chunk.offset+loc[1])
+						}
+					}
+
+					findings = append(findings, finding)
 				}
-
-				if e.includeContext {
-					finding.Context = line
-				}
-
-				findings = append(findings, finding)
 			}
 		}
 	}
@@ -250,6 +320,18 @@ func (e *EndpointScanner) ScanFile(filepath, url string) ([]EndpointFinding, err
 	return findings, nil
 }
 
+// submatches returns the text of each group located by a FindAllStringSubmatchIndex
+// result, with "" for groups that did not participate
+func submatches(text string, loc []int) []string {
+	match := make([]string, len(loc)/2)
+	for i := range match {
+		if loc[2*i] >= 0 {
+			match[i] = text[loc[2*i]:loc[2*i+1]]
+		}
+	}
+	return match
+}
+
 // extractParameters extracts parameter placeholders from endpoint
 func extractParameters(endpoint string) []string {
 	var params []string
@@ -327,20 +409,84 @@ func FormatBurpSuite(findings []EndpointFinding, baseURL string) string {
 	return strings.Join(lines, "\n")
 }
 
-// FormatNuclei formats endpoints for Nuclei template
-func FormatNuclei(findings []EndpointFinding) string {
-	var paths []string
+// NucleiTemplate holds the template metadata written by FormatNuclei.
+// Empty fields use the defaults below.
+type NucleiTemplate struct {
+	ID       string // Template id (default: discovered-endpoints)
+	Author   string // Template author (default: downurl)
+	Severity string // info, low, medium, high, critical or unknown (default: info)
+}
+
+// Nuclei template defaults
+const (
+	DefaultNucleiID       = "discovered-endpoints"
+	DefaultNucleiAuthor   = "downurl"
+	DefaultNucleiSeverity = "info"
+)
+
+var nucleiIDRegex = regexp.MustCompile(`^([a-zA-Z0-9]+[-_])*[a-zA-Z0-9]+$`)
+
+// nucleiSeverities are the severities Nuclei accepts
+var nucleiSeverities = map[string]bool{
+	"info": true, "low": true, "medium": true, "high": true, "critical": true, "unknown": true,
+}
+
+// nucleiMethodRank orders the request blocks; other methods follow, sorted by name
+var nucleiMethodRank = map[HTTPMethod]int{
+	MethodGET: 1, MethodPOST: 2, MethodPUT: 3, MethodPATCH: 4, MethodDELETE: 5, MethodHEAD: 6,
+}
+
+// Validate checks the id and severity against what Nuclei accepts
+func (t NucleiTemplate) Validate() error {
+	if t.ID != "" && !nucleiIDRegex.MatchString(t.ID) {
+		return fmt.Errorf("invalid nuclei template id: %q (letters and digits separated by - or _)", t.ID)
+	}
+	if t.Severity != "" && !nucleiSeverities[strings.ToLower(t.Severity)] {
+		return fmt.Errorf("unknown nuclei severity: %s (valid: info, low, medium, high, critical, unknown)", t.Severity)
+	}
+	return nil
+}
+
+// withDefaults fills in the empty fields
+func (t NucleiTemplate) withDefaults() NucleiTemplate {
+	if t.ID == "" {
+		t.ID = DefaultNucleiID
+	}
+	if t.Author == "" {
+		t.Author = DefaultNucleiAuthor
+	}
+	if t.Severity == "" {
+		t.Severity = DefaultNucleiSeverity
+	}
+	t.Severity = strings.ToLower(t.Severity)
+	return t
+}
+
+// FormatNuclei formats endpoints for Nuclei template, with one request
+// block per discovered HTTP method (endpoints with no known method are GET).
+// Nuclei paths are relative to {{BaseURL}}, so absolute URLs are left out;
+// use FilterByScope with EndpointScopeRelative to see exactly what is kept.
+func FormatNuclei(findings []EndpointFinding, tmpl NucleiTemplate) string {
+	paths := make(map[HTTPMethod][]string)
 	seen := make(map[string]bool)
 
 	for _, finding := range findings {
-		endpoint := finding.Endpoint
+		if IsAbsoluteEndpoint(finding.Endpoint) {
+			continue
+		}
 
-		// Only include paths, not full URLs
-		if strings.HasPrefix(endpoint, "/") {
-			if !seen[endpoint] {
-				seen[endpoint] = true
-				paths = append(paths, fmt.Sprintf("      - \"{{BaseURL}}%s\"", endpoint))
-			}
+		// Relative paths without a leading slash are anchored at the base URL
+		endpoint := finding.Endpoint
+		if !strings.HasPrefix(endpoint, "/") {
+			endpoint = "/" + endpoint
+		}
+		method := HTTPMethod(strings.ToUpper(string(finding.Method)))
+		if method == MethodAny {
+			method = MethodGET
+		}
+		if key := string(method) + " " + endpoint; !seen[key] {
+			seen[key] = true
+			paths[method] = append(paths[method], fmt.Sprintf("      - \"{{BaseURL}}%s\"", endpoint))
 		}
 	}
 
@@ -348,16 +494,32 @@ func FormatNuclei(findings []EndpointFinding) string {
 		return ""
 	}
 
-	template := `id: discovered-endpoints
-info:
-  name: Discovered Endpoints
-  author: downurl
-  severity: info
-
-requests:
-  - method: GET
-    path:
-` + strings.Join(paths, "\n")
+	methods := make([]HTTPMethod, 0, len(paths))
+	for method := range paths {
+		methods = append(methods, method)
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		ri, rj := nucleiMethodRank[methods[i]], nucleiMethodRank[methods[j]]
+		if ri == 0 || rj == 0 {
+			// Known methods first, then the rest by name
+			if ri != rj {
+				return ri != 0
+			}
+			return methods[i] < methods[j]
+		}
+		return ri < rj
+	})
+
+	tmpl = tmpl.withDefaults()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "id: %s\n", tmpl.ID)
+	sb.WriteString("info:\n  name: Discovered Endpoints\n")
+	fmt.Fprintf(&sb, "  author: %s\n", strconv.Quote(tmpl.Author))
+	fmt.Fprintf(&sb, "  severity: %s\n", tmpl.Severity)
+	sb.WriteString("\nrequests:")
+	for _, method := range methods {
+		fmt.Fprintf(&sb, "\n  - method: %s\n    path:\n%s", method, strings.Join(paths[method], "\n"))
+	}
 
-	return template
+	return sb.String()
 }
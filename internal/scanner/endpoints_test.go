@@ -157,7 +157,7 @@ func TestFormatNuclei(t *testing.T) {
 		{Endpoint: "/api/products", Method: MethodGET},
 	}
 
-	output := FormatNuclei(findings)
+	output := FormatNuclei(findings, NucleiTemplate{})
 
 	if !strings.Contains(output, "id: discovered-endpoints") {
 		t.Error("Expected Nuclei template to contain id")
@@ -171,3 +171,55 @@ func TestFormatNuclei(t *testing.T) {
 		t.Error("Expected Nuclei template to contain /api/products endpoint")
 	}
 }
+
+func TestFormatNuclei_Template(t *testing.T) {
+	findings := []EndpointFinding{
+		{Endpoint: "/api/users", Method: MethodGET},
+		{Endpoint: "/api/users", Method: MethodPOST},
+		{Endpoint: "api/users/{id}", Method: MethodDELETE},
+		{Endpoint: "/api/search", Method: MethodAny},
+		{Endpoint: "https://other.example.com/api", Method: MethodPUT},
+	}
+
+	output := FormatNuclei(findings, NucleiTemplate{ID: "acme-api", Author: "alice", Severity: "High"})
+
+	for _, want := range []string{"id: acme-api\n", "  author: \"alice\"\n", "  severity: high\n"} {
+		if !strings.Contains(output, want) {
+			t.Errorf("FormatNuclei() missing %q:\n%s", want, output)
+		}
+	}
+
+	want := `requests:
+  - method: GET
+    path:
+      - "{{BaseURL}}/api/users"
+      - "{{BaseURL}}/api/search"
+  - method: POST
+    path:
+      - "{{BaseURL}}/api/users"
+  - method: DELETE
+    path:
+      - "{{BaseURL}}/api/users/{id}"`
+	if !strings.HasSuffix(output, want) {
+		t.Errorf("FormatNuclei() requests =\n%s\nwant suffix\n%s", output, want)
+	}
+}
+
+func TestNucleiTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		tmpl    NucleiTemplate
+		wantErr bool
+	}{
+		{NucleiTemplate{}, false},
+		{NucleiTemplate{ID: "acme_api-2", Severity: "critical"}, false},
+		{NucleiTemplate{ID: "has space"}, true},
+		{NucleiTemplate{ID: "-leading"}, true},
+		{NucleiTemplate{Severity: "severe"}, true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.tmpl.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v.Validate() error = %v, wantErr %v", tt.tmpl, err, tt.wantErr)
+		}
+	}
+}
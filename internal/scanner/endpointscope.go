@@ -0,0 +1,59 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// EndpointScope selects which kinds of endpoint findings are kept
+type EndpointScope string
+
+const (
+	EndpointScopeBoth     EndpointScope = "both"     // Keep everything
+	EndpointScopeRelative EndpointScope = "relative" // Keep paths such as /api/users or api/users
+	EndpointScopeAbsolute EndpointScope = "absolute" // Keep full URLs such as https://api.example.com/users
+)
+
+// schemeRegex matches the scheme of an absolute URL (https://, wss://, ...)
+var schemeRegex = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9+.\-]*://`)
+
+// ParseEndpointScope parses an --endpoints-scope value; "" means both
+func ParseEndpointScope(s string) (EndpointScope, error) {
+	switch scope := EndpointScope(strings.ToLower(strings.TrimSpace(s))); scope {
+	case "":
+		return EndpointScopeBoth, nil
+	case EndpointScopeBoth, EndpointScopeRelative, EndpointScopeAbsolute:
+		return scope, nil
+	}
+	return "", fmt.Errorf("unknown endpoint scope: %s (valid: relative, absolute, both)", s)
+}
+
+// IsAbsoluteEndpoint reports whether an endpoint names its host: it has a
+// scheme (https://, wss://) or is protocol-relative (//cdn.example.com/x)
+func IsAbsoluteEndpoint(endpoint string) bool {
+	return schemeRegex.MatchString(endpoint) || strings.HasPrefix(endpoint, "//")
+}
+
+// Includes reports whether an endpoint falls within the scope.
+// The zero value includes everything, like EndpointScopeBoth.
+func (s EndpointScope) Includes(endpoint string) bool {
+	switch s {
+	case EndpointScopeRelative:
+		return !IsAbsoluteEndpoint(endpoint)
+	case EndpointScopeAbsolute:
+		return IsAbsoluteEndpoint(endpoint)
+	}
+	return true
+}
+
+// FilterByScope keeps the findings whose endpoint falls within scope
+func FilterByScope(findings []EndpointFinding, scope EndpointScope) []EndpointFinding {
+	var filtered []EndpointFinding
+	for _, finding := range findings {
+		if scope.Includes(finding.Endpoint) {
+			filtered = append(filtered, finding)
+		}
+	}
+	return filtered
+}
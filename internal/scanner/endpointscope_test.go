@@ -0,0 +1,108 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestEndpointScanner_Scope(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "app.js")
+
+	content := `
+fetch('/api/users');
+fetch('api/relative');
+fetch('https://api.example.com/v1/items');
+fetch('//cdn.example.com/config.json');
+new WebSocket('wss://ws.example.com/live');
+`
+	if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	tests := []struct {
+		scope EndpointScope
+		want  []string
+	}{
+		{EndpointScopeRelative, []string{"/api/users", "api/relative"}},
+		{EndpointScopeAbsolute, []string{"//cdn.example.com/config.json", "https://api.example.com/v1/items", "wss://ws.example.com/live"}},
+		{EndpointScopeBoth, []string{"//cdn.example.com/config.json", "/api/users", "api/relative", "https://api.example.com/v1/items", "wss://ws.example.com/live"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.scope), func(t *testing.T) {
+			s := NewEndpointScanner()
+			s.SetScope(tt.scope)
+			findings, err := s.ScanFile(testFile, "https://example.com/app.js")
+			if err != nil {
+				t.Fatalf("ScanFile() error = %v", err)
+			}
+
+			got := make(map[string]bool)
+			for _, f := range findings {
+				got[f.Endpoint] = true
+			}
+			var endpoints []string
+			for e := range got {
+				endpoints = append(endpoints, e)
+			}
+			sort.Strings(endpoints)
+
+			if !reflect.DeepEqual(endpoints, tt.want) {
+				t.Errorf("scope %s found %v, want %v", tt.scope, endpoints, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterByScope(t *testing.T) {
+	findings := []EndpointFinding{
+		{Endpoint: "/api/users"},
+		{Endpoint: "https://api.example.com/items"},
+		{Endpoint: "graphql"},
+	}
+
+	if got := FilterByScope(findings, EndpointScopeRelative); len(got) != 2 || got[0].Endpoint != "/api/users" || got[1].Endpoint != "graphql" {
+		t.Errorf("FilterByScope(relative) = %+v", got)
+	}
+	if got := FilterByScope(findings, EndpointScopeAbsolute); len(got) != 1 || got[0].Endpoint != "https://api.example.com/items" {
+		t.Errorf("FilterByScope(absolute) = %+v", got)
+	}
+	if got := FilterByScope(findings, EndpointScopeBoth); len(got) != 3 {
+		t.Errorf("FilterByScope(both) = %+v, want all 3", got)
+	}
+
+	// Relative findings reach Nuclei in full, anchored at the base URL
+	nuclei := FormatNuclei(FilterByScope(findings, EndpointScopeRelative), NucleiTemplate{})
+	if !strings.Contains(nuclei, `"{{BaseURL}}/graphql"`) || !strings.Contains(nuclei, `"{{BaseURL}}/api/users"`) {
+		t.Errorf("FormatNuclei() missing relative paths:\n%s", nuclei)
+	}
+}
+
+func TestParseEndpointScope(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    EndpointScope
+		wantErr bool
+	}{
+		{"", EndpointScopeBoth, false},
+		{"Relative", EndpointScopeRelative, false},
+		{"absolute", EndpointScopeAbsolute, false},
+		{"both", EndpointScopeBoth, false},
+		{"paths", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseEndpointScope(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseEndpointScope(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseEndpointScope(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
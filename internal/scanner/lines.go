@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"bufio"
+	"io"
+	"math"
+)
+
+// DefaultMaxLineLength is the longest line scanned in one piece. Longer
+// lines, typically minified bundles, are scanned in overlapping chunks.
+const DefaultMaxLineLength = 64 * 1024
+
+// lineOverlap is how far each chunk of a long line reaches into the next.
+// A match shorter than this that straddles a chunk boundary is found whole;
+// longer matches cut by a boundary are dropped.
+const lineOverlap = 1024
+
+// snippetRadius is how much of a long line around a match is kept as context
+const snippetRadius = 100
+
+// lineChunk is a piece of a line to scan
+type lineChunk struct {
+	text   string
+	offset int  // Position of text within the line
+	limit  int  // Matches starting at or after this index belong to the next chunk
+	last   bool // Chunk ends where the line does
+}
+
+// keeps reports whether a match at text[start:end] belongs to this chunk:
+// it starts before the next chunk does and isn't cut off by the chunk's end
+func (c lineChunk) keeps(start, end int) bool {
+	return start < c.limit && (c.last || end < len(c.text))
+}
+
+// splitLine returns line as one chunk if it is at most max bytes long (or
+// max is 0), otherwise as chunks of max bytes each extended by lineOverlap
+func splitLine(line string, max int) []lineChunk {
+	if max <= 0 || len(line) <= max {
+		return []lineChunk{{text: line, limit: len(line), last: true}}
+	}
+
+	var chunks []lineChunk
+	for offset := 0; offset < len(line); offset += max {
+		end, limit := offset+max+lineOverlap, max
+		last := end >= len(line)
+		if last {
+			end = len(line)
+			limit = end - offset
+		}
+		chunks = append(chunks, lineChunk{text: line[offset:end], offset: offset, limit: limit, last: last})
+		if last {
+			break
+		}
+	}
+	return chunks
+}
+
+// snippet returns the part of a long line around line[start:end]
+func snippet(line string, start, end int) string {
+	start -= snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end += snippetRadius
+	if end > len(line) {
+		end = len(line)
+	}
+	return line[start:end]
+}
+
+// newLineScanner returns a line scanner without bufio.Scanner's default 64KB
+// line limit, which single-line bundles easily exceed
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), math.MaxInt)
+	return sc
+}
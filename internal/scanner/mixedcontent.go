@@ -0,0 +1,204 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Subresource is a resource an HTML page loads (script, stylesheet, image, ...)
+type Subresource struct {
+	Tag       string `json:"tag"`
+	Attribute string `json:"attribute"`
+	URL       string `json:"url"`
+	Line      int    `json:"line"`
+}
+
+// MixedContentFinding represents an insecure http:// subresource on an https:// page
+type MixedContentFinding struct {
+	File      string `json:"file"`
+	URL       string `json:"url"`      // Page that references the resource
+	Resource  string `json:"resource"` // Insecure resource URL
+	Tag       string `json:"tag"`
+	Attribute string `json:"attribute"`
+	Line      int    `json:"line"`
+	Active    bool   `json:"active"` // Active content (scripts, frames, styles) is blocked by browsers
+}
+
+// subresourceAttrs lists, per tag, the attributes that load a resource
+var subresourceAttrs = map[string][]string{
+	"script": {"src"},
+	"link":   {"href"},
+	"img":    {"src", "srcset"},
+	"iframe": {"src"},
+	"frame":  {"src"},
+	"audio":  {"src"},
+	"video":  {"src", "poster"},
+	"source": {"src", "srcset"},
+	"track":  {"src"},
+	"embed":  {"src"},
+	"object": {"data"},
+	"form":   {"action"},
+}
+
+// activeTags load content that can act on the page
+var activeTags = map[string]bool{
+	"script": true,
+	"link":   true,
+	"iframe": true,
+	"frame":  true,
+	"embed":  true,
+	"object": true,
+	"form":   true,
+}
+
+// linkResourceRels are the <link rel> values that make the browser fetch href
+var linkResourceRels = []string{"stylesheet", "icon", "preload", "modulepreload", "prefetch", "manifest"}
+
+var (
+	htmlTagRegex  = regexp.MustCompile(`(?is)<([a-z]+)\b([^>]*)>`)
+	htmlAttrRegex = regexp.MustCompile(`(?is)([a-z-]+)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+)
+
+// ExtractSubresources returns the subresource references found in an HTML document
+func ExtractSubresources(html string) []Subresource {
+	var resources []Subresource
+
+	for _, loc := range htmlTagRegex.FindAllStringSubmatchIndex(html, -1) {
+		tag := strings.ToLower(html[loc[2]:loc[3]])
+		wanted, ok := subresourceAttrs[tag]
+		if !ok {
+			continue
+		}
+
+		attrs := parseAttributes(html[loc[4]:loc[5]])
+
+		// <link> only loads a resource for some rel values (not canonical, alternate, ...)
+		if tag == "link" && !isResourceLink(attrs["rel"]) {
+			continue
+		}
+
+		line := strings.Count(html[:loc[0]], "\n") + 1
+		for _, attr := range wanted {
+			value, ok := attrs[attr]
+			if !ok || value == "" {
+				continue
+			}
+
+			urls := []string{value}
+			if attr == "srcset" {
+				urls = parseSrcset(value)
+			}
+			for _, u := range urls {
+				resources = append(resources, Subresource{Tag: tag, Attribute: attr, URL: u, Line: line})
+			}
+		}
+	}
+
+	return resources
+}
+
+// parseAttributes parses the attributes of a tag, lowercasing names
+func parseAttributes(s string) map[string]string {
+	attrs := make(map[string]string)
+	for _, m := range htmlAttrRegex.FindAllStringSubmatch(s, -1) {
+		name := strings.ToLower(m[1])
+		if _, exists := attrs[name]; exists {
+			continue
+		}
+		attrs[name] = strings.TrimSpace(m[2] + m[3] + m[4])
+	}
+	return attrs
+}
+
+// isResourceLink checks if a <link rel> value makes the browser fetch the href
+func isResourceLink(rel string) bool {
+	for _, r := range strings.Fields(strings.ToLower(rel)) {
+		for _, want := range linkResourceRels {
+			if r == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseSrcset extracts the URLs from a srcset value ("a.png 1x, b.png 2x")
+func parseSrcset(srcset string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(srcset, ",") {
+		if fields := strings.Fields(candidate); len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
+
+// MixedContentScanner finds insecure subresources on HTTPS pages
+type MixedContentScanner struct {
+	scanBinary bool // Scan files that look binary instead of skipping them
+}
+
+// NewMixedContentScanner creates a new mixed content scanner
+func NewMixedContentScanner() *MixedContentScanner {
+	return &MixedContentScanner{}
+}
+
+// SetScanBinary controls whether files that look binary (see IsBinary) are
+// scanned anyway. By default they are skipped without findings or errors.
+func (m *MixedContentScanner) SetScanBinary(enabled bool) {
+	m.scanBinary = enabled
+}
+
+// ScanFile scans an HTML file downloaded from url.
+// Pages not served over HTTPS cannot have mixed content and yield no findings.
+func (m *MixedContentScanner) ScanFile(filepath, url string) ([]MixedContentFinding, error) {
+	return m.ScanFileContext(context.Background(), filepath, url)
+}
+
+// ScanFileContext scans an HTML file like ScanFile, giving up once ctx is done
+func (m *MixedContentScanner) ScanFileContext(ctx context.Context, filepath, url string) ([]MixedContentFinding, error) {
+	if !strings.HasPrefix(strings.ToLower(url), "https://") {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return m.ScanDataContext(ctx, filepath, url, data)
+}
+
+// ScanDataContext scans HTML already in memory like ScanFileContext,
+// reporting findings against filepath
+func (m *MixedContentScanner) ScanDataContext(ctx context.Context, filepath, url string, data []byte) ([]MixedContentFinding, error) {
+	if !strings.HasPrefix(strings.ToLower(url), "https://") {
+		return nil, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if !m.scanBinary && IsBinary(data) {
+		return nil, nil
+	}
+
+	var findings []MixedContentFinding
+	for _, res := range ExtractSubresources(string(data)) {
+		if !strings.HasPrefix(strings.ToLower(res.URL), "http://") {
+			continue
+		}
+		findings = append(findings, MixedContentFinding{
+			File:      filepath,
+			URL:       url,
+			Resource:  res.URL,
+			Tag:       res.Tag,
+			Attribute: res.Attribute,
+			Line:      res.Line,
+			Active:    activeTags[res.Tag],
+		})
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,97 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const mixedContentPage = `<!DOCTYPE html>
+<html>
+<head>
+  <link rel="stylesheet" href="http://cdn.example.com/site.css">
+  <link rel="canonical" href="http://example.com/">
+  <script src="https://cdn.example.com/safe.js"></script>
+</head>
+<body>
+  <script
+    src="http://cdn.example.com/tracker.js"></script>
+  <img src='/logo.png' srcset="http://img.example.com/a.png 1x, https://img.example.com/b.png 2x">
+  <a href="http://example.com/page">plain link</a>
+</body>
+</html>
+`
+
+func TestMixedContentScanner_HTTPSPage(t *testing.T) {
+	scanner := NewMixedContentScanner()
+
+	testFile := filepath.Join(t.TempDir(), "index.html")
+	if err := os.WriteFile(testFile, []byte(mixedContentPage), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	findings, err := scanner.ScanFile(testFile, "https://example.com/")
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+
+	want := map[string]struct {
+		tag    string
+		line   int
+		active bool
+	}{
+		"http://cdn.example.com/site.css":   {"link", 4, true},
+		"http://cdn.example.com/tracker.js": {"script", 9, true},
+		"http://img.example.com/a.png":      {"img", 11, false},
+	}
+
+	if len(findings) != len(want) {
+		t.Fatalf("ScanFile() returned %d findings, want %d: %+v", len(findings), len(want), findings)
+	}
+
+	for _, f := range findings {
+		w, ok := want[f.Resource]
+		if !ok {
+			t.Errorf("unexpected finding %s", f.Resource)
+			continue
+		}
+		if f.Tag != w.tag || f.Line != w.line || f.Active != w.active {
+			t.Errorf("finding %s = {tag %s line %d active %v}, want {tag %s line %d active %v}",
+				f.Resource, f.Tag, f.Line, f.Active, w.tag, w.line, w.active)
+		}
+		if f.URL != "https://example.com/" {
+			t.Errorf("finding URL = %s, want the page URL", f.URL)
+		}
+	}
+}
+
+func TestMixedContentScanner_HTTPPage(t *testing.T) {
+	scanner := NewMixedContentScanner()
+
+	testFile := filepath.Join(t.TempDir(), "index.html")
+	if err := os.WriteFile(testFile, []byte(mixedContentPage), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	findings, err := scanner.ScanFile(testFile, "http://example.com/")
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+	if len(findings) != 0 {
+		t.Errorf("ScanFile() on an http page returned %d findings, want 0", len(findings))
+	}
+}
+
+func TestExtractSubresources(t *testing.T) {
+	resources := ExtractSubresources(mixedContentPage)
+
+	// site.css, safe.js, tracker.js, logo.png, a.png, b.png
+	if len(resources) != 6 {
+		t.Fatalf("ExtractSubresources() returned %d resources, want 6: %+v", len(resources), resources)
+	}
+	for _, r := range resources {
+		if r.URL == "http://example.com/" || r.URL == "http://example.com/page" {
+			t.Errorf("ExtractSubresources() included non-subresource %s", r.URL)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// secretRule is one entry of a secret rules file
+type secretRule struct {
+	Name       string `json:"name"`
+	Regex      string `json:"regex"`
+	Confidence string `json:"confidence"`
+}
+
+// LoadSecretRules reads custom secret patterns from a file listing
+// {name, regex, confidence} entries, the confidence being low, medium or
+// high (default medium). A file starting with "[" is read as JSON:
+//
+//	[{"name": "Acme API Token", "regex": "acme_[0-9a-f]{32}", "confidence": "high"}]
+//
+// anything else as YAML, of which a block list ("- name: ..." followed by
+// the other keys, indented) of plain, 'single-' or "double-quoted" values
+// is understood.
+func LoadSecretRules(path string) ([]SecretPattern, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret rules: %w", err)
+	}
+
+	var rules []secretRule
+	if trimmed := bytes.TrimSpace(data); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &rules); err != nil {
+			return nil, fmt.Errorf("%s: invalid JSON: %w", path, err)
+		}
+	} else if rules, err = parseYAMLRules(string(data)); err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("%s: no rules found", path)
+	}
+
+	patterns := make([]SecretPattern, 0, len(rules))
+	for i, rule := range rules {
+		pattern, err := rule.pattern()
+		if err != nil {
+			return nil, fmt.Errorf("%s: rule %d: %w", path, i+1, err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// pattern compiles a rule
+func (r secretRule) pattern() (SecretPattern, error) {
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		return SecretPattern{}, fmt.Errorf("missing name")
+	}
+	if r.Regex == "" {
+		return SecretPattern{}, fmt.Errorf("%s: missing regex", name)
+	}
+	re, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return SecretPattern{}, fmt.Errorf("%s: invalid regex: %w", name, err)
+	}
+	confidence := ConfidenceMedium
+	if strings.TrimSpace(r.Confidence) != "" {
+		if confidence, err = ParseConfidence(r.Confidence); err != nil {
+			return SecretPattern{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	return SecretPattern{Name: SecretType(name), Regex: re, Confidence: confidence}, nil
+}
+
+// parseYAMLRules reads a YAML block list of rules, optionally under a
+// top-level "rules:" key. Errors start with the line number.
+func parseYAMLRules(text string) ([]secretRule, error) {
+	var rules []secretRule
+	var current *secretRule
+	for i, line := range strings.Split(text, "\n") {
+		lineNum := i + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") || trimmed == "---" || trimmed == "rules:" {
+			continue
+		}
+
+		if trimmed == "-" || strings.HasPrefix(trimmed, "- ") {
+			rules = append(rules, secretRule{})
+			current = &rules[len(rules)-1]
+			if trimmed = strings.TrimSpace(trimmed[1:]); trimmed == "" {
+				continue
+			}
+		} else if current == nil {
+			return nil, fmt.Errorf("%d: expected a list of rules (- name: ...)", lineNum)
+		}
+
+		key, raw, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			return nil, fmt.Errorf("%d: expected key: value", lineNum)
+		}
+		value, err := yamlScalar(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", lineNum, err)
+		}
+		switch strings.TrimSpace(key) {
+		case "name":
+			current.Name = value
+		case "regex":
+			current.Regex = value
+		case "confidence":
+			current.Confidence = value
+		default:
+			return nil, fmt.Errorf("%d: unknown key %q (valid: name, regex, confidence)", lineNum, strings.TrimSpace(key))
+		}
+	}
+	return rules, nil
+}
+
+// yamlScalar returns the value of a plain, single-quoted or double-quoted
+// YAML scalar, without any trailing comment
+func yamlScalar(s string) (string, error) {
+	var value, rest string
+	switch {
+	case strings.HasPrefix(s, "'"):
+		// '' is the only escape in single quotes
+		var b strings.Builder
+		i := 1
+		for ; i < len(s); i++ {
+			if s[i] == '\'' {
+				if i+1 < len(s) && s[i+1] == '\'' {
+					b.WriteByte('\'')
+					i++
+					continue
+				}
+				break
+			}
+			b.WriteByte(s[i])
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated single-quoted value")
+		}
+		value, rest = b.String(), s[i+1:]
+
+	case strings.HasPrefix(s, `"`):
+		i := 1
+		for ; i < len(s) && s[i] != '"'; i++ {
+			if s[i] == '\\' {
+				i++
+			}
+		}
+		if i >= len(s) {
+			return "", fmt.Errorf("unterminated double-quoted value")
+		}
+		unquoted, err := strconv.Unquote(s[:i+1])
+		if err != nil {
+			return "", fmt.Errorf("invalid double-quoted value %s (write \\\\ for a backslash, or use single quotes)", s[:i+1])
+		}
+		value, rest = unquoted, s[i+1:]
+
+	default:
+		if i := strings.Index(s, " #"); i != -1 {
+			s = s[:i]
+		}
+		return strings.TrimSpace(s), nil
+	}
+
+	if rest = strings.TrimSpace(rest); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected text after quoted value: %s", rest)
+	}
+	return value, nil
+}
@@ -0,0 +1,82 @@
+package scanner
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// SecretFingerprint identifies a finding across runs by its type, URL and
+// matched value. The line and local file path are left out, so a secret
+// that moves within a file, or a run saved to another directory, still
+// matches.
+func SecretFingerprint(s SecretFinding) string {
+	h := sha256.New()
+	h.Write([]byte(s.SecretType))
+	h.Write([]byte{0})
+	h.Write([]byte(s.URL))
+	h.Write([]byte{0})
+	h.Write([]byte(s.Match))
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// DiffSecrets compares a run's findings with a previous run's, returning
+// the ones not seen before and the previous ones that are gone
+func DiffSecrets(previous, current []SecretFinding) (added, resolved []SecretFinding) {
+	before := make(map[string]bool, len(previous))
+	for _, s := range previous {
+		before[SecretFingerprint(s)] = true
+	}
+	now := make(map[string]bool, len(current))
+	for _, s := range current {
+		fp := SecretFingerprint(s)
+		now[fp] = true
+		if !before[fp] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range previous {
+		if !now[SecretFingerprint(s)] {
+			resolved = append(resolved, s)
+		}
+	}
+	return added, resolved
+}
+
+// LoadSecrets reads findings saved by --secrets-output. A missing file
+// holds no findings: it is the state of a first run.
+func LoadSecrets(path string) ([]SecretFinding, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	var secrets []SecretFinding
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file %s: %w", path, err)
+	}
+	return secrets, nil
+}
+
+// SaveSecrets writes findings as the JSON array LoadSecrets reads
+func SaveSecrets(path string, secrets []SecretFinding) error {
+	if secrets == nil {
+		secrets = []SecretFinding{}
+	}
+	data, err := json.MarshalIndent(secrets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal secrets: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write secrets file: %w", err)
+	}
+	return nil
+}
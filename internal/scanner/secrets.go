@@ -1,12 +1,16 @@
 package scanner
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
 // SecretType represents the type of secret found
@@ -18,6 +22,14 @@ const (
 	SecretTypeGitHubToken  SecretType = "GitHub Token"
 	SecretTypeSlackToken   SecretType = "Slack Token"
 	SecretTypeGoogleAPIKey SecretType = "Google API Key"
+	SecretTypeStripeKey    SecretType = "Stripe Secret Key"
+	SecretTypeStripePublic SecretType = "Stripe Publishable Key"
+	SecretTypeTwilioKey    SecretType = "Twilio API Key"
+	SecretTypeTwilioSID    SecretType = "Twilio Account SID"
+	SecretTypeNPMToken     SecretType = "npm Token"
+	SecretTypeGitLabToken  SecretType = "GitLab Token"
+	SecretTypeSendGridKey  SecretType = "SendGrid API Key"
+	SecretTypeMailgunKey   SecretType = "Mailgun API Key"
 	SecretTypeJWT          SecretType = "JWT Token"
 	SecretTypePrivateKey   SecretType = "Private Key"
 	SecretTypeGenericAPI   SecretType = "Generic API Key"
@@ -42,6 +54,7 @@ type SecretPattern struct {
 	Confidence Confidence
 	MinLength  int
 	MaxLength  int
+	Group      int // Submatch reported as the match (0 = the whole match)
 }
 
 // SecretFinding represents a found secret
@@ -53,15 +66,38 @@ type SecretFinding struct {
 	Match      string     `json:"match"`
 	Context    string     `json:"context"`
 	Confidence Confidence `json:"confidence"`
+	Severity   int        `json:"severity"`          // 0-100, see SecretSeverity
+	Partial    bool       `json:"partial,omitempty"` // Found in a file cut short by --preview-bytes
 }
 
+// EntropyCandidate is a string literal scored by the entropy detector,
+// recorded with --entropy-debug to help pick a threshold
+type EntropyCandidate struct {
+	File     string  `json:"file"`
+	URL      string  `json:"url"`
+	Line     int     `json:"line"`
+	Value    string  `json:"value"`
+	Entropy  float64 `json:"entropy"`
+	Reported bool    `json:"reported"` // At or above the threshold, so also a finding
+}
+
+// DefaultEntropyDebugMargin is how far below the threshold candidates are still recorded
+const DefaultEntropyDebugMargin = 1.0
+
 // SecretScanner scans files for secrets
 type SecretScanner struct {
-	patterns        []SecretPattern
-	minEntropy      float64
-	entropyMinLen   int
-	includeContext  bool
-	contextLines    int
+	patterns       []SecretPattern
+	minEntropy     float64
+	entropyMinLen  int
+	includeContext bool
+	contextLines   int
+	assignmentOnly bool // Report entropy hits only when the literal is assigned (= '...', : '...')
+	scanBinary     bool // Scan files that look binary instead of skipping them
+	maxLineLength  int  // Lines longer than this are scanned in overlapping chunks (0 = never)
+
+	debugMargin float64            // Record candidates down to minEntropy-debugMargin (0 = off)
+	candidates  []EntropyCandidate // Recorded candidates, guarded by mu
+	mu          sync.Mutex
 }
 
 // NewSecretScanner creates a new secret scanner
@@ -72,9 +108,59 @@ func NewSecretScanner(minEntropy float64) *SecretScanner {
 		entropyMinLen:  20,
 		includeContext: true,
 		contextLines:   2,
+		maxLineLength:  DefaultMaxLineLength,
 	}
 }
 
+// NewSecretScannerWithRules creates a secret scanner that also looks for
+// custom patterns, such as those of LoadSecretRules, after the built-in ones
+func NewSecretScannerWithRules(minEntropy float64, rules []SecretPattern) *SecretScanner {
+	s := NewSecretScanner(minEntropy)
+	s.patterns = append(s.patterns, rules...)
+	return s
+}
+
+// SetAssignmentOnly makes the entropy detector ignore literals that are not
+// the value of an assignment or key (x = '...', key: '...', "key":"...").
+// Bare strings such as hashes and IDs in text are then no longer reported.
+func (s *SecretScanner) SetAssignmentOnly(enabled bool) {
+	s.assignmentOnly = enabled
+}
+
+// SetScanBinary controls whether files that look binary (see IsBinary) are
+// scanned anyway. By default they are skipped without findings or errors.
+func (s *SecretScanner) SetScanBinary(enabled bool) {
+	s.scanBinary = enabled
+}
+
+// SetMaxLineLength sets the longest line scanned in one piece. Longer lines
+// are scanned in overlapping chunks, keeping scan time and memory bounded on
+// megabyte-long minified lines; findings keep their line number and get the
+// text around the match as context. 0 scans every line whole.
+func (s *SecretScanner) SetMaxLineLength(n int) {
+	s.maxLineLength = n
+}
+
+// SetEntropyDebug records every entropy candidate scoring within margin
+// below the threshold or above it, retrievable with EntropyCandidates.
+// A margin of 0 turns recording off.
+func (s *SecretScanner) SetEntropyDebug(margin float64) {
+	s.debugMargin = margin
+}
+
+// EntropyCandidates returns the recorded candidates, highest entropy first
+func (s *SecretScanner) EntropyCandidates() []EntropyCandidate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidates := make([]EntropyCandidate, len(s.candidates))
+	copy(candidates, s.candidates)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Entropy > candidates[j].Entropy
+	})
+	return candidates
+}
+
 // buildPatterns creates the list of secret patterns
 func buildPatterns() []SecretPattern {
 	return []SecretPattern{
@@ -108,6 +194,51 @@ func buildPatterns() []SecretPattern {
 			Regex:      regexp.MustCompile(`AIza[0-9A-Za-z_\-]{35}`),
 			Confidence: ConfidenceHigh,
 		},
+		{
+			// Secret (sk_) and restricted (rk_) live keys
+			Name:       SecretTypeStripeKey,
+			Regex:      regexp.MustCompile(`\b[rs]k_live_[0-9a-zA-Z]{24,99}\b`),
+			Confidence: ConfidenceHigh,
+		},
+		{
+			// Publishable keys are meant for browsers, but tell which account a site uses
+			Name:       SecretTypeStripePublic,
+			Regex:      regexp.MustCompile(`\bpk_live_[0-9a-zA-Z]{24,99}\b`),
+			Confidence: ConfidenceLow,
+		},
+		{
+			Name:       SecretTypeTwilioKey,
+			Regex:      regexp.MustCompile(`\bSK[0-9a-f]{32}\b`),
+			Confidence: ConfidenceMedium,
+		},
+		{
+			Name:       SecretTypeTwilioSID,
+			Regex:      regexp.MustCompile(`\bAC[0-9a-f]{32}\b`),
+			Confidence: ConfidenceLow,
+		},
+		{
+			Name:       SecretTypeNPMToken,
+			Regex:      regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}\b`),
+			Confidence: ConfidenceHigh,
+		},
+		{
+			Name:       SecretTypeGitLabToken,
+			Regex:      regexp.MustCompile(`\b(glpat-[0-9A-Za-z_\-]{20,})(?:[^\w\-]|$)`),
+			Confidence: ConfidenceHigh,
+			Group:      1, // The token may end in '-', where \b would not match
+		},
+		{
+			Name:       SecretTypeSendGridKey,
+			Regex:      regexp.MustCompile(`\b(SG\.[\w\-]{22}\.[\w\-]{43})(?:[^\w\-]|$)`),
+			Confidence: ConfidenceHigh,
+			Group:      1,
+		},
+		{
+			// Legacy "key-" keys; newer keys have no prefix to tell them apart
+			Name:       SecretTypeMailgunKey,
+			Regex:      regexp.MustCompile(`\bkey-[0-9a-f]{32}\b`),
+			Confidence: ConfidenceMedium,
+		},
 		{
 			Name:       SecretTypeJWT,
 			Regex:      regexp.MustCompile(`eyJ[a-zA-Z0-9_\-]*\.eyJ[a-zA-Z0-9_\-]*\.[a-zA-Z0-9_\-]*`),
@@ -138,19 +269,49 @@ func buildPatterns() []SecretPattern {
 
 // ScanFile scans a single file for secrets
 func (s *SecretScanner) ScanFile(filepath, url string) ([]SecretFinding, error) {
+	return s.ScanFileContext(context.Background(), filepath, url)
+}
+
+// ScanFileContext scans a single file for secrets, giving up once ctx is done
+func (s *SecretScanner) ScanFileContext(ctx context.Context, filepath, url string) ([]SecretFinding, error) {
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 	defer file.Close()
 
+	if !s.scanBinary {
+		if binary, err := isBinaryFile(file); err != nil {
+			return nil, fmt.Errorf("error reading file: %w", err)
+		} else if binary {
+			return nil, nil
+		}
+	}
+	return s.scan(ctx, file, filepath, url)
+}
+
+// ScanDataContext scans content already in memory, reporting findings
+// against filepath, giving up once ctx is done
+func (s *SecretScanner) ScanDataContext(ctx context.Context, filepath, url string, data []byte) ([]SecretFinding, error) {
+	if !s.scanBinary && IsBinary(data) {
+		return nil, nil
+	}
+	return s.scan(ctx, bytes.NewReader(data), filepath, url)
+}
+
+// scan scans the lines read from r
+func (s *SecretScanner) scan(ctx context.Context, r io.Reader, filepath, url string) ([]SecretFinding, error) {
 	var findings []SecretFinding
-	scanner := bufio.NewScanner(file)
+	var candidates []EntropyCandidate
+	scanner := newLineScanner(r)
 	lineNum := 0
 	var lines []string
 
 	// Read all lines for context
 	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		lines = append(lines, scanner.Text())
 	}
 
@@ -160,50 +321,116 @@ func (s *SecretScanner) ScanFile(filepath, url string) ([]SecretFinding, error)
 
 	// Scan each line
 	for i, line := range lines {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		lineNum = i + 1
 
-		// Check pattern-based secrets
-		for _, pattern := range s.patterns {
-			matches := pattern.Regex.FindAllString(line, -1)
-			for _, match := range matches {
-				finding := SecretFinding{
-					File:       filepath,
-					URL:        url,
-					Line:       lineNum,
-					SecretType: pattern.Name,
-					Match:      match,
-					Confidence: pattern.Confidence,
-				}
-
-				if s.includeContext {
-					finding.Context = s.getContext(lines, i, s.contextLines)
-				}
-
-				findings = append(findings, finding)
+		chunks := splitLine(line, s.maxLineLength)
+		context := func(start, end int) string {
+			if len(chunks) > 1 {
+				return snippet(line, start, end)
+			}
+			return s.getContext(lines, i, s.contextLines)
+		}
+
+		for _, chunk := range chunks {
+			found, seen, err := s.scanChunk(ctx, chunk, filepath, url, lineNum, context)
+			if err != nil {
+				return nil, err
 			}
+			findings = append(findings, found...)
+			candidates = append(candidates, seen...)
 		}
+	}
+
+	if len(candidates) > 0 {
+		s.mu.Lock()
+		s.candidates = append(s.candidates, candidates...)
+		s.mu.Unlock()
+	}
+
+	return findings, nil
+}
 
-		// Check entropy-based detection
-		highEntropyStrings := s.findHighEntropyStrings(line)
-		for _, str := range highEntropyStrings {
+// scanChunk scans one chunk of a line for pattern and entropy findings,
+// returning them and the entropy candidates to record. context returns the
+// context for a match at the given line positions. Each pattern runs over
+// the whole chunk, so ctx is checked between patterns: a single long line
+// cannot hold a scan past its deadline for more than one pattern.
+func (s *SecretScanner) scanChunk(ctx context.Context, chunk lineChunk, filepath, url string, lineNum int, context func(start, end int) string) ([]SecretFinding, []EntropyCandidate, error) {
+	var findings []SecretFinding
+	var candidates []EntropyCandidate
+
+	// Check pattern-based secrets
+	for _, pattern := range s.patterns {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		for _, loc := range pattern.Regex.FindAllStringSubmatchIndex(chunk.text, -1) {
+			loc = loc[2*pattern.Group : 2*pattern.Group+2]
+			if !chunk.keeps(loc[0], loc[1]) {
+				continue
+			}
 			finding := SecretFinding{
 				File:       filepath,
 				URL:        url,
 				Line:       lineNum,
-				SecretType: SecretTypeGenericHigh,
-				Match:      str,
-				Confidence: ConfidenceLow,
+				SecretType: pattern.Name,
+				Match:      chunk.text[loc[0]:loc[1]],
+				Confidence: pattern.Confidence,
 			}
+			finding.Severity = SecretSeverity(finding)
 
 			if s.includeContext {
-				finding.Context = s.getContext(lines, i, s.contextLines)
+				finding.Context = context(chunk.offset+loc[0], chunk.offset+loc[1])
 			}
 
 			findings = append(findings, finding)
 		}
 	}
 
-	return findings, nil
+	// Check entropy-based detection
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	for _, str := range s.findHighEntropyStrings(chunk.text) {
+		if !chunk.keeps(str.start, str.end) {
+			continue
+		}
+		reported := str.entropy >= s.minEntropy
+		if s.debugMargin > 0 {
+			candidates = append(candidates, EntropyCandidate{
+				File:     filepath,
+				URL:      url,
+				Line:     lineNum,
+				Value:    str.value,
+				Entropy:  str.entropy,
+				Reported: reported,
+			})
+		}
+		if !reported {
+			continue
+		}
+
+		finding := SecretFinding{
+			File:       filepath,
+			URL:        url,
+			Line:       lineNum,
+			SecretType: SecretTypeGenericHigh,
+			Match:      str.value,
+			Confidence: ConfidenceLow,
+		}
+		finding.Severity = SecretSeverity(finding)
+
+		if s.includeContext {
+			finding.Context = context(chunk.offset+str.start, chunk.offset+str.end)
+		}
+
+		findings = append(findings, finding)
+	}
+
+	return findings, candidates, nil
 }
 
 // getContext returns context lines around the match
@@ -218,37 +445,79 @@ func (s *SecretScanner) getContext(lines []string, index, contextLines int) stri
 		end = len(lines)
 	}
 
-	contextSlice := lines[start:end]
+	contextSlice := make([]string, 0, end-start)
+	for _, line := range lines[start:end] {
+		// A neighbouring minified line would swamp the context
+		if s.maxLineLength > 0 && len(line) > s.maxLineLength {
+			line = line[:2*snippetRadius] + "..."
+		}
+		contextSlice = append(contextSlice, line)
+	}
 	return strings.Join(contextSlice, "\n")
 }
 
-// findHighEntropyStrings finds strings with high Shannon entropy
-func (s *SecretScanner) findHighEntropyStrings(line string) []string {
-	var highEntropyStrings []string
+// entropyString is a string literal, its Shannon entropy and where the
+// literal (with its quotes) lies in the scanned line
+type entropyString struct {
+	value      string
+	entropy    float64
+	start, end int
+}
+
+// findHighEntropyStrings finds strings with high Shannon entropy.
+// With entropy debugging on, strings up to debugMargin below the
+// threshold are returned too; callers compare against minEntropy.
+func (s *SecretScanner) findHighEntropyStrings(line string) []entropyString {
+	var highEntropyStrings []entropyString
 
 	// Extract potential string literals
 	stringRegex := regexp.MustCompile(`['"]([a-zA-Z0-9+/=_\-]{20,})['"]`)
-	matches := stringRegex.FindAllStringSubmatch(line, -1)
+	matches := stringRegex.FindAllStringSubmatchIndex(line, -1)
 
 	for _, match := range matches {
-		if len(match) < 2 {
+		if len(match) < 4 {
 			continue
 		}
 
-		str := match[1]
+		str := line[match[2]:match[3]]
 		if len(str) < s.entropyMinLen {
 			continue
 		}
 
+		if s.assignmentOnly && !isAssigned(line[:match[0]]) {
+			continue
+		}
+
 		entropy := s.calculateEntropy(str)
-		if entropy >= s.minEntropy {
-			highEntropyStrings = append(highEntropyStrings, str)
+		if entropy >= s.minEntropy-s.debugMargin {
+			highEntropyStrings = append(highEntropyStrings, entropyString{value: str, entropy: entropy, start: match[0], end: match[1]})
 		}
 	}
 
 	return highEntropyStrings
 }
 
+// isAssigned reports whether a string literal preceded by before is the value
+// of an assignment or key: the last non-space character must be ':' or a
+// single '=' (comparisons such as ==, != and <= do not count)
+func isAssigned(before string) bool {
+	before = strings.TrimRight(before, " \t")
+	if before == "" {
+		return false
+	}
+
+	switch before[len(before)-1] {
+	case ':':
+		return true
+	case '=':
+		if len(before) == 1 {
+			return true
+		}
+		return !strings.ContainsRune("=!<>", rune(before[len(before)-2]))
+	}
+	return false
+}
+
 // calculateEntropy calculates Shannon entropy of a string
 func (s *SecretScanner) calculateEntropy(str string) float64 {
 	if len(str) == 0 {
@@ -291,6 +560,17 @@ func (s *SecretScanner) ScanBatch(files map[string]string) ([]SecretFinding, err
 	return allFindings, nil
 }
 
+// ParseConfidence parses a confidence level name; "" means low (every finding)
+func ParseConfidence(s string) (Confidence, error) {
+	switch c := Confidence(strings.ToLower(strings.TrimSpace(s))); c {
+	case "":
+		return ConfidenceLow, nil
+	case ConfidenceLow, ConfidenceMedium, ConfidenceHigh:
+		return c, nil
+	}
+	return "", fmt.Errorf("unknown confidence: %s (valid: low, medium, high)", s)
+}
+
 // FilterByConfidence filters findings by confidence level
 func FilterByConfidence(findings []SecretFinding, minConfidence Confidence) []SecretFinding {
 	confidenceOrder := map[Confidence]int{
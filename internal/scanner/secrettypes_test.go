@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Samples are assembled at run time so the repository holds no string a
+// secret scanner would flag
+func TestSecretScanner_ServiceTokens(t *testing.T) {
+	hex32 := strings.Repeat("0123456789abcdef", 2)
+	alnum := "a1B2c3D4e5F6g7H8i9J0k1L2m3N4o5P6q7R8"
+
+	tests := []struct {
+		name       string
+		sample     string
+		secretType SecretType
+		confidence Confidence
+	}{
+		{"stripe secret", "sk_" + "live_" + alnum[:24], SecretTypeStripeKey, ConfidenceHigh},
+		{"stripe restricted", "rk_" + "live_" + alnum[:30], SecretTypeStripeKey, ConfidenceHigh},
+		{"stripe publishable", "pk_" + "live_" + alnum[:24], SecretTypeStripePublic, ConfidenceLow},
+		{"twilio api key", "SK" + hex32, SecretTypeTwilioKey, ConfidenceMedium},
+		{"twilio account sid", "AC" + hex32, SecretTypeTwilioSID, ConfidenceLow},
+		{"npm", "npm_" + alnum[:36], SecretTypeNPMToken, ConfidenceHigh},
+		{"gitlab", "glpat-" + "xY_z-" + alnum[:15], SecretTypeGitLabToken, ConfidenceHigh},
+		{"sendgrid", "SG." + alnum[:20] + "_-" + "." + alnum + "-_" + alnum[:5], SecretTypeSendGridKey, ConfidenceHigh},
+		{"gitlab ending in dash", "glpat-" + alnum[:19] + "-", SecretTypeGitLabToken, ConfidenceHigh},
+		{"gitlab longer", "glpat-" + alnum, SecretTypeGitLabToken, ConfidenceHigh},
+		{"sendgrid ending in dash", "SG." + alnum[:22] + "." + alnum + alnum[:6] + "-", SecretTypeSendGridKey, ConfidenceHigh},
+		{"mailgun", "key-" + hex32, SecretTypeMailgunKey, ConfidenceMedium},
+	}
+
+	scanner := NewSecretScanner(10) // Entropy detection out of the way
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := scanContent(t, scanner, "const token = \""+tt.sample+"\";\n")
+
+			var found *SecretFinding
+			for i := range findings {
+				if findings[i].SecretType == tt.secretType {
+					found = &findings[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("%s not detected in %q, findings: %+v", tt.secretType, tt.sample, findings)
+			}
+			if found.Match != tt.sample {
+				t.Errorf("match = %q, want %q", found.Match, tt.sample)
+			}
+			if found.Confidence != tt.confidence {
+				t.Errorf("confidence = %s, want %s", found.Confidence, tt.confidence)
+			}
+			if found.Severity == 0 {
+				t.Error("severity should be set")
+			}
+		})
+	}
+}
+
+func TestSecretScanner_ServiceTokensNoFalsePositives(t *testing.T) {
+	content := `
+// Ordinary code that looks a little like the token formats
+const skip_live_reload = true;
+const stripe = Stripe(publishableKey); // pk_live_ keys are configured at build time
+const sku = "SKU12345";
+const account = "ACCOUNT_ID";
+const hash = "` + "SK" + strings.Repeat("0123456789abcdef", 2) + "ff" + `"; // too long for a Twilio key
+const pkg = "npm_config_registry";
+const glpat = "glpat-short";
+const sg = "SG.header.value";
+const sgLong = "` + "SG." + strings.Repeat("a", 22) + "." + strings.Repeat("b", 43) + "-suffix" + `"; // longer than a SendGrid key
+const cacheKey = "key-" + id;
+const message = "Sending a message to the account page";
+`
+	for _, f := range scanContent(t, NewSecretScanner(10), content) {
+		switch f.SecretType {
+		case SecretTypeStripeKey, SecretTypeStripePublic, SecretTypeTwilioKey, SecretTypeTwilioSID,
+			SecretTypeNPMToken, SecretTypeGitLabToken, SecretTypeSendGridKey, SecretTypeMailgunKey:
+			t.Errorf("false positive: %s %q on line %d", f.SecretType, f.Match, f.Line)
+		}
+	}
+}
+
+// scanContent scans content written to a temporary file
+func scanContent(t *testing.T, scanner *SecretScanner, content string) []SecretFinding {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "app.js")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	findings, err := scanner.ScanFile(path, "https://example.com/app.js")
+	if err != nil {
+		t.Fatalf("ScanFile() error = %v", err)
+	}
+	return findings
+}
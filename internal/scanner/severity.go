@@ -0,0 +1,99 @@
+package scanner
+
+import (
+	"sort"
+	"strings"
+)
+
+// Severity scores run from 0 (informational) to 100 (act now)
+
+// confidenceWeight is how much detection confidence contributes to a secret's severity
+var confidenceWeight = map[Confidence]int{
+	ConfidenceHigh:   50,
+	ConfidenceMedium: 30,
+	ConfidenceLow:    10,
+}
+
+// secretTypeWeight is how much damage a leaked secret of each type can do
+var secretTypeWeight = map[SecretType]int{
+	SecretTypePrivateKey:   40,
+	SecretTypeAWSSecret:    40,
+	SecretTypeStripeKey:    40,
+	SecretTypeAWSKey:       35,
+	SecretTypeDatabaseURL:  35,
+	SecretTypeNPMToken:     35,
+	SecretTypeGitHubToken:  30,
+	SecretTypeGitLabToken:  30,
+	SecretTypeTwilioKey:    30,
+	SecretTypeSlackToken:   25,
+	SecretTypeSendGridKey:  25,
+	SecretTypeMailgunKey:   25,
+	SecretTypeGoogleAPIKey: 20,
+	SecretTypeTwilioSID:    10,
+	SecretTypeStripePublic: 5,
+	SecretTypeJWT:          20,
+	SecretTypeGenericAPI:   15,
+	SecretTypePassword:     15,
+	SecretTypeGenericHigh:  5,
+}
+
+// SecretSeverity scores a secret finding from its confidence and how
+// sensitive its type is
+func SecretSeverity(f SecretFinding) int {
+	return min(confidenceWeight[f.Confidence]+secretTypeWeight[f.SecretType], 100)
+}
+
+// methodWeight is how much an endpoint's HTTP method suggests it changes state
+var methodWeight = map[HTTPMethod]int{
+	MethodDELETE: 40,
+	MethodPUT:    35,
+	MethodPATCH:  35,
+	MethodPOST:   30,
+	MethodGET:    15,
+	MethodHEAD:   10,
+	MethodAny:    10,
+}
+
+// endpointTypeWeight is how much attack surface each kind of endpoint exposes
+var endpointTypeWeight = map[EndpointType]int{
+	EndpointTypeGraphQL:   35,
+	EndpointTypeREST:      25,
+	EndpointTypeWebSocket: 25,
+	EndpointTypeGeneric:   10,
+}
+
+// sensitivePathWords mark endpoints worth a closer look
+var sensitivePathWords = []string{"admin", "internal", "debug", "token", "auth", "password", "secret", "upload", "config"}
+
+// EndpointSeverity scores an endpoint finding from its method, its type,
+// whether it takes parameters and whether its path looks sensitive
+func EndpointSeverity(f EndpointFinding) int {
+	score := methodWeight[f.Method] + endpointTypeWeight[f.Type]
+	if len(f.Parameters) > 0 {
+		score += 5
+	}
+	path := strings.ToLower(f.Endpoint)
+	for _, word := range sensitivePathWords {
+		if strings.Contains(path, word) {
+			score += 20
+			break
+		}
+	}
+	return min(score, 100)
+}
+
+// SortSecretsBySeverity orders secrets from most to least severe, keeping
+// discovery order between equal scores
+func SortSecretsBySeverity(findings []SecretFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+}
+
+// SortEndpointsBySeverity orders endpoints from most to least severe, keeping
+// discovery order between equal scores
+func SortEndpointsBySeverity(findings []EndpointFinding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+}
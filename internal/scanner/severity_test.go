@@ -0,0 +1,47 @@
+package scanner
+
+import "testing"
+
+func TestSecretSeverity_Ordering(t *testing.T) {
+	entropy := SecretFinding{SecretType: SecretTypeGenericHigh, Confidence: ConfidenceLow, Match: "entropy"}
+	password := SecretFinding{SecretType: SecretTypePassword, Confidence: ConfidenceLow, Match: "password"}
+	jwt := SecretFinding{SecretType: SecretTypeJWT, Confidence: ConfidenceMedium, Match: "jwt"}
+	awsKey := SecretFinding{SecretType: SecretTypeAWSKey, Confidence: ConfidenceHigh, Match: "aws"}
+	privateKey := SecretFinding{SecretType: SecretTypePrivateKey, Confidence: ConfidenceHigh, Match: "private-key"}
+
+	findings := []SecretFinding{entropy, password, awsKey, jwt, privateKey}
+	for i := range findings {
+		findings[i].Severity = SecretSeverity(findings[i])
+	}
+	SortSecretsBySeverity(findings)
+
+	want := []string{"private-key", "aws", "jwt", "password", "entropy"}
+	for i, f := range findings {
+		if f.Match != want[i] {
+			t.Errorf("position %d = %s (severity %d), want %s", i, f.Match, f.Severity, want[i])
+		}
+	}
+
+	if s := SecretSeverity(privateKey); s > 100 {
+		t.Errorf("private key severity = %d, want at most 100", s)
+	}
+}
+
+func TestEndpointSeverity_Ordering(t *testing.T) {
+	findings := []EndpointFinding{
+		{Endpoint: "/static/app.css", Method: MethodAny, Type: EndpointTypeGeneric},
+		{Endpoint: "/api/users", Method: MethodGET, Type: EndpointTypeREST},
+		{Endpoint: "/api/admin/users/{id}", Method: MethodDELETE, Type: EndpointTypeREST, Parameters: []string{"id"}},
+	}
+	for i := range findings {
+		findings[i].Severity = EndpointSeverity(findings[i])
+	}
+	SortEndpointsBySeverity(findings)
+
+	want := []string{"/api/admin/users/{id}", "/api/users", "/static/app.css"}
+	for i, f := range findings {
+		if f.Endpoint != want[i] {
+			t.Errorf("position %d = %s (severity %d), want %s", i, f.Endpoint, f.Severity, want[i])
+		}
+	}
+}
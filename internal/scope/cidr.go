@@ -0,0 +1,162 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Resolver looks up the IP addresses of a host (satisfied by *net.Resolver)
+type Resolver interface {
+	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// resolution is a cached DNS lookup result
+type resolution struct {
+	ips []net.IP
+	err error
+}
+
+// CIDRScope restricts downloads to hosts that resolve inside allowed networks
+type CIDRScope struct {
+	networks []*net.IPNet
+	resolver Resolver
+	cache    map[string]resolution
+	mu       sync.Mutex
+}
+
+// ParseCIDRs parses a comma-separated list of CIDR ranges.
+// Bare IP addresses are accepted as single-host ranges.
+func ParseCIDRs(s string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if !strings.Contains(part, "/") {
+			ip := net.ParseIP(part)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid CIDR: %s", part)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 32
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR: %s", part)
+		}
+		networks = append(networks, network)
+	}
+
+	if len(networks) == 0 {
+		return nil, fmt.Errorf("no CIDR ranges specified")
+	}
+	return networks, nil
+}
+
+// NewCIDRScope creates a scope from allowed networks.
+// If resolver is nil, net.DefaultResolver is used.
+func NewCIDRScope(networks []*net.IPNet, resolver Resolver) *CIDRScope {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &CIDRScope{
+		networks: networks,
+		resolver: resolver,
+		cache:    make(map[string]resolution),
+	}
+}
+
+// InScope checks if every address the URL's host resolves to is inside an allowed network
+func (s *CIDRScope) InScope(ctx context.Context, rawURL string) (bool, string) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return false, "cannot determine host"
+	}
+	host := parsed.Hostname()
+
+	ips, err := s.resolve(ctx, host)
+	if err != nil {
+		return false, fmt.Sprintf("cannot resolve %s: %v", host, err)
+	}
+
+	for _, ip := range ips {
+		if !s.contains(ip) {
+			return false, fmt.Sprintf("%s resolves to %s, outside allowed CIDRs", host, ip)
+		}
+	}
+
+	return true, ""
+}
+
+// CheckAddr checks the "ip:port" address a connection is about to be made
+// to. Unlike InScope it sees the address really dialed, so a DNS answer that
+// changes after the check (rebinding) cannot reach another network.
+func (s *CIDRScope) CheckAddr(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("cannot check %s: not an IP address", address)
+	}
+	if !s.contains(ip) {
+		return fmt.Errorf("connection to %s refused: outside allowed CIDRs", ip)
+	}
+	return nil
+}
+
+// resolve returns the addresses for a host, using the per-host cache
+func (s *CIDRScope) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	// IP literals need no lookup
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+
+	s.mu.Lock()
+	cached, ok := s.cache[host]
+	s.mu.Unlock()
+	if ok {
+		return cached.ips, cached.err
+	}
+
+	addrs, err := s.resolver.LookupIPAddr(ctx, host)
+	var ips []net.IP
+	for _, addr := range addrs {
+		ips = append(ips, addr.IP)
+	}
+	if err == nil && len(ips) == 0 {
+		err = fmt.Errorf("no addresses found")
+	}
+
+	// Don't cache lookups aborted by cancellation
+	if ctx.Err() == nil {
+		s.mu.Lock()
+		s.cache[host] = resolution{ips: ips, err: err}
+		s.mu.Unlock()
+	}
+
+	return ips, err
+}
+
+// contains checks if an IP is inside any allowed network
+func (s *CIDRScope) contains(ip net.IP) bool {
+	for _, network := range s.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+package scope
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// stubResolver maps hostnames to fixed addresses and counts lookups
+type stubResolver struct {
+	hosts   map[string][]string
+	lookups map[string]int
+	mu      sync.Mutex
+}
+
+func newStubResolver(hosts map[string][]string) *stubResolver {
+	return &stubResolver{hosts: hosts, lookups: make(map[string]int)}
+}
+
+func (r *stubResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.mu.Lock()
+	r.lookups[host]++
+	r.mu.Unlock()
+
+	ips, ok := r.hosts[host]
+	if !ok {
+		return nil, fmt.Errorf("no such host")
+	}
+	var addrs []net.IPAddr
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestParseCIDRs(t *testing.T) {
+	networks, err := ParseCIDRs("10.0.0.0/8, 192.168.0.0/16,203.0.113.7,2001:db8::/32")
+	if err != nil {
+		t.Fatalf("ParseCIDRs() error = %v", err)
+	}
+	if len(networks) != 4 {
+		t.Fatalf("ParseCIDRs() returned %d networks, want 4", len(networks))
+	}
+	if !networks[2].Contains(net.ParseIP("203.0.113.7")) || networks[2].Contains(net.ParseIP("203.0.113.8")) {
+		t.Error("bare IP should be parsed as a single-host range")
+	}
+
+	for _, invalid := range []string{"", "10.0.0.0/33", "not-an-ip"} {
+		if _, err := ParseCIDRs(invalid); err == nil {
+			t.Errorf("ParseCIDRs(%q) expected error", invalid)
+		}
+	}
+}
+
+func TestCIDRScope_InScope(t *testing.T) {
+	resolver := newStubResolver(map[string][]string{
+		"internal.example.com": {"10.1.2.3"},
+		"lan.example.com":      {"192.168.1.10"},
+		"public.example.com":   {"93.184.216.34"},
+		"mixed.example.com":    {"10.1.2.4", "93.184.216.35"},
+	})
+	networks, _ := ParseCIDRs("10.0.0.0/8,192.168.0.0/16")
+	s := NewCIDRScope(networks, resolver)
+	ctx := context.Background()
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://internal.example.com/app.js", true},
+		{"http://lan.example.com:8080/app.js", true},
+		{"https://public.example.com/app.js", false},
+		{"https://mixed.example.com/app.js", false},
+		{"https://unknown.example.com/app.js", false},
+		{"http://10.9.9.9/app.js", true},
+		{"http://8.8.8.8/app.js", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, reason := s.InScope(ctx, tt.url)
+			if got != tt.want {
+				t.Errorf("InScope() = %v (%s), want %v", got, reason, tt.want)
+			}
+			if !got && reason == "" {
+				t.Error("InScope() returned no reason for an out-of-scope URL")
+			}
+		})
+	}
+}
+
+func TestCIDRScope_CachesResolutions(t *testing.T) {
+	resolver := newStubResolver(map[string][]string{
+		"internal.example.com": {"10.1.2.3"},
+	})
+	networks, _ := ParseCIDRs("10.0.0.0/8")
+	s := NewCIDRScope(networks, resolver)
+
+	for i := 0; i < 5; i++ {
+		s.InScope(context.Background(), fmt.Sprintf("https://internal.example.com/file%d.js", i))
+		s.InScope(context.Background(), "https://missing.example.com/app.js")
+	}
+
+	if n := resolver.lookups["internal.example.com"]; n != 1 {
+		t.Errorf("internal.example.com resolved %d times, want 1", n)
+	}
+	if n := resolver.lookups["missing.example.com"]; n != 1 {
+		t.Errorf("failed lookups should be cached too, resolved %d times", n)
+	}
+
+	_, reason := s.InScope(context.Background(), "https://missing.example.com/app.js")
+	if !strings.Contains(reason, "cannot resolve") {
+		t.Errorf("reason = %q, want resolution failure", reason)
+	}
+}
+
+func TestCIDRScope_CheckAddr(t *testing.T) {
+	networks, _ := ParseCIDRs("10.0.0.0/8,2001:db8::/32")
+	s := NewCIDRScope(networks, nil)
+
+	for addr, want := range map[string]bool{
+		"10.1.2.3:443":      true,
+		"[2001:db8::1]:80":  true,
+		"127.0.0.1:8080":    false,
+		"[::1]:443":         false,
+		"internal.host:443": false,
+		"10.0.0.1":          true,
+	} {
+		if err := s.CheckAddr(addr); (err == nil) != want {
+			t.Errorf("CheckAddr(%q) = %v, want allowed=%v", addr, err, want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"archive/tar"
+	"archive/zip"
 	"compress/gzip"
 	"fmt"
 	"io"
@@ -10,44 +11,141 @@ import (
 	"strings"
 )
 
-// Archiver handles tar.gz archive creation
-type Archiver struct{}
+// Archive formats
+const (
+	ArchiveTarGz = "tar.gz"
+	ArchiveZip   = "zip"
+)
+
+// ParseArchiveFormat validates an archive format name ("" = tar.gz).
+// "tgz" is accepted for tar.gz.
+func ParseArchiveFormat(s string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", ArchiveTarGz, "tgz":
+		return ArchiveTarGz, nil
+	case ArchiveZip:
+		return ArchiveZip, nil
+	default:
+		return "", fmt.Errorf("unsupported archive format: %s (use tar.gz, zip)", s)
+	}
+}
+
+// CompressionLevel is the gzip level used for archives. Anything else that
+// reports compressed sizes uses it too, so the numbers match the archive.
+const CompressionLevel = gzip.DefaultCompression
+
+// Archiver handles tar.gz and zip archive creation
+type Archiver struct {
+	tempDir string   // Where archives are built before being moved into place ("" = system temp)
+	exclude []string // Files left out of archives, such as reports
+}
 
 // NewArchiver creates a new Archiver instance
 func NewArchiver() *Archiver {
 	return &Archiver{}
 }
 
-// CreateTarGz creates a tar.gz archive from a source directory
+// SetTempDir sets the directory archives are built in ("" = system temp)
+func (a *Archiver) SetTempDir(dir string) {
+	a.tempDir = dir
+}
+
+// SetExclude leaves the given files out of the archives created afterwards
+func (a *Archiver) SetExclude(paths ...string) {
+	a.exclude = a.exclude[:0]
+	for _, p := range paths {
+		a.exclude = append(a.exclude, filepath.Clean(p))
+	}
+}
+
+// Create creates an archive of sourceDir in the given format (see ParseArchiveFormat)
+func (a *Archiver) Create(format, sourceDir, destFile string) error {
+	if format == ArchiveZip {
+		return a.CreateZip(sourceDir, destFile)
+	}
+	return a.CreateTarGz(sourceDir, destFile)
+}
+
+// CreateTarGz creates a tar.gz archive from a source directory.
+// The archive is built in the temp directory and only moved to destFile
+// once complete, so a failed run never leaves a truncated archive behind.
 func (a *Archiver) CreateTarGz(sourceDir, destFile string) error {
-	// Create destination file
-	outFile, err := os.Create(destFile)
+	return a.create(sourceDir, destFile, "downurl-*.tar.gz.tmp", writeArchive)
+}
+
+// CreateZip creates a zip archive from a source directory, laid out like
+// CreateTarGz's and built the same way: in the temp directory, then moved
+// into place
+func (a *Archiver) CreateZip(sourceDir, destFile string) error {
+	return a.create(sourceDir, destFile, "downurl-*.zip.tmp", writeZip)
+}
+
+// create builds an archive with write in a temp file named after pattern and moves it to destFile
+func (a *Archiver) create(sourceDir, destFile, pattern string, write func(w io.Writer, sourceDir string, skip ...string) error) error {
+	tmpFile, err := createTemp(a.tempDir, pattern)
 	if err != nil {
 		return fmt.Errorf("failed to create archive file: %w", err)
 	}
-	defer outFile.Close()
+	tmpPath := tmpFile.Name()
+
+	skip := append([]string{destFile, tmpPath}, a.exclude...)
+	if err := write(tmpFile, sourceDir, skip...); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	// Temp files are private (0600); the archive gets normal file permissions
+	if err := os.Chmod(tmpPath, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	if err := moveFile(tmpPath, destFile); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to move archive into place: %w", err)
+	}
+	return nil
+}
 
+// writeArchive writes sourceDir as a tar.gz stream to w, leaving out the
+// given paths (the archive itself, in case it lives inside sourceDir)
+func writeArchive(w io.Writer, sourceDir string, skip ...string) error {
 	// Create gzip writer
-	gzWriter := gzip.NewWriter(outFile)
-	defer gzWriter.Close()
+	gzWriter, err := gzip.NewWriterLevel(w, CompressionLevel)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip writer: %w", err)
+	}
 
 	// Create tar writer
 	tarWriter := tar.NewWriter(gzWriter)
-	defer tarWriter.Close()
 
 	// Walk through source directory and add files to archive
-	return filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+	err = filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Skip the archive file itself if it's in the source directory
-		if path == destFile {
-			return nil
+		for _, p := range skip {
+			if path == p {
+				return nil
+			}
+		}
+
+		// Symlinks (e.g. left by --dedupe-mode symlink) are stored as links
+		var link string
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(path); err != nil {
+				return fmt.Errorf("failed to read link: %w", err)
+			}
 		}
 
 		// Create tar header
-		header, err := tar.FileInfoHeader(info, "")
+		header, err := tar.FileInfoHeader(info, link)
 		if err != nil {
 			return fmt.Errorf("failed to create tar header: %w", err)
 		}
@@ -67,7 +165,7 @@ func (a *Archiver) CreateTarGz(sourceDir, destFile string) error {
 		}
 
 		// If it's a file, write its content
-		if !info.IsDir() {
+		if info.Mode().IsRegular() {
 			file, err := os.Open(path)
 			if err != nil {
 				return fmt.Errorf("failed to open file: %w", err)
@@ -84,4 +182,90 @@ func (a *Archiver) CreateTarGz(sourceDir, destFile string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gzWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+// writeZip writes sourceDir as a zip stream to w, with the same entry names
+// as writeArchive and leaving out the given paths
+func writeZip(w io.Writer, sourceDir string, skip ...string) error {
+	zipWriter := zip.NewWriter(w)
+
+	err := filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		// Skip the archive file itself if it's in the source directory
+		for _, p := range skip {
+			if path == p {
+				return nil
+			}
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return fmt.Errorf("failed to create zip header: %w", err)
+		}
+
+		// Entry names are relative to the source directory's parent, as in tar.gz
+		relPath, err := filepath.Rel(filepath.Dir(sourceDir), path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		header.Name = strings.ReplaceAll(relPath, string(os.PathSeparator), "/")
+		if info.IsDir() {
+			header.Name += "/"
+		} else {
+			header.Method = zip.Deflate
+		}
+
+		entry, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("failed to write zip header: %w", err)
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		// Zip stores a symlink as an entry with the link mode holding its target
+		if info.Mode()&os.ModeSymlink != 0 {
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read link: %w", err)
+			}
+			if _, err := io.WriteString(entry, link); err != nil {
+				return fmt.Errorf("failed to write file content: %w", err)
+			}
+			return nil
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		_, copyErr := io.Copy(entry, file)
+		file.Close()
+		if copyErr != nil {
+			return fmt.Errorf("failed to write file content: %w", copyErr)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiver_CreateZip(t *testing.T) {
+	sourceDir := filepath.Join(t.TempDir(), "output")
+	if err := os.MkdirAll(filepath.Join(sourceDir, "js"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"js/app.js":   "console.log(1)",
+		"report.json": "{}",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(sourceDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	archiver := NewArchiver()
+	archiver.SetTempDir(t.TempDir())
+	archiver.SetExclude(filepath.Join(sourceDir, "report.json"))
+	destFile := filepath.Join(sourceDir, "output.zip")
+	if err := archiver.Create(ArchiveZip, sourceDir, destFile); err != nil {
+		t.Fatalf("Create(zip) error = %v", err)
+	}
+
+	zr, err := zip.OpenReader(destFile)
+	if err != nil {
+		t.Fatalf("invalid zip archive: %v", err)
+	}
+	defer zr.Close()
+
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		if f.Name != "output/js/app.js" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", f.Name, err)
+		}
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(data) != files["js/app.js"] {
+			t.Errorf("%s = %q, want %q", f.Name, data, files["js/app.js"])
+		}
+	}
+	// Same layout as tar.gz; the excluded report and the archive itself are left out
+	if got := strings.Join(names, ","); got != "output/,output/js/,output/js/app.js" {
+		t.Errorf("archive entries = %s, want output/,output/js/,output/js/app.js", got)
+	}
+}
+
+func TestParseArchiveFormat(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{"", ArchiveTarGz, false},
+		{"tar.gz", ArchiveTarGz, false},
+		{"TGZ", ArchiveTarGz, false},
+		{"zip", ArchiveZip, false},
+		{"rar", "", true},
+	}
+	for _, tt := range tests {
+		got, err := ParseArchiveFormat(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseArchiveFormat(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrOutputNotEmpty is returned by RequireEmpty when the directory has entries
+var ErrOutputNotEmpty = errors.New("output directory is not empty")
+
+// RequireEmpty returns ErrOutputNotEmpty if dir contains anything.
+// A directory that does not exist yet counts as empty.
+func RequireEmpty(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("%w: %s has %d entries", ErrOutputNotEmpty, dir, len(entries))
+	}
+	return nil
+}
+
+// CleanDir removes everything inside dir, leaving dir itself in place.
+// Symlinks inside dir are removed, never followed. It refuses directories whose
+// removal would reach beyond the output: the filesystem root, the home
+// directory, and the working directory or any of its parents.
+func CleanDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("cannot access output directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("output path is not a directory: %s", dir)
+	}
+	if err := checkCleanable(dir); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read output directory: %w", err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// checkCleanable rejects directories that must never be wiped
+func checkCleanable(dir string) error {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("cannot resolve output directory: %w", err)
+	}
+	abs = filepath.Clean(abs)
+
+	if abs == filepath.VolumeName(abs)+string(filepath.Separator) {
+		return fmt.Errorf("refusing to clean the filesystem root")
+	}
+	if home, err := os.UserHomeDir(); err == nil && abs == filepath.Clean(home) {
+		return fmt.Errorf("refusing to clean the home directory: %s", abs)
+	}
+	if wd, err := os.Getwd(); err == nil {
+		wd = filepath.Clean(wd)
+		if wd == abs || strings.HasPrefix(wd, abs+string(filepath.Separator)) {
+			return fmt.Errorf("refusing to clean %s: it contains the working directory", abs)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCleanDir(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "old", "nested"), 0755)
+	os.WriteFile(filepath.Join(dir, "old", "nested", "app.js"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(dir, "report.txt"), []byte("x"), 0644)
+
+	// A symlink to a directory outside the output must be removed, not followed
+	outside := t.TempDir()
+	keep := filepath.Join(outside, "keep.txt")
+	os.WriteFile(keep, []byte("x"), 0644)
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatalf("Symlink() error = %v", err)
+	}
+
+	if err := CleanDir(dir); err != nil {
+		t.Fatalf("CleanDir() error = %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("output directory was removed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("CleanDir() left %d entries, want 0", len(entries))
+	}
+	if _, err := os.Stat(keep); err != nil {
+		t.Errorf("file behind symlink was removed: %v", err)
+	}
+
+	// Cleaning a directory that does not exist is a no-op
+	if err := CleanDir(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("CleanDir(missing) error = %v, want nil", err)
+	}
+}
+
+func TestCleanDir_RefusesUnsafeDirs(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "file.txt"), []byte("x"), 0644)
+	t.Chdir(dir)
+
+	for _, target := range []string{".", "..", "/"} {
+		if err := CleanDir(target); err == nil {
+			t.Errorf("CleanDir(%q) expected error", target)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "file.txt")); err != nil {
+		t.Errorf("refused clean still removed files: %v", err)
+	}
+}
+
+func TestRequireEmpty(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := RequireEmpty(dir); err != nil {
+		t.Errorf("RequireEmpty(empty) error = %v, want nil", err)
+	}
+	if err := RequireEmpty(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("RequireEmpty(missing) error = %v, want nil", err)
+	}
+
+	os.WriteFile(filepath.Join(dir, "app.js"), []byte("x"), 0644)
+	if err := RequireEmpty(dir); !errors.Is(err, ErrOutputNotEmpty) {
+		t.Errorf("RequireEmpty(non-empty) error = %v, want ErrOutputNotEmpty", err)
+	}
+}
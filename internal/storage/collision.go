@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultCollisionFormat names a file whose name is taken: test.js becomes test_1.js
+const DefaultCollisionFormat = "{name}_{n}{ext}"
+
+// collisionPlaceholder matches the {...} placeholders of a collision format
+var collisionPlaceholder = regexp.MustCompile(`\{[^}]*\}`)
+
+// CollisionFormat names files whose name is already taken. Placeholders:
+// {name} (the name without extension), {ext} (the extension, dot included),
+// {n} (a counter from 1) and {hash8} or {hash} (the SHA-256 of the content,
+// 8 or 64 hex digits). The zero value is DefaultCollisionFormat.
+type CollisionFormat struct {
+	pattern string
+	counter bool // Has {n}
+	hash    bool // Has {hash8} or {hash}
+}
+
+// ParseCollisionFormat parses a --collision-format pattern; "" means
+// DefaultCollisionFormat. The pattern needs {n} or a hash to tell files apart.
+func ParseCollisionFormat(s string) (CollisionFormat, error) {
+	if s == "" {
+		s = DefaultCollisionFormat
+	}
+	if strings.ContainsAny(s, `/\`) {
+		return CollisionFormat{}, fmt.Errorf("invalid collision format %q: it names a file, not a path", s)
+	}
+
+	f := CollisionFormat{pattern: s}
+	for _, p := range collisionPlaceholder.FindAllString(s, -1) {
+		switch p {
+		case "{name}", "{ext}":
+		case "{n}":
+			f.counter = true
+		case "{hash8}", "{hash}":
+			f.hash = true
+		default:
+			return CollisionFormat{}, fmt.Errorf("unknown placeholder %s in collision format (valid: {name}, {ext}, {n}, {hash8}, {hash})", p)
+		}
+	}
+	if !f.counter && !f.hash {
+		return CollisionFormat{}, fmt.Errorf("collision format %q needs {n}, {hash8} or {hash} to tell files apart", s)
+	}
+	return f, nil
+}
+
+// String returns the pattern
+func (f CollisionFormat) String() string {
+	if f.pattern == "" {
+		return DefaultCollisionFormat
+	}
+	return f.pattern
+}
+
+// usesHash reports whether names depend on the content
+func (f CollisionFormat) usesHash() bool {
+	return f.hash
+}
+
+// attempts returns how many names to try: with no counter, the name only
+// depends on the content, so there is just one
+func (f CollisionFormat) attempts() int {
+	if f.pattern != "" && !f.counter {
+		return 1
+	}
+	return 1000
+}
+
+// name returns the n-th name for filename; sum is the hex SHA-256 of the
+// content (only needed if usesHash)
+func (f CollisionFormat) name(filename string, n int, sum string) string {
+	ext := filepath.Ext(filename)
+	hash8 := sum
+	if len(hash8) > 8 {
+		hash8 = hash8[:8]
+	}
+	return strings.NewReplacer(
+		"{name}", filename[:len(filename)-len(ext)],
+		"{ext}", ext,
+		"{n}", strconv.Itoa(n),
+		"{hash8}", hash8,
+		"{hash}", sum,
+	).Replace(f.String())
+}
+
+// contentHash returns the hex SHA-256 of data
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Deduplication modes
+const (
+	DedupeReport   = "report"   // Only find duplicates
+	DedupeHardlink = "hardlink" // Replace duplicates with hard links to one copy
+	DedupeSymlink  = "symlink"  // Replace duplicates with relative symlinks to one copy
+)
+
+// ParseDedupeMode validates a --dedupe-mode value ("" means DedupeReport)
+func ParseDedupeMode(s string) (string, error) {
+	switch mode := strings.ToLower(strings.TrimSpace(s)); mode {
+	case "":
+		return DedupeReport, nil
+	case DedupeReport, DedupeHardlink, DedupeSymlink:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("invalid dedupe mode %q (expected report, hardlink or symlink)", s)
+	}
+}
+
+// DuplicateGroup is a set of files with identical content
+type DuplicateGroup struct {
+	SHA256     string
+	Size       int64
+	Kept       string   // The copy the others are linked to
+	Duplicates []string // The other copies
+}
+
+// DedupeResult summarises a DeduplicateByHash run
+type DedupeResult struct {
+	Groups     []DuplicateGroup
+	Files      int   // Duplicates beyond the kept copy of each group
+	SavedBytes int64 // Space they take up: freed when linked, freeable in report mode
+	Linked     int   // Duplicates replaced by a link
+}
+
+// DeduplicateByHash walks baseDir, hashes every regular file and groups those
+// with identical content. In DedupeReport mode nothing is changed; otherwise
+// each duplicate is replaced by a hard link or a relative symlink to the
+// newest copy, so pruning older dated runs never leaves a link dangling.
+// Symlinks are not followed, empty files are ignored and files that are
+// already hard links to each other are not counted again.
+func DeduplicateByHash(baseDir, mode string) (DedupeResult, error) {
+	var result DedupeResult
+	mode, err := ParseDedupeMode(mode)
+	if err != nil {
+		return result, err
+	}
+
+	// Group by size first, so only files that may be equal get hashed
+	bySize := make(map[int64][]dedupeFile)
+	err = filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		if info.Size() > 0 {
+			bySize[info.Size()] = append(bySize[info.Size()], dedupeFile{path: path, info: info})
+		}
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to walk %s: %w", baseDir, err)
+	}
+
+	sizes := make([]int64, 0, len(bySize))
+	for size, files := range bySize {
+		if len(files) > 1 {
+			sizes = append(sizes, size)
+		}
+	}
+	sort.Slice(sizes, func(i, j int) bool { return sizes[i] > sizes[j] })
+
+	for _, size := range sizes {
+		byHash := make(map[string][]dedupeFile)
+		var hashes []string
+		for _, f := range bySize[size] {
+			sum, err := hashFile(f.path)
+			if err != nil {
+				return result, err
+			}
+			if _, ok := byHash[sum]; !ok {
+				hashes = append(hashes, sum)
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+		sort.Strings(hashes)
+
+		for _, sum := range hashes {
+			group, ok := newDuplicateGroup(sum, size, byHash[sum])
+			if !ok {
+				continue
+			}
+			result.Groups = append(result.Groups, group)
+			result.Files += len(group.Duplicates)
+			result.SavedBytes += int64(len(group.Duplicates)) * size
+
+			if mode == DedupeReport {
+				continue
+			}
+			for _, dup := range group.Duplicates {
+				if err := replaceWithLink(group.Kept, dup, mode); err != nil {
+					return result, err
+				}
+				result.Linked++
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// dedupeFile is a file found by DeduplicateByHash
+type dedupeFile struct {
+	path string
+	info fs.FileInfo
+}
+
+// newDuplicateGroup picks the copy to keep among files with the same
+// content, newest first and then by path, and lists the others. Copies that
+// are already hard links to the kept one are left out; ok is false if no
+// others remain.
+func newDuplicateGroup(sum string, size int64, files []dedupeFile) (DuplicateGroup, bool) {
+	sort.Slice(files, func(i, j int) bool {
+		ti, tj := files[i].info.ModTime(), files[j].info.ModTime()
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return files[i].path < files[j].path
+	})
+
+	group := DuplicateGroup{SHA256: sum, Size: size, Kept: files[0].path}
+	for _, f := range files[1:] {
+		if !os.SameFile(files[0].info, f.info) {
+			group.Duplicates = append(group.Duplicates, f.path)
+		}
+	}
+	sort.Strings(group.Duplicates)
+	return group, len(group.Duplicates) > 0
+}
+
+// hashFile returns the hex SHA-256 of a file's content
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// replaceWithLink swaps dup for a link to kept. The link is created next to
+// dup and renamed over it, so dup is never missing if linking fails.
+func replaceWithLink(kept, dup, mode string) error {
+	tmp := dup + ".dedupe-tmp"
+	os.Remove(tmp)
+
+	var err error
+	if mode == DedupeHardlink {
+		err = os.Link(kept, tmp)
+	} else {
+		var target string
+		if target, err = filepath.Rel(filepath.Dir(dup), kept); err == nil {
+			err = os.Symlink(target, tmp)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to link %s to %s: %w", dup, kept, err)
+	}
+
+	if err := os.Rename(tmp, dup); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", dup, err)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package storage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeDedupeTree creates three copies of one file, one other file and an
+// empty one, the copy in b/ being the newest
+func writeDedupeTree(t *testing.T) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "output")
+	files := map[string]string{
+		"a/jquery.js":   "jquery",
+		"b/jquery.js":   "jquery",
+		"c/jq.min.js":   "jquery",
+		"a/app.js":      "app",
+		"a/empty.js":    "",
+		"b/other.empty": "",
+	}
+	old := time.Now().Add(-time.Hour)
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if name != "b/jquery.js" {
+			os.Chtimes(path, old, old)
+		}
+	}
+	return dir
+}
+
+func TestDeduplicateByHash_Report(t *testing.T) {
+	dir := writeDedupeTree(t)
+
+	res, err := DeduplicateByHash(dir, DedupeReport)
+	if err != nil {
+		t.Fatalf("DeduplicateByHash() error = %v", err)
+	}
+	if len(res.Groups) != 1 || res.Files != 2 || res.SavedBytes != 12 || res.Linked != 0 {
+		t.Fatalf("result = %+v, want 1 group of 2 duplicates (12 bytes), none linked", res)
+	}
+	g := res.Groups[0]
+	if g.Kept != filepath.Join(dir, "b/jquery.js") {
+		t.Errorf("Kept = %s, want the newest copy b/jquery.js", g.Kept)
+	}
+	want := []string{filepath.Join(dir, "a/jquery.js"), filepath.Join(dir, "c/jq.min.js")}
+	if len(g.Duplicates) != 2 || g.Duplicates[0] != want[0] || g.Duplicates[1] != want[1] {
+		t.Errorf("Duplicates = %v, want %v", g.Duplicates, want)
+	}
+
+	// Report mode leaves every file alone
+	for _, path := range want {
+		if info, err := os.Lstat(path); err != nil || !info.Mode().IsRegular() {
+			t.Errorf("%s changed in report mode", path)
+		}
+	}
+}
+
+func TestDeduplicateByHash_Link(t *testing.T) {
+	for _, mode := range []string{DedupeHardlink, DedupeSymlink} {
+		t.Run(mode, func(t *testing.T) {
+			dir := writeDedupeTree(t)
+			kept := filepath.Join(dir, "b/jquery.js")
+
+			res, err := DeduplicateByHash(dir, mode)
+			if err != nil {
+				t.Fatalf("DeduplicateByHash() error = %v", err)
+			}
+			if res.Linked != 2 || res.SavedBytes != 12 {
+				t.Errorf("result = %+v, want 2 linked, 12 bytes saved", res)
+			}
+
+			keptInfo, _ := os.Stat(kept)
+			for _, name := range []string{"a/jquery.js", "c/jq.min.js"} {
+				path := filepath.Join(dir, name)
+				data, err := os.ReadFile(path)
+				if err != nil || string(data) != "jquery" {
+					t.Errorf("%s = %q, %v after linking", name, data, err)
+				}
+				info, _ := os.Lstat(path)
+				if mode == DedupeSymlink {
+					target, _ := os.Readlink(path)
+					if info.Mode()&os.ModeSymlink == 0 || filepath.IsAbs(target) {
+						t.Errorf("%s is not a relative symlink (target %q)", name, target)
+					}
+				} else if !os.SameFile(keptInfo, info) {
+					t.Errorf("%s is not a hard link to %s", name, kept)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, "a/app.js")); err != nil {
+				t.Errorf("unique file touched: %v", err)
+			}
+
+			// A second run finds nothing left to do
+			res, err = DeduplicateByHash(dir, mode)
+			if err != nil || res.Files != 0 {
+				t.Errorf("second run = %+v, %v, want no duplicates", res, err)
+			}
+		})
+	}
+}
+
+func TestDeduplicateByHash_SymlinksArchived(t *testing.T) {
+	dir := writeDedupeTree(t)
+	if _, err := DeduplicateByHash(dir, DedupeSymlink); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, format := range []string{ArchiveTarGz, ArchiveZip} {
+		t.Run(format, func(t *testing.T) {
+			archiver := NewArchiver()
+			archiver.SetTempDir(t.TempDir())
+			dest := filepath.Join(t.TempDir(), "output."+format)
+			if err := archiver.Create(format, dir, dest); err != nil {
+				t.Fatalf("Create() error = %v", err)
+			}
+
+			entries := readArchive(t, format, dest)
+			link, ok := entries["output/a/jquery.js"]
+			if !ok || !link.symlink {
+				t.Fatalf("output/a/jquery.js archived as %+v, want a symlink", link)
+			}
+			resolved := path.Join("output/a", link.data)
+			if target := entries[resolved]; target.symlink || target.data != "jquery" {
+				t.Errorf("symlink points at %s = %+v, want the kept copy", resolved, target)
+			}
+		})
+	}
+}
+
+// archiveEntry is a file of an archive: its content, or its link target
+type archiveEntry struct {
+	data    string
+	symlink bool
+}
+
+// readArchive lists the files and symlinks of a tar.gz or zip archive by name
+func readArchive(t *testing.T, format, archivePath string) map[string]archiveEntry {
+	t.Helper()
+	entries := make(map[string]archiveEntry)
+
+	if format == ArchiveZip {
+		reader, err := zip.OpenReader(archivePath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		for _, f := range reader.File {
+			if f.Mode().IsDir() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(rc)
+			rc.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries[f.Name] = archiveEntry{data: string(data), symlink: f.Mode()&os.ModeSymlink != 0}
+		}
+		return entries
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch header.Typeflag {
+		case tar.TypeSymlink:
+			entries[header.Name] = archiveEntry{data: header.Linkname, symlink: true}
+		case tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			entries[header.Name] = archiveEntry{data: string(data)}
+		}
+	}
+}
+
+func TestParseDedupeMode(t *testing.T) {
+	for _, s := range []string{"", "report", "Hardlink", "symlink"} {
+		if _, err := ParseDedupeMode(s); err != nil {
+			t.Errorf("ParseDedupeMode(%q) error = %v", s, err)
+		}
+	}
+	if _, err := ParseDedupeMode("copy"); err == nil {
+		t.Error("ParseDedupeMode(copy) expected error")
+	}
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync/atomic"
+)
+
+// DiscardStorage reads and drops every file, counting only how many bytes
+// arrived. It is used to measure download throughput without disk I/O.
+type DiscardStorage struct {
+	baseDir string
+	files   atomic.Int64
+	bytes   atomic.Int64
+}
+
+// NewDiscardStorage creates a new DiscardStorage instance
+func NewDiscardStorage(baseDir string) *DiscardStorage {
+	return &DiscardStorage{baseDir: baseDir}
+}
+
+// Init is a no-op; nothing is written
+func (ds *DiscardStorage) Init() error {
+	return nil
+}
+
+// SaveFileFromReader drains the reader and returns the path the file would have had
+func (ds *DiscardStorage) SaveFileFromReader(host, urlPath, filename string, reader io.Reader) (string, int64, error) {
+	n, err := io.Copy(io.Discard, reader)
+	ds.bytes.Add(n)
+	if err != nil {
+		return "", n, fmt.Errorf("failed to read file: %w", err)
+	}
+	ds.files.Add(1)
+	return filepath.Join(ds.baseDir, sanitizePathComponent(host), filename), n, nil
+}
+
+// GetBaseDir returns the base directory
+func (ds *DiscardStorage) GetBaseDir() string {
+	return ds.baseDir
+}
+
+// Files returns how many files were read completely
+func (ds *DiscardStorage) Files() int64 {
+	return ds.files.Load()
+}
+
+// Bytes returns how many bytes were read, including from failed transfers
+func (ds *DiscardStorage) Bytes() int64 {
+	return ds.bytes.Load()
+}
@@ -1,6 +1,9 @@
 package storage
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -8,27 +11,64 @@ import (
 	"sync"
 )
 
+// ErrOutsideBaseDir is returned when a symlink below the base directory
+// would redirect a saved file outside it
+var ErrOutsideBaseDir = errors.New("save directory escapes base directory")
+
 // FileStorage handles file system operations
 type FileStorage struct {
 	baseDir   string
 	strategy  StorageStrategy
 	fileLocks map[string]*sync.Mutex
 	mu        sync.Mutex
+	collision CollisionFormat   // How files are renamed when their name is taken
+	hostDirs  map[string]string // Base directory per host, replacing baseDir for those hosts
 }
 
 // NewFileStorage creates a new FileStorage instance with a storage strategy
 func NewFileStorage(baseDir string, mode string) *FileStorage {
+	return NewFileStorageWithStrategy(baseDir, NewStrategy(mode))
+}
+
+// NewFileStorageWithStrategy creates a new FileStorage instance with an already built strategy
+func NewFileStorageWithStrategy(baseDir string, strategy StorageStrategy) *FileStorage {
 	return &FileStorage{
 		baseDir:   baseDir,
-		strategy:  NewStrategy(mode),
+		strategy:  strategy,
 		fileLocks: make(map[string]*sync.Mutex),
 	}
 }
 
+// SetCollisionFormat sets how files are named when their name is already taken
+func (fs *FileStorage) SetCollisionFormat(f CollisionFormat) {
+	fs.collision = f
+}
+
+// SetHostDirs saves files from the given hosts (subdomains included) under
+// their own base directory; the storage strategy still decides the layout
+// below it. Other hosts keep using the default base directory.
+func (fs *FileStorage) SetHostDirs(dirs map[string]string) {
+	fs.hostDirs = dirs
+}
+
+// baseDirFor returns the base directory files from host are saved under
+func (fs *FileStorage) baseDirFor(host string) string {
+	if dir, ok := hostDir(fs.hostDirs, host); ok {
+		return dir
+	}
+	return fs.baseDir
+}
+
 // SaveFile saves data to a file using the configured storage strategy
 func (fs *FileStorage) SaveFile(host, urlPath, filename string, data []byte) (string, error) {
 	// Use strategy to determine directory and filename
-	dir, finalFilename := fs.strategy.GeneratePath(fs.baseDir, host, urlPath, filename)
+	dir, finalFilename := fs.strategy.GeneratePath(fs.baseDirFor(host), host, urlPath, filename)
+	if err := fs.checkDir(host, dir); err != nil {
+		return "", err
+	}
+	if err := fs.markDated(host, dir); err != nil {
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
 
 	// Ensure directory exists
 	if err := fs.ensureDir(dir); err != nil {
@@ -65,10 +105,23 @@ func (fs *FileStorage) SaveFile(host, urlPath, filename string, data []byte) (st
 	return fullPath, nil
 }
 
+// PlanPath returns where a file would be saved, without creating anything.
+// An existing file there would make the real save pick a collision name.
+func (fs *FileStorage) PlanPath(host, urlPath, filename string) string {
+	dir, finalFilename := fs.strategy.GeneratePath(fs.baseDirFor(host), host, urlPath, filename)
+	return filepath.Join(dir, finalFilename)
+}
+
 // SaveFileFromReader saves data from an io.Reader to a file using the configured storage strategy
 func (fs *FileStorage) SaveFileFromReader(host, urlPath, filename string, reader io.Reader) (string, int64, error) {
 	// Use strategy to determine directory and filename
-	dir, finalFilename := fs.strategy.GeneratePath(fs.baseDir, host, urlPath, filename)
+	dir, finalFilename := fs.strategy.GeneratePath(fs.baseDirFor(host), host, urlPath, filename)
+	if err := fs.checkDir(host, dir); err != nil {
+		return "", 0, err
+	}
+	if err := fs.markDated(host, dir); err != nil {
+		return "", 0, fmt.Errorf("failed to create directory: %w", err)
+	}
 
 	// Ensure directory exists
 	if err := fs.ensureDir(dir); err != nil {
@@ -107,6 +160,9 @@ func (fs *FileStorage) SaveFileFromReader(host, urlPath, filename string, reader
 	// Copy from reader to file
 	bytesWritten, err := io.Copy(file, reader)
 	if err != nil {
+		// Don't leave partial files behind
+		file.Close()
+		os.Remove(fullPath)
 		return "", bytesWritten, fmt.Errorf("failed to write file: %w", err)
 	}
 
@@ -115,14 +171,13 @@ func (fs *FileStorage) SaveFileFromReader(host, urlPath, filename string, reader
 
 // saveFileFromReaderWithUniqueName creates a unique filename if collision occurs
 func (fs *FileStorage) saveFileFromReaderWithUniqueName(dir, originalName, existingPath string, reader io.Reader) (string, int64, error) {
-	// Extract extension
-	ext := filepath.Ext(originalName)
-	nameWithoutExt := originalName[:len(originalName)-len(ext)]
+	// The name depends on the content: save it first, then move it in place
+	if fs.collision.usesHash() {
+		return fs.saveHashNamed(dir, originalName, reader)
+	}
 
-	// Try up to 1000 variations
-	for i := 1; i <= 1000; i++ {
-		newName := fmt.Sprintf("%s_%d%s", nameWithoutExt, i, ext)
-		newPath := filepath.Join(dir, newName)
+	for i := 1; i <= fs.collision.attempts(); i++ {
+		newPath := filepath.Join(dir, fs.collision.name(originalName, i, ""))
 
 		// Check if this variation exists
 		if _, err := os.Stat(newPath); os.IsNotExist(err) {
@@ -136,25 +191,79 @@ func (fs *FileStorage) saveFileFromReaderWithUniqueName(dir, originalName, exist
 			// Copy from reader to file
 			bytesWritten, err := io.Copy(file, reader)
 			if err != nil {
+				// Don't leave partial files behind
+				file.Close()
+				os.Remove(newPath)
 				return "", bytesWritten, fmt.Errorf("failed to write file: %w", err)
 			}
 			return newPath, bytesWritten, nil
 		}
 	}
 
-	return "", 0, fmt.Errorf("failed to create unique filename after 1000 attempts")
+	return "", 0, fmt.Errorf("failed to create unique filename after %d attempts", fs.collision.attempts())
+}
+
+// saveHashNamed saves reader to a temporary file in dir, then renames it
+// after its content hash
+func (fs *FileStorage) saveHashNamed(dir, originalName string, reader io.Reader) (string, int64, error) {
+	tmp, err := os.CreateTemp(dir, ".downurl-*.tmp")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	h := sha256.New()
+	bytesWritten, err := io.Copy(io.MultiWriter(tmp, h), reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", bytesWritten, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil))
+	newPath, taken, err := fs.freeHashName(dir, originalName, sum)
+	if err != nil || taken {
+		os.Remove(tmp.Name())
+		return newPath, bytesWritten, err
+	}
+	if err := os.Rename(tmp.Name(), newPath); err != nil {
+		os.Remove(tmp.Name())
+		return "", bytesWritten, fmt.Errorf("failed to write file: %w", err)
+	}
+	return newPath, bytesWritten, nil
+}
+
+// freeHashName returns the first unused name for content with hash sum.
+// With no counter in the format, an existing file of that name has the
+// same content: its path is returned with taken set.
+func (fs *FileStorage) freeHashName(dir, originalName, sum string) (path string, taken bool, err error) {
+	for i := 1; i <= fs.collision.attempts(); i++ {
+		newPath := filepath.Join(dir, fs.collision.name(originalName, i, sum))
+		if _, err := os.Stat(newPath); os.IsNotExist(err) {
+			return newPath, false, nil
+		}
+		if fs.collision.attempts() == 1 {
+			return newPath, true, nil
+		}
+	}
+	return "", false, fmt.Errorf("failed to create unique filename after %d attempts", fs.collision.attempts())
 }
 
 // saveFileWithUniqueName creates a unique filename if collision occurs
 func (fs *FileStorage) saveFileWithUniqueName(dir, originalName, existingPath string, data []byte) (string, error) {
-	// Extract extension
-	ext := filepath.Ext(originalName)
-	nameWithoutExt := originalName[:len(originalName)-len(ext)]
+	if fs.collision.usesHash() {
+		newPath, taken, err := fs.freeHashName(dir, originalName, contentHash(data))
+		if err != nil || taken {
+			return newPath, err
+		}
+		if err := os.WriteFile(newPath, data, 0644); err != nil {
+			return "", fmt.Errorf("failed to write file: %w", err)
+		}
+		return newPath, nil
+	}
 
-	// Try up to 1000 variations
-	for i := 1; i <= 1000; i++ {
-		newName := fmt.Sprintf("%s_%d%s", nameWithoutExt, i, ext)
-		newPath := filepath.Join(dir, newName)
+	for i := 1; i <= fs.collision.attempts(); i++ {
+		newPath := filepath.Join(dir, fs.collision.name(originalName, i, ""))
 
 		// Check if this variation exists
 		if _, err := os.Stat(newPath); os.IsNotExist(err) {
@@ -166,7 +275,29 @@ func (fs *FileStorage) saveFileWithUniqueName(dir, originalName, existingPath st
 		}
 	}
 
-	return "", fmt.Errorf("failed to create unique filename after 1000 attempts")
+	return "", fmt.Errorf("failed to create unique filename after %d attempts", fs.collision.attempts())
+}
+
+// checkDir refuses a save directory that a symlink below host's base
+// directory (a symlinked host or path directory) redirects outside it
+func (fs *FileStorage) checkDir(host, dir string) error {
+	ok, err := resolvesWithin(fs.baseDirFor(host), dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve directory: %w", err)
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOutsideBaseDir, dir)
+	}
+	return nil
+}
+
+// markDated creates and marks the dated directory of a dated-mode save, so
+// --retain may prune it later
+func (fs *FileStorage) markDated(host, dir string) error {
+	if _, ok := fs.strategy.(*DatedMode); !ok {
+		return nil
+	}
+	return markDatedDir(fs.baseDirFor(host), dir)
 }
 
 // ensureDir creates a directory if it doesn't exist
@@ -182,7 +313,15 @@ func (fs *FileStorage) GetBaseDir() string {
 	return fs.baseDir
 }
 
-// Init ensures the base directory exists
+// Init ensures the base directory and any per-host directories exist
 func (fs *FileStorage) Init() error {
-	return fs.ensureDir(fs.baseDir)
+	if err := fs.ensureDir(fs.baseDir); err != nil {
+		return err
+	}
+	for _, dir := range fs.hostDirs {
+		if err := fs.ensureDir(dir); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -3,6 +3,7 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -143,3 +144,115 @@ func TestFileStorage_SaveFile_NoExtension(t *testing.T) {
 		t.Errorf("Second file path = %s, want %s", path2, expectedPath2)
 	}
 }
+
+func TestFileStorage_SaveFile_FlatHostPrefix(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs := NewFileStorageWithStrategy(tmpDir, &FlatMode{HostPrefix: true})
+
+	path1, err := fs.SaveFile("a.example.com", "/static/app.js", "app.js", []byte("a"))
+	if err != nil {
+		t.Fatalf("SaveFile() first call error = %v", err)
+	}
+	path2, err := fs.SaveFile("b.example.com", "/static/app.js", "app.js", []byte("b"))
+	if err != nil {
+		t.Fatalf("SaveFile() second call error = %v", err)
+	}
+
+	// Same-named files from different hosts share the directory without a counter suffix
+	if want := filepath.Join(tmpDir, "a.example.com_app.js"); path1 != want {
+		t.Errorf("first path = %s, want %s", path1, want)
+	}
+	if want := filepath.Join(tmpDir, "b.example.com_app.js"); path2 != want {
+		t.Errorf("second path = %s, want %s", path2, want)
+	}
+}
+
+func TestFileStorage_CollisionFormat(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs := NewFileStorage(tmpDir, "flat")
+	f, err := ParseCollisionFormat("{name}-{n}{ext}")
+	if err != nil {
+		t.Fatalf("ParseCollisionFormat() error = %v", err)
+	}
+	fs.SetCollisionFormat(f)
+
+	if _, err := fs.SaveFile("example.com", "/test.js", "test.js", []byte("one")); err != nil {
+		t.Fatalf("SaveFile() first call error = %v", err)
+	}
+	path2, err := fs.SaveFile("example.com", "/test.js", "test.js", []byte("two"))
+	if err != nil {
+		t.Fatalf("SaveFile() second call error = %v", err)
+	}
+	path3, _, err := fs.SaveFileFromReader("example.com", "/test.js", "test.js", strings.NewReader("three"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+
+	if want := filepath.Join(tmpDir, "test-1.js"); path2 != want {
+		t.Errorf("second path = %s, want %s", path2, want)
+	}
+	if want := filepath.Join(tmpDir, "test-2.js"); path3 != want {
+		t.Errorf("third path = %s, want %s", path3, want)
+	}
+}
+
+func TestFileStorage_CollisionFormatHash(t *testing.T) {
+	tmpDir := t.TempDir()
+	fs := NewFileStorage(tmpDir, "flat")
+	f, err := ParseCollisionFormat("{name}.{hash8}{ext}")
+	if err != nil {
+		t.Fatalf("ParseCollisionFormat() error = %v", err)
+	}
+	fs.SetCollisionFormat(f)
+
+	if _, err := fs.SaveFile("example.com", "/test.js", "test.js", []byte("one")); err != nil {
+		t.Fatalf("SaveFile() first call error = %v", err)
+	}
+	path2, n, err := fs.SaveFileFromReader("example.com", "/test.js", "test.js", strings.NewReader("two"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+	if n != 3 {
+		t.Errorf("SaveFileFromReader() bytes = %d, want 3", n)
+	}
+	if want := filepath.Join(tmpDir, "test."+contentHash([]byte("two"))[:8]+".js"); path2 != want {
+		t.Errorf("second path = %s, want %s", path2, want)
+	}
+	if content, _ := os.ReadFile(path2); string(content) != "two" {
+		t.Errorf("second file content = %q, want %q", content, "two")
+	}
+
+	// The same content again lands on the same name
+	path3, err := fs.SaveFile("example.com", "/test.js", "test.js", []byte("two"))
+	if err != nil {
+		t.Fatalf("SaveFile() third call error = %v", err)
+	}
+	if path3 != path2 {
+		t.Errorf("third path = %s, want %s", path3, path2)
+	}
+
+	entries, _ := os.ReadDir(tmpDir)
+	if len(entries) != 2 {
+		t.Errorf("got %d files, want 2 (no temp files left behind)", len(entries))
+	}
+}
+
+func TestParseCollisionFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		wantErr bool
+	}{
+		{"", false},
+		{"{name}-{n}{ext}", false},
+		{"{name}.{hash}{ext}", false},
+		{"{name}{ext}", true},
+		{"{name}_{count}{ext}", true},
+		{"dir/{name}_{n}{ext}", true},
+	}
+	for _, tt := range tests {
+		_, err := ParseCollisionFormat(tt.format)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseCollisionFormat(%q) error = %v, wantErr %v", tt.format, err, tt.wantErr)
+		}
+	}
+}
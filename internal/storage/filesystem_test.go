@@ -1,8 +1,10 @@
 package storage
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -44,6 +46,34 @@ func TestFileStorage_SaveFile(t *testing.T) {
 	}
 }
 
+func TestFileStorage_SymlinkedDirStaysInside(t *testing.T) {
+	dir := t.TempDir()
+	base, outside := filepath.Join(dir, "output"), filepath.Join(dir, "outside")
+	os.MkdirAll(base, 0755)
+	os.MkdirAll(outside, 0755)
+	if err := os.Symlink(outside, filepath.Join(base, "example.com")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	fs := NewFileStorage(base, "host")
+	if _, err := fs.SaveFile("example.com", "/app.js", "app.js", []byte("x")); !errors.Is(err, ErrOutsideBaseDir) {
+		t.Errorf("SaveFile() error = %v, want %v", err, ErrOutsideBaseDir)
+	}
+	if _, _, err := fs.SaveFileFromReader("example.com", "/app.js", "app.js", strings.NewReader("x")); !errors.Is(err, ErrOutsideBaseDir) {
+		t.Errorf("SaveFileFromReader() error = %v, want %v", err, ErrOutsideBaseDir)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("files written through the symlink: %v", entries)
+	}
+
+	// A symlinked base directory is resolved too, so saving into it still works
+	linked := filepath.Join(dir, "linked")
+	os.Symlink(base, linked)
+	if _, err := NewFileStorage(linked, "host").SaveFile("other.com", "/app.js", "app.js", []byte("x")); err != nil {
+		t.Errorf("SaveFile() under a symlinked base error = %v", err)
+	}
+}
+
 func TestFileStorage_Init(t *testing.T) {
 	tmpDir := t.TempDir()
 	baseDir := filepath.Join(tmpDir, "output")
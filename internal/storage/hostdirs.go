@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseHostDirs parses a --host-output value like "client-a.com:/data/a,client-b.com:/data/b".
+// The host ends at the first ":", so directories may contain one (C:\data).
+// Hosts are matched case-insensitively, subdomains included.
+func ParseHostDirs(s string) (map[string]string, error) {
+	dirs := make(map[string]string)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		i := strings.Index(part, ":")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid host output %q (expected host:dir)", part)
+		}
+		host := strings.ToLower(strings.TrimSpace(part[:i]))
+		dir := strings.TrimSpace(part[i+1:])
+		if dir == "" {
+			return nil, fmt.Errorf("missing directory in %q", part)
+		}
+		dirs[host] = dir
+	}
+	return dirs, nil
+}
+
+// hostDir returns the directory configured for host (or a parent domain of it)
+func hostDir(dirs map[string]string, host string) (string, bool) {
+	if len(dirs) == 0 {
+		return "", false
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	for {
+		if dir, ok := dirs[host]; ok {
+			return dir, true
+		}
+		i := strings.Index(host, ".")
+		if i == -1 {
+			return "", false
+		}
+		host = host[i+1:]
+	}
+}
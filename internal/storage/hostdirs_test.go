@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseHostDirs(t *testing.T) {
+	dirs, err := ParseHostDirs("Client-A.com:/data/a, client-b.com:C:\\data\\b,")
+	if err != nil {
+		t.Fatalf("ParseHostDirs() error = %v", err)
+	}
+	if dirs["client-a.com"] != "/data/a" {
+		t.Errorf("dirs[client-a.com] = %q, want /data/a", dirs["client-a.com"])
+	}
+	if dirs["client-b.com"] != "C:\\data\\b" {
+		t.Errorf("dirs[client-b.com] = %q, want C:\\data\\b", dirs["client-b.com"])
+	}
+
+	for _, bad := range []string{"client-a.com", ":/data/a", "client-a.com:"} {
+		if _, err := ParseHostDirs(bad); err == nil {
+			t.Errorf("ParseHostDirs(%q) expected error", bad)
+		}
+	}
+}
+
+func TestFileStorage_HostDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	baseDir := filepath.Join(tmpDir, "output")
+	dirA := filepath.Join(tmpDir, "a")
+	dirB := filepath.Join(tmpDir, "b")
+
+	fs := NewFileStorage(baseDir, "path")
+	fs.SetHostDirs(map[string]string{"client-a.com": dirA, "client-b.com": dirB})
+	if err := fs.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	if !dirExists(dirA) || !dirExists(dirB) {
+		t.Errorf("Init() did not create the per-host directories")
+	}
+
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"client-a.com", filepath.Join(dirA, "client-a.com", "js", "app.js")},
+		{"cdn.client-a.com", filepath.Join(dirA, "cdn.client-a.com", "js", "app.js")},
+		{"CLIENT-B.com:8443", filepath.Join(dirB, "CLIENT-B.com:8443", "js", "app.js")},
+		{"other.com", filepath.Join(baseDir, "other.com", "js", "app.js")},
+	}
+	for _, tt := range tests {
+		path, err := fs.SaveFile(tt.host, "/js/app.js", "app.js", []byte("x"))
+		if err != nil {
+			t.Fatalf("SaveFile(%s) error = %v", tt.host, err)
+		}
+		if path != tt.want {
+			t.Errorf("SaveFile(%s) = %s, want %s", tt.host, path, tt.want)
+		}
+	}
+}
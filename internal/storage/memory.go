@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// InMemoryStorage keeps saved files in memory, laid out with the same
+// storage strategy as FileStorage but without touching disk
+type InMemoryStorage struct {
+	baseDir   string
+	strategy  StorageStrategy
+	files     map[string][]byte
+	mu        sync.Mutex
+	collision CollisionFormat // How files are renamed when their name is taken
+}
+
+// NewInMemoryStorage creates a new InMemoryStorage instance with a storage strategy
+func NewInMemoryStorage(baseDir string, mode string) *InMemoryStorage {
+	return &InMemoryStorage{
+		baseDir:  baseDir,
+		strategy: NewStrategy(mode),
+		files:    make(map[string][]byte),
+	}
+}
+
+// SetCollisionFormat sets how files are named when their name is already taken
+func (ms *InMemoryStorage) SetCollisionFormat(f CollisionFormat) {
+	ms.collision = f
+}
+
+// Init is a no-op; there is nothing to prepare
+func (ms *InMemoryStorage) Init() error {
+	return nil
+}
+
+// SaveFileFromReader reads the content into memory under the path the strategy generates
+func (ms *InMemoryStorage) SaveFileFromReader(host, urlPath, filename string, reader io.Reader) (string, int64, error) {
+	dir, finalFilename := ms.strategy.GeneratePath(ms.baseDir, host, urlPath, filename)
+
+	// Read before locking so slow readers don't block other saves
+	var buf bytes.Buffer
+	bytesWritten, err := io.Copy(&buf, reader)
+	if err != nil {
+		return "", bytesWritten, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	fullPath := filepath.Join(dir, finalFilename)
+	if _, exists := ms.files[fullPath]; exists {
+		// Same collision naming as FileStorage
+		var sum string
+		if ms.collision.usesHash() {
+			sum = contentHash(buf.Bytes())
+		}
+		for i := 1; ; i++ {
+			fullPath = filepath.Join(dir, ms.collision.name(finalFilename, i, sum))
+			if _, exists := ms.files[fullPath]; !exists || ms.collision.attempts() == 1 {
+				// Without a counter, a taken name holds the same content
+				break
+			}
+		}
+	}
+
+	ms.files[fullPath] = buf.Bytes()
+	return fullPath, bytesWritten, nil
+}
+
+// GetBaseDir returns the base directory
+func (ms *InMemoryStorage) GetBaseDir() string {
+	return ms.baseDir
+}
+
+// ReadFile returns the content saved at path
+func (ms *InMemoryStorage) ReadFile(path string) ([]byte, bool) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	data, ok := ms.files[path]
+	return data, ok
+}
+
+// Paths returns all saved paths, sorted
+func (ms *InMemoryStorage) Paths() []string {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	paths := make([]string, 0, len(ms.files))
+	for path := range ms.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
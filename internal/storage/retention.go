@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// datedDirLayout is the directory name format used by DatedMode
+const datedDirLayout = "2006-01-02"
+
+// DatedMarker is the file FileStorage writes in each dated directory it
+// creates. PruneDated only removes directories holding it, never ones named
+// like a date by someone else.
+const DatedMarker = ".downurl-dated"
+
+// isDatedDirName checks if name is a well-formed YYYY-MM-DD directory name
+func isDatedDirName(name string) bool {
+	t, err := time.Parse(datedDirLayout, name)
+	return err == nil && t.Format(datedDirLayout) == name
+}
+
+// markDatedDir creates the dated directory dir leads into under baseDir, if
+// it does not exist yet, with a DatedMarker in it. A directory that already
+// exists is left unmarked: downurl did not create it.
+func markDatedDir(baseDir, dir string) error {
+	rel, err := filepath.Rel(baseDir, dir)
+	if err != nil {
+		return nil
+	}
+	name, _, _ := strings.Cut(filepath.ToSlash(rel), "/")
+	if !isDatedDirName(name) {
+		return nil
+	}
+	datedDir := filepath.Join(baseDir, name)
+	if _, err := os.Lstat(datedDir); !os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.MkdirAll(datedDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(datedDir, DatedMarker), nil, 0644)
+}
+
+// isMarkedDated checks if the directory holds a DatedMarker
+func isMarkedDated(dir string) bool {
+	info, err := os.Lstat(filepath.Join(dir, DatedMarker))
+	return err == nil && info.Mode().IsRegular()
+}
+
+// PruneDated removes all but the keep most recent dated-mode directories under baseDir.
+// Only real directories named YYYY-MM-DD that downurl created (holding a
+// DatedMarker) are considered; anything else is left alone.
+// It returns the paths of the removed directories.
+func PruneDated(baseDir string, keep int) ([]string, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("retention must keep at least 1 directory, got %d", keep)
+	}
+
+	entries, err := os.ReadDir(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read output directory: %w", err)
+	}
+
+	var dated []string
+	for _, entry := range entries {
+		// entry.IsDir is false for symlinks, so linked directories are never followed
+		if entry.IsDir() && isDatedDirName(entry.Name()) && isMarkedDated(filepath.Join(baseDir, entry.Name())) {
+			dated = append(dated, entry.Name())
+		}
+	}
+
+	if len(dated) <= keep {
+		return nil, nil
+	}
+
+	// YYYY-MM-DD sorts chronologically
+	sort.Strings(dated)
+
+	var removed []string
+	for _, name := range dated[:len(dated)-keep] {
+		path := filepath.Join(baseDir, name)
+		if err := os.RemoveAll(path); err != nil {
+			return removed, fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		removed = append(removed, path)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestPruneDated(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	dated := []string{"2024-01-03", "2024-01-01", "2024-02-10", "2023-12-31", "2024-01-02"}
+	for _, name := range dated {
+		if err := os.MkdirAll(filepath.Join(tmpDir, name, "example.com"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		os.WriteFile(filepath.Join(tmpDir, name, DatedMarker), nil, 0644)
+	}
+
+	// Things downurl didn't create as dated dirs must survive, even dated names without a marker
+	for _, name := range []string{"notes", "2024-13-01", "2024-1-5", "2019-06-01"} {
+		if err := os.Mkdir(filepath.Join(tmpDir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "2020-01-01"), []byte("file"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "output.tar.gz"), []byte("archive"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := PruneDated(tmpDir, 2)
+	if err != nil {
+		t.Fatalf("PruneDated() error = %v", err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("PruneDated() removed %v, want 3 directories", removed)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []string
+	for _, e := range entries {
+		remaining = append(remaining, e.Name())
+	}
+	sort.Strings(remaining)
+
+	want := []string{"2019-06-01", "2020-01-01", "2024-01-03", "2024-02-10", "2024-1-5", "2024-13-01", "notes", "output.tar.gz"}
+	if len(remaining) != len(want) {
+		t.Fatalf("remaining = %v, want %v", remaining, want)
+	}
+	for i := range want {
+		if remaining[i] != want[i] {
+			t.Errorf("remaining = %v, want %v", remaining, want)
+			break
+		}
+	}
+}
+
+func TestPruneDated_NothingToPrune(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(tmpDir, "2024-01-01"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(tmpDir, "2024-01-01", DatedMarker), nil, 0644)
+
+	removed, err := PruneDated(tmpDir, 3)
+	if err != nil {
+		t.Fatalf("PruneDated() error = %v", err)
+	}
+	if len(removed) != 0 {
+		t.Errorf("PruneDated() removed %v, want nothing", removed)
+	}
+
+	if _, err := PruneDated(tmpDir, 0); err == nil {
+		t.Error("PruneDated(0) expected error")
+	}
+}
+
+func TestFileStorage_MarksDatedDirs(t *testing.T) {
+	tmpDir := t.TempDir()
+	today := time.Now().Format(datedDirLayout)
+
+	fs := NewFileStorage(tmpDir, "dated")
+	if _, err := fs.SaveFile("example.com", "/app.js", "app.js", []byte("x")); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if !isMarkedDated(filepath.Join(tmpDir, today)) {
+		t.Errorf("dated directory %s created without %s", today, DatedMarker)
+	}
+
+	// A directory that was already there is not downurl's to prune
+	other := t.TempDir()
+	os.Mkdir(filepath.Join(other, today), 0755)
+	if _, err := NewFileStorage(other, "dated").SaveFile("example.com", "/app.js", "app.js", []byte("x")); err != nil {
+		t.Fatalf("SaveFile() error = %v", err)
+	}
+	if isMarkedDated(filepath.Join(other, today)) {
+		t.Errorf("existing directory %s was marked as created by downurl", today)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is returned when a name taken from downloaded content would be written outside the destination
+var ErrUnsafePath = errors.New("path escapes destination")
+
+// SafeJoin resolves a relative name under destDir, rejecting
+// absolute paths and ".." components that would escape it
+func SafeJoin(destDir, name string) (string, error) {
+	// Names use forward slashes, but be strict about backslashes too
+	name = strings.ReplaceAll(name, "\\", "/")
+	if name == "" || strings.HasPrefix(name, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+
+	target := filepath.Join(destDir, filepath.FromSlash(name))
+	if !isWithin(destDir, target) {
+		return "", fmt.Errorf("%w: %q", ErrUnsafePath, name)
+	}
+	return target, nil
+}
+
+// isWithin checks if path is destDir or lies beneath it
+func isWithin(destDir, path string) bool {
+	rel, err := filepath.Rel(filepath.Clean(destDir), filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	return rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)))
+}
+
+// evalExisting resolves the symlinks in path as far as it exists; the
+// missing rest is appended unresolved
+func evalExisting(path string) (string, error) {
+	var rest []string
+	for {
+		real, err := filepath.EvalSymlinks(path)
+		if err == nil {
+			return filepath.Join(append([]string{real}, rest...)...), nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return "", err
+		}
+		rest = append([]string{filepath.Base(path)}, rest...)
+		path = parent
+	}
+}
+
+// resolvesWithin checks if path lies beneath root once the symlinks in both
+// are resolved, so a symlinked subdirectory cannot lead outside root
+func resolvesWithin(root, path string) (bool, error) {
+	realRoot, err := evalExisting(root)
+	if err != nil {
+		return false, err
+	}
+	realPath, err := evalExisting(path)
+	if err != nil {
+		return false, err
+	}
+	return isWithin(realRoot, realPath), nil
+}
+
+// checkParents makes sure no symlink already on disk redirects target outside destDir
+func checkParents(destDir, target string) error {
+	ok, err := resolvesWithin(destDir, filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %q (via symlink)", ErrUnsafePath, target)
+	}
+	return nil
+}
+
+// WriteFileWithin writes r to name under destDir. The name may not escape
+// destDir, neither directly nor through a symlink already on disk. A file already at the target is
+// replaced, a symlink there is refused rather than followed. It returns
+// the path written.
+func WriteFileWithin(destDir, name string, r io.Reader) (string, error) {
+	target, err := SafeJoin(destDir, name)
+	if err != nil {
+		return "", err
+	}
+
+	if info, err := os.Lstat(target); err == nil {
+		if info.Mode()&os.ModeSymlink != 0 {
+			return "", fmt.Errorf("%w: %q (symlink)", ErrUnsafePath, name)
+		}
+		if err := os.Remove(target); err != nil {
+			return "", fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+	if err := writeWithin(destDir, target, r); err != nil {
+		return "", err
+	}
+	return target, nil
+}
+
+// writeWithin creates target once its parents are known to stay within destDir
+func writeWithin(destDir, target string, r io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := checkParents(destDir, target); err != nil {
+		return err
+	}
+
+	// O_EXCL refuses to follow a symlink planted at target in the meantime
+	out, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, r); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeJoin(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "dest")
+	if got, err := SafeJoin(dest, "output/app.js"); err != nil || got != filepath.Join(dest, "output", "app.js") {
+		t.Errorf("SafeJoin() = %q, %v", got, err)
+	}
+
+	for _, name := range []string{"", "../../evil.sh", "output/../../evil.sh", "output\\..\\..\\evil.sh", "/tmp/evil.sh"} {
+		if _, err := SafeJoin(dest, name); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("SafeJoin(%q) error = %v, want %v", name, err, ErrUnsafePath)
+		}
+	}
+}
+
+func TestWriteFileWithin_SymlinkedDir(t *testing.T) {
+	dir := t.TempDir()
+	outside := filepath.Join(dir, "outside")
+	os.MkdirAll(outside, 0755)
+
+	// A symlink planted beforehand must not be followed
+	dest := filepath.Join(dir, "dest")
+	os.MkdirAll(dest, 0755)
+	if err := os.Symlink(outside, filepath.Join(dest, "link")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	if _, err := WriteFileWithin(dest, "link/evil.sh", strings.NewReader("pwned")); !errors.Is(err, ErrUnsafePath) {
+		t.Fatalf("WriteFileWithin() error = %v, want %v", err, ErrUnsafePath)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "evil.sh")); err == nil {
+		t.Error("file was written through symlink outside destination")
+	}
+}
+
+func TestWriteFileWithin(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "dest")
+
+	target, err := WriteFileWithin(dest, "src/app.js", strings.NewReader("v1"))
+	if err != nil {
+		t.Fatalf("WriteFileWithin() error = %v", err)
+	}
+	// A second write replaces the file
+	if _, err := WriteFileWithin(dest, "src/app.js", strings.NewReader("v2")); err != nil {
+		t.Fatalf("WriteFileWithin() rewrite error = %v", err)
+	}
+	if data, _ := os.ReadFile(target); string(data) != "v2" {
+		t.Errorf("rewritten file = %q, want v2", data)
+	}
+
+	for _, name := range []string{"../evil.js", "src\\..\\..\\evil.js", "/etc/evil.js"} {
+		if _, err := WriteFileWithin(dest, name, strings.NewReader("pwned")); !errors.Is(err, ErrUnsafePath) {
+			t.Errorf("WriteFileWithin(%q) error = %v, want %v", name, err, ErrUnsafePath)
+		}
+	}
+
+	victim := filepath.Join(dir, "victim.js")
+	os.WriteFile(victim, []byte("untouched"), 0644)
+	if err := os.Symlink(victim, filepath.Join(dest, "link.js")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	if _, err := WriteFileWithin(dest, "link.js", strings.NewReader("pwned")); !errors.Is(err, ErrUnsafePath) {
+		t.Errorf("WriteFileWithin() through a symlink error = %v, want %v", err, ErrUnsafePath)
+	}
+	if data, _ := os.ReadFile(victim); string(data) != "untouched" {
+		t.Errorf("symlink target = %q, want it untouched", data)
+	}
+}
@@ -0,0 +1,255 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQLiteFile is the database SQLiteStorage writes in its base directory
+const SQLiteFile = "downurl.db"
+
+// sqliteChunkSize is the most content held in memory, and stored in one
+// row of the chunks table, while a download is saved
+const sqliteChunkSize = 1 << 20
+
+// ErrSQLiteUnavailable is returned by SQLiteStorage.Init in builds without
+// cgo, which the SQLite driver needs
+var ErrSQLiteUnavailable = errors.New("the sqlite storage backend needs a build with cgo (CGO_ENABLED=1)")
+
+// Rows are written with complete = 0 and marked complete once all their
+// chunks are in; Init drops whatever an interrupted run left incomplete
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS downloads (
+	id           INTEGER PRIMARY KEY AUTOINCREMENT,
+	url          TEXT NOT NULL DEFAULT '',
+	host         TEXT NOT NULL,
+	path         TEXT NOT NULL,
+	filename     TEXT NOT NULL,
+	size         INTEGER NOT NULL,
+	sha256       TEXT NOT NULL,
+	content_type TEXT NOT NULL DEFAULT '',
+	fetched_at   TEXT NOT NULL,
+	complete     INTEGER NOT NULL DEFAULT 0
+);
+CREATE TABLE IF NOT EXISTS chunks (
+	download_id INTEGER NOT NULL REFERENCES downloads(id),
+	seq         INTEGER NOT NULL,
+	data        BLOB NOT NULL,
+	PRIMARY KEY (download_id, seq)
+);
+CREATE INDEX IF NOT EXISTS downloads_url ON downloads(url);
+CREATE INDEX IF NOT EXISTS downloads_sha256 ON downloads(sha256);
+DELETE FROM chunks WHERE download_id IN (SELECT id FROM downloads WHERE complete = 0);
+DELETE FROM downloads WHERE complete = 0;`
+
+// Record is one row of a SQLiteStorage database
+type Record struct {
+	ID          int64
+	URL         string
+	Host        string
+	Path        string
+	Filename    string
+	Size        int64
+	SHA256      string
+	ContentType string
+	FetchedAt   time.Time
+	Content     []byte
+}
+
+// SQLiteStorage saves every download as a row of a single SQLite database
+// instead of a directory tree, with the content split into 1 MiB chunks so
+// no download is held in memory whole. The path it returns for a row is
+// "<dir>/downurl.db#<id>/<filename>", so reports and findings point at the
+// row and the file's extension still tells its type.
+type SQLiteStorage struct {
+	baseDir string
+	dbPath  string
+	db      *sql.DB
+}
+
+// NewSQLiteStorage creates a SQLiteStorage writing baseDir/downurl.db
+func NewSQLiteStorage(baseDir string) *SQLiteStorage {
+	return &SQLiteStorage{
+		baseDir: baseDir,
+		dbPath:  filepath.Join(baseDir, SQLiteFile),
+	}
+}
+
+// Init creates the base directory and opens (or creates) the database
+func (s *SQLiteStorage) Init() error {
+	if !SQLiteAvailable {
+		return ErrSQLiteUnavailable
+	}
+	if err := os.MkdirAll(s.baseDir, 0755); err != nil {
+		return fmt.Errorf("failed to create base directory: %w", err)
+	}
+	db, err := sql.Open("sqlite3", "file:"+s.dbPath+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	// Workers save concurrently; one connection serializes the writes
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return fmt.Errorf("failed to create database schema: %w", err)
+	}
+	s.db = db
+	return nil
+}
+
+// SaveFileFromReader stores the reader's content as a new row, a chunk at a
+// time, and returns the row's path. Nothing is kept if the reader fails.
+func (s *SQLiteStorage) SaveFileFromReader(host, urlPath, filename string, reader io.Reader) (string, int64, error) {
+	if s.db == nil {
+		return "", 0, fmt.Errorf("database not initialized")
+	}
+	res, err := s.db.Exec(
+		`INSERT INTO downloads (host, path, filename, size, sha256, fetched_at) VALUES (?, ?, ?, 0, '', ?)`,
+		host, urlPath, filename, time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to insert row: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get row id: %w", err)
+	}
+
+	h := sha256.New()
+	buf := make([]byte, sqliteChunkSize)
+	var size int64
+	for seq := 0; ; seq++ {
+		n, err := io.ReadFull(reader, buf)
+		if n > 0 {
+			h.Write(buf[:n])
+			size += int64(n)
+			if _, err := s.db.Exec(`INSERT INTO chunks (download_id, seq, data) VALUES (?, ?, ?)`, id, seq, buf[:n]); err != nil {
+				s.discard(id)
+				return "", size, fmt.Errorf("failed to insert chunk: %w", err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			s.discard(id)
+			return "", size, fmt.Errorf("failed to read file: %w", err)
+		}
+	}
+
+	if _, err := s.db.Exec(`UPDATE downloads SET size = ?, sha256 = ?, complete = 1 WHERE id = ?`, size, hex.EncodeToString(h.Sum(nil)), id); err != nil {
+		s.discard(id)
+		return "", size, fmt.Errorf("failed to update row %d: %w", id, err)
+	}
+	return s.RowPath(id, filename), size, nil
+}
+
+// discard removes a row whose content could not be saved
+func (s *SQLiteStorage) discard(id int64) {
+	s.db.Exec(`DELETE FROM chunks WHERE download_id = ?`, id)
+	s.db.Exec(`DELETE FROM downloads WHERE id = ?`, id)
+}
+
+// content reassembles the content of a complete row from its chunks
+func (s *SQLiteStorage) content(id int64, size int64) ([]byte, error) {
+	rows, err := s.db.Query(`SELECT data FROM chunks WHERE download_id = ? ORDER BY seq`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	content := make([]byte, 0, size)
+	for rows.Next() {
+		var chunk []byte
+		if err := rows.Scan(&chunk); err != nil {
+			return nil, err
+		}
+		content = append(content, chunk...)
+	}
+	return content, rows.Err()
+}
+
+// RecordMetadata fills in the URL and content type of the row at path
+func (s *SQLiteStorage) RecordMetadata(path, url, contentType string) error {
+	id, ok := s.RowID(path)
+	if !ok {
+		return fmt.Errorf("%q is not a row of %s", path, s.dbPath)
+	}
+	if _, err := s.db.Exec(`UPDATE downloads SET url = ?, content_type = ? WHERE id = ?`, url, contentType, id); err != nil {
+		return fmt.Errorf("failed to update row %d: %w", id, err)
+	}
+	return nil
+}
+
+// ReadFile returns the content of the row at path
+func (s *SQLiteStorage) ReadFile(path string) ([]byte, error) {
+	id, ok := s.RowID(path)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a row of %s", path, s.dbPath)
+	}
+	var size int64
+	if err := s.db.QueryRow(`SELECT size FROM downloads WHERE id = ? AND complete = 1`, id).Scan(&size); err != nil {
+		return nil, fmt.Errorf("failed to read row %d: %w", id, err)
+	}
+	content, err := s.content(id, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row %d: %w", id, err)
+	}
+	return content, nil
+}
+
+// Get returns the row with the given id
+func (s *SQLiteStorage) Get(id int64) (*Record, error) {
+	r := &Record{ID: id}
+	var fetchedAt string
+	err := s.db.QueryRow(
+		`SELECT url, host, path, filename, size, sha256, content_type, fetched_at FROM downloads WHERE id = ? AND complete = 1`, id,
+	).Scan(&r.URL, &r.Host, &r.Path, &r.Filename, &r.Size, &r.SHA256, &r.ContentType, &fetchedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read row %d: %w", id, err)
+	}
+	if r.Content, err = s.content(id, r.Size); err != nil {
+		return nil, fmt.Errorf("failed to read row %d: %w", id, err)
+	}
+	r.FetchedAt, _ = time.Parse(time.RFC3339, fetchedAt)
+	return r, nil
+}
+
+// RowPath returns the path SaveFileFromReader reports for row id
+func (s *SQLiteStorage) RowPath(id int64, filename string) string {
+	return s.dbPath + "#" + strconv.FormatInt(id, 10) + "/" + sanitizePathComponent(filename)
+}
+
+// RowID returns the row id of a path returned by SaveFileFromReader
+func (s *SQLiteStorage) RowID(path string) (int64, bool) {
+	rest, ok := strings.CutPrefix(path, s.dbPath+"#")
+	if !ok {
+		return 0, false
+	}
+	rest, _, _ = strings.Cut(rest, "/")
+	id, err := strconv.ParseInt(rest, 10, 64)
+	return id, err == nil
+}
+
+// GetBaseDir returns the base directory
+func (s *SQLiteStorage) GetBaseDir() string {
+	return s.baseDir
+}
+
+// Close closes the database, folding its write-ahead log into downurl.db.
+// Closing it again does nothing.
+func (s *SQLiteStorage) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	db := s.db
+	s.db = nil
+	return db.Close()
+}
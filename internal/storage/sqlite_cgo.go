@@ -0,0 +1,8 @@
+//go:build cgo
+
+package storage
+
+import _ "github.com/mattn/go-sqlite3"
+
+// SQLiteAvailable reports whether this build can open SQLite databases
+const SQLiteAvailable = true
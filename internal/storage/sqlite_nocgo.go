@@ -0,0 +1,7 @@
+//go:build !cgo
+
+package storage
+
+// SQLiteAvailable reports whether this build can open SQLite databases.
+// The driver needs cgo, so this build rejects --storage-backend sqlite.
+const SQLiteAvailable = false
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSQLiteStorage_SaveAndRead(t *testing.T) {
+	requireSQLite(t)
+	tmpDir := t.TempDir()
+	db := NewSQLiteStorage(tmpDir)
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer db.Close()
+
+	appPath, size, err := db.SaveFileFromReader("example.com", "/static/app.js", "app.js", strings.NewReader("console.log(1);"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+	if size != 15 {
+		t.Errorf("size = %d, want 15", size)
+	}
+	if err := db.RecordMetadata(appPath, "https://example.com/static/app.js", "application/javascript"); err != nil {
+		t.Fatalf("RecordMetadata() error = %v", err)
+	}
+	cssPath, _, err := db.SaveFileFromReader("cdn.example.com", "/site.css", "site.css", strings.NewReader("body{}"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+
+	if want := filepath.Join(tmpDir, SQLiteFile) + "#1/app.js"; appPath != want {
+		t.Errorf("path = %q, want %q", appPath, want)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, SQLiteFile)); err != nil {
+		t.Errorf("database not created: %v", err)
+	}
+
+	data, err := db.ReadFile(cssPath)
+	if err != nil || string(data) != "body{}" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+
+	id, ok := db.RowID(appPath)
+	if !ok {
+		t.Fatalf("RowID(%q) not found", appPath)
+	}
+	r, err := db.Get(id)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if r.URL != "https://example.com/static/app.js" || r.Host != "example.com" || r.Path != "/static/app.js" ||
+		r.ContentType != "application/javascript" || r.Size != 15 || string(r.Content) != "console.log(1);" {
+		t.Errorf("Get() = %+v", r)
+	}
+	if sum := sha256.Sum256([]byte("console.log(1);")); r.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("SHA256 = %q", r.SHA256)
+	}
+	if r.FetchedAt.IsZero() {
+		t.Error("FetchedAt not set")
+	}
+}
+
+func TestSQLiteStorage_ReopenKeepsRows(t *testing.T) {
+	requireSQLite(t)
+	tmpDir := t.TempDir()
+	db := NewSQLiteStorage(tmpDir)
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	path, _, err := db.SaveFileFromReader("example.com", "/a.js", "a.js", strings.NewReader("a"))
+	if err != nil {
+		t.Fatalf("SaveFileFromReader() error = %v", err)
+	}
+	db.Close()
+
+	db = NewSQLiteStorage(tmpDir)
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer db.Close()
+	if data, err := db.ReadFile(path); err != nil || string(data) != "a" {
+		t.Errorf("ReadFile() after reopening = %q, %v", data, err)
+	}
+	next, _, _ := db.SaveFileFromReader("example.com", "/b.js", "b.js", strings.NewReader("b"))
+	if id, _ := db.RowID(next); id != 2 {
+		t.Errorf("next row id = %d, want 2", id)
+	}
+}
+
+func TestSQLiteStorage_FailedReaderWritesNothing(t *testing.T) {
+	requireSQLite(t)
+	db := NewSQLiteStorage(t.TempDir())
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer db.Close()
+
+	reader := io.MultiReader(strings.NewReader("partial"), failingReader{})
+	if _, _, err := db.SaveFileFromReader("example.com", "/a.js", "a.js", reader); err == nil {
+		t.Fatal("SaveFileFromReader() expected error")
+	}
+	if _, err := db.Get(1); err == nil {
+		t.Error("a failed transfer should not leave a row")
+	}
+}
+
+func TestSQLiteStorage_LargeContentInChunks(t *testing.T) {
+	requireSQLite(t)
+	db := NewSQLiteStorage(t.TempDir())
+	if err := db.Init(); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+	defer db.Close()
+
+	content := strings.Repeat("0123456789abcdef", sqliteChunkSize/16*2+100)
+	path, size, err := db.SaveFileFromReader("example.com", "/big.js", "big.js", strings.NewReader(content))
+	if err != nil || size != int64(len(content)) {
+		t.Fatalf("SaveFileFromReader() = %d, %v; want %d bytes", size, err, len(content))
+	}
+	id, _ := db.RowID(path)
+	var chunks, largest int
+	db.db.QueryRow(`SELECT COUNT(*), MAX(LENGTH(data)) FROM chunks WHERE download_id = ?`, id).Scan(&chunks, &largest)
+	if chunks != 3 || largest != sqliteChunkSize {
+		t.Errorf("stored in %d chunks of at most %d bytes, want 3 of at most %d", chunks, largest, sqliteChunkSize)
+	}
+	if data, err := db.ReadFile(path); err != nil || string(data) != content {
+		t.Errorf("ReadFile() returned %d bytes, %v; want the %d saved", len(data), err, len(content))
+	}
+}
+
+func TestSQLiteStorage_Unavailable(t *testing.T) {
+	if SQLiteAvailable {
+		t.Skip("built with cgo")
+	}
+	if err := NewSQLiteStorage(t.TempDir()).Init(); !errors.Is(err, ErrSQLiteUnavailable) {
+		t.Errorf("Init() error = %v, want %v", err, ErrSQLiteUnavailable)
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	for in, want := range map[string]Backend{"": BackendFS, "fs": BackendFS, "sqlite": BackendSQLite} {
+		if got, err := ParseBackend(in); err != nil || got != want {
+			t.Errorf("ParseBackend(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseBackend("s3"); err == nil {
+		t.Error("ParseBackend(s3) expected error")
+	}
+}
+
+// requireSQLite skips tests needing the cgo SQLite driver in builds without it
+func requireSQLite(t *testing.T) {
+	t.Helper()
+	if !SQLiteAvailable {
+		t.Skip("sqlite backend needs cgo")
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
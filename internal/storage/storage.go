@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+)
+
+// Storage is where downloaded content is written (the storage backend).
+// FileStorage is the default; SQLiteStorage writes a single database and
+// InMemoryStorage keeps everything in memory.
+type Storage interface {
+	// Init prepares the storage for writes
+	Init() error
+
+	// SaveFileFromReader stores the reader's content and returns its path and size
+	SaveFileFromReader(host, urlPath, filename string, reader io.Reader) (string, int64, error)
+
+	// GetBaseDir returns the root all stored paths are under
+	GetBaseDir() string
+}
+
+// Planner is implemented by storages that can tell where a file would be
+// saved without writing anything (--dry-run)
+type Planner interface {
+	// PlanPath returns the path SaveFileFromReader would use, ignoring name collisions
+	PlanPath(host, urlPath, filename string) string
+}
+
+// MetadataRecorder is implemented by storages that keep the URL and content
+// type of a saved file next to its content
+type MetadataRecorder interface {
+	// RecordMetadata is called with a path SaveFileFromReader returned
+	RecordMetadata(path, url, contentType string) error
+}
+
+// Backend names a Storage implementation for --storage-backend
+type Backend string
+
+const (
+	BackendFS     Backend = "fs"     // FileStorage: one file per download
+	BackendSQLite Backend = "sqlite" // SQLiteStorage: one row per download in downurl.db
+)
+
+// ParseBackend parses a --storage-backend value ("" means fs)
+func ParseBackend(s string) (Backend, error) {
+	switch Backend(s) {
+	case "", BackendFS:
+		return BackendFS, nil
+	case BackendSQLite:
+		return BackendSQLite, nil
+	default:
+		return "", fmt.Errorf("unknown storage backend %q (valid: fs, sqlite)", s)
+	}
+}
+
+// Ensure implementations satisfy the interface
+var (
+	_ Storage          = (*FileStorage)(nil)
+	_ Storage          = (*SQLiteStorage)(nil)
+	_ Storage          = (*InMemoryStorage)(nil)
+	_ Storage          = (*DiscardStorage)(nil)
+	_ Planner          = (*FileStorage)(nil)
+	_ MetadataRecorder = (*SQLiteStorage)(nil)
+)
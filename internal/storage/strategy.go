@@ -1,6 +1,7 @@
 package storage
 
 import (
+	"fmt"
 	"path/filepath"
 	"strings"
 	"time"
@@ -36,6 +37,33 @@ func sanitizePathComponent(component string) string {
 	return component
 }
 
+// Host layouts for modes that mix files from several hosts in one directory
+const (
+	HostLayoutPrefix = "prefix" // Prefix filenames with the host (host_file.js)
+	HostLayoutDir    = "dir"    // Nest files under a host subdirectory (host/file.js)
+)
+
+// ParseHostLayout validates a host layout name, defaulting to HostLayoutPrefix
+func ParseHostLayout(layout string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(layout)) {
+	case "", HostLayoutPrefix:
+		return HostLayoutPrefix, nil
+	case HostLayoutDir:
+		return HostLayoutDir, nil
+	default:
+		return "", fmt.Errorf("invalid host layout: %s (use prefix or dir)", layout)
+	}
+}
+
+// placeByHost keeps files from different hosts apart within dir, using the given host layout
+func placeByHost(dir, host, filename, layout string) (string, string) {
+	if layout == HostLayoutDir {
+		return filepath.Join(dir, host), filename
+	}
+	// Prefix the filename with the host to avoid collisions
+	return dir, host + "_" + filename
+}
+
 // StorageStrategy defines how files should be organized in the filesystem
 type StorageStrategy interface {
 	// GeneratePath creates the full directory and filename path for a file
@@ -53,15 +81,21 @@ type StorageStrategy interface {
 
 // NewStrategy creates a storage strategy based on the mode name
 func NewStrategy(mode string) StorageStrategy {
+	return NewStrategyWithHostLayout(mode, HostLayoutPrefix)
+}
+
+// NewStrategyWithHostLayout creates a storage strategy, using hostLayout
+// to separate hosts in the type and dated modes
+func NewStrategyWithHostLayout(mode, hostLayout string) StorageStrategy {
 	switch strings.ToLower(mode) {
 	case "path":
 		return &PathMode{}
 	case "host":
 		return &HostMode{}
 	case "type":
-		return &TypeMode{}
+		return &TypeMode{HostLayout: hostLayout}
 	case "dated":
-		return &DatedMode{}
+		return &DatedMode{HostLayout: hostLayout}
 	case "flat":
 		fallthrough
 	default:
@@ -70,13 +104,22 @@ func NewStrategy(mode string) StorageStrategy {
 }
 
 // FlatMode stores all files in a single directory
-type FlatMode struct{}
+type FlatMode struct {
+	HostPrefix bool // Prefix filenames with the host so same-named files from different hosts don't collide
+}
 
 func (f *FlatMode) GeneratePath(baseDir, host, urlPath, filename string) (string, string) {
+	if f.HostPrefix {
+		// Sanitize host to prevent directory traversal
+		return placeByHost(baseDir, sanitizePathComponent(host), filename, HostLayoutPrefix)
+	}
 	return baseDir, filename
 }
 
 func (f *FlatMode) GetDescription() string {
+	if f.HostPrefix {
+		return "Flat mode: All files in a single directory, prefixed with their host"
+	}
 	return "Flat mode: All files in a single directory"
 }
 
@@ -142,7 +185,9 @@ func (h *HostMode) GetDescription() string {
 }
 
 // TypeMode organizes files by their extension/type
-type TypeMode struct{}
+type TypeMode struct {
+	HostLayout string // How hosts are kept apart; empty means HostLayoutPrefix
+}
 
 func (t *TypeMode) GeneratePath(baseDir, host, urlPath, filename string) (string, string) {
 	// Sanitize host to prevent directory traversal
@@ -163,10 +208,7 @@ func (t *TypeMode) GeneratePath(baseDir, host, urlPath, filename string) (string
 		ext = "unknown"
 	}
 
-	// Create a filename with host prefix to avoid collisions
-	prefixedFilename := host + "_" + filename
-
-	return filepath.Join(baseDir, ext), prefixedFilename
+	return placeByHost(filepath.Join(baseDir, ext), host, filename, t.HostLayout)
 }
 
 func (t *TypeMode) GetDescription() string {
@@ -174,19 +216,18 @@ func (t *TypeMode) GetDescription() string {
 }
 
 // DatedMode organizes files by download date
-type DatedMode struct{}
+type DatedMode struct {
+	HostLayout string // How hosts are kept apart; empty means HostLayoutPrefix
+}
 
 func (d *DatedMode) GeneratePath(baseDir, host, urlPath, filename string) (string, string) {
 	// Sanitize host to prevent directory traversal
 	host = sanitizePathComponent(host)
 
 	// Get current date in YYYY-MM-DD format
-	dateStr := time.Now().Format("2006-01-02")
-
-	// Create a filename with host prefix to avoid collisions
-	prefixedFilename := host + "_" + filename
+	dateStr := time.Now().Format(datedDirLayout)
 
-	return filepath.Join(baseDir, dateStr), prefixedFilename
+	return placeByHost(filepath.Join(baseDir, dateStr), host, filename, d.HostLayout)
 }
 
 func (d *DatedMode) GetDescription() string {
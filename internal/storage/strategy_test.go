@@ -3,6 +3,7 @@ package storage
 import (
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestPathMode_GeneratePath(t *testing.T) {
@@ -207,6 +208,28 @@ func TestFlatMode_GeneratePath(t *testing.T) {
 	}
 }
 
+func TestFlatMode_GeneratePath_HostPrefix(t *testing.T) {
+	mode := &FlatMode{HostPrefix: true}
+
+	tests := []struct {
+		host         string
+		expectedFile string
+	}{
+		{"example.com", "example.com_file.js"},
+		{"cdn.example.com:8443", "cdn.example.com:8443_file.js"},
+	}
+
+	for _, tt := range tests {
+		dir, file := mode.GeneratePath("/output", tt.host, "/js/file.js", "file.js")
+		if dir != "/output" {
+			t.Errorf("GeneratePath(%q) dir = %v, want /output", tt.host, dir)
+		}
+		if file != tt.expectedFile {
+			t.Errorf("GeneratePath(%q) file = %v, want %v", tt.host, file, tt.expectedFile)
+		}
+	}
+}
+
 func TestDatedMode_GeneratePath(t *testing.T) {
 	mode := &DatedMode{}
 	baseDir := "/output"
@@ -227,6 +250,85 @@ func TestDatedMode_GeneratePath(t *testing.T) {
 	}
 }
 
+func TestHostLayout_GeneratePath(t *testing.T) {
+	baseDir := "/output"
+	date := time.Now().Format("2006-01-02")
+
+	tests := []struct {
+		name         string
+		mode         string
+		layout       string
+		expectedDir  string
+		expectedFile string
+	}{
+		{
+			name:         "type prefix",
+			mode:         "type",
+			layout:       HostLayoutPrefix,
+			expectedDir:  filepath.Join("/output", "js"),
+			expectedFile: "cdn.example.com_jquery.min.js",
+		},
+		{
+			name:         "type dir",
+			mode:         "type",
+			layout:       HostLayoutDir,
+			expectedDir:  filepath.Join("/output", "js", "cdn.example.com"),
+			expectedFile: "jquery.min.js",
+		},
+		{
+			name:         "dated prefix",
+			mode:         "dated",
+			layout:       HostLayoutPrefix,
+			expectedDir:  filepath.Join("/output", date),
+			expectedFile: "cdn.example.com_jquery.min.js",
+		},
+		{
+			name:         "dated dir",
+			mode:         "dated",
+			layout:       HostLayoutDir,
+			expectedDir:  filepath.Join("/output", date, "cdn.example.com"),
+			expectedFile: "jquery.min.js",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := NewStrategyWithHostLayout(tt.mode, tt.layout)
+			dir, file := strategy.GeneratePath(baseDir, "cdn.example.com", "/lib/jquery.min.js", "jquery.min.js")
+			if dir != tt.expectedDir {
+				t.Errorf("GeneratePath() dir = %v, want %v", dir, tt.expectedDir)
+			}
+			if file != tt.expectedFile {
+				t.Errorf("GeneratePath() file = %v, want %v", file, tt.expectedFile)
+			}
+		})
+	}
+}
+
+func TestParseHostLayout(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"", HostLayoutPrefix, false},
+		{"prefix", HostLayoutPrefix, false},
+		{"DIR", HostLayoutDir, false},
+		{"nested", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseHostLayout(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseHostLayout(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseHostLayout(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
 func TestNewStrategy(t *testing.T) {
 	tests := []struct {
 		mode     string
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+)
+
+// createTemp creates temporary files; tests replace it to observe where they go
+var createTemp = os.CreateTemp
+
+// ValidateTempDir checks that dir exists, is a directory and is writable
+func ValidateTempDir(dir string) error {
+	info, err := os.Stat(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("temp directory does not exist: %s", dir)
+		}
+		return fmt.Errorf("cannot access temp directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("temp directory is not a directory: %s", dir)
+	}
+
+	probe, err := createTemp(dir, ".downurl-probe-*")
+	if err != nil {
+		return fmt.Errorf("temp directory is not writable: %w", err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	return nil
+}
+
+// moveFile renames src to dst, falling back to copy-and-delete when they are
+// on different filesystems (a temp dir on another volume)
+func moveFile(src, dst string) error {
+	err := os.Rename(src, dst)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, syscall.EXDEV) {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	return os.Remove(src)
+}
@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestArchiver_TempDir(t *testing.T) {
+	sourceDir := filepath.Join(t.TempDir(), "output")
+	if err := os.MkdirAll(sourceDir, 0755); err != nil {
+		t.Fatalf("Failed to create source dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "app.js"), []byte("console.log(1)"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+	tempDir := t.TempDir()
+
+	// Record every temp file the archiver creates
+	var created []string
+	orig := createTemp
+	createTemp = func(dir, pattern string) (*os.File, error) {
+		f, err := orig(dir, pattern)
+		if err == nil {
+			created = append(created, f.Name())
+		}
+		return f, err
+	}
+	defer func() { createTemp = orig }()
+
+	archiver := NewArchiver()
+	archiver.SetTempDir(tempDir)
+	destFile := filepath.Join(sourceDir, "output.tar.gz")
+	if err := archiver.CreateTarGz(sourceDir, destFile); err != nil {
+		t.Fatalf("CreateTarGz() error = %v", err)
+	}
+
+	if len(created) != 1 {
+		t.Fatalf("created %d temp files, want 1", len(created))
+	}
+	if dir := filepath.Dir(created[0]); dir != tempDir {
+		t.Errorf("temp file created in %s, want %s", dir, tempDir)
+	}
+
+	// The temp file was moved into place, not left behind
+	if entries, _ := os.ReadDir(tempDir); len(entries) != 0 {
+		t.Errorf("temp dir has %d leftover entries, want 0", len(entries))
+	}
+
+	f, err := os.Open(destFile)
+	if err != nil {
+		t.Fatalf("archive not created: %v", err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("invalid gzip archive: %v", err)
+	}
+	var names []string
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, hdr.Name)
+	}
+	if got := strings.Join(names, ","); got != "output,output/app.js" {
+		t.Errorf("archive entries = %s, want output,output/app.js", got)
+	}
+}
+
+func TestValidateTempDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := ValidateTempDir(dir); err != nil {
+		t.Errorf("ValidateTempDir(%s) error = %v", dir, err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("ValidateTempDir() left %d probe files behind", len(entries))
+	}
+
+	if err := ValidateTempDir(filepath.Join(dir, "missing")); err == nil {
+		t.Error("ValidateTempDir() expected error for missing directory")
+	}
+
+	file := filepath.Join(dir, "file")
+	os.WriteFile(file, []byte("x"), 0644)
+	if err := ValidateTempDir(file); err == nil {
+		t.Error("ValidateTempDir() expected error for a regular file")
+	}
+}
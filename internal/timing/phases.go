@@ -0,0 +1,132 @@
+package timing
+
+import (
+	"sync"
+	"time"
+)
+
+// Phase is the measured duration of one pipeline step
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// PhaseTimer measures consecutive pipeline phases.
+// Starting a phase ends the previous one, so phases never overlap
+// and their durations add up to the total.
+type PhaseTimer struct {
+	start      time.Time
+	end        time.Time
+	phases     []Phase
+	current    string
+	phaseStart time.Time
+	now        func() time.Time
+	mu         sync.Mutex
+}
+
+// NewPhaseTimer creates a timer; the total is measured from this call
+func NewPhaseTimer() *PhaseTimer {
+	return newPhaseTimer(time.Now)
+}
+
+// newPhaseTimer creates a timer using the given clock
+func newPhaseTimer(now func() time.Time) *PhaseTimer {
+	start := now()
+	return &PhaseTimer{
+		start:      start,
+		phaseStart: start,
+		now:        now,
+	}
+}
+
+// Start ends the running phase (if any) and begins a new one.
+// Time elapsed since the last phase ended is attributed to the new phase.
+func (t *PhaseTimer) Start(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.finish(now)
+	t.current = name
+	t.end = time.Time{}
+}
+
+// Stop ends the running phase
+func (t *PhaseTimer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.finish(now)
+	t.end = now
+}
+
+// finish records the running phase as ending at now
+func (t *PhaseTimer) finish(now time.Time) {
+	if t.current == "" {
+		return
+	}
+	t.add(t.current, now.Sub(t.phaseStart))
+	t.current = ""
+	t.phaseStart = now
+}
+
+// add accumulates a duration, merging repeated phase names
+func (t *PhaseTimer) add(name string, d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	for i := range t.phases {
+		if t.phases[i].Name == name {
+			t.phases[i].Duration += d
+			return
+		}
+	}
+	t.phases = append(t.phases, Phase{Name: name, Duration: d})
+}
+
+// Phases returns the phases in the order they first started.
+// A running phase is included with its duration so far.
+func (t *PhaseTimer) Phases() []Phase {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	phases := make([]Phase, len(t.phases))
+	copy(phases, t.phases)
+
+	if t.current != "" {
+		running := t.now().Sub(t.phaseStart)
+		found := false
+		for i := range phases {
+			if phases[i].Name == t.current {
+				phases[i].Duration += running
+				found = true
+			}
+		}
+		if !found {
+			phases = append(phases, Phase{Name: t.current, Duration: running})
+		}
+	}
+
+	return phases
+}
+
+// Total returns the time from creation until Stop (or now, if still running)
+func (t *PhaseTimer) Total() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.end.IsZero() {
+		return t.end.Sub(t.start)
+	}
+	return t.now().Sub(t.start)
+}
+
+// Seconds returns phase durations in seconds keyed by phase name
+func (t *PhaseTimer) Seconds() map[string]float64 {
+	seconds := make(map[string]float64)
+	for _, p := range t.Phases() {
+		seconds[p.Name] = p.Duration.Seconds()
+	}
+	return seconds
+}
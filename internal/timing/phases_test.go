@@ -0,0 +1,105 @@
+package timing
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock advances by a fixed step on every reading
+type fakeClock struct {
+	t    time.Time
+	step time.Duration
+}
+
+func (c *fakeClock) now() time.Time {
+	c.t = c.t.Add(c.step)
+	return c.t
+}
+
+func TestPhaseTimer_SumsToTotal(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0), step: 10 * time.Millisecond}
+	timer := newPhaseTimer(clock.now)
+
+	for _, name := range []string{"parse", "download", "process", "report", "archive"} {
+		timer.Start(name)
+	}
+	timer.Stop()
+
+	phases := timer.Phases()
+	if len(phases) != 5 {
+		t.Fatalf("Phases() returned %d phases, want 5", len(phases))
+	}
+
+	var sum time.Duration
+	for _, p := range phases {
+		if p.Duration < 0 {
+			t.Errorf("phase %s duration = %v, want >= 0", p.Name, p.Duration)
+		}
+		sum += p.Duration
+	}
+
+	if total := timer.Total(); sum != total {
+		t.Errorf("sum of phases = %v, want total %v", sum, total)
+	}
+}
+
+func TestPhaseTimer_RealClock(t *testing.T) {
+	timer := NewPhaseTimer()
+
+	timer.Start("parse")
+	time.Sleep(5 * time.Millisecond)
+	timer.Start("download")
+	time.Sleep(10 * time.Millisecond)
+	timer.Stop()
+
+	var sum time.Duration
+	for _, p := range timer.Phases() {
+		if p.Duration < 0 {
+			t.Errorf("phase %s duration = %v, want >= 0", p.Name, p.Duration)
+		}
+		sum += p.Duration
+	}
+
+	total := timer.Total()
+	if total < 15*time.Millisecond {
+		t.Errorf("Total() = %v, want >= 15ms", total)
+	}
+	if diff := total - sum; diff < 0 || diff > time.Millisecond {
+		t.Errorf("sum of phases = %v, total = %v, want roughly equal", sum, total)
+	}
+}
+
+func TestPhaseTimer_RepeatedPhase(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	timer := newPhaseTimer(clock.now)
+
+	timer.Start("download")
+	timer.Start("process")
+	timer.Start("download")
+	timer.Stop()
+
+	seconds := timer.Seconds()
+	if len(seconds) != 2 {
+		t.Fatalf("Seconds() = %v, want 2 phases", seconds)
+	}
+	if seconds["download"] != 3 {
+		t.Errorf("download = %v, want 3", seconds["download"])
+	}
+	if seconds["process"] != 1 {
+		t.Errorf("process = %v, want 1", seconds["process"])
+	}
+}
+
+func TestPhaseTimer_RunningPhase(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0), step: time.Second}
+	timer := newPhaseTimer(clock.now)
+
+	timer.Start("parse")
+	phases := timer.Phases()
+	if len(phases) != 1 || phases[0].Name != "parse" {
+		t.Fatalf("Phases() = %v, want running parse phase", phases)
+	}
+	if phases[0].Duration <= 0 {
+		t.Errorf("running phase duration = %v, want > 0", phases[0].Duration)
+	}
+}
@@ -3,6 +3,8 @@ package ui
 import (
 	"fmt"
 	"strings"
+
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
 )
 
 // FriendlyError wraps an error with user-friendly messages and suggestions
@@ -56,6 +58,7 @@ func WrapFileNotFound(filename string, err error) *FriendlyError {
 
 // WrapInvalidURL creates a friendly error for invalid URL
 func WrapInvalidURL(url string, lineNum int, err error) *FriendlyError {
+	url = sanitize.Text(url)
 	desc := fmt.Sprintf("Line %d: \"%s\"\n", lineNum, url)
 
 	// Analyze the error
@@ -117,7 +120,7 @@ func WrapNetworkError(url string, err error) *FriendlyError {
 
 	return &FriendlyError{
 		Title:       title,
-		Description: fmt.Sprintf("Failed to download: %s", url),
+		Description: fmt.Sprintf("Failed to download: %s", sanitize.Text(url)),
 		Suggestion:  suggestion,
 		OriginalErr: err,
 	}
@@ -134,6 +137,28 @@ func WrapPermissionError(path string, err error) *FriendlyError {
 	}
 }
 
+// WrapTempDirError creates a friendly error for an unusable --temp-dir
+func WrapTempDirError(path string, err error) *FriendlyError {
+	return &FriendlyError{
+		Title:       "Temporary directory unusable",
+		Description: fmt.Sprintf("Cannot use for temporary files: %s", path),
+		Suggestion:  "Point --temp-dir at an existing, writable directory with enough free space, or omit it to use the system temp",
+		Example:     "./downurl -i urls.txt --temp-dir /mnt/scratch",
+		OriginalErr: err,
+	}
+}
+
+// WrapOutputNotEmpty creates a friendly error for --require-empty on a used directory
+func WrapOutputNotEmpty(path string, err error) *FriendlyError {
+	return &FriendlyError{
+		Title:       "Output directory is not empty",
+		Description: fmt.Sprintf("--require-empty is set and %s already has files from an earlier run.", path),
+		Suggestion:  "Use --clean to wipe it first, or give each run its own directory with {date}, {time} or {runid}",
+		Example:     "./downurl -i urls.txt --output 'output/{runid}' --require-empty",
+		OriginalErr: err,
+	}
+}
+
 // WrapNoURLsError creates a friendly error for empty input
 func WrapNoURLsError() *FriendlyError {
 	return &FriendlyError{
@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+	"golang.org/x/term"
+)
+
+// URL states shown by CompactLog
+const (
+	StateQueued      = "queued"
+	StateDownloading = "downloading"
+	StateDone        = "done"
+	StateFailed      = "failed"
+	StateSkipped     = "skipped"
+)
+
+// IsTerminal reports whether w is a terminal, where lines can be redrawn
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	stat, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return stat.Mode()&os.ModeCharDevice != 0
+}
+
+// terminalHeight returns the number of rows of the terminal w writes to
+// (0 = unknown)
+func terminalHeight(w io.Writer) int {
+	f, ok := w.(*os.File)
+	if !ok {
+		return 0
+	}
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0
+	}
+	return height
+}
+
+// compactRow is one URL's line in a CompactLog
+type compactRow struct {
+	url    string
+	state  string
+	detail string // Error message for failed and skipped URLs
+}
+
+// CompactLog is a models.Observer printing one line per URL. On a terminal
+// each line is redrawn in place as the URL goes queued, downloading, then
+// done or failed; elsewhere only the final line of each URL is printed.
+type CompactLog struct {
+	w      io.Writer
+	tty    bool
+	height func() int // Terminal rows, read on every redraw as the window may be resized (0 = unknown)
+
+	mu    sync.Mutex
+	rows  []*compactRow
+	byURL map[string]int
+}
+
+// NewCompactLog creates a compact log of urls on w. On a terminal the
+// queued URLs are printed right away.
+func NewCompactLog(w io.Writer, urls []string, tty bool) *CompactLog {
+	l := &CompactLog{
+		w:      w,
+		tty:    tty,
+		height: func() int { return terminalHeight(w) },
+		byURL:  make(map[string]int, len(urls)),
+	}
+	for _, u := range urls {
+		if _, ok := l.byURL[u]; ok {
+			continue
+		}
+		l.byURL[u] = len(l.rows)
+		l.rows = append(l.rows, &compactRow{url: u, state: StateQueued})
+		if tty {
+			fmt.Fprintln(w, l.rows[len(l.rows)-1].line(true))
+		}
+	}
+	return l
+}
+
+// OnEvent implements models.Observer
+func (l *CompactLog) OnEvent(e models.Event) {
+	var state, detail string
+	switch e.Type {
+	case models.EventStarted:
+		state = StateDownloading
+	case models.EventSucceeded:
+		state = StateDone
+	case models.EventFailed:
+		state = StateFailed
+	case models.EventSkipped:
+		state = StateSkipped
+	default:
+		return
+	}
+	if e.Error != nil {
+		detail = e.Error.Message
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	i, ok := l.byURL[e.URL]
+	if !ok {
+		// URLs found during the run, e.g. crawled chunks, go at the bottom
+		i = len(l.rows)
+		l.byURL[e.URL] = i
+		l.rows = append(l.rows, &compactRow{url: e.URL, state: StateQueued})
+		if l.tty {
+			fmt.Fprintln(l.w, l.rows[i].line(true))
+		}
+	}
+	row := l.rows[i]
+	row.state, row.detail = state, detail
+
+	switch {
+	case l.tty:
+		// Move up to the row, rewrite it, and come back below the last row.
+		// The cursor cannot reach a row scrolled off the top of the screen:
+		// print that one again at the bottom instead.
+		up := len(l.rows) - i
+		if height := l.height(); height > 0 && up >= height {
+			l.byURL[e.URL] = len(l.rows)
+			l.rows = append(l.rows, row)
+			fmt.Fprintln(l.w, row.line(true))
+			return
+		}
+		fmt.Fprintf(l.w, "\033[%dA\r\033[K%s\033[%dB\r", up, row.line(true), up)
+	case state != StateDownloading:
+		fmt.Fprintln(l.w, row.line(false))
+	}
+}
+
+// line renders the row, colored on a terminal
+func (r *compactRow) line(tty bool) string {
+	s := fmt.Sprintf("%-11s %s", r.state, sanitize.Text(r.url))
+	if r.detail != "" {
+		s += " (" + sanitize.Text(r.detail) + ")"
+	}
+	if !tty {
+		return s
+	}
+	switch r.state {
+	case StateDone:
+		return Colorize(s, ColorGreen)
+	case StateFailed:
+		return Colorize(s, ColorRed)
+	case StateSkipped:
+		return Colorize(s, ColorYellow)
+	}
+	return s
+}
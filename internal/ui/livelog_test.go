@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+func TestCompactLog_NonTTY(t *testing.T) {
+	urls := []string{"https://example.com/a.js", "https://example.com/b.js", "https://example.com/c.js"}
+	var buf bytes.Buffer
+	l := NewCompactLog(&buf, urls, false)
+
+	if buf.Len() != 0 {
+		t.Errorf("queued URLs printed off a terminal: %q", buf.String())
+	}
+
+	for _, u := range urls {
+		l.OnEvent(models.Event{Type: models.EventStarted, URL: u})
+	}
+	l.OnEvent(models.Event{Type: models.EventSucceeded, URL: urls[0], Path: "out/a.js"})
+	l.OnEvent(models.Event{Type: models.EventFailed, URL: urls[1], Error: &models.DownloadError{Message: "HTTP 404: 404 Not Found"}})
+	l.OnEvent(models.Event{Type: models.EventSkipped, URL: urls[2], Error: &models.DownloadError{Message: "skipped: binary"}})
+	// Chunks found while crawling are logged too
+	l.OnEvent(models.Event{Type: models.EventSucceeded, URL: "https://example.com/chunk.js"})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	want := []string{
+		"done        https://example.com/a.js",
+		"failed      https://example.com/b.js (HTTP 404: 404 Not Found)",
+		"skipped     https://example.com/c.js (skipped: binary)",
+		"done        https://example.com/chunk.js",
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("got %d lines, want one final line per URL:\n%s", len(lines), buf.String())
+	}
+	for i := range want {
+		if lines[i] != want[i] {
+			t.Errorf("line %d = %q, want %q", i, lines[i], want[i])
+		}
+	}
+}
+
+func TestCompactLog_TTYRedrawsInPlace(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewCompactLog(&buf, []string{"https://example.com/a.js", "https://example.com/b.js"}, true)
+	l.OnEvent(models.Event{Type: models.EventStarted, URL: "https://example.com/a.js"})
+
+	// a.js is two lines above the cursor: go up, rewrite, come back down
+	out := buf.String()
+	if strings.Count(out, "queued") != 2 {
+		t.Errorf("queued URLs not listed up front: %q", out)
+	}
+	if !strings.Contains(out, "\033[2A\r\033[Kdownloading https://example.com/a.js\033[2B\r") {
+		t.Errorf("row not redrawn in place: %q", out)
+	}
+}
+
+func TestCompactLog_TTYRowOffScreen(t *testing.T) {
+	var buf bytes.Buffer
+	urls := []string{"https://example.com/a.js", "https://example.com/b.js", "https://example.com/c.js"}
+	l := NewCompactLog(&buf, urls, true)
+	l.height = func() int { return 3 } // Room for the last two rows above the cursor
+	buf.Reset()
+
+	// b.js is still on screen and redrawn in place
+	l.OnEvent(models.Event{Type: models.EventStarted, URL: urls[1]})
+	if out := buf.String(); !strings.Contains(out, "\033[2A\r\033[Kdownloading https://example.com/b.js\033[2B\r") {
+		t.Errorf("on-screen row not redrawn in place: %q", out)
+	}
+	buf.Reset()
+
+	// a.js scrolled off: the cursor is not moved past the top, the row is printed again
+	l.OnEvent(models.Event{Type: models.EventStarted, URL: urls[0]})
+	if out := buf.String(); strings.Contains(out, "\033[3A") || out != "downloading https://example.com/a.js\n" {
+		t.Errorf("off-screen row output = %q, want it printed again below the others", out)
+	}
+	buf.Reset()
+
+	// From then on a.js is redrawn at its new place, the last row
+	l.OnEvent(models.Event{Type: models.EventSucceeded, URL: urls[0]})
+	if out := buf.String(); !strings.Contains(out, "\033[1A\r\033[K") || !strings.Contains(out, "https://example.com/a.js") {
+		t.Errorf("moved row not redrawn at the bottom: %q", out)
+	}
+}
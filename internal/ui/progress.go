@@ -1,12 +1,29 @@
 package ui
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"sync"
 	"time"
 )
 
+// out receives progress bars, tables and status messages
+var out io.Writer = os.Stdout
+
+// SetOutput sends progress and status messages to w instead of stdout,
+// e.g. stderr when stdout carries a report
+func SetOutput(w io.Writer) {
+	out = w
+}
+
+// Output returns where progress and status messages are printed
+func Output() io.Writer {
+	return out
+}
+
 // ProgressBar displays download progress
 type ProgressBar struct {
 	total       int
@@ -100,7 +117,7 @@ func (pb *ProgressBar) Finish() {
 	pb.mu.Lock()
 	pb.current = pb.total
 	pb.mu.Unlock()
-	fmt.Println(pb.Render())
+	fmt.Fprintln(out, pb.Render())
 }
 
 // formatBytes formats bytes to human readable format
@@ -148,20 +165,29 @@ func Colorize(text string, color string) string {
 
 // Success prints a success message
 func Success(msg string) {
-	fmt.Printf("✓ %s\n", Colorize(msg, ColorGreen))
+	fmt.Fprintf(out, "✓ %s\n", Colorize(msg, ColorGreen))
 }
 
 // Error prints an error message
 func Error(msg string) {
-	fmt.Printf("✗ %s\n", Colorize(msg, ColorRed))
+	fmt.Fprintf(out, "✗ %s\n", Colorize(msg, ColorRed))
 }
 
 // Warning prints a warning message
 func Warning(msg string) {
-	fmt.Printf("⚠ %s\n", Colorize(msg, ColorYellow))
+	fmt.Fprintf(out, "⚠ %s\n", Colorize(msg, ColorYellow))
 }
 
 // Info prints an info message
 func Info(msg string) {
-	fmt.Printf("ℹ %s\n", Colorize(msg, ColorBlue))
+	fmt.Fprintf(out, "ℹ %s\n", Colorize(msg, ColorBlue))
+}
+
+// Confirm asks a yes/no question on out and reads the answer from in.
+// Only "y" or "yes" (any case) confirms; anything else, including EOF, declines.
+func Confirm(in io.Reader, out io.Writer, question string) bool {
+	fmt.Fprintf(out, "%s [y/N] ", Colorize(question, ColorYellow))
+	answer, _ := bufio.NewReader(in).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
 }
@@ -2,9 +2,14 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/lcalzada-xor/downurl/internal/benchmark"
+	"github.com/lcalzada-xor/downurl/internal/ratelimit"
+	"github.com/lcalzada-xor/downurl/internal/sanitize"
+	"github.com/lcalzada-xor/downurl/internal/timing"
 	"github.com/lcalzada-xor/downurl/pkg/models"
 )
 
@@ -59,21 +64,14 @@ func (rt *ResultsTable) Render() string {
 		result := rt.results[i]
 
 		// Truncate URL if too long
-		url := result.URL
+		url := sanitize.Text(result.URL)
 		if len(url) > urlWidth {
 			url = url[:urlWidth-3] + "..."
 		}
 
-		// Calculate total size
-		var totalSize int64
-		for range result.Downloaded {
-			// We don't have size info in result, using placeholder
-			totalSize += 0 // TODO: Add size tracking
-		}
-
 		size := "-"
-		if totalSize > 0 {
-			size = formatBytes(totalSize)
+		if len(result.Downloaded) > 0 {
+			size = formatBytes(result.Bytes)
 		}
 
 		duration := formatDuration(result.Duration)
@@ -108,6 +106,11 @@ func (rt *ResultsTable) Render() string {
 
 // RenderSummary renders a detailed summary
 func RenderSummary(results []models.DownloadResult, elapsed time.Duration, outputDir string) string {
+	return RenderRunSummary(models.Summarize(results), elapsed, outputDir)
+}
+
+// RenderRunSummary renders a detailed summary from incrementally collected totals
+func RenderRunSummary(s models.RunSummary, elapsed time.Duration, outputDir string) string {
 	var sb strings.Builder
 
 	// Header
@@ -115,21 +118,10 @@ func RenderSummary(results []models.DownloadResult, elapsed time.Duration, outpu
 	sb.WriteString(Colorize("📊 Download Summary", ColorCyan) + "\n")
 	sb.WriteString(strings.Repeat("═", 60) + "\n\n")
 
-	// Calculate stats
-	total := len(results)
-	successful := 0
-	failed := 0
-	var totalBytes int64
-	var totalErrors int
-
-	for _, r := range results {
-		if r.IsSuccess() {
-			successful++
-		} else {
-			failed++
-		}
-		totalErrors += len(r.Errors)
-	}
+	total := s.Total
+	successful := s.Successful
+	failed := s.Failed
+	totalBytes := s.Bytes
 
 	// Duration and success rate
 	sb.WriteString(fmt.Sprintf("⏱️  Duration: %s\n", Colorize(formatDuration(elapsed), ColorYellow)))
@@ -165,27 +157,20 @@ func RenderSummary(results []models.DownloadResult, elapsed time.Duration, outpu
 	sb.WriteString("\n")
 
 	// Failures breakdown
-	if totalErrors > 0 {
+	if s.Errors > 0 {
 		sb.WriteString(Colorize("⚠️  Failures:", ColorYellow) + "\n")
-		errorTypes := make(map[string]int)
-		for _, r := range results {
-			for _, err := range r.Errors {
-				if strings.Contains(err, "timeout") {
-					errorTypes["timeout"]++
-				} else if strings.Contains(err, "404") || strings.Contains(err, "not found") {
-					errorTypes["not found"]++
-				} else if strings.Contains(err, "connection refused") {
-					errorTypes["connection refused"]++
-				} else {
-					errorTypes["other"]++
-				}
-			}
+		errorTypes := s.ByCategory
+
+		// Sort categories for stable output
+		categories := make([]string, 0, len(errorTypes))
+		for errType := range errorTypes {
+			categories = append(categories, string(errType))
 		}
+		sort.Strings(categories)
 
-		i := 1
-		for errType, count := range errorTypes {
-			sb.WriteString(fmt.Sprintf("   %d. %s (%d files)\n", i, errType, count))
-			i++
+		for i, errType := range categories {
+			count := errorTypes[models.ErrorCategory(errType)]
+			sb.WriteString(fmt.Sprintf("   %d. %s (%d files)\n", i+1, errType, count))
 		}
 		sb.WriteString("\n")
 	}
@@ -194,3 +179,172 @@ func RenderSummary(results []models.DownloadResult, elapsed time.Duration, outpu
 
 	return sb.String()
 }
+
+// RenderSecretsDiff renders how many secrets appeared and disappeared since the previous run
+func RenderSecretsDiff(added, resolved int) string {
+	color := ColorGreen
+	if added > 0 {
+		color = ColorRed
+	}
+	return fmt.Sprintf("🔐 Secrets since last run: %s new, %d resolved\n\n",
+		Colorize(fmt.Sprintf("%d", added), color), resolved)
+}
+
+// RenderRateLimit renders how much the rate limiter held requests back
+func RenderRateLimit(m ratelimit.Metrics) string {
+	if m.Blocked == 0 {
+		return "🚦 Rate limiting: no requests delayed\n\n"
+	}
+	return fmt.Sprintf("🚦 Rate limiting: %s requests delayed, %s waited (avg %s)\n\n",
+		Colorize(fmt.Sprintf("%d", m.Blocked), ColorYellow),
+		formatDuration(m.WaitTime), formatDuration(m.AvgWait()))
+}
+
+// RenderDuplicates renders how many URLs returned content another URL already had
+func RenderDuplicates(clusters, files int, bytes int64) string {
+	if clusters == 0 {
+		return ""
+	}
+	return fmt.Sprintf("📑 Duplicate content: %s redundant files (%s) in %d clusters, listed in the report\n\n",
+		Colorize(fmt.Sprintf("%d", files), ColorYellow), formatBytes(bytes), clusters)
+}
+
+// RenderDedupe renders what --dedupe found: duplicates linked to a single
+// copy and the space freed, or in report mode the space they take up
+func RenderDedupe(files, linked int, bytes int64) string {
+	if files == 0 {
+		return "🔗 Deduplication: no duplicate files\n\n"
+	}
+	if linked > 0 {
+		return fmt.Sprintf("🔗 Deduplication: %s duplicate files linked to a single copy, %s saved\n\n",
+			Colorize(fmt.Sprintf("%d", linked), ColorGreen), formatBytes(bytes))
+	}
+	return fmt.Sprintf("🔗 Deduplication: %s duplicate files taking %s (--dedupe-mode hardlink or symlink reclaims it)\n\n",
+		Colorize(fmt.Sprintf("%d", files), ColorYellow), formatBytes(bytes))
+}
+
+// RenderPhaseTimings renders how long each pipeline phase took, with its share of the total
+func RenderPhaseTimings(phases []timing.Phase, total time.Duration) string {
+	if len(phases) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(Colorize("⏳ Phase timings:", ColorCyan) + "\n")
+
+	barWidth := 20
+	for _, p := range phases {
+		share := 0.0
+		if total > 0 {
+			share = float64(p.Duration) / float64(total) * 100
+		}
+		filled := int(share / 100 * float64(barWidth))
+		if filled > barWidth {
+			filled = barWidth
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		// Phases are often sub-second, so show millisecond precision below a minute
+		duration := formatDuration(p.Duration)
+		if p.Duration < time.Minute {
+			duration = p.Duration.Round(time.Millisecond).String()
+		}
+		sb.WriteString(fmt.Sprintf("   %-12s %s %8s (%5.1f%%)\n", p.Name, bar, duration, share))
+	}
+
+	return sb.String()
+}
+
+// RenderBenchmark renders throughput figures from a benchmark run
+func RenderBenchmark(r benchmark.Report) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.Repeat("═", 60) + "\n")
+	sb.WriteString(Colorize("🏁 Benchmark", ColorCyan) + "\n")
+	sb.WriteString(strings.Repeat("═", 60) + "\n\n")
+
+	sb.WriteString(fmt.Sprintf("⏱️  Duration: %s\n", Colorize(r.Elapsed.Round(time.Millisecond).String(), ColorYellow)))
+	sb.WriteString(fmt.Sprintf("   Requests: %d (%s ok, %s failed)\n", r.Requests,
+		Colorize(fmt.Sprintf("%d", r.Succeeded), ColorGreen),
+		Colorize(fmt.Sprintf("%d", r.Failed), ColorRed)))
+	sb.WriteString(fmt.Sprintf("   Received: %s\n\n", formatBytes(r.Bytes)))
+
+	sb.WriteString(Colorize("🚀 Throughput:", ColorCyan) + "\n")
+	sb.WriteString(fmt.Sprintf("   - Requests/sec: %.2f\n", r.RequestsPerSec))
+	sb.WriteString(fmt.Sprintf("   - MB/s: %.2f\n\n", r.MBPerSec))
+
+	sb.WriteString(Colorize("📈 Latency:", ColorCyan) + "\n")
+	sb.WriteString(fmt.Sprintf("   - p50: %s\n", r.P50.Round(time.Millisecond)))
+	sb.WriteString(fmt.Sprintf("   - p95: %s\n", r.P95.Round(time.Millisecond)))
+	sb.WriteString(fmt.Sprintf("   - max: %s\n\n", r.Max.Round(time.Millisecond)))
+
+	sb.WriteString(strings.Repeat("═", 60) + "\n")
+
+	return sb.String()
+}
+
+// RenderPlan renders the outcome of a --dry-run: every file that would be
+// downloaded with its target path and declared size, then the totals
+func RenderPlan(results []models.DownloadResult) string {
+	var sb strings.Builder
+
+	sb.WriteString(strings.Repeat("═", 60) + "\n")
+	sb.WriteString(Colorize("📋 Dry Run: nothing was downloaded", ColorCyan) + "\n")
+	sb.WriteString(strings.Repeat("═", 60) + "\n\n")
+
+	var planned, unknown int
+	var expected int64
+	for _, r := range results {
+		if !r.Planned || len(r.Downloaded) == 0 {
+			continue
+		}
+		planned++
+		size := "size unknown"
+		if r.PlannedSize >= 0 {
+			expected += r.PlannedSize
+			size = formatBytes(r.PlannedSize)
+		} else {
+			unknown++
+		}
+		sb.WriteString(fmt.Sprintf("   %s\n     -> %s (%s)\n", sanitize.Text(r.URL), sanitize.Text(r.Downloaded[0]), size))
+	}
+	if planned > 0 {
+		sb.WriteString("\n")
+	}
+
+	s := models.Summarize(results)
+	sb.WriteString(fmt.Sprintf("✓  Would download: %s, %s",
+		Colorize(fmt.Sprintf("%d files", planned), ColorGreen),
+		Colorize(formatBytes(expected), ColorYellow)))
+	if unknown > 0 {
+		sb.WriteString(fmt.Sprintf(" (+%d of unknown size)", unknown))
+	}
+	sb.WriteString("\n")
+	if s.Skipped > 0 {
+		sb.WriteString(fmt.Sprintf("-  Skipped by filters: %d\n", s.Skipped))
+	}
+	if failed := s.Total - planned - s.Skipped; failed > 0 {
+		sb.WriteString(fmt.Sprintf("✗  Would fail: %s\n", Colorize(fmt.Sprintf("%d", failed), ColorRed)))
+		categories := make([]string, 0, len(s.ByCategory))
+		for category := range s.ByCategory {
+			if category != models.ErrorCategorySkipped {
+				categories = append(categories, string(category))
+			}
+		}
+		sort.Strings(categories)
+		for i, category := range categories {
+			sb.WriteString(fmt.Sprintf("   %d. %s (%d files)\n", i+1, category, s.ByCategory[models.ErrorCategory(category)]))
+		}
+	}
+	sb.WriteString("\n" + strings.Repeat("═", 60) + "\n")
+
+	return sb.String()
+}
+
+// CategorizeErrors counts failures by category across all results
+func CategorizeErrors(results []models.DownloadResult) map[models.ErrorCategory]int {
+	counts := models.Summarize(results).ByCategory
+	if counts == nil {
+		counts = make(map[models.ErrorCategory]int)
+	}
+	return counts
+}
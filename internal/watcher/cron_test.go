@@ -0,0 +1,131 @@
+package watcher
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCronSchedule_Next(t *testing.T) {
+	// Wednesday
+	from := time.Date(2024, 5, 1, 12, 34, 56, 0, time.UTC)
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 5, 1, 12, 35, 0, 0, time.UTC)},
+		{"0 */6 * * *", time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)},
+		{"30 2 * * *", time.Date(2024, 5, 2, 2, 30, 0, 0, time.UTC)},
+		{"0 9 * * mon-fri", time.Date(2024, 5, 2, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan *", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"15,45 12 * * *", time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2024, 5, 1, 12, 45, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Both day fields restricted: the 15th or any Friday
+		{"0 0 15 * fri", time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)},
+		// A field starting with * leaves the day to the other one (Vixie cron)
+		{"0 0 */2 * sun", time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC)},
+		{"0 0 15 * */2", time.Date(2024, 5, 15, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron() error = %v", err)
+			}
+			if got := c.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_NextNever(t *testing.T) {
+	c, err := ParseCron("0 0 30 2 *")
+	if err != nil {
+		t.Fatalf("ParseCron() error = %v", err)
+	}
+	if got := c.Next(time.Now()); !got.IsZero() {
+		t.Errorf("Next() = %v, want zero time for 30 February", got)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"5-1 * * * *",
+		"a * * * *",
+		"* * * foo *",
+	} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) expected error", expr)
+		}
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	for _, ok := range []string{"5m", "1h", "0 */6 * * *", "@weekly"} {
+		if err := ValidateSchedule(ok); err != nil {
+			t.Errorf("ValidateSchedule(%q) error = %v", ok, err)
+		}
+	}
+	for _, bad := range []string{"0s", "-5m", "every hour", "* * *"} {
+		if err := ValidateSchedule(bad); err == nil {
+			t.Errorf("ValidateSchedule(%q) expected error", bad)
+		}
+	}
+}
+
+func TestIsCron(t *testing.T) {
+	for schedule, want := range map[string]bool{"5m": false, "1h": false, "0 */6 * * *": true, "@daily": true, "every hour": false} {
+		if got := IsCron(schedule); got != want {
+			t.Errorf("IsCron(%q) = %v, want %v", schedule, got, want)
+		}
+	}
+}
+
+func TestScheduler_CronInitialDelay(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "schedule.json")
+	now := time.Date(2024, 5, 1, 12, 0, 30, 0, time.UTC)
+
+	s := NewScheduler("0 */6 * * *", func() error { return nil })
+	s.now = func() time.Time { return now }
+	var err error
+	if s.interval, s.cron, err = parseSchedule(s.schedule); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without state: wait for the next listed time
+	if got, want := s.initialDelay(), 6*time.Hour-30*time.Second; got != want {
+		t.Errorf("initialDelay() without state = %v, want %v", got, want)
+	}
+
+	// The last success was before the 12:00 run, which was missed
+	s.SetStateFile(state)
+	if err := saveScheduleState(state, now.Add(-7*time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.initialDelay(); got != 0 {
+		t.Errorf("initialDelay() after a missed run = %v, want 0", got)
+	}
+
+	// The 12:00 run happened: wait for 18:00
+	if err := saveScheduleState(state, now.Add(-20*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.initialDelay(), 6*time.Hour-30*time.Second; got != want {
+		t.Errorf("initialDelay() after the last run = %v, want %v", got, want)
+	}
+}
@@ -3,10 +3,12 @@ package watcher
 import (
 	"context"
 	"crypto/sha256"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -92,8 +94,13 @@ func (fw *FileWatcher) getFileHash() ([]byte, error) {
 
 // Scheduler handles scheduled downloads
 type Scheduler struct {
-	schedule string // cron expression
-	runFunc  func() error
+	schedule  string // Interval ("5m", "1h") or cron expression ("0 */6 * * *")
+	runFunc   func() error
+	stateFile string // Where the last successful run time is kept ("" = not kept)
+	now       func() time.Time
+
+	interval time.Duration // Set when the schedule is an interval
+	cron     *CronSchedule // Set when the schedule is a cron expression
 }
 
 // NewScheduler creates a new scheduler
@@ -101,53 +108,191 @@ func NewScheduler(schedule string, runFunc func() error) *Scheduler {
 	return &Scheduler{
 		schedule: schedule,
 		runFunc:  runFunc,
+		now:      time.Now,
 	}
 }
 
-// Start starts the scheduler
+// SetStateFile keeps the time of the last successful run in path. A
+// scheduler started again (e.g. after a reboot) then waits out the rest of
+// the interval instead of running right away.
+func (s *Scheduler) SetStateFile(path string) {
+	s.stateFile = path
+}
+
+// Start starts the scheduler. An interval schedule runs right away and then
+// every interval; a cron schedule runs at the times it lists.
 func (s *Scheduler) Start(ctx context.Context) error {
 	log.Printf("📅 Scheduled download: %s", s.schedule)
-	log.Println("Note: Full cron support requires external scheduler")
-	log.Println("Consider using systemd timer or crontab:")
-	log.Printf("  */5 * * * * /path/to/downurl -i urls.txt\n")
 
-	// For now, we'll implement simple interval-based scheduling
-	interval, err := s.parseSimpleSchedule()
-	if err != nil {
+	var err error
+	if s.interval, s.cron, err = parseSchedule(s.schedule); err != nil {
 		return err
 	}
+	if s.cron != nil && s.cron.Next(s.now()).IsZero() {
+		return fmt.Errorf("schedule %q never runs", s.schedule)
+	}
 
-	ticker := time.NewTicker(interval)
-	defer ticker.Stop()
-
-	// Run immediately
-	log.Println("Running initial download...")
-	if err := s.runFunc(); err != nil {
-		log.Printf("Error: %v", err)
+	wait := s.initialDelay()
+	switch {
+	case wait > 0 && s.cron != nil:
+		log.Printf("Next download at %s", s.now().Add(wait).Format("2006-01-02 15:04"))
+	case wait > 0:
+		log.Printf("Last run finished less than %v ago, next download in %v", s.interval, wait.Round(time.Second))
+	default:
+		// Run immediately
+		log.Println("Running initial download...")
+		wait = s.run()
 	}
 
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			log.Println("\nScheduler stopped")
 			return nil
-		case <-ticker.C:
-			timestamp := time.Now().Format("2006-01-02 15:04:05")
+		case <-timer.C:
+			timestamp := s.now().Format("2006-01-02 15:04:05")
 			log.Printf("\n[%s] Running scheduled download...", timestamp)
-			if err := s.runFunc(); err != nil {
-				log.Printf("Error: %v", err)
-			}
+			timer.Reset(s.run())
+		}
+	}
+}
+
+// run runs the download once, records it if it succeeded, and returns how
+// long to wait for the next one
+func (s *Scheduler) run() time.Duration {
+	start := s.now()
+	if err := s.runFunc(); err != nil {
+		log.Printf("Error: %v", err)
+	} else if s.stateFile != "" {
+		if err := saveScheduleState(s.stateFile, start); err != nil {
+			log.Printf("[WARN] Failed to save schedule state: %v", err)
+		}
+	}
+
+	// Intervals count from the start of a run, like a ticker. Cron times
+	// missed while the run was going are skipped, as cron does.
+	now := s.now()
+	next := start.Add(s.interval)
+	if s.cron != nil {
+		next = s.cron.Next(now)
+	}
+	if wait := next.Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// initialDelay returns how long to wait before the first run. With an
+// interval, that is the rest of it since the last successful run in the
+// state file, or 0. With a cron schedule it is the time until the next
+// listed time, or 0 if one went by since the last successful run.
+func (s *Scheduler) initialDelay() time.Duration {
+	now := s.now()
+	last, ok := s.lastSuccess()
+	if s.cron != nil {
+		if ok && s.cron.Next(last).Before(now) {
+			// A run was missed while the scheduler was down
+			return 0
+		}
+		return s.cron.Next(now).Sub(now)
+	}
+	if !ok {
+		return 0
+	}
+
+	wait := last.Add(s.interval).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+	if wait > s.interval {
+		// The clock went back, or the interval got shorter: wait no longer than one interval
+		return s.interval
+	}
+	return wait
+}
+
+// lastSuccess returns the last successful run recorded in the state file
+func (s *Scheduler) lastSuccess() (time.Time, bool) {
+	if s.stateFile == "" {
+		return time.Time{}, false
+	}
+	last, err := loadScheduleState(s.stateFile)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("[WARN] Ignoring schedule state: %v", err)
 		}
+		return time.Time{}, false
+	}
+	return last, true
+}
+
+// scheduleState is the content of a --schedule-state file
+type scheduleState struct {
+	LastSuccess time.Time `json:"last_success"`
+}
+
+// loadScheduleState reads the last successful run time from path
+func loadScheduleState(path string) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var state scheduleState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, fmt.Errorf("failed to parse %s: %w", path, err)
 	}
+	return state.LastSuccess, nil
 }
 
-// parseSimpleSchedule parses simple schedule formats
-func (s *Scheduler) parseSimpleSchedule() (time.Duration, error) {
-	// Support simple formats like "5m", "1h", "30s"
-	if d, err := time.ParseDuration(s.schedule); err == nil {
-		return d, nil
+// saveScheduleState records a successful run started at last, replacing
+// path atomically so a crash never leaves it half-written
+func saveScheduleState(path string, last time.Time) error {
+	data, err := json.Marshal(scheduleState{LastSuccess: last})
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedule state: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create schedule state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write schedule state: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write schedule state: %w", err)
+	}
+	return nil
+}
+
+// ValidateSchedule checks that schedule is an interval or a cron expression
+func ValidateSchedule(schedule string) error {
+	_, _, err := parseSchedule(schedule)
+	return err
+}
+
+// IsCron reports whether schedule is a cron expression rather than an interval
+func IsCron(schedule string) bool {
+	_, cron, err := parseSchedule(schedule)
+	return err == nil && cron != nil
+}
+
+// parseSchedule parses an interval like "5m" or "1h", or failing that a
+// cron expression; exactly one of the results is set
+func parseSchedule(schedule string) (time.Duration, *CronSchedule, error) {
+	if d, err := time.ParseDuration(schedule); err == nil {
+		if d <= 0 {
+			return 0, nil, fmt.Errorf("invalid schedule interval: %s (must be positive)", schedule)
+		}
+		return d, nil, nil
 	}
 
-	// TODO: Add full cron expression support
-	return 0, fmt.Errorf("invalid schedule format: %s (use duration like 5m, 1h, etc.)", s.schedule)
+	cron, err := ParseCron(schedule)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w (use an interval like 5m or 1h, or a cron expression like \"0 */6 * * *\")", err)
+	}
+	return 0, cron, nil
 }
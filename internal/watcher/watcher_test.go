@@ -0,0 +1,74 @@
+package watcher
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestScheduler_InitialDelayFromState(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "state", "schedule.json")
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewScheduler("1h", func() error { return nil })
+	s.SetStateFile(state)
+	s.now = func() time.Time { return now }
+	s.interval = time.Hour
+
+	// No state yet: run right away
+	if got := s.initialDelay(); got != 0 {
+		t.Errorf("initialDelay() without state = %v, want 0", got)
+	}
+
+	// Restarted 40 minutes after the last successful run
+	if err := saveScheduleState(state, now.Add(-40*time.Minute)); err != nil {
+		t.Fatalf("saveScheduleState() error = %v", err)
+	}
+	if got := s.initialDelay(); got != 20*time.Minute {
+		t.Errorf("initialDelay() = %v, want 20m", got)
+	}
+
+	// The interval has passed while the scheduler was down
+	s.interval = 30 * time.Minute
+	if got := s.initialDelay(); got != 0 {
+		t.Errorf("initialDelay() after the interval = %v, want 0", got)
+	}
+}
+
+func TestScheduler_RestartWaitsForInterval(t *testing.T) {
+	state := filepath.Join(t.TempDir(), "schedule.json")
+	const interval = 300 * time.Millisecond
+
+	// The previous process ran 100ms ago, then restarted
+	if err := saveScheduleState(state, time.Now().Add(-100*time.Millisecond)); err != nil {
+		t.Fatalf("saveScheduleState() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	var firstRun time.Duration
+	s := NewScheduler(interval.String(), func() error {
+		firstRun = time.Since(start)
+		cancel()
+		return nil
+	})
+	s.SetStateFile(state)
+
+	if err := s.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if firstRun < 150*time.Millisecond {
+		t.Errorf("first run after %v, want it delayed by the rest of the interval (~200ms)", firstRun)
+	}
+
+	// The run was recorded for the next restart
+	last, err := loadScheduleState(state)
+	if err != nil {
+		t.Fatalf("loadScheduleState() error = %v", err)
+	}
+	if last.Before(start) {
+		t.Errorf("state last_success = %v, want the new run (after %v)", last, start)
+	}
+}
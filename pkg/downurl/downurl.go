@@ -0,0 +1,165 @@
+package downurl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lcalzada-xor/downurl/internal/downloader"
+	"github.com/lcalzada-xor/downurl/internal/output"
+	"github.com/lcalzada-xor/downurl/internal/processor"
+	"github.com/lcalzada-xor/downurl/internal/storage"
+	"github.com/lcalzada-xor/downurl/pkg/models"
+)
+
+const (
+	DefaultWorkers = 10               // Concurrent downloads when Options.Workers is 0
+	DefaultTimeout = 15 * time.Second // Request timeout when Options.Timeout is 0
+)
+
+// FileProcessor analyzes one downloaded file and adds its findings to the
+// report (see Reporter.AddFinding)
+type FileProcessor = processor.FileProcessor
+
+// FileProcessorFunc adapts a function to a FileProcessor
+type FileProcessorFunc = processor.FileProcessorFunc
+
+// Reporter collects the downloads and findings of a run
+type Reporter = output.Reporter
+
+// CustomFinding is a finding added by a FileProcessor
+type CustomFinding = output.CustomFinding
+
+// ScanReport is the report of a finished run
+type ScanReport = output.ScanReport
+
+// Observer receives the lifecycle events of every URL as they happen (see
+// models.Event). Downloads run on several workers, so implementations must
+// be safe for concurrent use.
+type Observer = models.Observer
+
+// ObserverFunc adapts a function to an Observer
+type ObserverFunc = models.ObserverFunc
+
+// RequestSigner adds computed headers, such as an HMAC over the path and a
+// timestamp, to each request. Sign runs on every attempt once every other
+// header is set, and again on each redirect with the redirected request.
+type RequestSigner = downloader.RequestSigner
+
+// RequestSignerFunc adapts a function to a RequestSigner
+type RequestSignerFunc = downloader.RequestSignerFunc
+
+// HMACSigner is the built-in RequestSigner: a hex-encoded HMAC-SHA256 of a
+// message built from a template of request fields
+type HMACSigner = downloader.HMACSigner
+
+// NewHMACSigner creates an HMACSigner that sends the signature in header
+// ("" = X-Signature) for the message built from template ("" =
+// "{method}\n{path}\n{timestamp}")
+func NewHMACSigner(key, header, template string) *HMACSigner {
+	return downloader.NewHMACSigner(key, header, template)
+}
+
+// Options configures a Client
+type Options struct {
+	OutputDir     string        // Directory downloads are saved in
+	StorageMode   string        // Layout of OutputDir: flat, path, host, type or dated ("" = flat)
+	Workers       int           // Concurrent downloads (0 = DefaultWorkers)
+	Timeout       time.Duration // Per-request timeout (0 = DefaultTimeout)
+	RetryAttempts int           // Attempts per URL after the first one fails
+	ScanSecrets   bool          // Run the built-in secret scanner on every file
+	ScanEndpoints bool          // Run the built-in endpoint scanner on every file
+}
+
+// Client downloads URLs and runs the built-in scanners and the registered
+// processors on every saved file
+type Client struct {
+	opts     Options
+	plugins  []FileProcessor
+	observer Observer
+	signer   RequestSigner
+}
+
+// Result is the outcome of Client.Run
+type Result struct {
+	Downloads []*models.DownloadResult
+	Report    ScanReport
+}
+
+// New creates a Client
+func New(opts Options) *Client {
+	if opts.Workers <= 0 {
+		opts.Workers = DefaultWorkers
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.StorageMode == "" {
+		opts.StorageMode = "flat"
+	}
+	return &Client{opts: opts}
+}
+
+// Register adds a processor run on every saved file after the built-in
+// ones, in the order registered
+func (c *Client) Register(fp FileProcessor) {
+	c.plugins = append(c.plugins, fp)
+}
+
+// SetObserver sets the observer notified when each URL starts, succeeds, fails
+// or is skipped, and when a secret is found in its file. nil (the default)
+// disables events.
+func (c *Client) SetObserver(o Observer) {
+	c.observer = o
+}
+
+// SetSigner sets a signer every request passes through just before it is
+// sent. nil (the default) disables signing.
+func (c *Client) SetSigner(s RequestSigner) {
+	c.signer = s
+}
+
+// Run downloads urls into the output directory and processes each saved file
+// as soon as its download finishes. Files that fail to process do not stop
+// the others: their errors are returned together, alongside the complete
+// result.
+func (c *Client) Run(ctx context.Context, urls []string) (*Result, error) {
+	if c.opts.OutputDir == "" {
+		return nil, fmt.Errorf("no output directory")
+	}
+	store := storage.NewFileStorage(c.opts.OutputDir, c.opts.StorageMode)
+	if err := store.Init(); err != nil {
+		return nil, err
+	}
+
+	httpClient := downloader.NewHTTPClient(c.opts.Timeout, c.opts.RetryAttempts)
+	httpClient.SetSigner(c.signer)
+	dl := downloader.New(httpClient, store, c.opts.Workers)
+
+	proc := processor.NewProcessor(processor.Config{
+		ScanSecrets:   c.opts.ScanSecrets,
+		ScanEndpoints: c.opts.ScanEndpoints,
+	})
+	for _, fp := range c.plugins {
+		proc.Register(fp)
+	}
+	if c.observer != nil {
+		dl.SetObserver(c.observer)
+		proc.SetObserver(c.observer)
+	}
+
+	results := make([]*models.DownloadResult, 0, len(urls))
+	var errs []error
+	dl.DownloadJobsStream(ctx, downloader.NewJobs(urls), nil, nil, func(result *models.DownloadResult) {
+		results = append(results, result)
+		if err := proc.ProcessResult(*result, c.opts.OutputDir); err != nil {
+			errs = append(errs, err)
+		}
+	})
+
+	return &Result{
+		Downloads: results,
+		Report:    proc.GetReporter().GetReport(),
+	}, errors.Join(errs...)
+}
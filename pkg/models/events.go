@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// EventType identifies a step in a URL's lifecycle
+type EventType string
+
+const (
+	EventStarted     EventType = "started"      // Download of a URL began
+	EventSucceeded   EventType = "succeeded"    // File was saved
+	EventFailed      EventType = "failed"       // Download failed
+	EventSkipped     EventType = "skipped"      // Download was skipped by a filter
+	EventSecretFound EventType = "secret_found" // Secret scanner matched in a saved file
+)
+
+// SecretInfo describes a secret found in a downloaded file
+type SecretInfo struct {
+	Type       string
+	Confidence string
+	Match      string
+	Line       int
+}
+
+// Event is a single lifecycle notification for a URL
+type Event struct {
+	Type   EventType
+	URL    string
+	Path   string         // Saved file (succeeded, secret_found)
+	Error  *DownloadError // Failure details (failed, skipped)
+	Secret *SecretInfo    // Secret details (secret_found)
+	Time   time.Time
+}
+
+// Observer receives events as they happen. Downloads run on several
+// workers, so implementations must be safe for concurrent use.
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc adapts a plain function to the Observer interface
+type ObserverFunc func(Event)
+
+// OnEvent calls f(e)
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+// ResultEvent builds the final event for a finished download result
+func ResultEvent(r *DownloadResult) Event {
+	e := Event{URL: r.URL, Time: time.Now()}
+	switch {
+	case r.IsSuccess():
+		e.Type = EventSucceeded
+		e.Path = r.Downloaded[0]
+	case len(r.Failures) > 0 && r.Failures[0].Category == ErrorCategorySkipped:
+		e.Type = EventSkipped
+		e.Error = &r.Failures[0]
+	default:
+		e.Type = EventFailed
+		if len(r.Failures) > 0 {
+			e.Error = &r.Failures[0]
+		}
+	}
+	return e
+}
@@ -2,13 +2,55 @@ package models
 
 import "time"
 
+// ErrorCategory classifies why a download failed
+type ErrorCategory string
+
+const (
+	ErrorCategoryHTTP      ErrorCategory = "http"      // Server answered with another non-2xx status (1xx, 3xx)
+	ErrorCategoryHTTP4xx   ErrorCategory = "http_4xx"  // Server answered with a client error status
+	ErrorCategoryHTTP5xx   ErrorCategory = "http_5xx"  // Server answered with a server error status
+	ErrorCategoryTimeout   ErrorCategory = "timeout"   // Request or connection timed out
+	ErrorCategoryDNS       ErrorCategory = "dns"       // Host name could not be resolved
+	ErrorCategoryRefused   ErrorCategory = "refused"   // Connection refused
+	ErrorCategoryNetwork   ErrorCategory = "network"   // Connection reset, unreachable, cut off mid-body, etc.
+	ErrorCategoryTLS       ErrorCategory = "tls"       // Handshake or certificate verification failed
+	ErrorCategoryCancelled ErrorCategory = "cancelled" // Download was cancelled before completing
+	ErrorCategorySkipped   ErrorCategory = "skipped"   // Download was skipped by a filter
+	ErrorCategoryScope     ErrorCategory = "scope"     // Host resolved outside the allowed scope
+	ErrorCategoryChecksum  ErrorCategory = "checksum"  // Body did not match its expected digest
+	ErrorCategoryOther     ErrorCategory = "other"     // Anything else (storage, size limits, ...)
+)
+
+// DownloadError is the structured form of a single download failure
+type DownloadError struct {
+	Category   ErrorCategory `json:"category"`
+	Message    string        `json:"message"`
+	StatusCode int           `json:"status_code,omitempty"`
+}
+
 // DownloadResult represents the result of downloading a file from a URL
 type DownloadResult struct {
-	URL        string        // Original URL
-	Host       string        // Hostname extracted from URL
-	Downloaded []string      // List of successfully downloaded file paths
-	Errors     []string      // List of error messages
-	Duration   time.Duration // Time taken to download
+	URL         string          // Original URL
+	Host        string          // Hostname extracted from URL
+	Downloaded  []string        // List of successfully downloaded file paths
+	Bytes       int64           // Bytes written to those files
+	Errors      []string        // List of error messages
+	Failures    []DownloadError // Structured form of Errors, one entry per message
+	Duration    time.Duration   // Time taken to download
+	Partial     bool            // Only a preview (the first bytes) of the file was saved
+	ContentType string          // Content-Type declared by the server ("" if none or served from cache)
+	Verified    bool            // Body matched its digest in the --verify-hashes file
+	Planned     bool            // --dry-run: nothing was downloaded; Downloaded holds the target path
+	PlannedSize int64           // --dry-run: size the server declared (-1 = unknown)
+
+	RequestHeaders map[string]string // Headers of the last request sent, credentials redacted (with --report-request-headers)
+	AuthType       string            // Authentication the client applied: none, bearer, basic, custom (with --report-request-headers)
+}
+
+// AddError records a failure both as a plain message and in structured form
+func (r *DownloadResult) AddError(e DownloadError) {
+	r.Errors = append(r.Errors, e.Message)
+	r.Failures = append(r.Failures, e)
 }
 
 // Summary returns a summary of the download result
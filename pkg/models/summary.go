@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// RunSummary aggregates download results as they arrive, so a run's totals
+// can be reported without keeping every result in memory
+type RunSummary struct {
+	Total         int                   // Results seen
+	Successful    int                   // Results with files and no errors
+	Failed        int                   // All other results
+	Skipped       int                   // Failed results that were only skipped by a filter
+	Downloaded    int                   // Files saved
+	Bytes         int64                 // Bytes written to the saved files
+	Errors        int                   // Error messages recorded
+	TotalDuration time.Duration         // Sum of per-URL durations
+	ByCategory    map[ErrorCategory]int // Failures by category
+}
+
+// Add folds one result into the summary
+func (s *RunSummary) Add(r *DownloadResult) {
+	if s.ByCategory == nil {
+		s.ByCategory = make(map[ErrorCategory]int)
+	}
+
+	s.Total++
+	if r.IsSuccess() {
+		s.Successful++
+	} else {
+		s.Failed++
+	}
+	s.Downloaded += len(r.Downloaded)
+	s.Bytes += r.Bytes
+	s.Errors += len(r.Errors)
+	s.TotalDuration += r.Duration
+
+	skipped := len(r.Failures) > 0 && len(r.Failures) == len(r.Errors)
+	for _, f := range r.Failures {
+		s.ByCategory[f.Category]++
+		skipped = skipped && f.Category == ErrorCategorySkipped
+	}
+	if skipped && !r.IsSuccess() {
+		s.Skipped++
+	}
+	// Errors recorded without structured details fall back to "other"
+	if extra := len(r.Errors) - len(r.Failures); extra > 0 {
+		s.ByCategory[ErrorCategoryOther] += extra
+	}
+}
+
+// SuccessRate returns the percentage of results that succeeded, leaving out
+// the ones skipped by a filter; with nothing attempted it is 100
+func (s *RunSummary) SuccessRate() float64 {
+	attempted := s.Total - s.Skipped
+	if attempted <= 0 {
+		return 100
+	}
+	return float64(s.Successful) / float64(attempted) * 100
+}
+
+// AvgDuration returns the mean per-URL duration
+func (s *RunSummary) AvgDuration() time.Duration {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.TotalDuration / time.Duration(s.Total)
+}
+
+// Summarize builds a summary from a complete result list
+func Summarize(results []DownloadResult) RunSummary {
+	var s RunSummary
+	for i := range results {
+		s.Add(&results[i])
+	}
+	return s
+}